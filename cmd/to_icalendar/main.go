@@ -1,15 +1,22 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
 
 	"github.com/allanpk716/to_icalendar/pkg/app"
+	"github.com/allanpk716/to_icalendar/pkg/clipboard"
 	"github.com/allanpk716/to_icalendar/pkg/commands"
+	"github.com/allanpk716/to_icalendar/pkg/config"
 	"github.com/allanpk716/to_icalendar/pkg/logger"
 	svcs "github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/timezone"
+	"golang.org/x/term"
 )
 
 const (
@@ -46,7 +53,19 @@ func main() {
 
 	// init 命令使用独立处理路径
 	if command == "init" {
-		handleInitDirect()
+		handleInitDirect(os.Args[2:])
+		return
+	}
+
+	// parse-check 只使用本地解析器，不需要配置文件
+	if command == "parse-check" {
+		handleParseCheck(os.Args[2:])
+		return
+	}
+
+	// config 只读取配置文件，配置无效时也能查看
+	if command == "config" {
+		handleConfig(os.Args[2:])
 		return
 	}
 
@@ -134,6 +153,137 @@ func main() {
 			os.Exit(1)
 		}
 		cleanCmd.ShowResult(resp.Data, resp.Metadata)
+	case "sync":
+		// 从 Microsoft Todo 增量同步任务
+		syncCmd := commands.NewSyncCommand(container)
+		listNames, fullSync := parseSyncOptions(os.Args[2:])
+		req := &commands.CommandRequest{
+			Command: "sync",
+			Args: map[string]interface{}{
+				"lists": listNames,
+				"full":  fullSync,
+			},
+		}
+		resp, err := syncCmd.Execute(ctx, req)
+		if err != nil {
+			logger.Errorf("命令执行失败: %v", err)
+			os.Exit(1)
+		}
+		if !resp.Success {
+			logger.Errorf("命令执行失败: %s", resp.Error)
+			os.Exit(1)
+		}
+		syncCmd.ShowResult(resp.Data, resp.Metadata)
+	case "upload":
+		// 通过 Graph 批处理批量上传提醒事项文件
+		uploadCmd := commands.NewUploadCommand(container)
+		if err := uploadCmd.Validate(os.Args[2:]); err != nil {
+			logger.Errorf("参数错误: %v", err)
+			os.Exit(1)
+		}
+		req := &commands.CommandRequest{
+			Command: "upload",
+			Args: map[string]interface{}{
+				"patterns": os.Args[2:],
+			},
+		}
+		resp, err := uploadCmd.Execute(ctx, req)
+		if err != nil {
+			logger.Errorf("命令执行失败: %v", err)
+			os.Exit(1)
+		}
+		if !resp.Success {
+			logger.Errorf("命令执行失败: %s", resp.Error)
+			os.Exit(1)
+		}
+		uploadCmd.ShowResult(resp.Data, resp.Metadata)
+	case "watch":
+		// 监听剪贴板，Ctrl+C 停止
+		watchCmd := commands.NewWatchCommand(container)
+		req := &commands.CommandRequest{
+			Command: "watch",
+			Args: map[string]interface{}{
+				"trigger": parseWatchOptions(os.Args[2:]),
+			},
+		}
+		watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		stopMetrics := startWatchMetrics(container)
+		startConfigReload(container)
+		resp, err := watchCmd.Execute(watchCtx, req)
+		stopMetrics()
+		stop()
+		if err != nil {
+			logger.Errorf("命令执行失败: %v", err)
+			os.Exit(1)
+		}
+		if !resp.Success {
+			logger.Errorf("命令执行失败: %s", resp.Error)
+			os.Exit(1)
+		}
+		watchCmd.ShowResult(resp.Data, resp.Metadata)
+	case "replay":
+		// 重放保存的任务会话
+		replayCmd := commands.NewReplayCommand(container)
+		if err := replayCmd.Validate(os.Args[2:]); err != nil {
+			logger.Errorf("参数错误: %v", err)
+			os.Exit(1)
+		}
+		req := &commands.CommandRequest{
+			Command: "replay",
+			Args:    parseReplayOptions(os.Args[2:]),
+		}
+		resp, err := replayCmd.Execute(ctx, req)
+		if err != nil {
+			logger.Errorf("命令执行失败: %v", err)
+			os.Exit(1)
+		}
+		if !resp.Success {
+			logger.Errorf("命令执行失败: %s", resp.Error)
+			os.Exit(1)
+		}
+		replayCmd.ShowResult(resp.Data, resp.Metadata)
+	case "cache":
+		// 查看缓存用量和配额
+		cacheCmd := commands.NewCacheCommand(container)
+		if err := cacheCmd.Validate(os.Args[2:]); err != nil {
+			logger.Errorf("参数错误: %v", err)
+			os.Exit(1)
+		}
+		req := &commands.CommandRequest{
+			Command: "cache",
+			Args:    parseCacheOptions(os.Args[2:]),
+		}
+		resp, err := cacheCmd.Execute(ctx, req)
+		if err != nil {
+			logger.Errorf("命令执行失败: %v", err)
+			os.Exit(1)
+		}
+		if !resp.Success {
+			logger.Errorf("命令执行失败: %s", resp.Error)
+			os.Exit(1)
+		}
+		cacheCmd.ShowResult(resp.Data, resp.Metadata)
+	case "stats":
+		// 查看处理指标统计
+		statsCmd := commands.NewStatsCommand(container)
+		if err := statsCmd.Validate(os.Args[2:]); err != nil {
+			logger.Errorf("参数错误: %v", err)
+			os.Exit(1)
+		}
+		req := &commands.CommandRequest{
+			Command: "stats",
+			Args:    parseStatsOptions(os.Args[2:]),
+		}
+		resp, err := statsCmd.Execute(ctx, req)
+		if err != nil {
+			logger.Errorf("命令执行失败: %v", err)
+			os.Exit(1)
+		}
+		if !resp.Success {
+			logger.Errorf("命令执行失败: %s", resp.Error)
+			os.Exit(1)
+		}
+		statsCmd.ShowResult(resp.Data, resp.Metadata)
 		case "help", "-h", "--help":
 		showUsage()
 	default:
@@ -197,10 +347,323 @@ func parseCleanOptions(args []string) CleanOptions {
 	return options
 }
 
+// parseSyncOptions 解析同步命令选项
+func parseSyncOptions(args []string) ([]string, bool) {
+	var listNames []string
+	fullSync := false
+
+	for i, arg := range args {
+		switch arg {
+		case "--list":
+			if i+1 < len(args) {
+				listNames = append(listNames, args[i+1])
+			}
+		case "--full":
+			fullSync = true
+		}
+	}
+
+	return listNames, fullSync
+}
+
+// parseWatchOptions 解析监听命令选项，返回覆盖的触发条件
+func parseWatchOptions(args []string) string {
+	for i, arg := range args {
+		if arg == "--trigger" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// startWatchMetrics 启用 metrics 时为 watch 模式启动 Token 刷新、剪贴板健康检查和 /metrics 端点
+// 返回的函数用于停止后台检查，端点在应用关闭时停止
+func startWatchMetrics(container commands.ServiceContainer) func() {
+	sc, ok := container.(*app.ServiceContainer)
+	if !ok || sc.GetConfig() == nil || !sc.GetConfig().Metrics.Enabled {
+		return func() {}
+	}
+
+	// Token 状态只在管理器运行时更新
+	tokenRefresher := sc.GetTokenRefresherService()
+	if err := tokenRefresher.Start(); err != nil {
+		logger.Warnf("启动 Token 刷新服务失败: %v", err)
+	}
+
+	healthChecker := clipboard.NewClipboardHealthChecker(0)
+	stopCh := make(chan struct{})
+	go healthChecker.StartPeriodicCheck(stopCh)
+
+	if err := sc.StartMetricsServer(healthChecker); err != nil {
+		logger.Warnf("启动指标端点失败: %v", err)
+	}
+
+	return func() {
+		close(stopCh)
+		if err := tokenRefresher.Stop(); err != nil {
+			logger.Warnf("停止 Token 刷新服务失败: %v", err)
+		}
+	}
+}
+
+// startConfigReload 为 watch 模式开启配置热加载，监听停止时随应用关闭
+// 剪贴板监听参数（watch 段）在监听开始时读取，修改后需要重新运行 watch
+func startConfigReload(container commands.ServiceContainer) {
+	sc, ok := container.(*app.ServiceContainer)
+	if !ok {
+		return
+	}
+
+	serverConfigPath, _ := config.ConfigFilePaths(sc.GetConfigDir())
+	sc.WatchConfig(serverConfigPath, func(result *app.ReloadResult) {
+		if result.Err == nil && result.HasChanged("watch") {
+			logger.Warn("⚠️  watch 配置已修改，重新运行 watch 命令后生效")
+		}
+	})
+}
+
+// parseReplayOptions 解析重放命令选项
+func parseReplayOptions(args []string) map[string]interface{} {
+	options := map[string]interface{}{
+		"reparse":  false,
+		"resubmit": false,
+		"backend":  "",
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--reparse":
+			options["reparse"] = true
+		case "--resubmit":
+			options["resubmit"] = true
+		case "--backend":
+			if i+1 < len(args) {
+				options["backend"] = args[i+1]
+				i++
+			}
+		default:
+			options["task_id"] = args[i]
+		}
+	}
+
+	return options
+}
+
+// parseCacheOptions 解析缓存命令选项
+func parseCacheOptions(args []string) map[string]interface{} {
+	options := map[string]interface{}{
+		"action":  commands.CacheActionStats,
+		"by_type": false,
+	}
+
+	for _, arg := range args {
+		if arg == "--by-type" {
+			options["by_type"] = true
+		} else {
+			options["action"] = arg
+		}
+	}
+
+	return options
+}
+
+// parseStatsOptions 解析统计命令选项
+func parseStatsOptions(args []string) map[string]interface{} {
+	options := map[string]interface{}{
+		"since": commands.DefaultStatsSince,
+	}
+
+	for i, arg := range args {
+		switch {
+		case arg == "--since":
+			if i+1 < len(args) {
+				options["since"] = args[i+1]
+			}
+		case strings.HasPrefix(arg, "--since="):
+			options["since"] = strings.TrimPrefix(arg, "--since=")
+		}
+	}
+
+	return options
+}
+
+// handleParseCheck 使用统一的响应解析器解析一个 Dify 回答样本
+func handleParseCheck(args []string) {
+	parseCheckCmd := commands.NewParseCheckCommand()
+	if err := parseCheckCmd.Validate(args); err != nil {
+		logger.Errorf("参数错误: %v", err)
+		os.Exit(1)
+	}
+
+	reqArgs := map[string]interface{}{}
+	for _, arg := range args {
+		if arg == "--ics" {
+			reqArgs["ics"] = true
+		} else {
+			reqArgs["file"] = arg
+		}
+	}
+	req := &commands.CommandRequest{
+		Command: "parse-check",
+		Args:    reqArgs,
+	}
+	resp, err := parseCheckCmd.Execute(context.Background(), req)
+	if err != nil {
+		logger.Errorf("命令执行失败: %v", err)
+		os.Exit(1)
+	}
+	if !resp.Success {
+		logger.Errorf("命令执行失败: %s", resp.Error)
+		os.Exit(1)
+	}
+	parseCheckCmd.ShowResult(resp.Data, resp.Metadata)
+}
+
+// handleConfig 处理配置命令：显示、校验、迁移配置文件或输出 JSON Schema
+func handleConfig(args []string) {
+	configCmd := commands.NewConfigCommand()
+	if err := configCmd.Validate(args); err != nil {
+		logger.Errorf("参数错误: %v", err)
+		os.Exit(1)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logger.Errorf("❌ 获取用户目录失败: %v", err)
+		os.Exit(1)
+	}
+
+	reqArgs := parseConfigOptions(args)
+	reqArgs["config_dir"] = filepath.Join(homeDir, ".to_icalendar")
+	req := &commands.CommandRequest{
+		Command: "config",
+		Args:    reqArgs,
+	}
+	resp, err := configCmd.Execute(context.Background(), req)
+	if err != nil {
+		logger.Errorf("命令执行失败: %v", err)
+		os.Exit(1)
+	}
+	if !resp.Success {
+		logger.Errorf("命令执行失败: %s", resp.Error)
+		os.Exit(1)
+	}
+	configCmd.ShowResult(resp.Data, resp.Metadata)
+
+	// 校验发现错误时以非零状态退出，便于在脚本中使用
+	if result, ok := resp.Data.(*commands.ConfigValidateResult); ok && result.HasErrors() {
+		os.Exit(1)
+	}
+}
+
+// parseConfigOptions 解析配置命令选项
+func parseConfigOptions(args []string) map[string]interface{} {
+	options := map[string]interface{}{
+		"action":   commands.ConfigActionShow,
+		"resolved": false,
+		"redacted": false,
+		"dry_run":  false,
+	}
+
+	var positional []string
+	for _, arg := range args {
+		switch arg {
+		case "--resolved":
+			options["resolved"] = true
+		case "--redacted":
+			options["redacted"] = true
+		case "--dry-run":
+			options["dry_run"] = true
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) > 0 {
+		options["action"] = positional[0]
+	}
+	if len(positional) > 1 {
+		options["target"] = positional[1]
+	}
+
+	return options
+}
+
+// initField 交互式初始化时询问的配置项
+type initField struct {
+	key      string
+	prompt   string
+	required bool
+	secret   bool
+	check    func(string) error
+}
+
+// initFields 交互式初始化依次询问的配置项
+var initFields = []initField{
+	{key: "microsoft_todo.tenant_id", prompt: "Azure 租户 ID", required: true},
+	{key: "microsoft_todo.client_id", prompt: "应用程序客户端 ID", required: true},
+	{key: "microsoft_todo.client_secret", prompt: "客户端密钥（也可填写 ${ENV_VAR}、file:路径 或 cmd:命令）", required: true, secret: true},
+	{key: "microsoft_todo.timezone", prompt: "时区（如 Asia/Shanghai）", check: checkTimezone},
+	{key: "reminder.default_list", prompt: "默认任务列表（留空使用默认列表）"},
+	{key: "dify.api_endpoint", prompt: "Dify API 端点（可选）"},
+	{key: "dify.api_key", prompt: "Dify API 密钥（可选）", secret: true},
+}
+
+// initPlaceholders 配置模板中的占位符，交互式初始化时不作为默认值
+var initPlaceholders = map[string]bool{
+	"YOUR_TENANT_ID":     true,
+	"YOUR_CLIENT_ID":     true,
+	"YOUR_CLIENT_SECRET": true,
+}
+
+// InitOptions 初始化命令选项
+type InitOptions struct {
+	Interactive bool
+	Values      map[string]string // --set 指定的配置项
+	NoVerify    bool
+}
+
+// parseInitOptions 解析初始化命令选项
+func parseInitOptions(args []string) (*InitOptions, error) {
+	options := &InitOptions{Values: make(map[string]string)}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--interactive" || arg == "-i":
+			options.Interactive = true
+		case arg == "--no-verify":
+			options.NoVerify = true
+		case arg == "--set":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--set 需要 key=value 参数")
+			}
+			i++
+			arg = "--set=" + args[i]
+			fallthrough
+		case strings.HasPrefix(arg, "--set="):
+			key, value, err := config.ParseSetValue(strings.TrimPrefix(arg, "--set="))
+			if err != nil {
+				return nil, err
+			}
+			options.Values[key] = value
+		default:
+			return nil, fmt.Errorf("未知参数: %s", arg)
+		}
+	}
+
+	return options, nil
+}
+
 // handleInitDirect 独立处理 init 命令，不依赖应用初始化
-func handleInitDirect() {
+func handleInitDirect(args []string) {
 	logger.Info("🚀 初始化配置...")
 
+	options, err := parseInitOptions(args)
+	if err != nil {
+		logger.Errorf("参数错误: %v", err)
+		os.Exit(1)
+	}
+
 	// 获取用户配置目录
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -224,61 +687,24 @@ func handleInitDirect() {
 	}
 	logger.Debugf("配置目录创建成功: %s", configDir)
 
+	// 交互式或 --set 方式直接写入配置项
+	if options.Interactive || len(options.Values) > 0 {
+		initWithValues(serverConfigPath, options)
+		return
+	}
+
 	// 检查文件是否已存在
 	logger.Debug("检查配置文件是否已存在...")
 	if _, err := os.Stat(serverConfigPath); err == nil {
 		logger.Warnf("⚠️  配置文件已存在: %s", serverConfigPath)
-		logger.Info("如需重新生成，请先删除现有配置文件")
+		logger.Infof("如需修改配置项，请运行 '%s init --interactive' 或 '%s init --set key=value'", appName, appName)
+		logger.Infof("升级旧版本配置请运行 '%s config migrate'", appName)
 		return
 	}
 
-	// 创建默认 server.yaml 内容
-	logger.Debug("创建默认配置文件内容...")
-	serverConfigContent := `# Microsoft Todo 配置
-microsoft_todo:
-  tenant_id: "YOUR_TENANT_ID"          # Azure 租户 ID
-  client_id: "YOUR_CLIENT_ID"        # 应用程序客户端 ID
-  client_secret: "YOUR_CLIENT_SECRET"  # 客户端密钥
-  user_email: ""                     # 目标用户邮箱（可选）
-  timezone: "Asia/Shanghai"          # 时区设置
-
-# 提醒配置
-reminder:
-  default_remind_before: "15m"       # 默认提前提醒时间
-  enable_smart_reminder: true        # 启用智能提醒功能
-
-# 去重配置
-deduplication:
-  enabled: true                      # 启用去重功能
-  time_window_minutes: 5              # 时间匹配窗口（分钟）
-  similarity_threshold: 80            # 相似度阈值（0-100）
-  check_incomplete_only: true         # 只检查未完成的任务
-  enable_local_cache: true            # 启用本地缓存
-  enable_remote_query: true           # 启用远程查询
-
-# Dify AI 配置（可选）
-dify:
-  api_endpoint: ""                   # Dify API 端点
-  api_key: ""                        # Dify API 密钥
-  timeout: 60                        # 请求超时时间（秒）
-
-# 缓存配置
-cache:
-  auto_cleanup_days: 30              # 自动清理天数
-  cleanup_on_startup: true           # 启动时清理
-  preserve_successful_hashes: true   # 保留成功哈希记录
-
-# 日志配置
-logging:
-  level: "info"                      # 日志级别
-  console_output: true               # 控制台输出
-  file_output: true                  # 文件输出
-  log_dir: "./Logs"                  # 日志目录
-`
-
-	// 写入配置文件
+	// 写入带注释的配置模板和 JSON Schema
 	logger.Debug("写入配置文件...")
-	if err := os.WriteFile(serverConfigPath, []byte(serverConfigContent), 0600); err != nil {
+	if err := config.NewConfigManager().CreateServerConfigTemplate(serverConfigPath); err != nil {
 		logger.Errorf("❌ 创建配置文件失败: %v", err)
 		os.Exit(1)
 	}
@@ -297,12 +723,228 @@ logging:
 	logger.Info("💡 获取 Azure AD 配置信息：")
 	logger.Info("   1. 访问 https://portal.azure.com")
 	logger.Info("   2. 注册新应用程序或选择现有应用")
-	logger.Info("   3. 配置 API 权限：Tasks.ReadWrite.All")
+	logger.Info("   3. 配置 API 权限：Tasks.ReadWrite、Calendars.ReadWrite")
 	logger.Info("   4. 创建客户端密钥")
 	logger.Info("")
+	logger.Infof("💡 也可以运行 '%s init --interactive' 按提示填写并验证连接", appName)
+	logger.Info("💡 编辑后可运行 'to_icalendar config validate' 检查配置")
 	logger.Info("🎉 配置完成后，运行 'to_icalendar test' 测试连接")
 }
 
+// initWithValues 按交互输入或 --set 的值写入配置文件，已有配置文件时只修改变化的配置项并保留注释
+// 写入后校验配置，并像 test 命令一样验证连接（首次连接 Microsoft Todo 时会进行登录授权）
+func initWithValues(serverConfigPath string, options *InitOptions) {
+	data, err := os.ReadFile(serverConfigPath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		logger.Errorf("❌ 读取配置文件失败: %v", err)
+		os.Exit(1)
+	}
+	if !exists {
+		data = config.ServerConfigTemplate()
+	}
+
+	values := options.Values
+	if options.Interactive {
+		reader := bufio.NewReader(os.Stdin)
+		if exists {
+			logger.Infof("⚙️  配置文件已存在: %s", serverConfigPath)
+			if !promptYesNo(reader, "只更新修改的配置项并保留其余内容和注释？", true) {
+				logger.Info("已取消")
+				return
+			}
+		}
+		values, err = promptInitValues(reader, data, options.Values)
+		if err != nil {
+			logger.Errorf("❌ 读取输入失败: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	updated, changed, err := config.SetServerConfigValues(data, values)
+	if err != nil {
+		logger.Errorf("❌ 更新配置失败: %v", err)
+		os.Exit(1)
+	}
+
+	// 写入前校验，校验失败时不修改已有的配置文件
+	issues := config.ValidateServerConfigData(updated)
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			logger.Errorf("   %s", issue.String())
+		} else {
+			logger.Warnf("   %s", issue.String())
+		}
+	}
+	if config.HasErrors(issues) {
+		logger.Errorf("❌ 配置校验失败，未写入 %s，请修正后重新运行", serverConfigPath)
+		os.Exit(1)
+	}
+
+	// 新建时同时写入 JSON Schema
+	if !exists {
+		dir := filepath.Dir(serverConfigPath)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			logger.Errorf("❌ 创建配置目录失败: %v", err)
+			os.Exit(1)
+		}
+		if err := config.WriteSchemaFiles(dir); err != nil {
+			logger.Errorf("❌ 写入配置 Schema 失败: %v", err)
+			os.Exit(1)
+		}
+	}
+	if !exists || len(changed) > 0 {
+		// 原子替换，已有文件保留原来的权限
+		if err := config.WriteFileAtomic(serverConfigPath, updated, 0600); err != nil {
+			logger.Errorf("❌ 写入配置文件失败: %v", err)
+			os.Exit(1)
+		}
+	}
+	if len(changed) > 0 {
+		logger.Infof("✅ 已更新 %d 个配置项: %s", len(changed), strings.Join(changed, ", "))
+	} else {
+		logger.Info("✅ 配置项没有变化")
+	}
+	logger.Infof("⚙️  服务器配置文件: %s", serverConfigPath)
+
+	if options.NoVerify {
+		logger.Infof("🎉 配置完成，运行 '%s test' 测试连接", appName)
+		return
+	}
+	if !verifyInitConfig() {
+		os.Exit(1)
+	}
+	logger.Info("🎉 配置完成！")
+}
+
+// promptInitValues 依次询问 initFields 中的配置项，回车保留当前值；--set 指定的值作为默认值
+func promptInitValues(reader *bufio.Reader, data []byte, preset map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(preset)+len(initFields))
+	for key, value := range preset {
+		values[key] = value
+	}
+
+	logger.Info("📝 请输入配置项，直接回车保留方括号中的当前值")
+	for _, field := range initFields {
+		defaultValue, ok := preset[field.key]
+		if !ok {
+			defaultValue = config.ServerConfigValue(data, field.key)
+		}
+		if initPlaceholders[defaultValue] {
+			defaultValue = ""
+		}
+
+		for {
+			shown := defaultValue
+			// 当前密钥以掩码显示，引用（file:、cmd:、${VAR}）本身不含密钥，原样显示
+			if field.secret && shown != "" && !config.IsSecretRef(shown) && !strings.Contains(shown, "${") {
+				shown = config.RedactedValue
+			}
+			if shown != "" {
+				fmt.Printf("%s [%s]: ", field.prompt, shown)
+			} else {
+				fmt.Printf("%s: ", field.prompt)
+			}
+
+			line, err := readInitLine(reader, field.secret)
+			if err != nil && line == "" {
+				return nil, err
+			}
+			value := strings.TrimSpace(line)
+			if value == "" {
+				value = defaultValue
+			}
+
+			if value == "" && field.required {
+				fmt.Println("  该项必须填写")
+				continue
+			}
+			if field.check != nil {
+				if err := field.check(value); err != nil {
+					fmt.Printf("  %v\n", err)
+					continue
+				}
+			}
+			values[field.key] = value
+			break
+		}
+	}
+
+	return values, nil
+}
+
+// readInitLine 读取一行输入；标准输入是终端时密钥输入不回显，管道输入按行读取
+func readInitLine(reader *bufio.Reader, secret bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !secret || !term.IsTerminal(fd) {
+		return reader.ReadString('\n')
+	}
+
+	line, err := term.ReadPassword(fd)
+	fmt.Println()
+	return string(line), err
+}
+
+// promptYesNo 询问是否继续，直接回车使用 defaultYes
+func promptYesNo(reader *bufio.Reader, question string, defaultYes bool) bool {
+	hint := "[y/N]"
+	if defaultYes {
+		hint = "[Y/n]"
+	}
+	fmt.Printf("%s %s: ", question, hint)
+
+	line, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// checkTimezone 校验时区名称
+func checkTimezone(value string) error {
+	if !timezone.IsValidTimezone(value) {
+		return fmt.Errorf("无效的时区: %s，请使用 IANA 时区名称（如 Asia/Shanghai）或 UTC", value)
+	}
+	return nil
+}
+
+// verifyInitConfig 加载新配置并运行 test 命令的检查，返回是否全部通过
+func verifyInitConfig() bool {
+	logger.Info("")
+	logger.Info("🔍 验证配置和连接...")
+	logger.Info("💡 尚未登录时，连接 Microsoft Todo 会启动授权流程，请按提示在浏览器中登录")
+
+	application := app.NewApplication()
+	ctx := context.Background()
+	if err := application.Initialize(ctx); err != nil {
+		logger.Errorf("❌ 加载配置失败: %v", err)
+		return false
+	}
+	defer application.Shutdown(ctx)
+
+	testCmd := commands.NewTestCommand(application.GetServiceContainer())
+	req := &commands.CommandRequest{
+		Command: "test",
+		Args:    make(map[string]interface{}),
+	}
+	resp, err := testCmd.Execute(ctx, req)
+	if err != nil {
+		logger.Errorf("命令执行失败: %v", err)
+		return false
+	}
+	if !resp.Success {
+		logger.Errorf("❌ 验证失败: %s", resp.Error)
+		logger.Infof("💡 修改配置后可重新运行 '%s init --interactive'，或运行 '%s test' 重新验证", appName, appName)
+		return false
+	}
+	testCmd.ShowTestResult(resp.Data, resp.Metadata)
+	return true
+}
+
 // handleInit 处理初始化命令
 func handleInit(container commands.ServiceContainer) {
 	ctx := context.Background()
@@ -354,13 +996,30 @@ func showUsage() {
   %s <command> [options]
 
 Commands:
-  init                    Initialize configuration files
+  init                    Initialize configuration files (--interactive for a guided setup)
   test                    Test service connection
   clip-upload             Process clipboard content and directly upload to Microsoft Todo
+  upload <files...>       Upload reminder JSON files (globs allowed) in Graph batches
   clean                   Clean cache files
+  sync                    Sync tasks from Microsoft Todo into the local cache
+  watch                   Watch the clipboard and process new content automatically
+  replay <task-id>        Re-run a stored task session (see ~/.to_icalendar/cache/tasks)
+  parse-check <file>      Parse a saved Dify answer and print the resulting reminder
+  cache [stats|evict]     Show cache usage against quotas, or evict over-quota entries now
+  stats                   Show processing metrics (success rate, Dify latency/tokens, Graph retries)
+  config show             Show server.yaml as parsed, or the effective configuration
+  config validate         Check server.yaml and image_processing.json, listing every problem with its line
+  config migrate          Upgrade config files (and files in legacy locations) to the current config_version
+  config schema [target]  Print the JSON Schema for server.yaml (server) or image_processing.json (image)
   help                    Show this help message
 
 Options:
+  Init command:
+    --interactive, -i       Prompt for credentials, timezone, default list and Dify, then verify and log in
+    --set key=value         Set a server.yaml key without prompting (repeatable, e.g. microsoft_todo.tenant_id=...)
+    --no-verify             Skip the connection checks after writing the file
+                            An existing server.yaml is updated in place: only changed keys, comments kept
+
   Clean command:
     --all                   Clean all cache types (default)
     --tasks                 Clean task deduplication cache only
@@ -373,17 +1032,57 @@ Options:
     --older-than 7d         Only clean files older than specified time (7d, 24h, 30m)
     --clear-all             Completely clear all cache data
 
+  Sync command:
+    --list <name>           Only sync the named list (repeatable, default: all lists)
+    --full                  Ignore the saved delta link and resync everything
+
+  Watch command:
+    --trigger <mode>        Override the configured trigger (any, text, image, text_with_date)
+
+  Replay command:
+    --reparse               Rebuild the reminder from the saved Dify response only
+    --backend <name>        Re-send the saved screenshot to a Dify backend (default or a dify_backends entry)
+    --resubmit              Create the rebuilt reminder in Microsoft Todo
+                            Without --reparse/--backend the saved response is reparsed when present
+
+  Parse-check command:
+    --ics                   Also print the reminder as an iCalendar VEVENT
+
+  Cache command:
+    --by-type               Show usage and quota for each cache type
+
+  Stats command:
+    --since 7d              Only include runs within the given time span (default 7d)
+
+  Config command:
+    --resolved              Expand ${ENV_VAR}, apply TO_ICALENDAR_* overrides and resolve file:/cmd: secrets
+    --redacted              Hide secret values (client_secret, api_key)
+    --dry-run               With migrate: show the changes without writing (a .v<N>.bak backup is kept otherwise)
+
 Examples:
   %s init                                          # Initialize configuration
+  %s init --interactive                            # Guided setup with connection check and login
+  %s init --set dify.api_key=xxx --no-verify       # Update one setting from a script
   %s test                                          # Test connection
   %s clip-upload                                   # Process clipboard and upload
   %s clean --all                                   # Clean all cache
   %s clean --dry-run                               # Preview files to be cleaned
+  %s sync --list Tasks                             # Sync one list from Microsoft Todo
+  %s upload reminders/*.json                       # Batch upload reminder files
+  %s watch --trigger text_with_date                # Auto-process copied text containing dates
+  %s replay 2025-03-10_091500_ab12cd --resubmit   # Reparse a stored task and upload it again
+  %s parse-check answer.txt                        # Check how a Dify answer is parsed
+  %s cache stats --by-type                         # Show cache usage against each quota
+  %s stats --since 30d                             # Show processing metrics for the last 30 days
+  %s config show --resolved --redacted             # Print the effective configuration without secrets
+  %s config validate                               # Report config problems with line numbers
+  %s config migrate --dry-run                      # Preview upgrading config files to the current version
 
 Configuration files:
   ~/.to_icalendar/server.yaml       Service configuration (Microsoft Todo & Dify)
   ~/.to_icalendar/reminder.json     Reminder template
+  ~/.to_icalendar/*.schema.json     JSON Schemas for editor completion (written by init and config migrate)
 
 For more information, see README.md
-`, appName, appName, appName, appName, appName)
+`, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName)
 }
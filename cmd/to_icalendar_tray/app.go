@@ -18,10 +18,15 @@ import (
 
 	"github.com/allanpk716/to_icalendar/pkg/app"
 	"github.com/allanpk716/to_icalendar/pkg/cache"
+	"github.com/allanpk716/to_icalendar/pkg/clipboard"
+	"github.com/allanpk716/to_icalendar/pkg/clipwatch"
 	"github.com/allanpk716/to_icalendar/pkg/commands"
 	"github.com/allanpk716/to_icalendar/pkg/config"
 	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/metrics"
+	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
 	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/task"
 	"github.com/allanpk716/to_icalendar/pkg/testing"
 	"github.com/getlantern/systray"
 	"gopkg.in/yaml.v3"
@@ -79,7 +84,11 @@ type TaskInfo struct {
 	EndTime   time.Time  `json:"end_time,omitempty"`
 }
 
-// TaskManager 任务管理器
+// finishedTaskTTL 已结束的任务在内存中保留的时间，供前端轮询最终状态
+// 任务历史由 pkg/task 持久化，超过该时间后 GetTaskStatus 从磁盘读取
+const finishedTaskTTL = time.Minute
+
+// TaskManager 跟踪正在处理的任务进度
 type TaskManager struct {
 	tasks map[string]*TaskInfo
 	mutex sync.RWMutex
@@ -132,6 +141,9 @@ func (tm *TaskManager) UpdateTask(taskID string, status TaskStatus, progress int
 
 		if status == TaskStatusCompleted || status == TaskStatusFailed {
 			task.EndTime = time.Now()
+			time.AfterFunc(finishedTaskTTL, func() {
+				tm.RemoveTask(taskID)
+			})
 		}
 
 		// 发射状态变化事件
@@ -145,7 +157,25 @@ func (tm *TaskManager) UpdateTask(taskID string, status TaskStatus, progress int
 	}
 }
 
-// generateTaskID 生成任务ID
+// FailedError 任务处理失败时返回包含失败步骤和原因的错误，成功或任务不存在时返回 nil
+func (tm *TaskManager) FailedError(taskID string) error {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	if task, exists := tm.tasks[taskID]; exists && task.Status == TaskStatusFailed {
+		return fmt.Errorf("%s: %s", task.Step, task.Error)
+	}
+	return nil
+}
+
+// RemoveTask 从内存中移除任务
+func (tm *TaskManager) RemoveTask(taskID string) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	delete(tm.tasks, taskID)
+}
+
+// generateTaskID 生成任务ID，仅在无法创建持久化任务会话时使用
 func generateTaskID() string {
 	bytes := make([]byte, 8)
 	rand.Read(bytes)
@@ -186,6 +216,17 @@ type App struct {
 	oauthState       string          // OAuth state 参数
 	oauthCodeVerifier string         // PKCE code verifier
 	oauthMutex       sync.RWMutex    // OAuth 操作互斥锁
+	// 剪贴板监听相关字段
+	clipWatcher      *clipwatch.Watcher // 剪贴板监听器
+	clipWatchCancel  context.CancelFunc // 停止监听，为 nil 表示未在监听
+	clipWatchMutex   sync.Mutex         // 剪贴板监听互斥锁
+	// 托盘菜单相关字段
+	profile          string             // 当前配置名称，空表示 default (server.yaml)
+	watcherMenuItems []*systray.MenuItem // 监听开关菜单项
+	profileMenuItems []profileMenuItem   // 切换配置子菜单项
+	trayMenuMutex    sync.Mutex          // 托盘菜单互斥锁
+	// 剪贴板健康检查，供 /metrics 端点使用
+	clipboardHealth  *clipboard.ClipboardHealthChecker
 }
 
 // NewApp 创建应用
@@ -195,6 +236,7 @@ func NewApp(icon []byte) *App {
 		isWindowVisible: false,
 		isQuitting:      false,
 		quitDone:        make(chan struct{}),
+		clipboardHealth: clipboard.NewClipboardHealthChecker(0),
 	}
 }
 
@@ -204,6 +246,9 @@ func (a *App) OnStartup(ctx context.Context) {
 	a.taskManager = NewTaskManager(ctx)
 	a.isWindowVisible = true  // 启动时窗口可见
 
+	// 定期检查剪贴板健康状态，应用关闭时停止
+	go a.clipboardHealth.StartPeriodicCheck(ctx.Done())
+
 	// 初始化服务容器
 	if err := a.InitializeServiceContainer(); err != nil {
 		logger.Errorf("初始化服务容器失败: %v", err)
@@ -238,6 +283,9 @@ func (a *App) OnBeforeClose(ctx context.Context) (prevent bool) {
 
 // OnShutdown 关闭
 func (a *App) OnShutdown(ctx context.Context) {
+	if a.serviceContainer != nil {
+		a.serviceContainer.Close()
+	}
 	systray.Quit()
 }
 
@@ -272,23 +320,35 @@ func (a *App) startTokenRefresher() {
 	}
 }
 
-// InitializeServiceContainer 初始化服务容器
-func (a *App) InitializeServiceContainer() error {
-	// 获取配置目录 ~/.to_icalendar
+// getConfigDir 获取配置目录 ~/.to_icalendar
+func getConfigDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("获取用户目录失败: %w", err)
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(homeDir, ".to_icalendar"), nil
+}
+
+// InitializeServiceContainer 初始化服务容器，使用当前选择的配置
+func (a *App) InitializeServiceContainer() error {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return err
 	}
-	configDir := filepath.Join(homeDir, ".to_icalendar")
 
 	// 加载CLI版本的配置
 	configManager := config.NewConfigManager()
-	serverConfigPath := filepath.Join(configDir, "server.yaml")
+	serverConfigPath := filepath.Join(configDir, profileFileName(a.profile))
 	serverConfig, err := configManager.LoadServerConfig(serverConfigPath)
 	if err != nil {
 		return fmt.Errorf("加载配置文件失败: %w", err)
 	}
 
+	// 按配置文件设置日志级别和格式
+	if err := logger.Initialize(&serverConfig.Logging); err != nil {
+		logger.Errorf("初始化日志系统失败: %v", err)
+	}
+
 	// 初始化缓存管理器
 	cacheManager, err := cache.NewUnifiedCacheManager(configDir, logger.GetLogger().GetStdLogger())
 	if err != nil {
@@ -306,6 +366,11 @@ func (a *App) InitializeServiceContainer() error {
 		}
 	}
 
+	// 停止旧服务容器的后台任务
+	if a.serviceContainer != nil {
+		a.serviceContainer.Close()
+	}
+
 	// 创建服务容器
 	a.serviceContainer = app.NewServiceContainer(
 		configDir,
@@ -314,10 +379,60 @@ func (a *App) InitializeServiceContainer() error {
 		logger.GetLogger(),
 	)
 
+	// 按配置启动 /metrics 端点，失败不影响其他功能
+	if err := a.serviceContainer.StartMetricsServer(a.clipboardHealth); err != nil {
+		logger.Warnf("启动指标端点失败: %v", err)
+	}
+
+	// 配置文件修改后热加载，切换配置或重新初始化时随旧容器停止
+	container := a.serviceContainer
+	container.WatchConfig(serverConfigPath, func(result *app.ReloadResult) {
+		a.onConfigReloaded(container, result)
+	})
+
 	a.config = serverConfig
 	return nil
 }
 
+// onConfigReloaded 配置热加载后更新当前配置并通知前端，新配置无效时继续使用上一次有效的配置
+func (a *App) onConfigReloaded(container *app.ServiceContainer, result *app.ReloadResult) {
+	if result.Err != nil {
+		a.sendClipboardLog("error", fmt.Sprintf("配置热加载失败，继续使用上一次有效的配置: %v", result.Err))
+		wailsRuntime.EventsEmit(a.ctx, "configReloaded", map[string]interface{}{
+			"success": false,
+			"path":    result.Path,
+			"error":   result.Err.Error(),
+			"issues":  result.Issues,
+		})
+		return
+	}
+	if len(result.Changed) == 0 {
+		return
+	}
+
+	a.config = container.GetConfig()
+
+	// 剪贴板监听参数在开始监听时读取，正在监听时按新配置重新开始
+	if result.HasChanged("watch") && a.IsClipboardWatching() {
+		a.StopClipboardWatch()
+		if err := a.StartClipboardWatch(); err != nil {
+			a.sendClipboardLog("error", fmt.Sprintf("按新配置重新开启剪贴板监听失败: %v", err))
+		}
+	}
+
+	message := fmt.Sprintf("配置已更新: %s", strings.Join(result.Changed, ", "))
+	if len(result.RestartRequired) > 0 {
+		message += fmt.Sprintf("（%s 需要重启后生效）", strings.Join(result.RestartRequired, ", "))
+	}
+	a.sendClipboardLog("info", message)
+	wailsRuntime.EventsEmit(a.ctx, "configReloaded", map[string]interface{}{
+		"success":         true,
+		"path":            result.Path,
+		"changed":         result.Changed,
+		"restartRequired": result.RestartRequired,
+	})
+}
+
 // GetConfigStatus 获取配置状态
 func (a *App) GetConfigStatus() map[string]interface{} {
 	status := map[string]interface{}{
@@ -467,7 +582,26 @@ func (a *App) GetClipboardBase64() (string, error) {
 
 // StartProcessImageToTodo 开始异步处理图片到Todo
 func (a *App) StartProcessImageToTodo(imageBase64 string) (string, error) {
+	return a.startImageTask(imageBase64, "")
+}
+
+// startImageTask 创建任务会话并开始异步处理图片，retryOf 为被重试的任务ID
+func (a *App) startImageTask(imageBase64, retryOf string) (string, error) {
+	taskID, session := a.newImageTask(retryOf)
+
+	// 启动异步处理
+	go a.processImageAsync(taskID, imageBase64, session)
+
+	return taskID, nil
+}
+
+// newImageTask 创建任务会话和进度记录，返回任务ID
+func (a *App) newImageTask(retryOf string) (string, *task.TaskSession) {
+	session := a.startTaskSession(retryOf)
 	taskID := generateTaskID()
+	if session != nil {
+		taskID = session.TaskID
+	}
 
 	// 创建任务信息
 	taskInfo := &TaskInfo{
@@ -479,50 +613,52 @@ func (a *App) StartProcessImageToTodo(imageBase64 string) (string, error) {
 	}
 
 	a.taskManager.AddTask(taskInfo)
-
-	// 启动异步处理
-	go a.processImageAsync(taskID, imageBase64)
-
-	return taskID, nil
+	return taskID, session
 }
 
-// processImageAsync 异步处理图片
-func (a *App) processImageAsync(taskID, imageBase64 string) {
+// processImageAsync 异步处理图片，处理过程记录到任务会话
+func (a *App) processImageAsync(taskID, imageBase64 string, session *task.TaskSession) {
+	startTime := time.Now()
+	// 任务 ID 作为关联 ID，Dify、解析和 Graph 的日志都带有同一个 task_id
+	ctx := logger.ContextWithTaskID(context.Background(), taskID)
+
 	defer func() {
 		if r := recover(); r != nil {
 			errMsg := fmt.Sprintf("处理出现异常: %v", r)
-			a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, "处理出现异常", "", "", errMsg)
-			a.sendClipboardLog("error", errMsg)
+			a.failImageTask(taskID, session, "处理出现异常", errMsg)
+			a.sendClipboardLogContext(ctx, "error", errMsg)
 		}
 	}()
 
 	// 输入验证
 	if imageBase64 == "" {
-		a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, "输入为空", "", "", "base64字符串为空")
-		a.sendClipboardLog("error", "输入的base64字符串为空")
+		a.failImageTask(taskID, session, "输入为空", "base64字符串为空")
+		a.sendClipboardLogContext(ctx, "error", "输入的base64字符串为空")
 		return
 	}
 
 	if len(imageBase64) < 100 {
-		a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, "输入无效", "", "", "base64字符串长度异常")
-		a.sendClipboardLog("error", "输入的base64字符串长度异常")
+		a.failImageTask(taskID, session, "输入无效", "base64字符串长度异常")
+		a.sendClipboardLogContext(ctx, "error", "输入的base64字符串长度异常")
 		return
 	}
 
 	// 记录开始处理
-	a.sendClipboardLog("info", fmt.Sprintf("开始解码图片，输入长度: %d", len(imageBase64)))
+	a.sendClipboardLogContext(ctx, "info", fmt.Sprintf("开始解码图片，输入长度: %d", len(imageBase64)))
 
 	// 步骤1：解码图片
 	a.taskManager.UpdateTask(taskID, TaskStatusRunning, 10, "正在解码图片...", "", "", "")
 	imageData, err := base64.StdEncoding.DecodeString(imageBase64)
 	if err != nil {
 		detailedError := fmt.Sprintf("base64解码失败: %v, 输入长度: %d", err, len(imageBase64))
-		a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, "解码失败", "", "", detailedError)
-		a.sendClipboardLog("error", detailedError)
+		a.failImageTask(taskID, session, "解码失败", detailedError)
+		a.sendClipboardLogContext(ctx, "error", detailedError)
 		return
 	}
 
-	a.sendClipboardLog("success", fmt.Sprintf("解码成功，输出长度: %d", len(imageData)))
+	a.sendClipboardLogContext(ctx, "success", fmt.Sprintf("解码成功，输出长度: %d", len(imageData)))
+	a.recordOriginalImage(session, imageData)
+	tracker := a.processingTracker(string(models.ContentTypeImage), session)
 	a.taskManager.UpdateTask(taskID, TaskStatusRunning, 20, "图片解码完成", "", "", "")
 
 	// 步骤2：调用CLI服务处理
@@ -533,46 +669,56 @@ func (a *App) processImageAsync(taskID, imageBase64 string) {
 
 	// 获取Dify服务并处理图片
 	difyService := a.serviceContainer.GetDifyService()
-	difyResponse, err := difyService.ProcessImage(context.Background(), imageData)
+	difyResponse, err := difyService.ProcessImage(ctx, imageData)
 	if err != nil {
-		a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, "AI处理失败", "", "", err.Error())
-		a.sendClipboardLog("error", fmt.Sprintf("AI处理失败: %v", err))
+		a.failImageTask(taskID, session, "AI处理失败", err.Error())
+		tracker.Fail(metrics.StageDify)
+		a.sendClipboardLogContext(ctx, "error", fmt.Sprintf("AI处理失败: %v", err))
 		return
 	}
 
-    a.sendClipboardLog("success", "AI服务调用成功")
+    a.sendClipboardLogContext(ctx, "success", "AI服务调用成功")
+    a.recordDifyResponse(session, difyResponse)
+    tracker.SetDifyResponse(difyResponse)
     a.taskManager.UpdateTask(taskID, TaskStatusRunning, 60, "AI服务调用成功", "", "", "")
 
     // 步骤4：解析AI响应
     a.taskManager.UpdateTask(taskID, TaskStatusRunning, 70, "正在解析AI响应...", "", "", "")
-    rawAnswer := ""
-    if difyResponse.Answer != "" {
-        rawAnswer = difyResponse.Answer
-    } else if difyResponse.Data != nil && difyResponse.Data.Outputs != nil {
-        rawAnswer = difyResponse.Data.Outputs.Text
-    }
+    rawAnswer := difyAnswer(difyResponse)
     if rawAnswer != "" {
-        a.sendClipboardLog("info", fmt.Sprintf("AI响应内容: %s", rawAnswer))
+        a.sendClipboardLogContext(ctx, "info", fmt.Sprintf("AI响应内容: %s", rawAnswer))
     }
-    reminder, err := commands.ParseDifyResponseToReminder(difyResponse, "image", "[图片内容]")
+    reminder, err := commands.ParseDifyResponseToReminder(ctx, difyResponse, "image", "[图片内容]")
     if err != nil {
-        a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, "解析AI响应失败", "", "", err.Error())
-        a.sendClipboardLog("error", fmt.Sprintf("解析AI响应失败: %v", err))
+        a.failImageTask(taskID, session, "解析AI响应失败", err.Error())
+        tracker.Fail(metrics.StageParse)
+        a.sendClipboardLogContext(ctx, "error", fmt.Sprintf("解析AI响应失败: %v", err))
         return
 	}
 
-	a.sendClipboardLog("info", fmt.Sprintf("解析任务信息: %s", reminder.Title))
+	a.sendClipboardLogContext(ctx, "info", fmt.Sprintf("解析任务信息: %s", reminder.Title))
 	a.taskManager.UpdateTask(taskID, TaskStatusRunning, 80, "AI分析完成", "", "", "")
 
 	// 步骤5：创建Todo任务
 	a.taskManager.UpdateTask(taskID, TaskStatusRunning, 90, "正在创建Microsoft Todo任务...", "", "", "")
 	todoService := a.serviceContainer.GetTodoService()
-	err = todoService.CreateTask(context.Background(), reminder)
+	tracker.TrackGraph(commands.GraphRequestStats(todoService))
+	creation, err := todoService.CreateTaskWithAttachment(ctx, reminder, imageData)
 	if err != nil {
-		a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, "创建任务失败", "", "", err.Error())
-		a.sendClipboardLog("error", fmt.Sprintf("创建Microsoft Todo任务失败: %v", err))
+		a.failImageTask(taskID, session, "创建任务失败", err.Error())
+		tracker.Fail(metrics.StageTodo)
+		a.sendClipboardLogContext(ctx, "error", fmt.Sprintf("创建Microsoft Todo任务失败: %v", err))
 		return
 	}
+	if creation.Duplicate {
+		a.sendClipboardLogContext(ctx, "info", "Microsoft Todo 中已存在相同任务，未重复创建")
+	}
+	if creation.AttachmentID != "" {
+		a.sendClipboardLogContext(ctx, "success", "截图已作为附件上传到任务")
+	}
+	for _, warning := range creation.Warnings {
+		a.sendClipboardLogContext(ctx, "warn", warning)
+	}
 
 	// 完成处理
     result := &ClipUploadResult{
@@ -582,22 +728,46 @@ func (a *App) processImageAsync(taskID, imageBase64 string) {
         Message:     "任务创建成功",
         List:        reminder.List,
         Priority:    string(reminder.Priority),
-        Duration:    time.Since(time.Now()).Milliseconds(),
+        Duration:    time.Since(startTime).Milliseconds(),
         ParsedAnswer: rawAnswer,
     }
 
 	resultJSON, _ := json.Marshal(result)
+	a.recordTodoResult(session, reminder, creation, resultJSON)
+	tracker.Succeed()
 	a.taskManager.UpdateTask(taskID, TaskStatusCompleted, 100, "任务创建成功！", string(resultJSON), "", "")
-	a.sendClipboardLog("success", "处理完成")
+	a.sendClipboardLogContext(ctx, "success", "处理完成")
 }
 
-// GetTaskStatus 获取任务状态
+// GetTaskStatus 获取任务状态，已不在内存中的任务从任务历史读取
 func (a *App) GetTaskStatus(taskID string) (*TaskInfo, error) {
-	return a.taskManager.GetTask(taskID)
+	if info, err := a.taskManager.GetTask(taskID); err == nil {
+		return info, nil
+	}
+	return a.taskInfoFromHistory(taskID)
 }
 
-// sendClipboardLog 发送剪贴板处理日志
+// sendClipboardLog 发送剪贴板处理日志到前端，同时写入日志文件
 func (a *App) sendClipboardLog(logType, message string) {
+	a.sendClipboardLogContext(context.Background(), logType, message)
+}
+
+// sendClipboardLogContext 同 sendClipboardLog，写入日志文件时附带 ctx 中的任务 ID
+func (a *App) sendClipboardLogContext(ctx context.Context, logType, message string) {
+	message = logger.Redact(message)
+
+	trayLog := logger.FromContext(ctx).With("source", "tray")
+	switch logType {
+	case "error":
+		trayLog.Error(message)
+	case "warn":
+		trayLog.Warn(message)
+	case "debug":
+		trayLog.Debug(message)
+	default:
+		trayLog.Info(message)
+	}
+
 	logMessage := LogMessage{
 		Type:    logType,
 		Message: message,
@@ -628,23 +798,8 @@ func (a *App) onSystrayReady() {
 	systray.SetTitle("to_icalendar")
 	systray.SetTooltip("to_icalendar - Microsoft Todo Reminders")
 
-	// 显示窗口菜单项
-	mShow := systray.AddMenuItem("显示窗口", "显示主窗口")
-	go func() {
-		for range mShow.ClickedCh {
-			a.ShowWindow()
-		}
-	}()
-
-	systray.AddSeparator()
-
-	// 退出菜单项
-	mQuit := systray.AddMenuItem("退出", "退出应用程序")
-	go func() {
-		for range mQuit.ClickedCh {
-			a.Quit()
-		}
-	}()
+	// 根据托盘菜单配置构建菜单
+	a.buildTrayMenu()
 
 	// 添加调试输出，确认菜单项创建成功
 	println("系统托盘菜单初始化完成")
@@ -698,6 +853,9 @@ func (a *App) Quit() {
 			}
 		}
 
+		// 停止剪贴板监听
+		a.StopClipboardWatch()
+
 		// 先停止托盘
 		systray.Quit()
 
@@ -884,50 +1042,8 @@ func (a *App) InitConfig() string {
 		return fmt.Sprintf(`{"success":true,"message":"%s","configDir":"%s","serverConfig":"%s"}`, result.Message, result.ConfigDir, result.ServerConfig)
 	}
 
-	// 创建配置文件内容（复用现有的完整模板）
-	serverConfigContent := `# Microsoft Todo 配置
-microsoft_todo:
-  tenant_id: "YOUR_TENANT_ID"
-  client_id: "YOUR_CLIENT_ID"
-  client_secret: "YOUR_CLIENT_SECRET"
-  user_email: ""
-  timezone: "Asia/Shanghai"
-
-# 提醒配置
-reminder:
-  default_remind_before: "15m"
-  enable_smart_reminder: true
-
-# 去重配置
-deduplication:
-  enabled: true
-  time_window_minutes: 5
-  similarity_threshold: 80
-  check_incomplete_only: true
-  enable_local_cache: true
-  enable_remote_query: true
-
-# Dify AI 配置（可选）
-dify:
-  api_endpoint: ""
-  api_key: ""
-  timeout: 60
-
-# 缓存配置
-cache:
-  auto_cleanup_days: 30
-  cleanup_on_startup: true
-  preserve_successful_hashes: true
-
-# 日志配置
-logging:
-  level: "info"
-  console_output: true
-  file_output: true
-  log_dir: "./Logs"`
-
-	// 写入文件
-	if err := os.WriteFile(serverConfigPath, []byte(serverConfigContent), 0600); err != nil {
+	// 写入与命令行 init 相同的配置模板和 JSON Schema
+	if err := config.NewConfigManager().CreateServerConfigTemplate(serverConfigPath); err != nil {
 		result := InitResult{
 			Success:      false,
 			Message:      fmt.Sprintf("创建配置文件失败: %v", err),
@@ -1019,8 +1135,8 @@ func (a *App) testConfigurationFile() *testing.TestItemResult {
 		return result
 	}
 
-	var config models.ServerConfig
-	if err := yaml.Unmarshal(configData, &config); err != nil {
+	// 先检查 YAML 语法，再展开环境变量、覆盖和密钥引用，与服务实际加载的配置一致
+	if err := yaml.Unmarshal(configData, new(yaml.Node)); err != nil {
 		result.Error = "配置文件格式错误"
 		result.Details = map[string]interface{}{
 			"error":   "YAML解析错误: " + err.Error(),
@@ -1029,6 +1145,17 @@ func (a *App) testConfigurationFile() *testing.TestItemResult {
 		result.Duration = time.Since(startTime)
 		return result
 	}
+	resolved, err := config.NewConfigManager().ReadServerConfig(serverConfigPath)
+	if err != nil {
+		result.Error = "配置引用解析失败"
+		result.Details = map[string]interface{}{
+			"error":   err.Error(),
+			"message": "请检查 ${ENV_VAR}、TO_ICALENDAR_* 环境变量以及 file:/cmd: 密钥引用",
+		}
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	config := *resolved
 
 	// 验证必需字段
 	missingFields := []string{}
@@ -1146,8 +1273,7 @@ func (a *App) testDifyService() *testing.TestItemResult {
 		}
 	}
 
-	var config models.ServerConfig
-	if err := yaml.Unmarshal(configData, &config); err != nil {
+	if err := yaml.Unmarshal(configData, new(yaml.Node)); err != nil {
 		return &testing.TestItemResult{
 			Name:     "Dify 服务测试",
 			Success:  false,
@@ -1158,6 +1284,16 @@ func (a *App) testDifyService() *testing.TestItemResult {
 			Duration: 0,
 		}
 	}
+	resolved, err := config.NewConfigManager().ReadServerConfig(serverConfigPath)
+	if err != nil {
+		return &testing.TestItemResult{
+			Name:     "Dify 服务测试",
+			Success:  false,
+			Error:    "配置引用解析失败: " + err.Error(),
+			Duration: 0,
+		}
+	}
+	config := *resolved
 
 	// 转换 models.DifyConfig 到 testing.DifyConfig
 	testingDifyConfig := &testing.DifyConfig{
@@ -1195,7 +1331,7 @@ func (a *App) buildOAuthURL(codeChallenge, state string) string {
 	params.Add("client_id", a.config.MicrosoftTodo.ClientID)
 	params.Add("response_type", "code")
 	params.Add("redirect_uri", "http://localhost:8080/callback")
-	params.Add("scope", "https://graph.microsoft.com/Tasks.ReadWrite https://graph.microsoft.com/User.Read offline_access")
+	params.Add("scope", microsofttodo.GraphScopes)
 	params.Add("state", state)
 	params.Add("code_challenge", codeChallenge)
 	params.Add("code_challenge_method", "S256")
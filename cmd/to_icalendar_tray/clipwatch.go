@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/allanpk716/to_icalendar/pkg/clipboard"
+	"github.com/allanpk716/to_icalendar/pkg/clipwatch"
+	"github.com/allanpk716/to_icalendar/pkg/commands"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// StartClipboardWatch 开始监听剪贴板，新内容自动处理并创建到 Microsoft Todo
+func (a *App) StartClipboardWatch() error {
+	a.clipWatchMutex.Lock()
+	defer a.clipWatchMutex.Unlock()
+
+	if a.clipWatchCancel != nil {
+		return nil
+	}
+	if a.serviceContainer == nil || a.config == nil {
+		return fmt.Errorf("服务未初始化，请先完成配置")
+	}
+
+	watcher, err := clipwatch.NewWatcher(
+		clipwatch.NewSequenceDetector(clipboard.SequenceNumber),
+		a.serviceContainer.GetClipboardService(),
+		a.handleWatchedContent,
+		a.config.Watch,
+	)
+	if err != nil {
+		return fmt.Errorf("创建剪贴板监听器失败: %w", err)
+	}
+	watcher.SetValidator(clipboard.ValidateClipboardContent)
+	metricsStore := a.serviceContainer.GetMetricsStore()
+	watcher.SetDuplicateHandler(func(content *models.ClipboardContent) {
+		metricsStore.RecordDuplicate("tray", string(content.Type))
+	})
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.clipWatchCancel = cancel
+	a.clipWatcher = watcher
+	go watcher.Run(ctx)
+
+	a.sendClipboardLog("info", fmt.Sprintf("已开启剪贴板自动监听（触发条件: %s）", a.config.Watch.GetTrigger()))
+	wailsRuntime.EventsEmit(a.ctx, "clipboardWatchChanged", true)
+	a.syncWatcherMenuItems(true)
+	return nil
+}
+
+// StopClipboardWatch 停止监听剪贴板
+func (a *App) StopClipboardWatch() {
+	a.clipWatchMutex.Lock()
+	defer a.clipWatchMutex.Unlock()
+
+	if a.clipWatchCancel == nil {
+		return
+	}
+	a.clipWatchCancel()
+	a.clipWatchCancel = nil
+
+	a.sendClipboardLog("info", "已关闭剪贴板自动监听")
+	wailsRuntime.EventsEmit(a.ctx, "clipboardWatchChanged", false)
+	a.syncWatcherMenuItems(false)
+}
+
+// IsClipboardWatching 是否正在监听剪贴板
+func (a *App) IsClipboardWatching() bool {
+	a.clipWatchMutex.Lock()
+	defer a.clipWatchMutex.Unlock()
+	return a.clipWatchCancel != nil
+}
+
+// GetClipboardWatchStats 获取剪贴板监听统计
+func (a *App) GetClipboardWatchStats() *clipwatch.Stats {
+	a.clipWatchMutex.Lock()
+	defer a.clipWatchMutex.Unlock()
+
+	if a.clipWatcher == nil {
+		return nil
+	}
+	stats := a.clipWatcher.Stats()
+	return &stats
+}
+
+// handleWatchedContent 处理监听到的剪贴板内容
+// 图片走与手动上传相同的任务流程，以便前端显示进度；文本使用 clip-upload 流程。
+// 两者都在监听 goroutine 中同步处理，失败时返回错误，由监听器稍后重试
+func (a *App) handleWatchedContent(ctx context.Context, content *models.ClipboardContent) error {
+	if content.Type == models.ContentTypeImage {
+		taskID, session := a.newImageTask("")
+		a.sendClipboardLog("info", fmt.Sprintf("检测到新的剪贴板图片，开始处理 (任务 %s)", taskID))
+		a.processImageAsync(taskID, base64.StdEncoding.EncodeToString(content.Image), session)
+		return a.taskManager.FailedError(taskID)
+	}
+
+	a.sendClipboardLog("info", "检测到新的剪贴板文本，开始处理...")
+	clipUpload := commands.NewClipUploadCommand(a.serviceContainer)
+	clipUpload.SetSource("tray")
+	resp, err := clipUpload.ProcessContent(ctx, content)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		a.sendClipboardLog("error", fmt.Sprintf("处理剪贴板文本失败: %s", resp.Error))
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	title, _ := resp.Metadata["task_title"].(string)
+	a.sendClipboardLog("success", fmt.Sprintf("已创建任务: %s", title))
+	logger.Infof("剪贴板监听已创建任务: %s", title)
+	return nil
+}
@@ -8,7 +8,11 @@ import type {
   ClipboardContent,
   ParseResult,
   CacheInfo,
-  CleanProgress
+  CleanProgress,
+  TaskHistoryFilter,
+  TaskHistoryPage,
+  TaskDetail,
+  ProcessingStats
 } from '@/types/api'
 
 // Wails API 封装类
@@ -224,4 +228,68 @@ export class WailsAPI {
       }
     }
   }
-}
\ No newline at end of file
+
+  // 查询任务历史
+  static async GetTaskHistory(filter: TaskHistoryFilter, page: number): Promise<WailsResponse<TaskHistoryPage>> {
+    try {
+      const result = await (window as any).go.main.App.GetTaskHistory(filter, page)
+      return {
+        success: true,
+        data: result
+      }
+    } catch (error) {
+      return {
+        success: false,
+        error: `查询任务历史失败: ${error}`
+      }
+    }
+  }
+
+  // 获取任务详情
+  static async GetTaskDetail(taskId: string): Promise<WailsResponse<TaskDetail>> {
+    try {
+      const result = await (window as any).go.main.App.GetTaskDetail(taskId)
+      return {
+        success: true,
+        data: result
+      }
+    } catch (error) {
+      return {
+        success: false,
+        error: `获取任务详情失败: ${error}`
+      }
+    }
+  }
+
+  // 重试任务，返回新任务ID
+  static async RetryTask(taskId: string): Promise<WailsResponse<string>> {
+    try {
+      const result = await (window as any).go.main.App.RetryTask(taskId)
+      return {
+        success: true,
+        data: result
+      }
+    } catch (error) {
+      return {
+        success: false,
+        error: `重试任务失败: ${error}`
+      }
+    }
+  }
+
+  // 获取处理统计，since 为时间范围（如 7d、24h）
+  static async GetProcessingStats(since: string): Promise<WailsResponse<ProcessingStats>> {
+    try {
+      const result = await (window as any).go.main.App.GetProcessingStats(since)
+      return {
+        success: true,
+        data: result
+      }
+    } catch (error) {
+      return {
+        success: false,
+        error: `获取处理统计失败: ${error}`
+      }
+    }
+  }
+}
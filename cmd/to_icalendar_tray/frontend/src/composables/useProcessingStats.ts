@@ -0,0 +1,35 @@
+import { ref } from 'vue'
+import { WailsAPI } from '@/api/wails'
+import type { ProcessingStats } from '@/types/api'
+
+// 处理统计
+export function useProcessingStats() {
+  const stats = ref<ProcessingStats | null>(null)
+  const since = ref<string>('7d')
+  const isLoading = ref<boolean>(false)
+  const error = ref<string>('')
+
+  // 加载统计
+  const loadStats = async () => {
+    isLoading.value = true
+    error.value = ''
+    try {
+      const result = await WailsAPI.GetProcessingStats(since.value)
+      if (result.success && result.data) {
+        stats.value = result.data
+      } else {
+        error.value = result.error || '获取处理统计失败'
+      }
+    } finally {
+      isLoading.value = false
+    }
+  }
+
+  return {
+    stats,
+    since,
+    isLoading,
+    error,
+    loadStats
+  }
+}
@@ -0,0 +1,83 @@
+import { reactive, ref } from 'vue'
+import { WailsAPI } from '@/api/wails'
+import type { TaskDetail, TaskHistoryFilter, TaskIndex } from '@/types/api'
+
+// 任务历史管理
+export function useTaskHistory() {
+  // 列表状态
+  const items = ref<TaskIndex[]>([])
+  const total = ref<number>(0)
+  const page = ref<number>(1)
+  const pageSize = ref<number>(20)
+  const isLoading = ref<boolean>(false)
+  const error = ref<string>('')
+
+  // 查询条件
+  const filter = reactive<TaskHistoryFilter>({
+    status: '',
+    keyword: ''
+  })
+
+  // 详情状态
+  const detail = ref<TaskDetail | null>(null)
+  const isLoadingDetail = ref<boolean>(false)
+
+  // 加载任务历史
+  const loadHistory = async (targetPage = page.value) => {
+    isLoading.value = true
+    error.value = ''
+    try {
+      const result = await WailsAPI.GetTaskHistory({ ...filter }, targetPage)
+      if (result.success && result.data) {
+        items.value = result.data.items || []
+        total.value = result.data.total
+        page.value = result.data.page
+        pageSize.value = result.data.page_size
+      } else {
+        error.value = result.error || '查询任务历史失败'
+      }
+    } finally {
+      isLoading.value = false
+    }
+  }
+
+  // 加载任务详情
+  const loadDetail = async (taskId: string) => {
+    isLoadingDetail.value = true
+    detail.value = null
+    try {
+      const result = await WailsAPI.GetTaskDetail(taskId)
+      if (result.success && result.data) {
+        detail.value = result.data
+      } else {
+        error.value = result.error || '获取任务详情失败'
+      }
+    } finally {
+      isLoadingDetail.value = false
+    }
+  }
+
+  // 重试任务，返回新任务ID
+  const retryTask = async (taskId: string): Promise<{ success: boolean; taskId?: string; error?: string }> => {
+    const result = await WailsAPI.RetryTask(taskId)
+    if (result.success) {
+      return { success: true, taskId: result.data }
+    }
+    return { success: false, error: result.error }
+  }
+
+  return {
+    items,
+    total,
+    page,
+    pageSize,
+    isLoading,
+    error,
+    filter,
+    detail,
+    isLoadingDetail,
+    loadHistory,
+    loadDetail,
+    retryTask
+  }
+}
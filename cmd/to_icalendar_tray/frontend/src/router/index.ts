@@ -22,6 +22,16 @@ const router = createRouter({
       name: 'clipboard',
       component: () => import('../views/ClipboardView.vue'),
     },
+    {
+      path: '/history',
+      name: 'history',
+      component: () => import('../views/HistoryView.vue'),
+    },
+    {
+      path: '/stats',
+      name: 'stats',
+      component: () => import('../views/StatsView.vue'),
+    },
     {
       path: '/about',
       name: 'about',
@@ -98,4 +98,107 @@ export interface CleanProgress {
   found: number
   cleaned: number
   currentPath?: string
-}
\ No newline at end of file
+}
+
+// 任务历史相关类型
+export type TaskHistoryStatus = 'running' | 'success' | 'failed'
+
+export interface TaskHistoryFilter {
+  status?: TaskHistoryStatus | ''
+  keyword?: string
+  since?: string
+  until?: string
+}
+
+export interface TaskIndex {
+  task_id: string
+  start_time: string
+  end_time?: string
+  status: TaskHistoryStatus
+  title?: string
+  dify_success: boolean
+  todo_success: boolean
+  todo_task_id?: string
+}
+
+export interface TaskHistoryPage {
+  items: TaskIndex[]
+  total: number
+  page: number
+  page_size: number
+}
+
+export interface TaskSession {
+  task_id: string
+  start_time: string
+  end_time?: string
+  status: TaskHistoryStatus
+  title?: string
+  description?: string
+  dify_success: boolean
+  todo_success: boolean
+  error_message?: string
+  todo_task_id?: string
+  todo_list_id?: string
+  attachment_id?: string
+  metadata?: Record<string, any>
+}
+
+export interface TaskDetail {
+  session: TaskSession
+  image?: string
+  aiAnswer?: string
+  result?: {
+    success: boolean
+    title: string
+    description: string
+    list?: string
+    priority?: string
+    duration?: number
+  }
+  todoUrl?: string
+  canRetry: boolean
+  retryOf?: string
+}
+
+// 处理统计相关类型
+export interface ProcessingCounts {
+  runs: number
+  succeeded: number
+  failed: number
+  duplicates: number
+}
+
+export interface DailyProcessingSummary extends ProcessingCounts {
+  date: string
+  tokens: number
+}
+
+export interface ProcessingSummary extends ProcessingCounts {
+  since: string
+  until: string
+  by_content_type: Record<string, ProcessingCounts>
+  failed_stages: Record<string, number>
+  dify: {
+    calls: number
+    avg_latency_ms: number
+    p95_latency_ms: number
+    max_latency_ms: number
+    total_tokens: number
+    avg_tokens: number
+  }
+  graph: {
+    calls: number
+    retries: number
+  }
+  avg_duration_ms: number
+  daily: DailyProcessingSummary[]
+}
+
+export interface ProcessingStats {
+  summary: ProcessingSummary
+  enabled: boolean
+  retentionDays: number
+  successRate: number
+  dedupHitRate: number
+}
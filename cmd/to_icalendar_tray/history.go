@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/task"
+)
+
+// TaskDetail 任务详情，包含原始截图、AI 回答和 To Do 创建结果
+type TaskDetail struct {
+	Session  *task.TaskSession `json:"session"`
+	Image    string            `json:"image,omitempty"`    // 原始截图 (base64)
+	AIAnswer string            `json:"aiAnswer,omitempty"` // AI 原始回答
+	Result   *ClipUploadResult `json:"result,omitempty"`   // To Do 创建结果
+	TodoURL  string            `json:"todoUrl,omitempty"`  // Microsoft To Do 任务链接
+	CanRetry bool              `json:"canRetry"`           // 是否保存了原始截图，可以重试
+	RetryOf  string            `json:"retryOf,omitempty"`  // 被重试的任务ID
+}
+
+// taskHistory 获取持久化任务记录的任务管理器
+func (a *App) taskHistory() (*task.TaskManager, error) {
+	if a.serviceContainer == nil {
+		return nil, fmt.Errorf("服务未初始化")
+	}
+	return a.serviceContainer.GetTaskManager()
+}
+
+// startTaskSession 创建任务会话，失败时只记录警告并返回 nil，不影响处理流程
+func (a *App) startTaskSession(retryOf string) *task.TaskSession {
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		logger.Warnf("获取任务管理器失败，跳过任务历史记录: %v", err)
+		return nil
+	}
+
+	session, err := taskManager.CreateTaskSession()
+	if err != nil {
+		logger.Warnf("创建任务会话失败: %v", err)
+		return nil
+	}
+
+	session.Metadata["source"] = "tray"
+	if retryOf != "" {
+		session.Metadata["retry_of"] = retryOf
+	}
+	return session
+}
+
+// recordOriginalImage 保存原始截图到任务会话
+func (a *App) recordOriginalImage(session *task.TaskSession, imageData []byte) {
+	if session == nil {
+		return
+	}
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return
+	}
+	if err := taskManager.SaveFileToTask(session, task.FileTypeClipboardOriginal, imageData); err != nil {
+		logger.Warnf("保存原始截图失败: %v", err)
+	}
+}
+
+// recordDifyResponse 保存 AI 响应到任务会话
+func (a *App) recordDifyResponse(session *task.TaskSession, resp *models.DifyResponse) {
+	if session == nil {
+		return
+	}
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return
+	}
+
+	session.Metadata["ai_answer"] = difyAnswer(resp)
+	data, _ := json.MarshalIndent(resp, "", "  ")
+	if err := taskManager.SetDifyResult(session, true, nil, data); err != nil {
+		logger.Warnf("保存AI响应失败: %v", err)
+	}
+}
+
+// recordTodoResult 保存 To Do 创建结果，并将会话标记为成功
+func (a *App) recordTodoResult(session *task.TaskSession, reminder *models.Reminder, creation *services.TaskCreationResult, resultJSON []byte) {
+	if session == nil {
+		return
+	}
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return
+	}
+
+	session.Title = reminder.Title
+	session.Description = reminder.Description
+	session.Metadata["list"] = creation.ListName
+	if err := taskManager.SetTodoResult(session, true, resultJSON); err != nil {
+		logger.Warnf("保存To Do创建结果失败: %v", err)
+	}
+	taskManager.SetTodoTaskInfo(session, creation.TaskID, creation.ListID, creation.AttachmentID)
+	taskManager.UpdateTaskStatus(session, task.TaskStatusSuccess)
+}
+
+// failImageTask 将任务标记为失败，同时更新进度和任务会话
+func (a *App) failImageTask(taskID string, session *task.TaskSession, step, errMsg string) {
+	a.taskManager.UpdateTask(taskID, TaskStatusFailed, 0, step, "", "", errMsg)
+	if session == nil {
+		return
+	}
+	if taskManager, err := a.taskHistory(); err == nil {
+		taskManager.UpdateTaskStatus(session, task.TaskStatusFailed, fmt.Sprintf("%s: %s", step, errMsg))
+	}
+}
+
+// difyAnswer 提取 AI 回答文本
+func difyAnswer(resp *models.DifyResponse) string {
+	if resp == nil {
+		return ""
+	}
+	if resp.Answer != "" {
+		return resp.Answer
+	}
+	if resp.Data != nil && resp.Data.Outputs != nil {
+		return resp.Data.Outputs.Text
+	}
+	return ""
+}
+
+// taskInfoFromHistory 根据任务历史构建任务状态
+func (a *App) taskInfoFromHistory(taskID string) (*TaskInfo, error) {
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return nil, fmt.Errorf("任务不存在: %s", taskID)
+	}
+	session, err := taskManager.GetTaskSession(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("任务不存在: %s", taskID)
+	}
+
+	info := &TaskInfo{
+		ID:        session.TaskID,
+		Status:    TaskStatusRunning,
+		StartTime: session.StartTime,
+		EndTime:   session.EndTime,
+		Error:     session.ErrorMessage,
+	}
+	switch session.Status {
+	case task.TaskStatusSuccess:
+		info.Status = TaskStatusCompleted
+		info.Progress = 100
+		info.Step = "任务创建成功！"
+		if data, err := taskManager.ReadTaskFile(session, task.FileTypeTodoResult); err == nil {
+			info.Result = string(data)
+		}
+	case task.TaskStatusFailed:
+		info.Status = TaskStatusFailed
+		info.Step = "处理失败"
+	}
+	return info, nil
+}
+
+// GetTaskHistory 分页查询任务历史，page 从 1 开始
+func (a *App) GetTaskHistory(filter task.HistoryFilter, page int) (*task.HistoryPage, error) {
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return nil, err
+	}
+	return taskManager.QueryHistory(filter, page, task.DefaultHistoryPageSize), nil
+}
+
+// GetTaskDetail 获取任务详情
+func (a *App) GetTaskDetail(taskID string) (*TaskDetail, error) {
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return nil, err
+	}
+	session, err := taskManager.GetTaskSession(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("任务不存在: %s", taskID)
+	}
+
+	detail := &TaskDetail{Session: session}
+	if retryOf, ok := session.Metadata["retry_of"].(string); ok {
+		detail.RetryOf = retryOf
+	}
+	if session.TodoTaskID != "" {
+		detail.TodoURL = fmt.Sprintf(todoTaskURLTemplate, session.TodoTaskID)
+	}
+
+	if image, err := taskManager.ReadTaskFile(session, task.FileTypeClipboardOriginal); err == nil {
+		detail.Image = base64.StdEncoding.EncodeToString(image)
+		detail.CanRetry = session.Status != task.TaskStatusRunning
+	}
+
+	if answer, ok := session.Metadata["ai_answer"].(string); ok {
+		detail.AIAnswer = answer
+	} else if data, err := taskManager.ReadTaskFile(session, task.FileTypeDifyResponse); err == nil {
+		var resp models.DifyResponse
+		if json.Unmarshal(data, &resp) == nil {
+			detail.AIAnswer = difyAnswer(&resp)
+		}
+	}
+
+	if data, err := taskManager.ReadTaskFile(session, task.FileTypeTodoResult); err == nil {
+		var result ClipUploadResult
+		if json.Unmarshal(data, &result) == nil {
+			detail.Result = &result
+		}
+	}
+
+	return detail, nil
+}
+
+// RetryTask 使用保存的原始截图重新处理任务，返回新任务ID
+func (a *App) RetryTask(taskID string) (string, error) {
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return "", err
+	}
+	session, err := taskManager.GetTaskSession(taskID)
+	if err != nil {
+		return "", fmt.Errorf("任务不存在: %s", taskID)
+	}
+	if session.Status == task.TaskStatusRunning {
+		return "", fmt.Errorf("任务 %s 仍在处理中", taskID)
+	}
+
+	image, err := taskManager.ReadTaskFile(session, task.FileTypeClipboardOriginal)
+	if err != nil {
+		return "", fmt.Errorf("任务没有保存原始截图，无法重试: %w", err)
+	}
+
+	a.sendClipboardLog("info", fmt.Sprintf("重新处理任务 %s", taskID))
+	return a.startImageTask(base64.StdEncoding.EncodeToString(image), taskID)
+}
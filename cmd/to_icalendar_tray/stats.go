@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/commands"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/metrics"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/task"
+)
+
+// ProcessingStats 统计面板数据
+type ProcessingStats struct {
+	Summary       *metrics.Summary `json:"summary"`
+	Enabled       bool             `json:"enabled"`       // 是否正在记录指标（enable_cache_metrics）
+	RetentionDays int              `json:"retentionDays"` // 指标保留天数
+	SuccessRate   float64          `json:"successRate"`
+	DedupHitRate  float64          `json:"dedupHitRate"`
+}
+
+// processingTracker 开始跟踪一次托盘处理，服务未初始化时返回不写入指标的跟踪器
+func (a *App) processingTracker(contentType string, session *task.TaskSession) *metrics.Tracker {
+	var store *metrics.Store
+	if a.serviceContainer != nil {
+		store = a.serviceContainer.GetMetricsStore()
+	}
+
+	tracker := store.Track("tray", contentType)
+	if session != nil {
+		tracker.SetTaskID(session.TaskID)
+	}
+	return tracker
+}
+
+// GetProcessingStats 获取统计面板数据，since 为时间范围（如 7d、24h），为空时使用 7d
+func (a *App) GetProcessingStats(since string) (*ProcessingStats, error) {
+	if a.serviceContainer == nil {
+		return nil, fmt.Errorf("服务未初始化")
+	}
+	if since == "" {
+		since = commands.DefaultStatsSince
+	}
+	span, err := models.ParseSpan(since)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时间范围: %w", err)
+	}
+
+	store := a.serviceContainer.GetMetricsStore()
+	if _, err := store.Prune(); err != nil {
+		logger.Warnf("清理过期指标失败: %v", err)
+	}
+
+	until := time.Now()
+	runs, err := store.Query(until.Add(-span))
+	if err != nil {
+		return nil, fmt.Errorf("读取处理指标失败: %w", err)
+	}
+
+	summary := metrics.Summarize(runs, until.Add(-span), until)
+	return &ProcessingStats{
+		Summary:       summary,
+		Enabled:       store.Enabled(),
+		RetentionDays: int(store.Retention() / (24 * time.Hour)),
+		SuccessRate:   summary.SuccessRate(),
+		DedupHitRate:  summary.DedupHitRate(),
+	}, nil
+}
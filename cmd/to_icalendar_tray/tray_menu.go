@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/commands"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/task"
+	"github.com/allanpk716/to_icalendar/pkg/tray"
+	"github.com/getlantern/systray"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	defaultProfile      = "default"        // 默认配置，对应 server.yaml
+	recentTaskSlots     = 8                // 最近任务子菜单的槽位数量
+	recentTaskRefresh   = 30 * time.Second // 最近任务子菜单刷新间隔
+	todoTaskURLTemplate = "https://to-do.office.com/tasks/id/%s/details"
+)
+
+// recentTaskSlot 最近任务子菜单中的一个槽位
+// systray 不支持删除菜单项，因此预先创建固定数量的槽位，刷新时更新标题并显示/隐藏
+type recentTaskSlot struct {
+	item *systray.MenuItem
+	task *task.TaskIndex
+}
+
+// buildTrayMenu 根据托盘菜单配置构建系统托盘菜单
+func (a *App) buildTrayMenu() {
+	menu := tray.DefaultTrayMenu()
+	if configDir, err := getConfigDir(); err == nil {
+		loaded, err := tray.LoadMenuConfig(filepath.Join(configDir, tray.MenuConfigFileName))
+		if err != nil {
+			logger.Errorf("加载托盘菜单配置失败，使用默认菜单: %v", err)
+		} else {
+			menu = loaded
+		}
+	}
+
+	for _, item := range menu.VisibleItems() {
+		a.addTrayMenuItem(item, nil)
+	}
+}
+
+// addTrayMenuItem 添加一个菜单项，parent 为 nil 时添加到顶层
+func (a *App) addTrayMenuItem(item tray.MenuItem, parent *systray.MenuItem) {
+	if item.IsSeparator {
+		// systray 只支持顶层分隔符
+		if parent == nil {
+			systray.AddSeparator()
+		}
+		return
+	}
+
+	checked := item.Action == tray.ActionToggleWatcher && a.IsClipboardWatching()
+	var mi *systray.MenuItem
+	switch {
+	case parent == nil && item.Checkable:
+		mi = systray.AddMenuItemCheckbox(item.DisplayLabel(), item.Tooltip, checked)
+	case parent == nil:
+		mi = systray.AddMenuItem(item.DisplayLabel(), item.Tooltip)
+	case item.Checkable:
+		mi = parent.AddSubMenuItemCheckbox(item.DisplayLabel(), item.Tooltip, checked)
+	default:
+		mi = parent.AddSubMenuItem(item.DisplayLabel(), item.Tooltip)
+	}
+
+	if !item.IsEnabled {
+		mi.Disable()
+	}
+
+	switch item.Action {
+	case tray.ActionRecentTasks:
+		a.buildRecentTasksMenu(mi)
+		return
+	case tray.ActionSwitchProfile:
+		a.buildProfilesMenu(mi)
+		return
+	case tray.ActionToggleWatcher:
+		a.trayMenuMutex.Lock()
+		a.watcherMenuItems = append(a.watcherMenuItems, mi)
+		a.trayMenuMutex.Unlock()
+	}
+
+	for _, child := range item.Children {
+		a.addTrayMenuItem(child, mi)
+	}
+
+	if item.Type == tray.MenuTypeSubmenu {
+		return
+	}
+
+	action := item.Action
+	go func() {
+		for range mi.ClickedCh {
+			a.runTrayAction(action)
+		}
+	}()
+}
+
+// runTrayAction 执行托盘菜单动作
+func (a *App) runTrayAction(action string) {
+	switch action {
+	case tray.ActionShowWindow:
+		a.ShowWindow()
+	case tray.ActionProcessClipboard:
+		a.processClipboardNow()
+	case tray.ActionOpenLastTask:
+		a.openLastTask()
+	case tray.ActionToggleWatcher:
+		if a.IsClipboardWatching() {
+			a.StopClipboardWatch()
+			return
+		}
+		if err := a.StartClipboardWatch(); err != nil {
+			logger.Errorf("开启剪贴板监听失败: %v", err)
+			a.sendClipboardLog("error", fmt.Sprintf("开启剪贴板监听失败: %v", err))
+		}
+	case tray.ActionTestConnection:
+		a.ShowWindow()
+		go a.TestConfiguration()
+	case tray.ActionCleanCache:
+		a.cleanCache()
+	case tray.ActionQuit:
+		a.Quit()
+	default:
+		logger.Warnf("未知的托盘菜单动作: %s", action)
+	}
+}
+
+// syncWatcherMenuItems 同步监听开关菜单项的勾选状态
+func (a *App) syncWatcherMenuItems(watching bool) {
+	a.trayMenuMutex.Lock()
+	defer a.trayMenuMutex.Unlock()
+
+	for _, mi := range a.watcherMenuItems {
+		if watching {
+			mi.Check()
+		} else {
+			mi.Uncheck()
+		}
+	}
+}
+
+// processClipboardNow 立即读取并处理当前剪贴板内容
+func (a *App) processClipboardNow() {
+	if a.serviceContainer == nil {
+		a.sendClipboardLog("error", "服务未初始化，请先完成配置")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, 2*time.Minute)
+	defer cancel()
+
+	content, err := a.serviceContainer.GetClipboardService().ReadContent(ctx)
+	if err != nil {
+		a.sendClipboardLog("error", fmt.Sprintf("读取剪贴板失败: %v", err))
+		return
+	}
+	if err := a.handleWatchedContent(ctx, content); err != nil {
+		logger.Errorf("处理剪贴板内容失败: %v", err)
+	}
+}
+
+// getRecentTasks 获取最近处理的任务
+func (a *App) getRecentTasks(limit int) ([]*task.TaskIndex, error) {
+	taskManager, err := a.taskHistory()
+	if err != nil {
+		return nil, err
+	}
+	return taskManager.GetRecentTasks(limit)
+}
+
+// buildRecentTasksMenu 构建最近任务子菜单，并定期刷新
+func (a *App) buildRecentTasksMenu(parent *systray.MenuItem) {
+	placeholder := parent.AddSubMenuItem("暂无任务", "")
+	placeholder.Disable()
+
+	slots := make([]*recentTaskSlot, recentTaskSlots)
+	for i := range slots {
+		slot := &recentTaskSlot{item: parent.AddSubMenuItem("", "")}
+		slot.item.Hide()
+		slots[i] = slot
+
+		go func() {
+			for range slot.item.ClickedCh {
+				a.trayMenuMutex.Lock()
+				current := slot.task
+				a.trayMenuMutex.Unlock()
+				a.openTask(current)
+			}
+		}()
+	}
+
+	refresh := func() {
+		tasks, err := a.getRecentTasks(recentTaskSlots)
+		if err != nil {
+			tasks = nil
+		}
+
+		a.trayMenuMutex.Lock()
+		defer a.trayMenuMutex.Unlock()
+
+		for i, slot := range slots {
+			if i >= len(tasks) {
+				slot.task = nil
+				slot.item.Hide()
+				continue
+			}
+			slot.task = tasks[i]
+			slot.item.SetTitle(recentTaskTitle(tasks[i]))
+			slot.item.Show()
+		}
+		if len(tasks) == 0 {
+			placeholder.Show()
+		} else {
+			placeholder.Hide()
+		}
+	}
+
+	refresh()
+	go func() {
+		ticker := time.NewTicker(recentTaskRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
+
+// recentTaskTitle 生成最近任务菜单项标题
+func recentTaskTitle(t *task.TaskIndex) string {
+	title := t.Title
+	if title == "" {
+		title = t.TaskID
+	}
+	if len([]rune(title)) > 30 {
+		title = string([]rune(title)[:30]) + "..."
+	}
+
+	status := "⏳"
+	switch t.Status {
+	case task.TaskStatusSuccess:
+		status = "✅"
+	case task.TaskStatusFailed:
+		status = "❌"
+	}
+	return fmt.Sprintf("%s %s  %s", status, t.StartTime.Format("01-02 15:04"), title)
+}
+
+// openTask 打开任务：已创建到 Microsoft To Do 的任务在浏览器中打开，否则显示主窗口
+func (a *App) openTask(t *task.TaskIndex) {
+	if t == nil || t.TodoTaskID == "" {
+		a.ShowWindow()
+		return
+	}
+	wailsRuntime.BrowserOpenURL(a.ctx, fmt.Sprintf(todoTaskURLTemplate, t.TodoTaskID))
+}
+
+// openLastTask 在 Microsoft To Do 中打开最近创建的任务
+func (a *App) openLastTask() {
+	tasks, err := a.getRecentTasks(0)
+	if err != nil {
+		a.sendClipboardLog("error", fmt.Sprintf("获取最近任务失败: %v", err))
+		return
+	}
+	for _, t := range tasks {
+		if t.TodoTaskID != "" {
+			a.openTask(t)
+			return
+		}
+	}
+	a.sendClipboardLog("warn", "还没有创建到 Microsoft To Do 的任务")
+}
+
+// cleanCache 清理超过 auto_cleanup_days 的图片和临时文件，任务索引、归档和处理指标不受影响
+func (a *App) cleanCache() {
+	if a.serviceContainer == nil {
+		a.sendClipboardLog("error", "服务未初始化，请先完成配置")
+		return
+	}
+
+	cacheConfig := models.DefaultCacheConfig()
+	if serverConfig := a.serviceContainer.GetConfig(); serverConfig != nil {
+		cacheConfig = serverConfig.Cache
+	}
+	days := cacheConfig.AutoCleanupDays
+	if days <= 0 {
+		days = models.DefaultCacheConfig().AutoCleanupDays
+	}
+
+	req := &commands.CommandRequest{
+		Command: "clean",
+		Args: map[string]interface{}{"options": &services.CleanupOptions{
+			Images:    true,
+			Temp:      true,
+			OlderThan: fmt.Sprintf("%dd", days),
+		}},
+	}
+	resp, err := commands.NewCleanCommand(a.serviceContainer).Execute(a.ctx, req)
+	if err != nil {
+		a.sendClipboardLog("error", fmt.Sprintf("清理缓存失败: %v", err))
+		return
+	}
+	if !resp.Success {
+		a.sendClipboardLog("error", fmt.Sprintf("清理缓存失败: %s", resp.Error))
+		return
+	}
+
+	if data, ok := resp.Data.(map[string]interface{}); ok {
+		a.sendClipboardLog("success", fmt.Sprintf("清理缓存完成: 删除 %v 个文件", data["total_files"]))
+		return
+	}
+	a.sendClipboardLog("success", "清理缓存完成")
+}
+
+// buildProfilesMenu 构建切换配置子菜单
+func (a *App) buildProfilesMenu(parent *systray.MenuItem) {
+	profiles, err := a.GetProfiles()
+	if err != nil || len(profiles) == 0 {
+		placeholder := parent.AddSubMenuItem("没有可用的配置", "")
+		placeholder.Disable()
+		return
+	}
+
+	active := a.GetActiveProfile()
+	for _, name := range profiles {
+		mi := parent.AddSubMenuItemCheckbox(name, profileFileName(name), name == active)
+
+		a.trayMenuMutex.Lock()
+		a.profileMenuItems = append(a.profileMenuItems, profileMenuItem{name: name, item: mi})
+		a.trayMenuMutex.Unlock()
+
+		profile := name
+		go func() {
+			for range mi.ClickedCh {
+				if err := a.SwitchProfile(profile); err != nil {
+					a.sendClipboardLog("error", fmt.Sprintf("切换配置失败: %v", err))
+				}
+			}
+		}()
+	}
+}
+
+// profileMenuItem 切换配置子菜单中的一项
+type profileMenuItem struct {
+	name string
+	item *systray.MenuItem
+}
+
+// syncProfileMenuItems 同步配置菜单项的勾选状态
+func (a *App) syncProfileMenuItems(active string) {
+	a.trayMenuMutex.Lock()
+	defer a.trayMenuMutex.Unlock()
+
+	for _, p := range a.profileMenuItems {
+		if p.name == active {
+			p.item.Check()
+		} else {
+			p.item.Uncheck()
+		}
+	}
+}
+
+// profileFileName 返回配置对应的文件名：default 对应 server.yaml，其他对应 server.<name>.yaml
+func profileFileName(name string) string {
+	if name == "" || name == defaultProfile {
+		return "server.yaml"
+	}
+	return fmt.Sprintf("server.%s.yaml", name)
+}
+
+// GetProfiles 获取配置目录下可用的配置列表
+func (a *App) GetProfiles() ([]string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(configDir, "server*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []string
+	for _, match := range matches {
+		base := filepath.Base(match)
+		if base == "server.yaml" {
+			profiles = append(profiles, defaultProfile)
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(base, "server."), ".yaml")
+		if name != "" && name != base && !strings.Contains(name, ".") {
+			profiles = append(profiles, name)
+		}
+	}
+
+	// default 始终排在第一位
+	sort.SliceStable(profiles, func(i, j int) bool {
+		if profiles[i] == defaultProfile || profiles[j] == defaultProfile {
+			return profiles[i] == defaultProfile
+		}
+		return profiles[i] < profiles[j]
+	})
+	return profiles, nil
+}
+
+// GetActiveProfile 获取当前使用的配置
+func (a *App) GetActiveProfile() string {
+	if a.profile == "" {
+		return defaultProfile
+	}
+	return a.profile
+}
+
+// SwitchProfile 切换到指定配置并重建服务容器
+func (a *App) SwitchProfile(name string) error {
+	if name == a.GetActiveProfile() {
+		return nil
+	}
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(configDir, profileFileName(name))); err != nil {
+		return fmt.Errorf("配置 %s 不存在: %w", name, err)
+	}
+
+	// 停止依赖旧服务容器的后台服务
+	wasWatching := a.IsClipboardWatching()
+	a.StopClipboardWatch()
+	if a.serviceContainer != nil {
+		if tokenRefresher := a.serviceContainer.GetTokenRefresherService(); tokenRefresher != nil {
+			if err := tokenRefresher.Stop(); err != nil {
+				logger.Warnf("停止 token 刷新服务失败: %v", err)
+			}
+		}
+	}
+
+	previous := a.profile
+	a.profile = name
+	if err := a.InitializeServiceContainer(); err != nil {
+		a.profile = previous
+		if restoreErr := a.InitializeServiceContainer(); restoreErr != nil {
+			logger.Errorf("恢复配置 %s 失败: %v", a.GetActiveProfile(), restoreErr)
+		}
+		go a.startTokenRefresher()
+		return fmt.Errorf("切换到配置 %s 失败: %w", name, err)
+	}
+
+	go a.startTokenRefresher()
+	if wasWatching {
+		if err := a.StartClipboardWatch(); err != nil {
+			logger.Errorf("重新开启剪贴板监听失败: %v", err)
+		}
+	}
+
+	a.syncProfileMenuItems(name)
+	a.sendClipboardLog("success", fmt.Sprintf("已切换到配置: %s", name))
+	wailsRuntime.EventsEmit(a.ctx, "profileChanged", name)
+	return nil
+}
@@ -118,6 +118,8 @@ type ParsedTaskInfo struct {
 	Description  string    `json:"description,omitempty"`   // 任务描述
 	Date         string    `json:"date"`                    // 任务日期 (YYYY-MM-DD)
 	Time         string    `json:"time"`                    // 任务时间 (HH:MM)
+	EndDate      string    `json:"end_date,omitempty"`      // 结束日期 (YYYY-MM-DD)，跨天范围
+	EndTime      string    `json:"end_time,omitempty"`      // 结束时间 (HH:MM)
 	RemindBefore string    `json:"remind_before,omitempty"` // 提前提醒时间
 	Priority     string    `json:"priority,omitempty"`      // 优先级
 	List         string    `json:"list,omitempty"`          // 任务列表
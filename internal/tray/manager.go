@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	traymenu "github.com/allanpk716/to_icalendar/pkg/tray"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -12,7 +13,7 @@ type TrayManager struct {
 	ctx     context.Context
 	app     *TrayApplication
 	icon    *TrayIcon
-	menu    *TrayMenu
+	menu    *traymenu.TrayMenu
 	running bool
 }
 
@@ -37,8 +38,8 @@ func (tm *TrayManager) Initialize() error {
 	tm.icon = NewTrayIcon("assets/icons/tray-32.png", 32)
 
 	// 创建默认托盘菜单
-	tm.menu = NewTrayMenu()
-	tm.menu.AddItem(NewMenuItem("exit", "退出", "退出应用程序", MenuTypeAction, "quit", "", false, true, 1))
+	tm.menu = traymenu.NewTrayMenu()
+	tm.menu.AddItem(traymenu.NewMenuItem("exit", "退出", "退出应用程序", traymenu.MenuTypeAction, "quit", "", false, true, 1))
 
 	LogInfo("Tray manager initialized for app: %s", tm.app.Name)
 	return nil
@@ -116,8 +116,9 @@ func (app *Application) Shutdown(ctx context.Context) error {
 	app.mu.Lock()
 	defer app.mu.Unlock()
 
-	if app.unifiedCacheMgr != nil {
-		// 清理缓存资源
+	// 停止后台缓存淘汰和指标端点
+	if container, ok := app.container.(*ServiceContainer); ok {
+		container.Close()
 	}
 
 	logger.Info("应用已关闭")
@@ -281,8 +282,30 @@ func (app *Application) forceCleanupLegacyDirs(legacyPaths *cache.LegacyCachePat
 }
 
 // checkAndMigrateConfigFiles 检查并迁移配置文件到用户配置目录
+// 配置目录中缺少的文件从旧位置（工作目录、程序目录）复制过来；版本升级由用户运行 config migrate 完成，这里只提示
 func (app *Application) checkAndMigrateConfigFiles(configDir string) error {
-	// 这里可以添加配置文件迁移逻辑
-	// 目前暂时不需要特殊的迁移逻辑
+	serverPath, imagePath := config.ConfigFilePaths(configDir)
+	for _, path := range []string{serverPath, imagePath} {
+		legacy := config.FindLegacyConfigFile(configDir, filepath.Base(path))
+		if legacy == "" {
+			continue
+		}
+		data, err := os.ReadFile(legacy)
+		if err != nil {
+			return fmt.Errorf("读取旧配置文件失败: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("复制旧配置文件失败: %w", err)
+		}
+		logger.Infof("已将旧位置的配置文件 %s 复制到 %s", legacy, path)
+	}
+
+	if issues, err := config.ValidateServerConfigFile(serverPath); err == nil {
+		for _, issue := range issues {
+			if issue.Path == "config_version" {
+				logger.Infof("配置文件版本检查: %s", issue.String())
+			}
+		}
+	}
 	return nil
 }
\ No newline at end of file
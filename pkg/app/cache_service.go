@@ -1,19 +1,30 @@
 package app
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
 	"github.com/allanpk716/to_icalendar/pkg/cache"
+	"github.com/allanpk716/to_icalendar/pkg/deduplication"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
 	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/task"
 )
 
 // CacheServiceImpl 缓存服务实现
 type CacheServiceImpl struct {
 	cacheManager *cache.UnifiedCacheManager
+	quotaManager *cache.QuotaManager
 }
 
 // NewCacheService 创建缓存服务
-func NewCacheService(cacheManager *cache.UnifiedCacheManager) services.CacheService {
+func NewCacheService(cacheManager *cache.UnifiedCacheManager, quotaManager *cache.QuotaManager) services.CacheService {
 	return &CacheServiceImpl{
 		cacheManager: cacheManager,
+		quotaManager: quotaManager,
 	}
 }
 
@@ -28,6 +39,11 @@ func (s *CacheServiceImpl) GetManager() *cache.UnifiedCacheManager {
 	return s.cacheManager
 }
 
+// GetQuotaManager 获取缓存配额管理器
+func (s *CacheServiceImpl) GetQuotaManager() *cache.QuotaManager {
+	return s.quotaManager
+}
+
 // GetCacheDir 获取缓存目录
 func (s *CacheServiceImpl) GetCacheDir() string {
 	return s.cacheManager.GetBaseCacheDir()
@@ -37,4 +53,55 @@ func (s *CacheServiceImpl) GetCacheDir() string {
 func (s *CacheServiceImpl) Cleanup() error {
 	// 简单实现，实际的清理逻辑在 CleanupService 中
 	return nil
-}
\ No newline at end of file
+}
+
+// quotasFromConfig 将缓存配置中的配额转换为各缓存类型的配额
+func quotasFromConfig(cacheConfig models.CacheConfig) map[cache.CacheType]cache.Quota {
+	quotas := make(map[cache.CacheType]cache.Quota)
+	for cacheType, quota := range cacheConfig.GetQuotas() {
+		quotas[cache.CacheType(cacheType)] = cache.Quota{
+			MaxBytes: quota.MaxSizeMB * 1024 * 1024,
+			MaxItems: quota.MaxItems,
+			MaxAge:   time.Duration(quota.MaxAgeDays) * 24 * time.Hour,
+		}
+	}
+	return quotas
+}
+
+// successfulHashProtector 保护成功处理的图片哈希引用的缓存文件，
+// 避免淘汰后同一张截图被重新识别和提交；引用的文件所在的任务目录随之受保护
+func successfulHashProtector(cacheManager *cache.UnifiedCacheManager) cache.ProtectFunc {
+	return func() map[string]bool {
+		protected := make(map[string]bool)
+
+		for _, cacheType := range []cache.CacheType{cache.CacheTypeGlobal, cache.CacheTypeHashes} {
+			data, err := os.ReadFile(cacheManager.GetCacheFilePath(cacheType, "image_hashes.json"))
+			if err != nil {
+				continue
+			}
+			var images []*deduplication.ImageHashCache
+			if err := json.Unmarshal(data, &images); err != nil {
+				continue
+			}
+			for _, image := range images {
+				if image.Success && image.FilePath != "" {
+					protected[filepath.Clean(image.FilePath)] = true
+				}
+			}
+		}
+
+		return protected
+	}
+}
+
+// taskEvictor 通过任务清理器淘汰任务目录，同步更新任务索引和归档索引
+func taskEvictor(taskManager func() (*task.TaskManager, error)) cache.EvictFunc {
+	return func(path string) error {
+		tm, err := taskManager()
+		if err != nil {
+			return err
+		}
+		_, err = task.NewTaskCleaner(tm, logger.GetLogger().GetStdLogger()).EvictTaskDir(path)
+		return err
+	}
+}
@@ -9,18 +9,29 @@ import (
 	"time"
 
 	"github.com/allanpk716/to_icalendar/pkg/cache"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
 	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/task"
 )
 
+// preservedFiles 清理时保留的索引文件，删除后任务历史和归档无法读取
+var preservedFiles = map[string]bool{
+	"task_index.json":    true,
+	"archive_index.json": true,
+}
+
 // CleanupServiceImpl 清理服务实现
 type CleanupServiceImpl struct {
 	cacheManager *cache.UnifiedCacheManager
+	taskManager  func() (*task.TaskManager, error)
 }
 
-// NewCleanupService 创建清理服务
-func NewCleanupService(cacheManager *cache.UnifiedCacheManager) services.CleanupService {
+// NewCleanupService 创建清理服务，taskManager 用于按归档清理任务会话，可以为 nil
+func NewCleanupService(cacheManager *cache.UnifiedCacheManager, taskManager func() (*task.TaskManager, error)) services.CleanupService {
 	return &CleanupServiceImpl{
 		cacheManager: cacheManager,
+		taskManager:  taskManager,
 	}
 }
 
@@ -38,11 +49,27 @@ func (s *CleanupServiceImpl) Cleanup(ctx context.Context, options *services.Clea
 
 	// 如果是预览模式，只统计不删除
 	if options.DryRun {
-		return s.previewCleanup(cacheDir, options)
+		preview := &services.CleanupResult{
+			FilesByType: make(map[string]int64),
+			Skipped:     true,
+			Message:     "预览模式 - 未实际删除文件",
+		}
+		for _, dir := range s.cleanupDirs(cacheDir, options) {
+			if err := s.previewCleanup(dir, options, preview); err != nil {
+				return preview, err
+			}
+		}
+		return preview, s.cleanupTaskArchives(options, preview)
 	}
 
 	// 执行实际清理
-	if err := s.performCleanup(ctx, cacheDir, options, result); err != nil {
+	for _, dir := range s.cleanupDirs(cacheDir, options) {
+		if err := s.performCleanup(ctx, dir, options, result); err != nil {
+			return result, err
+		}
+	}
+
+	if err := s.cleanupTaskArchives(options, result); err != nil {
 		return result, err
 	}
 
@@ -66,14 +93,57 @@ func (s *CleanupServiceImpl) GetCleanupStats(ctx context.Context) (*services.Cle
 	return stats, nil
 }
 
-// previewCleanup 预览清理操作
-func (s *CleanupServiceImpl) previewCleanup(cacheDir string, options *services.CleanupOptions) (*services.CleanupResult, error) {
-	result := &services.CleanupResult{
-		FilesByType: make(map[string]int64),
-		Skipped:     true,
-		Message:     "预览模式 - 未实际删除文件",
+// cleanupTaskArchives 启用 compress_old_tasks 时，将旧任务目录打包归档并按归档删除过期任务
+func (s *CleanupServiceImpl) cleanupTaskArchives(options *services.CleanupOptions, result *services.CleanupResult) error {
+	if s.taskManager == nil || !(options.All || options.Tasks) {
+		return nil
+	}
+
+	taskManager, err := s.taskManager()
+	if err != nil {
+		return fmt.Errorf("获取任务管理器失败: %w", err)
+	}
+	cacheConfig := taskManager.GetCacheConfig()
+	if !cacheConfig.CompressOldTasks {
+		return nil
+	}
+
+	retention := time.Duration(cacheConfig.GetEffectiveTaskRetentionDays()) * 24 * time.Hour
+	if options.OlderThan != "" {
+		span, err := models.ParseSpan(options.OlderThan)
+		if err != nil {
+			return fmt.Errorf("无效的 --older-than: %w", err)
+		}
+		retention = span
+	}
+
+	cleaner := task.NewTaskCleaner(taskManager, logger.GetLogger().GetStdLogger())
+	archived, err := cleaner.ArchiveOldTasks(retention, options.DryRun)
+	if err != nil {
+		return fmt.Errorf("归档任务会话失败: %w", err)
+	}
+
+	result.FilesByType["tasks_archived"] += int64(archived.TasksArchived)
+	result.FilesByType["task_archives"] += int64(archived.ArchivesRemoved)
+	result.TotalFiles += int64(archived.ArchivesRemoved)
+	result.TotalSize += archived.BytesFreed
+	return nil
+}
+
+// cleanupDirs 需要遍历的缓存目录：全局缓存目录，以及选中的图片和临时文件目录
+func (s *CleanupServiceImpl) cleanupDirs(globalDir string, options *services.CleanupOptions) []string {
+	dirs := []string{globalDir}
+	if options.All || options.Images {
+		dirs = append(dirs, s.cacheManager.GetCacheDir(cache.CacheTypeImages))
+	}
+	if options.All || options.Temp {
+		dirs = append(dirs, s.cacheManager.GetCacheDir(cache.CacheTypeTemp))
 	}
+	return dirs
+}
 
+// previewCleanup 预览清理操作，统计结果累加到 result
+func (s *CleanupServiceImpl) previewCleanup(cacheDir string, options *services.CleanupOptions, result *services.CleanupResult) error {
 	err := filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 忽略访问错误
@@ -90,10 +160,10 @@ func (s *CleanupServiceImpl) previewCleanup(cacheDir string, options *services.C
 	})
 
 	if err != nil {
-		return result, fmt.Errorf("预览清理时出错: %w", err)
+		return fmt.Errorf("预览清理时出错: %w", err)
 	}
 
-	return result, nil
+	return nil
 }
 
 // performCleanup 执行实际清理
@@ -121,9 +191,16 @@ func (s *CleanupServiceImpl) performCleanup(ctx context.Context, cacheDir string
 
 // shouldCleanFile 判断文件是否应该被清理
 func (s *CleanupServiceImpl) shouldCleanFile(path string, info os.FileInfo, options *services.CleanupOptions) bool {
-	// 检查文件时间
+	if preservedFiles[filepath.Base(path)] {
+		return false
+	}
+
+	// 检查文件时间，支持 30d、2w 和 Go 时长格式
 	if options.OlderThan != "" {
-		duration, err := time.ParseDuration(options.OlderThan)
+		duration, err := models.ParseSpan(options.OlderThan)
+		if err != nil {
+			duration, err = time.ParseDuration(options.OlderThan)
+		}
 		if err == nil {
 			if time.Since(info.ModTime()) < duration {
 				return false
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/config"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// ImageProcessingSection 热加载结果中表示 image_processing.json 的配置段名
+const ImageProcessingSection = "image_processing"
+
+// restartRequiredSections 热加载后仍需重启才能生效的配置段
+var restartRequiredSections = map[string]bool{
+	"metrics": true, // 指标端点的监听地址在启动时绑定
+}
+
+// ReloadResult 一次配置热加载的结果
+type ReloadResult struct {
+	Path            string         // 发生变化的配置文件
+	Changed         []string       // 发生变化的配置段
+	RestartRequired []string       // 已更新但需要重启才能生效的配置段
+	Issues          []config.Issue // 新配置无效时的问题及行号
+	Err             error          // 新配置无效时的错误，此时继续使用上一次有效的配置
+}
+
+// HasChanged 指定配置段是否发生了变化
+func (r *ReloadResult) HasChanged(section string) bool {
+	for _, changed := range r.Changed {
+		if changed == section {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchConfig 监听 server.yaml 和同目录的 image_processing.json，内容变化后校验并热加载
+// onReload 在监听 goroutine 中接收每次热加载的结果，可以为 nil；Close 时停止监听
+func (sc *ServiceContainer) WatchConfig(serverConfigPath string, onReload func(*ReloadResult)) {
+	if sc.configWatcher != nil {
+		return
+	}
+
+	_, imageConfigPath := config.ConfigFilePaths(filepath.Dir(serverConfigPath))
+	paths := []string{serverConfigPath, imageConfigPath}
+	sc.configWatcher = config.NewFileWatcher(paths, config.DefaultWatchInterval, func(path string) {
+		var result *ReloadResult
+		if path == imageConfigPath {
+			result = sc.ReloadImageConfig(path)
+		} else {
+			result = sc.ReloadServerConfig(path)
+		}
+		logReloadResult(result)
+		if onReload != nil {
+			onReload(result)
+		}
+	})
+	sc.configWatcher.Start()
+	logger.Infof("已开启配置热加载: %s", strings.Join(paths, ", "))
+}
+
+// ReloadServerConfig 重新读取并校验 server.yaml，有效时替换当前配置
+func (sc *ServiceContainer) ReloadServerConfig(path string) *ReloadResult {
+	newConfig, err := config.NewConfigManager().ResolveServerConfig(path)
+	if err != nil {
+		result := &ReloadResult{Path: path, Err: fmt.Errorf("新配置无效: %w", err)}
+		if issues, validateErr := config.ValidateServerConfigFile(path); validateErr == nil {
+			result.Issues = issues
+		}
+		return result
+	}
+
+	result := sc.ApplyServerConfig(newConfig)
+	result.Path = path
+	return result
+}
+
+// ApplyServerConfig 原子替换服务器配置，并重建受影响的服务
+// Todo 客户端、Todo 和 Dify 服务在下次使用时按新配置创建；日志级别、缓存配额、处理指标设置和 Token 刷新间隔立即生效。
+// Token 管理器无法按新配置创建时返回错误，不替换配置
+func (sc *ServiceContainer) ApplyServerConfig(newConfig *models.ServerConfig) *ReloadResult {
+	sc.configMutex.RLock()
+	result := &ReloadResult{Changed: config.ChangedSections(sc.config, newConfig)}
+	tokenRefresher := sc.tokenRefresherService
+	quotaManager := sc.quotaManager
+	sc.configMutex.RUnlock()
+
+	if len(result.Changed) == 0 {
+		return result
+	}
+
+	if tokenRefresher != nil && (result.HasChanged("microsoft_todo") || result.HasChanged("token_manager")) {
+		if err := tokenRefresher.Reload(newConfig); err != nil {
+			result.Err = fmt.Errorf("重建 Token 刷新服务失败: %w", err)
+			return result
+		}
+	}
+
+	// 淘汰过程会读取配置，在替换配置前停止旧的配额管理器
+	rebuildQuota := result.HasChanged("cache") && quotaManager != nil
+	if rebuildQuota {
+		quotaManager.Stop()
+	}
+
+	sc.configMutex.Lock()
+	sc.config = newConfig
+
+	// 已创建的服务持有旧配置，置空后按需重新创建
+	sc.todoService = nil
+	sc.difyService = nil
+	if result.HasChanged("microsoft_todo") {
+		sc.todoClientMutex.Lock()
+		sc.todoClient = nil
+		sc.todoClientMutex.Unlock()
+	}
+
+	if rebuildQuota {
+		sc.quotaManager = sc.newQuotaManager()
+		sc.cacheService = NewCacheService(sc.cacheManager, sc.quotaManager)
+	}
+	sc.configMutex.Unlock()
+
+	// 已取得指标存储的命令仍持有同一个实例，原地更新设置
+	if result.HasChanged("cache") {
+		sc.metricsStoreMutex.Lock()
+		if sc.metricsStore != nil {
+			sc.metricsStore.SetConfig(newConfig.Cache)
+		}
+		sc.metricsStoreMutex.Unlock()
+	}
+
+	if result.HasChanged("logging") {
+		if err := logger.GetLogger().UpdateConfig(&newConfig.Logging); err != nil {
+			logger.Warnf("更新日志配置失败: %v", err)
+		}
+	}
+
+	for _, section := range result.Changed {
+		if restartRequiredSections[section] {
+			result.RestartRequired = append(result.RestartRequired, section)
+		}
+	}
+	return result
+}
+
+// ReloadImageConfig 校验 image_processing.json，有效时让剪贴板读取和截图附件使用新配置
+func (sc *ServiceContainer) ReloadImageConfig(path string) *ReloadResult {
+	result := &ReloadResult{Path: path}
+
+	issues, err := config.ValidateImageProcessingFile(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if config.HasErrors(issues) {
+		result.Issues = issues
+		result.Err = fmt.Errorf("新配置无效: %s", firstError(issues))
+		return result
+	}
+
+	if err := sc.clipboardService.ReloadImageConfig(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	// Todo 服务缓存了截图标准化器
+	sc.configMutex.Lock()
+	sc.todoService = nil
+	sc.configMutex.Unlock()
+
+	result.Changed = []string{ImageProcessingSection}
+	return result
+}
+
+// firstError 返回第一个 error 级别的问题
+func firstError(issues []config.Issue) string {
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			return issue.String()
+		}
+	}
+	return ""
+}
+
+// logReloadResult 记录热加载结果
+func logReloadResult(result *ReloadResult) {
+	switch {
+	case result.Err != nil:
+		logger.Errorf("配置热加载失败，继续使用上一次有效的配置: %s: %v", result.Path, result.Err)
+		for _, issue := range result.Issues {
+			if issue.Severity == config.SeverityError {
+				logger.Errorf("  %s", issue.String())
+			}
+		}
+	case len(result.Changed) == 0:
+		logger.Debugf("配置文件已保存但没有变化: %s", result.Path)
+	default:
+		logger.Infof("配置已热加载: %s（%s）", result.Path, strings.Join(result.Changed, ", "))
+		if len(result.RestartRequired) > 0 {
+			logger.Warnf("以下配置需要重启后生效: %s", strings.Join(result.RestartRequired, ", "))
+		}
+	}
+}
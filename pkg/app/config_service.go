@@ -73,18 +73,9 @@ func (s *ConfigServiceImpl) CreateConfigTemplates(ctx context.Context, configDir
 		reminderTemplateExists = true
 	}
 
-	// 创建服务器配置模板
+	// 创建服务器配置模板（带注释，并写入 JSON Schema）
 	if !serverConfigExists {
-		serverConfig := &models.ServerConfig{
-			MicrosoftTodo: models.MicrosoftTodoConfig{
-				TenantID:     "YOUR_TENANT_ID",
-				ClientID:     "YOUR_CLIENT_ID",
-				ClientSecret: "YOUR_CLIENT_SECRET",
-				Timezone:     "Asia/Shanghai",
-			},
-		}
-
-		if err := s.configManager.SaveServerConfig(serverConfigPath, serverConfig); err != nil {
+		if err := s.configManager.CreateServerConfigTemplate(serverConfigPath); err != nil {
 			return &services.ConfigResult{
 				Success: false,
 				Message: fmt.Sprintf("保存服务器配置失败: %v", err),
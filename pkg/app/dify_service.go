@@ -42,6 +42,8 @@ func (ds *DifyServiceImpl) ProcessText(ctx context.Context, text string) (*model
 	// 创建处理选项
 	processingOptions := dify.DefaultProcessingOptions()
 	processingOptions.DefaultRemindBefore = ds.config.Reminder.DefaultRemindBefore
+	processingOptions.DefaultTime = ds.config.Reminder.GetDefaultTime()
+	processingOptions.DefaultList = ds.config.Reminder.GetDefaultList(processingOptions.DefaultList)
 
 	// 创建处理器
 	difyProcessor := dify.NewProcessor(difyClient, "dify-service-user", processingOptions)
@@ -73,6 +75,8 @@ func (ds *DifyServiceImpl) ProcessImage(ctx context.Context, imageData []byte) (
 	// 创建处理选项
 	processingOptions := dify.DefaultProcessingOptions()
 	processingOptions.DefaultRemindBefore = ds.config.Reminder.DefaultRemindBefore
+	processingOptions.DefaultTime = ds.config.Reminder.GetDefaultTime()
+	processingOptions.DefaultList = ds.config.Reminder.GetDefaultList(processingOptions.DefaultList)
 
 	// 创建处理器
 	difyProcessor := dify.NewProcessor(difyClient, "dify-service-user", processingOptions)
@@ -95,12 +99,22 @@ func (ds *DifyServiceImpl) convertProcessingResponseToDifyResponse(resp *dify.Pr
 		Metadata:  make(map[string]interface{}),
 	}
 
+	// 保留工作流的耗时和 token 统计，输出已转换到 Answer 中，不再重复保留
+	if resp.Workflow != nil {
+		workflow := *resp.Workflow
+		workflow.Outputs = nil
+		difyResp.Data = &workflow
+	}
+
 	// 将处理结果添加到Answer字段
 	if resp.Success && resp.Reminder != nil {
 		// 成功情况，将提醒信息序列化为JSON字符串
 		if reminderJSON, err := json.Marshal(resp.Reminder); err == nil {
 			difyResp.Answer = string(reminderJSON)
 		}
+	} else if resp.ErrorMessage == "" && resp.ParsedInfo != nil && resp.ParsedInfo.OriginalText != "" {
+		// 置信度不足未生成提醒时，保留原始回答交给调用方解析
+		difyResp.Answer = resp.ParsedInfo.OriginalText
 	} else {
 		// 失败情况，将错误信息放入Answer
 		difyResp.Answer = resp.ErrorMessage
@@ -1,16 +1,29 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
 	"github.com/allanpk716/to_icalendar/pkg/cache"
+	"github.com/allanpk716/to_icalendar/pkg/config"
 	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/metrics"
+	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
 	"github.com/allanpk716/to_icalendar/pkg/models"
 	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/task"
+	"github.com/allanpk716/to_icalendar/pkg/todosync"
 )
 
 // ServiceContainer 服务容器实现
 type ServiceContainer struct {
 	configDir            string
 	config               *models.ServerConfig
+	configMutex          sync.RWMutex // 保护 config 及依赖配置的服务，热加载时整体替换
+	configWatcher        *config.FileWatcher
 	cacheManager         *cache.UnifiedCacheManager
 	logger               interface{}
 	configService        services.ConfigService
@@ -20,6 +33,16 @@ type ServiceContainer struct {
 	todoService          services.TodoService
 	difyService          services.DifyService
 	tokenRefresherService services.TokenRefresherService
+	taskManager          *task.TaskManager
+	taskManagerMutex     sync.Mutex
+	quotaManager         *cache.QuotaManager
+	metricsStore         *metrics.Store
+	metricsStoreMutex    sync.Mutex
+	metricsServer        *metrics.Server
+	syncStore            *todosync.Store
+	syncStoreMutex       sync.Mutex
+	todoClient           *microsofttodo.SimpleTodoClient
+	todoClientMutex      sync.Mutex
 }
 
 // NewServiceContainer 创建服务容器
@@ -42,11 +65,14 @@ func (sc *ServiceContainer) initializeServices() {
 	// 初始化配置服务
 	sc.configService = NewConfigService()
 
+	// 初始化缓存配额，配置了配额时在后台执行淘汰
+	sc.quotaManager = sc.newQuotaManager()
+
 	// 初始化缓存服务
-	sc.cacheService = NewCacheService(sc.cacheManager)
+	sc.cacheService = NewCacheService(sc.cacheManager, sc.quotaManager)
 
 	// 初始化清理服务
-	sc.cleanupService = NewCleanupService(sc.cacheManager)
+	sc.cleanupService = NewCleanupService(sc.cacheManager, sc.GetTaskManager)
 
 	// 初始化剪贴板服务
 	sc.clipboardService = services.NewClipboardService()
@@ -61,6 +87,51 @@ func (sc *ServiceContainer) initializeServices() {
 	sc.tokenRefresherService = nil
 }
 
+// newQuotaManager 根据缓存配置创建配额管理器，缓存不可用时返回 nil
+func (sc *ServiceContainer) newQuotaManager() *cache.QuotaManager {
+	if sc.cacheManager == nil {
+		return nil
+	}
+
+	cacheConfig := models.DefaultCacheConfig()
+	if sc.config != nil {
+		cacheConfig = sc.config.Cache
+	}
+
+	quotaManager := cache.NewQuotaManager(sc.cacheManager, quotasFromConfig(cacheConfig),
+		cache.EvictionPolicy(cacheConfig.EvictionPolicy), logger.GetLogger().GetStdLogger())
+	if cacheConfig.PreserveSuccessfulHashes {
+		quotaManager.SetProtector(successfulHashProtector(sc.cacheManager))
+	}
+	quotaManager.SetEvictor(cache.CacheTypeTasks, taskEvictor(sc.GetTaskManager))
+	quotaManager.Start(cacheConfig.GetEvictionInterval())
+	return quotaManager
+}
+
+// Close 停止后台任务
+func (sc *ServiceContainer) Close() {
+	if sc.configWatcher != nil {
+		sc.configWatcher.Stop()
+		sc.configWatcher = nil
+	}
+
+	// 热加载会替换配额管理器
+	sc.configMutex.RLock()
+	quotaManager := sc.quotaManager
+	sc.configMutex.RUnlock()
+	if quotaManager != nil {
+		quotaManager.Stop()
+	}
+	if sc.metricsServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := sc.metricsServer.Shutdown(ctx); err != nil {
+			logger.Warnf("停止指标端点失败: %v", err)
+		}
+		sc.metricsServer = nil
+	}
+}
+
 // GetConfigService 获取配置服务
 func (sc *ServiceContainer) GetConfigService() services.ConfigService {
 	return sc.configService
@@ -68,6 +139,8 @@ func (sc *ServiceContainer) GetConfigService() services.ConfigService {
 
 // GetCacheService 获取缓存服务
 func (sc *ServiceContainer) GetCacheService() services.CacheService {
+	sc.configMutex.RLock()
+	defer sc.configMutex.RUnlock()
 	return sc.cacheService
 }
 
@@ -83,22 +156,78 @@ func (sc *ServiceContainer) GetClipboardService() services.ClipboardService {
 
 // GetTodoService 获取 Todo 服务
 func (sc *ServiceContainer) GetTodoService() services.TodoService {
+	sc.configMutex.Lock()
+	defer sc.configMutex.Unlock()
+
 	if sc.todoService == nil {
-		sc.todoService = NewTodoService(sc.config, sc.logger)
+		sc.todoService = NewTodoService(sc.configDir, sc.config, sc.GetTodoClient, sc.GetSyncStore, sc.logger)
 	}
 	return sc.todoService
 }
 
+// GetTodoClient 获取共享的 Microsoft Todo 客户端
+// 同一容器内的所有调用复用该客户端，令牌和列表ID缓存只需加载一次
+func (sc *ServiceContainer) GetTodoClient() (*microsofttodo.SimpleTodoClient, error) {
+	serverConfig := sc.GetConfig()
+
+	sc.todoClientMutex.Lock()
+	defer sc.todoClientMutex.Unlock()
+
+	if sc.todoClient == nil {
+		if serverConfig == nil {
+			return nil, fmt.Errorf("配置未初始化")
+		}
+		client, err := microsofttodo.NewSimpleTodoClient(
+			serverConfig.MicrosoftTodo.TenantID,
+			serverConfig.MicrosoftTodo.ClientID,
+			serverConfig.MicrosoftTodo.ClientSecret,
+			serverConfig.MicrosoftTodo.UserEmail,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("创建 Microsoft Todo 客户端失败: %w", err)
+		}
+		sc.todoClient = client
+	}
+	return sc.todoClient, nil
+}
+
 // GetDifyService 获取 Dify 服务
 func (sc *ServiceContainer) GetDifyService() services.DifyService {
+	sc.configMutex.Lock()
+	defer sc.configMutex.Unlock()
+
 	if sc.difyService == nil {
 		sc.difyService = NewDifyService(sc.config, sc.logger)
 	}
 	return sc.difyService
 }
 
+// GetDifyBackend 按名称获取 Dify 服务，名称为空或 default 时返回默认 Dify 服务
+func (sc *ServiceContainer) GetDifyBackend(name string) (services.DifyService, error) {
+	if name == "" || name == models.DefaultDifyBackend {
+		return sc.GetDifyService(), nil
+	}
+	serverConfig := sc.GetConfig()
+	if serverConfig == nil {
+		return nil, fmt.Errorf("配置未初始化")
+	}
+
+	backend, err := serverConfig.GetDifyBackend(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 使用配置副本替换 Dify 配置，其他设置（如默认提醒时间）保持一致
+	config := *serverConfig
+	config.Dify = backend
+	return NewDifyService(&config, sc.logger), nil
+}
+
 // GetTokenRefresherService 获取 Token 刷新服务
 func (sc *ServiceContainer) GetTokenRefresherService() services.TokenRefresherService {
+	sc.configMutex.Lock()
+	defer sc.configMutex.Unlock()
+
 	if sc.tokenRefresherService == nil {
 		// 获取 logger 实例
 		var loggerInstance logger.Logger
@@ -114,6 +243,112 @@ func (sc *ServiceContainer) GetTokenRefresherService() services.TokenRefresherSe
 	return sc.tokenRefresherService
 }
 
+// GetTaskManager 获取任务会话管理器
+func (sc *ServiceContainer) GetTaskManager() (*task.TaskManager, error) {
+	// 后台淘汰也会读取任务索引
+	sc.taskManagerMutex.Lock()
+	defer sc.taskManagerMutex.Unlock()
+
+	if sc.taskManager == nil {
+		cacheConfig := models.DefaultCacheConfig()
+		if serverConfig := sc.GetConfig(); serverConfig != nil {
+			cacheConfig = serverConfig.Cache
+		}
+
+		taskManager, err := task.NewTaskManager(sc.configDir, cacheConfig, logger.GetLogger().GetStdLogger())
+		if err != nil {
+			return nil, fmt.Errorf("创建任务管理器失败: %w", err)
+		}
+		sc.taskManager = taskManager
+	}
+	return sc.taskManager, nil
+}
+
+// GetMetricsStore 获取处理指标存储，enable_cache_metrics 关闭时只累加到进程内收集器，不写入文件
+func (sc *ServiceContainer) GetMetricsStore() *metrics.Store {
+	sc.metricsStoreMutex.Lock()
+	defer sc.metricsStoreMutex.Unlock()
+
+	if sc.metricsStore == nil {
+		cacheConfig := models.DefaultCacheConfig()
+		if serverConfig := sc.GetConfig(); serverConfig != nil {
+			cacheConfig = serverConfig.Cache
+		}
+
+		var dir string
+		if sc.cacheManager != nil {
+			// 指标使用单独的缓存目录，clean 不会删除，过期文件由 Store.Prune 清理
+			dir = sc.cacheManager.GetCacheDir(cache.CacheTypeMetrics)
+			legacyDir := filepath.Join(sc.cacheManager.GetCacheDir(cache.CacheTypeGlobal), metrics.DirName)
+			if err := metrics.MigrateDir(legacyDir, dir); err != nil {
+				logger.Warnf("迁移处理指标目录失败: %v", err)
+			}
+		} else {
+			// 缓存不可用时无处存放指标
+			cacheConfig.EnableCacheMetrics = false
+		}
+		sc.metricsStore = metrics.NewStore(dir, cacheConfig, logger.GetLogger().GetStdLogger())
+		sc.metricsStore.SetCollector(metrics.NewCollector())
+	}
+	return sc.metricsStore
+}
+
+// GetSyncStore 获取共享的任务同步存储
+// sync 命令写入和创建任务前的去重检查使用同一个存储，同步结果无需重新加载即可生效
+func (sc *ServiceContainer) GetSyncStore() (*todosync.Store, error) {
+	sc.syncStoreMutex.Lock()
+	defer sc.syncStoreMutex.Unlock()
+
+	if sc.syncStore == nil {
+		if sc.cacheManager == nil {
+			return nil, fmt.Errorf("缓存管理器未初始化")
+		}
+		syncDir, err := sc.cacheManager.GetSyncCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("获取同步缓存目录失败: %w", err)
+		}
+		store, err := todosync.NewStore(syncDir)
+		if err != nil {
+			return nil, fmt.Errorf("加载同步缓存失败: %w", err)
+		}
+		sc.syncStore = store
+	}
+	return sc.syncStore, nil
+}
+
+// StartMetricsServer 按 metrics 配置启动本地 /metrics 端点，未启用时不做处理
+// clipboardHealth 为 nil 时不输出剪贴板健康指标
+func (sc *ServiceContainer) StartMetricsServer(clipboardHealth metrics.HealthChecker) error {
+	serverConfig := sc.GetConfig()
+	if serverConfig == nil || !serverConfig.Metrics.Enabled || sc.metricsServer != nil {
+		return nil
+	}
+
+	sources := metrics.Sources{
+		Collector:   sc.GetMetricsStore().Collector(),
+		TokenStatus: sc.GetTokenRefresherService().GetTokenStatus,
+		Clipboard:   clipboardHealth,
+	}
+	if sc.quotaManager != nil {
+		sources.CacheUsage = sc.cacheUsage
+	}
+
+	server, err := metrics.StartServer(serverConfig.Metrics.GetListen(), sources, logger.GetLogger().GetStdLogger())
+	if err != nil {
+		return err
+	}
+	sc.metricsServer = server
+	return nil
+}
+
+// cacheUsage 返回当前配额管理器统计的缓存用量，缓存配置热加载后使用新的配额
+func (sc *ServiceContainer) cacheUsage() ([]cache.TypeUsage, error) {
+	sc.configMutex.RLock()
+	quotaManager := sc.quotaManager
+	sc.configMutex.RUnlock()
+	return quotaManager.Usage()
+}
+
 // GetLogger 获取日志器
 func (sc *ServiceContainer) GetLogger() interface{} {
 	return sc.logger
@@ -124,8 +359,10 @@ func (sc *ServiceContainer) GetConfigDir() string {
 	return sc.configDir
 }
 
-// GetConfig 获取配置
+// GetConfig 获取配置，热加载后返回新的配置
 func (sc *ServiceContainer) GetConfig() *models.ServerConfig {
+	sc.configMutex.RLock()
+	defer sc.configMutex.RUnlock()
 	return sc.config
 }
 
@@ -3,58 +3,282 @@ package app
 import (
 	"context"
 	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/allanpk716/to_icalendar/pkg/image"
 	"github.com/allanpk716/to_icalendar/pkg/logger"
 	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
 	"github.com/allanpk716/to_icalendar/pkg/models"
 	"github.com/allanpk716/to_icalendar/pkg/services"
 	timezonepkg "github.com/allanpk716/to_icalendar/pkg/timezone"
+	"github.com/allanpk716/to_icalendar/pkg/todosync"
+	wqlogger "github.com/WQGroup/logger"
 )
 
 // NewTodoService 创建 Todo 服务
-func NewTodoService(config *models.ServerConfig, logger interface{}) services.TodoService {
+// clientProvider 为 nil 时每次调用都会新建客户端，syncStoreProvider 为 nil 时不做同步缓存去重
+func NewTodoService(configDir string, config *models.ServerConfig, clientProvider func() (*microsofttodo.SimpleTodoClient, error), syncStoreProvider func() (*todosync.Store, error), logger interface{}) services.TodoService {
 	return &TodoServiceImpl{
-		config: config,
-		logger: logger,
+		configDir:         configDir,
+		config:            config,
+		clientProvider:    clientProvider,
+		syncStoreProvider: syncStoreProvider,
+		logger:            logger,
 	}
 }
 
 // TodoServiceImpl Todo 服务实现
 type TodoServiceImpl struct {
-	config *models.ServerConfig
-	logger interface{}
+	configDir      string
+	config         *models.ServerConfig
+	clientProvider func() (*microsofttodo.SimpleTodoClient, error)
+	logger         interface{}
+
+	syncStoreProvider func() (*todosync.Store, error)
+
+	normalizerOnce sync.Once
+	normalizer     *image.ImageNormalizer
 }
 
 // CreateTask 创建任务
 func (ts *TodoServiceImpl) CreateTask(ctx context.Context, reminder *models.Reminder) error {
+	_, err := ts.CreateTaskWithAttachment(ctx, reminder, nil)
+	return err
+}
+
+// CreateTaskWithAttachment 创建任务，并按列表配置将源截图作为附件上传
+// 附件上传失败不影响任务创建，只记录在结果的 Warnings 中。
+// 提醒的 kind 为 event 时只创建日历事件，为 both 时同时创建任务和日历事件，
+// 此时日历事件创建失败只记录警告
+func (ts *TodoServiceImpl) CreateTaskWithAttachment(ctx context.Context, reminder *models.Reminder, screenshot []byte) (*services.TaskCreationResult, error) {
 	if ts.config == nil {
-		return fmt.Errorf("配置未初始化")
+		return nil, fmt.Errorf("配置未初始化")
 	}
 
 	if reminder == nil {
-		return fmt.Errorf("提醒对象为空")
+		return nil, fmt.Errorf("提醒对象为空")
 	}
 
-	// 验证 Microsoft Todo 配置
-	if ts.config.MicrosoftTodo.TenantID == "" ||
-		ts.config.MicrosoftTodo.ClientID == "" ||
-		ts.config.MicrosoftTodo.ClientSecret == "" ||
-		ts.config.MicrosoftTodo.UserEmail == "" {
-		return fmt.Errorf("Microsoft Todo 配置不完整")
+	todoClient, err := ts.getClient()
+	if err != nil {
+		return nil, err
 	}
 
-	// 创建 Todo 客户端
-	todoClient, err := microsofttodo.NewSimpleTodoClient(
-		ts.config.MicrosoftTodo.TenantID,
-		ts.config.MicrosoftTodo.ClientID,
-		ts.config.MicrosoftTodo.ClientSecret,
-		ts.config.MicrosoftTodo.UserEmail,
-	)
+	kind := models.NormalizeKind(string(reminder.Kind))
+	result := &services.TaskCreationResult{Kind: string(kind)}
+	if kind.WantsTask() {
+		if err := ts.createTodoTask(ctx, todoClient, reminder, screenshot, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if kind.WantsEvent() {
+		event, warnings, err := ts.createEvent(ctx, todoClient, reminder)
+		if err != nil {
+			if !kind.WantsTask() {
+				return nil, err
+			}
+			logger.FromContext(ctx).Warnf("任务已创建，但日历事件创建失败: %v", err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("calendar event: %v", err))
+		} else {
+			result.EventID = event.ID
+			result.EventLink = event.WebLink
+			result.Warnings = append(result.Warnings, warnings...)
+		}
+	}
+
+	return result, nil
+}
+
+// createTodoTask 创建 Microsoft Todo 任务并上传截图附件，结果写入 result
+func (ts *TodoServiceImpl) createTodoTask(ctx context.Context, todoClient *microsofttodo.SimpleTodoClient, reminder *models.Reminder, screenshot []byte, result *services.TaskCreationResult) error {
+	log := logger.FromContext(ctx)
+	request, listName, err := ts.buildTaskRequest(reminder)
+	if err != nil {
+		return err
+	}
+
+	// 同步缓存中已有相同任务时不再调用 Graph 创建
+	if existing := ts.findSyncedDuplicate(ctx, request.Title, reminder.Date, listName); existing != nil {
+		log.Infof("同步缓存中已存在相同任务，跳过创建: %s (列表: %s, ID: %s)", existing.Title, listName, existing.ID)
+		result.TaskID = existing.ID
+		result.ListID = existing.ListID
+		result.ListName = listName
+		result.Duplicate = true
+		return nil
+	}
+
+	// 获取或创建任务列表（列表ID在共享客户端中缓存）
+	listID, err := todoClient.GetOrCreateTaskList(listName)
 	if err != nil {
-		return fmt.Errorf("创建 Microsoft Todo 客户端失败: %w", err)
+		return fmt.Errorf("无法创建任务列表 '%s': %w", listName, err)
+	}
+	request.ListID = listID
+
+	// 创建任务（使用UTC时间传递，时区信息用于API转换）
+	created, err := todoClient.CreateTaskFromRequest(ctx, request)
+	if err != nil {
+		return fmt.Errorf("创建 Microsoft Todo 任务失败: %w", err)
+	}
+	if len(created.Warnings) > 0 {
+		log.Warnf("任务已创建，但部分检查项或链接添加失败: %v", created.Warnings)
 	}
 
+	result.TaskID = created.ID
+	result.ListID = listID
+	result.ListName = listName
+	result.Warnings = append(result.Warnings, created.Warnings...)
+
+	// 上传源截图附件
+	if len(screenshot) > 0 && ts.config.MicrosoftTodo.Attachments.ShouldAttachScreenshot(listName) {
+		attachmentID, err := ts.attachScreenshot(ctx, todoClient, listID, created.ID, screenshot)
+		if err != nil {
+			log.Warnf("任务已创建，但截图附件上传失败: %v", err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("screenshot attachment: %v", err))
+		} else {
+			result.AttachmentID = attachmentID
+		}
+	}
+
+	return nil
+}
+
+// findSyncedDuplicate 在同步缓存中查找相同的任务，未启用去重或缓存不可用时返回 nil
+func (ts *TodoServiceImpl) findSyncedDuplicate(ctx context.Context, title, date, listName string) *todosync.SyncedTask {
+	if !ts.config.Deduplication.Enabled || ts.syncStoreProvider == nil {
+		return nil
+	}
+
+	store, err := ts.syncStoreProvider()
+	if err != nil {
+		logger.FromContext(ctx).Warnf("同步缓存不可用，跳过去重检查: %v", err)
+		return nil
+	}
+	return store.FindDuplicate(listName, title, date, ts.config.Deduplication.CheckIncompleteOnly)
+}
+
+// createEvent 创建 Outlook 日历事件，返回无法加入参会人等非致命警告
+func (ts *TodoServiceImpl) createEvent(ctx context.Context, todoClient *microsofttodo.SimpleTodoClient, reminder *models.Reminder) (*microsofttodo.CreatedEvent, []string, error) {
+	request, warnings, err := ts.buildEventRequest(reminder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created, err := todoClient.CreateEvent(ctx, request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建 Outlook 日历事件失败: %w", err)
+	}
+	return created, warnings, nil
+}
+
+// CreateTasks 通过 Graph JSON 批处理批量创建任务
+// 缺失的列表在一次批处理中创建，任务按每批 20 个提交，失败的子请求自动重试
+func (ts *TodoServiceImpl) CreateTasks(ctx context.Context, reminders []*models.Reminder) (*services.BatchCreateResult, error) {
+	if ts.config == nil {
+		return nil, fmt.Errorf("配置未初始化")
+	}
+
+	startTime := time.Now()
+	result := &services.BatchCreateResult{Items: make([]*services.BatchCreateItem, len(reminders))}
+
+	todoClient, err := ts.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析时间并构建请求，解析失败的提醒不参与批处理
+	var requests []*microsofttodo.TaskRequest
+	var requestItems []*services.BatchCreateItem
+	var listNames []string
+	eventReminders := map[int]*models.Reminder{}
+	for i, reminder := range reminders {
+		item := &services.BatchCreateItem{Index: i}
+		result.Items[i] = item
+		if reminder == nil {
+			item.Error = "提醒对象为空"
+			continue
+		}
+		item.Title = reminder.Title
+
+		// 日历事件不支持任务批处理，在批处理之后逐个创建
+		if reminder.Kind.WantsEvent() {
+			eventReminders[i] = reminder
+		}
+		if !reminder.Kind.WantsTask() {
+			continue
+		}
+
+		request, listName, err := ts.buildTaskRequest(reminder)
+		if err != nil {
+			item.Error = err.Error()
+			continue
+		}
+		item.ListName = listName
+		requests = append(requests, request)
+		requestItems = append(requestItems, item)
+		listNames = append(listNames, listName)
+	}
+
+	if len(requests) > 0 {
+		listIDs, err := todoClient.ResolveTaskListIDs(ctx, listNames)
+		if err != nil {
+			return nil, fmt.Errorf("获取或创建任务列表失败: %w", err)
+		}
+		for i, request := range requests {
+			request.ListID = listIDs[requestItems[i].ListName]
+		}
+
+		taskResults, err := todoClient.CreateTasksBatch(ctx, requests)
+		if err != nil {
+			return nil, fmt.Errorf("批量创建任务失败: %w", err)
+		}
+		for i, taskResult := range taskResults {
+			item := requestItems[i]
+			item.TaskID = taskResult.TaskID
+			item.ListID = taskResult.ListID
+			item.Attempts = taskResult.Attempts
+			item.Error = taskResult.Error
+			item.Warnings = taskResult.Warnings
+		}
+	}
+
+	for i := range reminders {
+		reminder, ok := eventReminders[i]
+		item := result.Items[i]
+		if !ok || item.Error != "" {
+			continue
+		}
+		event, warnings, err := ts.createEvent(ctx, todoClient, reminder)
+		if err != nil {
+			if !reminder.Kind.WantsTask() {
+				item.Error = err.Error()
+			} else {
+				item.Warnings = append(item.Warnings, fmt.Sprintf("calendar event: %v", err))
+			}
+			continue
+		}
+		item.EventID = event.ID
+		item.Warnings = append(item.Warnings, warnings...)
+	}
+
+	for _, item := range result.Items {
+		if item.Error == "" {
+			result.SuccessCount++
+		} else {
+			result.FailedCount++
+		}
+	}
+	result.Duration = time.Since(startTime)
+
+	logger.FromContext(ctx).Infof("批量创建完成: 成功 %d, 失败 %d, 耗时 %v", result.SuccessCount, result.FailedCount, result.Duration)
+	return result, nil
+}
+
+// parseReminder 使用配置的时区解析提醒时间
+func (ts *TodoServiceImpl) parseReminder(reminder *models.Reminder) (*models.ParsedReminder, error) {
 	// 改进的时区处理逻辑（使用timezone工具函数）
 	var timezone *time.Location
 	if ts.config.MicrosoftTodo.Timezone != "" {
@@ -75,7 +299,16 @@ func (ts *TodoServiceImpl) CreateTask(ctx context.Context, reminder *models.Remi
 	// 使用工作版本的完整时间解析函数
 	parsedReminder, parseErr := models.ParseReminderTimeWithConfig(*reminder, timezone, &ts.config.Reminder)
 	if parseErr != nil {
-		return fmt.Errorf("完整时间解析失败: %w", parseErr)
+		return nil, fmt.Errorf("完整时间解析失败: %w", parseErr)
+	}
+	return parsedReminder, nil
+}
+
+// buildTaskRequest 解析提醒时间并构建任务请求，返回目标列表名称（未指定时为默认列表 Tasks）
+func (ts *TodoServiceImpl) buildTaskRequest(reminder *models.Reminder) (*microsofttodo.TaskRequest, string, error) {
+	parsedReminder, err := ts.parseReminder(reminder)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// 从解析结果中提取时间信息（使用UTC标准化字段）
@@ -90,6 +323,13 @@ func (ts *TodoServiceImpl) CreateTask(ctx context.Context, reminder *models.Remi
 	logger.Infof("  本地截止时间: %s", parsedReminder.DueTime.Format("2006-01-02 15:04:05"))
 	logger.Infof("  UTC截止时间: %s", parsedReminder.DueTimeUTC.Format("2006-01-02 15:04:05"))
 	logger.Infof("  本地提醒时间: %s", parsedReminder.AlarmTime.Format("2006-01-02 15:04:05"))
+	if parsedReminder.AlarmShiftReason != "" {
+		logger.Infof("  提醒时间调整: %s", parsedReminder.AlarmShiftReason)
+	}
+	if len(parsedReminder.Alarms) > 1 {
+		logger.Infof("  共 %d 个提醒，Microsoft Todo 只使用其中一个（alarm_policy: %s）",
+			len(parsedReminder.Alarms), ts.config.Reminder.AlarmPolicy)
+	}
 	logger.Infof("  UTC提醒时间: %s", parsedReminder.AlarmTimeUTC.Format("2006-01-02 15:04:05"))
 
 	importance := 1 // 默认重要性
@@ -104,39 +344,149 @@ func (ts *TodoServiceImpl) CreateTask(ctx context.Context, reminder *models.Remi
 		importance = 1
 	}
 
-	// 获取或创建任务列表
-	var listID string
-	if reminder.List != "" {
-		// 如果指定了列表名称，尝试获取列表ID
-		listID, err = todoClient.GetOrCreateTaskList(reminder.List)
-		if err != nil {
-			return fmt.Errorf("无法创建任务列表 '%s': %w", reminder.List, err)
-		}
+	listName := reminder.List
+	if listName == "" {
+		listName = ts.config.Reminder.GetDefaultList("Tasks")
 	}
 
-	// 如果没有指定列表，创建默认列表
-	if listID == "" {
-		listID, err = todoClient.GetOrCreateTaskList("Tasks")
-		if err != nil {
-			return fmt.Errorf("无法创建默认任务列表: %w", err)
+	// Microsoft Todo 任务只有截止时间，时间范围写入任务正文
+	description := reminder.Description
+	if parsedReminder.HasEnd() {
+		timeLine := fmt.Sprintf("时间: %s", parsedReminder.RangeText())
+		logger.Infof("  时间范围: %s", parsedReminder.RangeText())
+		if description == "" {
+			description = timeLine
+		} else {
+			description = timeLine + "\n\n" + description
 		}
 	}
 
-	// 创建任务（使用UTC时间传递，时区信息用于API转换）
-	err = todoClient.CreateTaskWithDetails(
-		reminder.Title,
-		reminder.Description,
-		listID,
-		dueDateTime,      // UTC时间
-		reminderTime,     // UTC时间
-		importance,
-		userTimezone,     // 用户配置的时区名称
-	)
+	links := make([]microsofttodo.LinkedResource, 0, len(reminder.Links))
+	for _, link := range reminder.Links {
+		links = append(links, microsofttodo.LinkedResource{URL: link.URL, Title: link.Title})
+	}
+
+	return &microsofttodo.TaskRequest{
+		Title:        reminder.Title,
+		Description:  description,
+		DueTime:      dueDateTime,  // UTC时间
+		ReminderTime: reminderTime, // UTC时间
+		Importance:   importance,
+		Timezone:     userTimezone, // 用户配置的时区名称
+		Checklist:    reminder.Checklist,
+		Categories:   reminder.Categories,
+		Links:        links,
+	}, listName, nil
+}
+
+// buildEventRequest 解析提醒时间并构建日历事件请求
+// 参会人需要邮箱地址，只有姓名的参会人写入事件正文并返回警告
+func (ts *TodoServiceImpl) buildEventRequest(reminder *models.Reminder) (*microsofttodo.EventRequest, []string, error) {
+	parsedReminder, err := ts.parseReminder(reminder)
 	if err != nil {
-		return fmt.Errorf("创建 Microsoft Todo 任务失败: %w", err)
+		return nil, nil, err
 	}
 
-	return nil
+	attendees, unresolved := parseAttendees(reminder.Attendees)
+	sendInvitations := ts.config.MicrosoftTodo.SendEventInvitations
+	var warnings []string
+	if sendInvitations && len(unresolved) > 0 {
+		logger.Warnf("以下参会人没有邮箱地址，只写入事件正文: %v", unresolved)
+		warnings = append(warnings, fmt.Sprintf("attendees without email: %s", strings.Join(unresolved, ", ")))
+	}
+	// 没有邮箱的参会人只写入正文
+	for _, name := range unresolved {
+		attendees = append(attendees, microsofttodo.EventAttendee{Name: name})
+	}
+
+	importance := 5
+	switch reminder.Priority {
+	case models.PriorityHigh:
+		importance = 9
+	case models.PriorityLow:
+		importance = 1
+	}
+
+	request := &microsofttodo.EventRequest{
+		Subject:         reminder.Title,
+		Body:            reminder.Description,
+		Start:           parsedReminder.DueTimeUTC,
+		End:             parsedReminder.EndTimeUTC,
+		Timezone:        parsedReminder.UserTimezone,
+		Location:        reminder.Location,
+		Attendees:       attendees,
+		SendInvitations: sendInvitations,
+		ReminderMinutes: int(parsedReminder.DueTime.Sub(parsedReminder.AlarmTime) / time.Minute),
+		Importance:      importance,
+		Categories:      reminder.Categories,
+	}
+	logger.Infof("日历事件: %s (%s), 地点: %s, 参会人: %d (发送邀请: %v), 提前 %d 分钟提醒",
+		request.Subject, parsedReminder.RangeText(), request.Location, len(attendees), sendInvitations, request.ReminderMinutes)
+
+	return request, warnings, nil
+}
+
+// parseAttendees 解析 "张三 <zhangsan@example.com>" 或纯邮箱形式的参会人，
+// 返回可以加入事件的参会人和没有邮箱地址的参会人
+func parseAttendees(values []string) ([]microsofttodo.EventAttendee, []string) {
+	var attendees []microsofttodo.EventAttendee
+	var unresolved []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if address, err := mail.ParseAddress(value); err == nil {
+			attendees = append(attendees, microsofttodo.EventAttendee{Email: address.Address, Name: address.Name})
+			continue
+		}
+		unresolved = append(unresolved, value)
+	}
+	return attendees, unresolved
+}
+
+// attachScreenshot 标准化截图并上传为任务附件
+func (ts *TodoServiceImpl) attachScreenshot(ctx context.Context, client *microsofttodo.SimpleTodoClient, listID, taskID string, screenshot []byte) (string, error) {
+	log := logger.FromContext(ctx)
+	attachmentConfig := &ts.config.MicrosoftTodo.Attachments
+	attachment := &microsofttodo.FileAttachment{
+		Name:        "screenshot.png",
+		ContentType: "image/png",
+		Data:        screenshot,
+	}
+
+	if attachmentConfig.ShouldNormalizeImage() {
+		if normalizer := ts.getNormalizer(); normalizer != nil {
+			normalized, err := normalizer.NormalizeBytes(screenshot)
+			if err != nil {
+				log.Warnf("截图标准化失败，上传原始图片: %v", err)
+			} else {
+				attachment.Data = normalized
+				attachment.ContentType = normalizer.OutputContentType()
+				attachment.Name = "screenshot" + normalizer.OutputExtension()
+				log.Debugf("截图标准化完成: %d -> %d bytes", len(screenshot), len(normalized))
+			}
+		}
+	}
+
+	if maxSize := attachmentConfig.GetMaxSizeBytes(); int64(len(attachment.Data)) > maxSize {
+		return "", fmt.Errorf("截图大小 %d bytes 超过附件上限 %d bytes", len(attachment.Data), maxSize)
+	}
+
+	return client.AttachFile(ctx, listID, taskID, attachment)
+}
+
+// getNormalizer 获取图片标准化器，未启用标准化时返回 nil
+func (ts *TodoServiceImpl) getNormalizer() *image.ImageNormalizer {
+	ts.normalizerOnce.Do(func() {
+		configManager := image.NewConfigManager(ts.configDir, wqlogger.GetLogger())
+		if err := configManager.LoadConfig(); err != nil {
+			logger.Warnf("加载图片处理配置失败: %v", err)
+			return
+		}
+		ts.normalizer = configManager.GetNormalizer()
+	})
+	return ts.normalizer
 }
 
 // TestConnection 测试连接
@@ -145,65 +495,74 @@ func (ts *TodoServiceImpl) TestConnection() error {
 		return fmt.Errorf("配置未初始化")
 	}
 
-	// 验证 Microsoft Todo 配置
-	if ts.config.MicrosoftTodo.TenantID == "" ||
-		ts.config.MicrosoftTodo.ClientID == "" ||
-		ts.config.MicrosoftTodo.ClientSecret == "" ||
-		ts.config.MicrosoftTodo.UserEmail == "" {
-		return fmt.Errorf("Microsoft Todo 配置不完整")
-	}
-
-	// 创建 Todo 客户端
-	todoClient, err := microsofttodo.NewSimpleTodoClient(
-		ts.config.MicrosoftTodo.TenantID,
-		ts.config.MicrosoftTodo.ClientID,
-		ts.config.MicrosoftTodo.ClientSecret,
-		ts.config.MicrosoftTodo.UserEmail,
-	)
+	todoClient, err := ts.getClient()
 	if err != nil {
-		return fmt.Errorf("创建 Microsoft Todo 客户端失败: %w", err)
+		return err
 	}
 
 	// 测试连接
 	return todoClient.TestConnection()
 }
 
+// TestCalendarAccess 检查日历读写权限，返回默认日历名称
+func (ts *TodoServiceImpl) TestCalendarAccess(ctx context.Context) (string, error) {
+	if ts.config == nil {
+		return "", fmt.Errorf("配置未初始化")
+	}
+
+	todoClient, err := ts.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	return todoClient.TestCalendarAccess(ctx)
+}
+
 // GetServerInfo 获取服务器信息
 func (ts *TodoServiceImpl) GetServerInfo() (map[string]interface{}, error) {
 	if ts.config == nil {
 		return nil, fmt.Errorf("配置未初始化")
 	}
 
-	// 验证 Microsoft Todo 配置
-	if ts.config.MicrosoftTodo.TenantID == "" ||
-		ts.config.MicrosoftTodo.ClientID == "" ||
-		ts.config.MicrosoftTodo.ClientSecret == "" ||
-		ts.config.MicrosoftTodo.UserEmail == "" {
-		return nil, fmt.Errorf("Microsoft Todo 配置不完整")
-	}
-
-	// 创建 Todo 客户端
-	todoClient, err := microsofttodo.NewSimpleTodoClient(
-		ts.config.MicrosoftTodo.TenantID,
-		ts.config.MicrosoftTodo.ClientID,
-		ts.config.MicrosoftTodo.ClientSecret,
-		ts.config.MicrosoftTodo.UserEmail,
-	)
+	todoClient, err := ts.getClient()
 	if err != nil {
-		return nil, fmt.Errorf("创建 Microsoft Todo 客户端失败: %w", err)
+		return nil, err
 	}
 
 	// 获取服务器信息
 	return todoClient.GetServerInfo()
 }
 
-// GetClient 获取 Microsoft Todo 客户端实例
+// GetClient 获取共享的 Microsoft Todo 客户端实例
 func (ts *TodoServiceImpl) GetClient() *microsofttodo.SimpleTodoClient {
 	if ts.config == nil {
 		return nil
 	}
 
-	// 创建并返回客户端实例
+	todoClient, err := ts.getClient()
+	if err != nil {
+		logger.Errorf("创建 Microsoft Todo 客户端失败: %v", err)
+		return nil
+	}
+
+	return todoClient
+}
+
+// getClient 验证配置并获取客户端
+// 由服务容器提供共享客户端时复用同一实例（包括令牌和列表ID缓存），否则每次新建
+func (ts *TodoServiceImpl) getClient() (*microsofttodo.SimpleTodoClient, error) {
+	// 验证 Microsoft Todo 配置
+	if ts.config.MicrosoftTodo.TenantID == "" ||
+		ts.config.MicrosoftTodo.ClientID == "" ||
+		ts.config.MicrosoftTodo.ClientSecret == "" ||
+		ts.config.MicrosoftTodo.UserEmail == "" {
+		return nil, fmt.Errorf("Microsoft Todo 配置不完整")
+	}
+
+	if ts.clientProvider != nil {
+		return ts.clientProvider()
+	}
+
 	todoClient, err := microsofttodo.NewSimpleTodoClient(
 		ts.config.MicrosoftTodo.TenantID,
 		ts.config.MicrosoftTodo.ClientID,
@@ -211,11 +570,9 @@ func (ts *TodoServiceImpl) GetClient() *microsofttodo.SimpleTodoClient {
 		ts.config.MicrosoftTodo.UserEmail,
 	)
 	if err != nil {
-		logger.Errorf("创建 Microsoft Todo 客户端失败: %v", err)
-		return nil
+		return nil, fmt.Errorf("创建 Microsoft Todo 客户端失败: %w", err)
 	}
-
-	return todoClient
+	return todoClient, nil
 }
 
 // 注意：parseReminderDateTime 和 parseReminderBeforeTime 函数已被移除
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package cache
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime 非 Windows 平台不读取访问时间，按修改时间计算
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
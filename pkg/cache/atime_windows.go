@@ -0,0 +1,17 @@
+//go:build windows
+
+package cache
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// accessTime 文件最近访问时间（NTFS 可能关闭了访问时间更新，此时与修改时间相近）
+func accessTime(info os.FileInfo) time.Time {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.LastAccessTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,379 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EvictionPolicy 超出配额时的淘汰策略
+type EvictionPolicy string
+
+const (
+	EvictionLRU EvictionPolicy = "lru" // 最久未使用的先淘汰（默认）
+	EvictionAge EvictionPolicy = "age" // 最早写入的先淘汰
+)
+
+// Quota 单个缓存类型的配额，零值字段表示不限制
+type Quota struct {
+	MaxBytes int64         `json:"max_bytes"` // 最大总大小
+	MaxItems int           `json:"max_items"` // 最大条目数（缓存目录下的文件或子目录）
+	MaxAge   time.Duration `json:"max_age"`   // 超过该时间未修改的条目直接淘汰
+}
+
+// IsZero 是否未设置任何限制
+func (q Quota) IsZero() bool {
+	return q.MaxBytes <= 0 && q.MaxItems <= 0 && q.MaxAge <= 0
+}
+
+// ProtectFunc 返回本轮淘汰中需要保护的路径集合（绝对路径）
+type ProtectFunc func() map[string]bool
+
+// EvictFunc 淘汰单个条目，用于需要同步维护索引的缓存类型（如任务目录）
+type EvictFunc func(path string) error
+
+// cacheItem 缓存目录下的一个条目，子目录（如任务目录）作为整体淘汰
+type cacheItem struct {
+	path     string
+	size     int64
+	files    int
+	modTime  time.Time
+	lastUsed time.Time
+}
+
+// TypeUsage 单个缓存类型的用量
+type TypeUsage struct {
+	Type      CacheType `json:"type"`
+	Dir       string    `json:"dir"`
+	SizeBytes int64     `json:"size_bytes"`
+	Files     int       `json:"files"`
+	Items     int       `json:"items"`
+	Protected int       `json:"protected"`
+	Quota     Quota     `json:"quota"`
+}
+
+// OverQuota 用量是否超出配额
+func (u TypeUsage) OverQuota() bool {
+	return (u.Quota.MaxBytes > 0 && u.SizeBytes > u.Quota.MaxBytes) ||
+		(u.Quota.MaxItems > 0 && u.Items > u.Quota.MaxItems)
+}
+
+// EvictionResult 单个缓存类型的淘汰结果
+type EvictionResult struct {
+	Type       CacheType `json:"type"`
+	Evicted    int       `json:"evicted"`
+	BytesFreed int64     `json:"bytes_freed"`
+	Protected  int       `json:"protected"`  // 因受保护而跳过的条目
+	OverQuota  bool      `json:"over_quota"` // 淘汰后仍超出配额（剩余条目均受保护）
+	Errors     []string  `json:"errors,omitempty"`
+}
+
+// QuotaManager 按缓存类型执行配额和淘汰
+type QuotaManager struct {
+	unifiedCacheMgr *UnifiedCacheManager
+	quotas          map[CacheType]Quota
+	policy          EvictionPolicy
+	protect         ProtectFunc
+	evictors        map[CacheType]EvictFunc
+	logger          *log.Logger
+
+	mutex    sync.Mutex // 保证同一时间只有一轮淘汰
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewQuotaManager 创建配额管理器，policy 为空时使用 LRU
+func NewQuotaManager(unifiedCacheMgr *UnifiedCacheManager, quotas map[CacheType]Quota, policy EvictionPolicy, logger *log.Logger) *QuotaManager {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if policy == "" {
+		policy = EvictionLRU
+	}
+
+	return &QuotaManager{
+		unifiedCacheMgr: unifiedCacheMgr,
+		quotas:          quotas,
+		policy:          policy,
+		evictors:        make(map[CacheType]EvictFunc),
+		logger:          logger,
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// SetProtector 设置受保护路径的来源，例如成功处理的图片哈希引用的文件
+func (qm *QuotaManager) SetProtector(protect ProtectFunc) {
+	qm.mutex.Lock()
+	defer qm.mutex.Unlock()
+	qm.protect = protect
+}
+
+// SetEvictor 设置指定缓存类型的淘汰方式，未设置的类型直接删除条目
+func (qm *QuotaManager) SetEvictor(cacheType CacheType, evict EvictFunc) {
+	qm.mutex.Lock()
+	defer qm.mutex.Unlock()
+	qm.evictors[cacheType] = evict
+}
+
+// HasQuotas 是否配置了任何配额
+func (qm *QuotaManager) HasQuotas() bool {
+	for _, quota := range qm.quotas {
+		if !quota.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// Start 在后台按间隔执行淘汰，启动时立即执行一次
+func (qm *QuotaManager) Start(interval time.Duration) {
+	if !qm.HasQuotas() || interval <= 0 {
+		return
+	}
+
+	qm.wg.Add(1)
+	go func() {
+		defer qm.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := qm.EnforceAll(); err != nil {
+				qm.logger.Printf("缓存配额淘汰失败: %v", err)
+			}
+
+			select {
+			case <-qm.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台淘汰并等待当前一轮结束
+func (qm *QuotaManager) Stop() {
+	qm.stopOnce.Do(func() { close(qm.stopCh) })
+	qm.wg.Wait()
+}
+
+// EnforceAll 对所有配置了配额的缓存类型执行淘汰
+func (qm *QuotaManager) EnforceAll() ([]*EvictionResult, error) {
+	types := make([]CacheType, 0, len(qm.quotas))
+	for cacheType, quota := range qm.quotas {
+		if !quota.IsZero() {
+			types = append(types, cacheType)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	results := make([]*EvictionResult, 0, len(types))
+	for _, cacheType := range types {
+		result, err := qm.Enforce(cacheType)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Enforce 对指定缓存类型执行淘汰：先淘汰超过 MaxAge 的条目，
+// 再按策略淘汰直到大小和数量都在配额内，受保护的条目不会被淘汰
+func (qm *QuotaManager) Enforce(cacheType CacheType) (*EvictionResult, error) {
+	qm.mutex.Lock()
+	defer qm.mutex.Unlock()
+
+	result := &EvictionResult{Type: cacheType}
+	quota := qm.quotas[cacheType]
+	if quota.IsZero() {
+		return result, nil
+	}
+
+	items, err := listCacheItems(qm.unifiedCacheMgr.GetCacheDir(cacheType))
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存目录失败: %s: %w", cacheType, err)
+	}
+
+	protected := qm.protectedPaths()
+	var totalSize int64
+	candidates := make([]*cacheItem, 0, len(items))
+	for _, item := range items {
+		totalSize += item.size
+		if isProtected(item.path, protected) {
+			result.Protected++
+			continue
+		}
+		candidates = append(candidates, item)
+	}
+	remaining := len(items)
+
+	remove := qm.evictors[cacheType]
+	if remove == nil {
+		remove = os.RemoveAll
+	}
+	evict := func(item *cacheItem) {
+		if err := remove(item.path); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			return
+		}
+		result.Evicted++
+		result.BytesFreed += item.size
+		totalSize -= item.size
+		remaining--
+	}
+
+	// 先淘汰过期条目
+	if quota.MaxAge > 0 {
+		cutoff := time.Now().Add(-quota.MaxAge)
+		kept := candidates[:0]
+		for _, item := range candidates {
+			if item.modTime.Before(cutoff) {
+				evict(item)
+			} else {
+				kept = append(kept, item)
+			}
+		}
+		candidates = kept
+	}
+
+	// 再按策略淘汰，直到满足配额
+	sort.Slice(candidates, func(i, j int) bool {
+		if qm.policy == EvictionAge {
+			return candidates[i].modTime.Before(candidates[j].modTime)
+		}
+		return candidates[i].lastUsed.Before(candidates[j].lastUsed)
+	})
+	overQuota := func() bool {
+		return (quota.MaxBytes > 0 && totalSize > quota.MaxBytes) ||
+			(quota.MaxItems > 0 && remaining > quota.MaxItems)
+	}
+	for _, item := range candidates {
+		if !overQuota() {
+			break
+		}
+		evict(item)
+	}
+	result.OverQuota = overQuota()
+
+	if result.Evicted > 0 {
+		qm.logger.Printf("缓存配额淘汰: %s 淘汰 %d 个条目，释放 %.2f MB",
+			cacheType, result.Evicted, float64(result.BytesFreed)/(1024*1024))
+	}
+	if result.OverQuota {
+		qm.logger.Printf("缓存配额淘汰: %s 仍超出配额，剩余 %d 个受保护的条目", cacheType, result.Protected)
+	}
+	return result, nil
+}
+
+// Usage 统计各缓存类型的用量和配额，按类型名称排序
+func (qm *QuotaManager) Usage() ([]TypeUsage, error) {
+	types := qm.unifiedCacheMgr.ListCacheTypes()
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	qm.mutex.Lock()
+	protected := qm.protectedPaths()
+	qm.mutex.Unlock()
+
+	usages := make([]TypeUsage, 0, len(types))
+	for _, cacheType := range types {
+		dir := qm.unifiedCacheMgr.GetCacheDir(cacheType)
+		items, err := listCacheItems(dir)
+		if err != nil {
+			return nil, fmt.Errorf("读取缓存目录失败: %s: %w", cacheType, err)
+		}
+
+		usage := TypeUsage{Type: cacheType, Dir: dir, Items: len(items), Quota: qm.quotas[cacheType]}
+		for _, item := range items {
+			usage.SizeBytes += item.size
+			usage.Files += item.files
+			if isProtected(item.path, protected) {
+				usage.Protected++
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// protectedPaths 获取受保护的路径（调用方需持有锁）
+func (qm *QuotaManager) protectedPaths() map[string]bool {
+	if qm.protect == nil {
+		return nil
+	}
+	return qm.protect()
+}
+
+// isProtected 条目本身或其中的任意文件受保护时，整个条目受保护
+func isProtected(path string, protected map[string]bool) bool {
+	if protected[path] {
+		return true
+	}
+	prefix := path + string(filepath.Separator)
+	for p := range protected {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// listCacheItems 列出缓存目录下的条目，子目录的大小和最近使用时间取其中所有文件
+func listCacheItems(dir string) ([]*cacheItem, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	items := make([]*cacheItem, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		item := &cacheItem{path: path, modTime: info.ModTime(), lastUsed: lastUsed(info)}
+		if !entry.IsDir() {
+			item.size = info.Size()
+			item.files = 1
+			items = append(items, item)
+			continue
+		}
+
+		filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			item.size += info.Size()
+			item.files++
+			if info.ModTime().After(item.modTime) {
+				item.modTime = info.ModTime()
+			}
+			if used := lastUsed(info); used.After(item.lastUsed) {
+				item.lastUsed = used
+			}
+			return nil
+		})
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// lastUsed 文件最近使用时间，取修改时间和访问时间中较晚的一个
+func lastUsed(info os.FileInfo) time.Time {
+	if accessed := accessTime(info); accessed.After(info.ModTime()) {
+		return accessed
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCacheFile 写入缓存文件并设置修改时间
+func writeCacheFile(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestQuotaManager_EnforceEvictsOldestAndSkipsProtected(t *testing.T) {
+	ucm, err := NewUnifiedCacheManager(t.TempDir(), nil)
+	require.NoError(t, err)
+	imagesDir := ucm.GetCacheDir(CacheTypeImages)
+	now := time.Now()
+
+	for i, name := range []string{"a.png", "b.png", "c.png", "d.png"} {
+		writeCacheFile(t, filepath.Join(imagesDir, name), 100, now.Add(time.Duration(i-10)*time.Hour))
+	}
+	protectedPath := filepath.Join(imagesDir, "a.png")
+
+	qm := NewQuotaManager(ucm, map[CacheType]Quota{CacheTypeImages: {MaxItems: 2}}, "", nil)
+	qm.SetProtector(func() map[string]bool { return map[string]bool{protectedPath: true} })
+
+	result, err := qm.Enforce(CacheTypeImages)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Evicted)
+	assert.Equal(t, int64(200), result.BytesFreed)
+	assert.Equal(t, 1, result.Protected)
+	assert.False(t, result.OverQuota)
+
+	// 最旧的 a.png 受保护，淘汰其后最旧的 b.png 和 c.png
+	assert.FileExists(t, protectedPath)
+	assert.NoFileExists(t, filepath.Join(imagesDir, "b.png"))
+	assert.NoFileExists(t, filepath.Join(imagesDir, "c.png"))
+	assert.FileExists(t, filepath.Join(imagesDir, "d.png"))
+}
+
+func TestQuotaManager_SizeAndAgeLimits(t *testing.T) {
+	ucm, err := NewUnifiedCacheManager(t.TempDir(), nil)
+	require.NoError(t, err)
+	tasksDir := ucm.GetCacheDir(CacheTypeTasks)
+	tempDir := ucm.GetCacheDir(CacheTypeTemp)
+	now := time.Now()
+
+	// 任务目录作为整体淘汰
+	writeCacheFile(t, filepath.Join(tasksDir, "task1", "a.json"), 600, now.Add(-3*time.Hour))
+	writeCacheFile(t, filepath.Join(tasksDir, "task1", "b.json"), 600, now.Add(-3*time.Hour))
+	writeCacheFile(t, filepath.Join(tasksDir, "task2", "a.json"), 600, now.Add(-time.Hour))
+	writeCacheFile(t, filepath.Join(tempDir, "old.tmp"), 10, now.Add(-72*time.Hour))
+	writeCacheFile(t, filepath.Join(tempDir, "new.tmp"), 10, now)
+
+	qm := NewQuotaManager(ucm, map[CacheType]Quota{
+		CacheTypeTasks: {MaxBytes: 1000},
+		CacheTypeTemp:  {MaxAge: 48 * time.Hour},
+	}, EvictionAge, nil)
+
+	results, err := qm.EnforceAll()
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.NoDirExists(t, filepath.Join(tasksDir, "task1"))
+	assert.DirExists(t, filepath.Join(tasksDir, "task2"))
+	assert.NoFileExists(t, filepath.Join(tempDir, "old.tmp"))
+	assert.FileExists(t, filepath.Join(tempDir, "new.tmp"))
+
+	usages, err := qm.Usage()
+	require.NoError(t, err)
+	for _, usage := range usages {
+		switch usage.Type {
+		case CacheTypeTasks:
+			assert.Equal(t, 1, usage.Items)
+			assert.Equal(t, int64(600), usage.SizeBytes)
+			assert.False(t, usage.OverQuota())
+		case CacheTypeTemp:
+			assert.Equal(t, 1, usage.Files)
+		}
+	}
+}
+
+func TestQuotaManager_EvictorAndNestedProtection(t *testing.T) {
+	ucm, err := NewUnifiedCacheManager(t.TempDir(), nil)
+	require.NoError(t, err)
+	tasksDir := ucm.GetCacheDir(CacheTypeTasks)
+	now := time.Now()
+
+	for i, name := range []string{"task1", "task2", "task3"} {
+		writeCacheFile(t, filepath.Join(tasksDir, name, "clipboard.png"), 100, now.Add(time.Duration(i-10)*time.Hour))
+	}
+	// 受保护的文件位于 task1 中，整个任务目录受保护
+	protectedFile := filepath.Join(tasksDir, "task1", "clipboard.png")
+
+	qm := NewQuotaManager(ucm, map[CacheType]Quota{CacheTypeTasks: {MaxItems: 1}}, EvictionAge, nil)
+	qm.SetProtector(func() map[string]bool { return map[string]bool{protectedFile: true} })
+	var evicted []string
+	qm.SetEvictor(CacheTypeTasks, func(path string) error {
+		evicted = append(evicted, filepath.Base(path))
+		return os.RemoveAll(path)
+	})
+
+	result, err := qm.Enforce(CacheTypeTasks)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task2", "task3"}, evicted)
+	assert.Equal(t, 1, result.Protected)
+	assert.False(t, result.OverQuota)
+	assert.FileExists(t, protectedFile)
+
+	usages, err := qm.Usage()
+	require.NoError(t, err)
+	for _, usage := range usages {
+		if usage.Type == CacheTypeTasks {
+			assert.Equal(t, 1, usage.Protected)
+		}
+	}
+}
+
+func TestQuotaManager_StartAndStop(t *testing.T) {
+	ucm, err := NewUnifiedCacheManager(t.TempDir(), nil)
+	require.NoError(t, err)
+	path := filepath.Join(ucm.GetCacheDir(CacheTypeTemp), "old.tmp")
+	writeCacheFile(t, path, 10, time.Now().Add(-time.Hour))
+
+	qm := NewQuotaManager(ucm, map[CacheType]Quota{CacheTypeTemp: {MaxAge: time.Minute}}, EvictionLRU, nil)
+	qm.Start(time.Hour)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+	qm.Stop()
+	qm.Stop()
+}
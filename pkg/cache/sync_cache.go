@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RegisterCacheType 注册额外的缓存类型并创建对应子目录
+// 用于按需启用的缓存，已注册的类型会直接返回目录
+func (ucm *UnifiedCacheManager) RegisterCacheType(cacheType CacheType, subDir string) (string, error) {
+	ucm.mutex.Lock()
+	defer ucm.mutex.Unlock()
+
+	if existing, exists := ucm.subDirs[cacheType]; exists {
+		subDir = existing
+	} else {
+		ucm.subDirs[cacheType] = subDir
+	}
+
+	fullPath := filepath.Join(ucm.baseCacheDir, subDir)
+	if err := os.MkdirAll(fullPath, 0755); err != nil {
+		return "", fmt.Errorf("创建缓存子目录失败: %s -> %s: %w", cacheType, fullPath, err)
+	}
+
+	return fullPath, nil
+}
+
+// GetSyncCacheDir 获取任务同步缓存目录，目录被删除时重新创建
+func (ucm *UnifiedCacheManager) GetSyncCacheDir() (string, error) {
+	return ucm.RegisterCacheType(CacheTypeSync, string(CacheTypeSync))
+}
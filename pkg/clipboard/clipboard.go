@@ -257,6 +257,11 @@ func (r *WindowsClipboardReader) getOpenClipboardWindow() uintptr {
 
 // getClipboardSequenceNumber 获取剪贴板序列号，用于检测内容变化
 func (r *WindowsClipboardReader) getClipboardSequenceNumber() uint32 {
+	return SequenceNumber()
+}
+
+// SequenceNumber 获取系统剪贴板序列号，剪贴板内容每次变化时递增
+func SequenceNumber() uint32 {
 	seq, _, _ := procGetClipboardSequenceNumber.Call()
 	return uint32(seq)
 }
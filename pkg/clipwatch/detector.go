@@ -0,0 +1,54 @@
+package clipwatch
+
+import (
+	"context"
+	"sync"
+)
+
+// ChangeDetector 剪贴板变化检测器
+// Watcher 每次轮询先询问检测器，只有检测到变化时才读取剪贴板内容
+type ChangeDetector interface {
+	// Changed 自上次调用以来剪贴板是否发生变化
+	Changed(ctx context.Context) (bool, error)
+}
+
+// SequenceDetector 基于剪贴板序列号的变化检测器（Windows GetClipboardSequenceNumber）
+// 首次调用只记录当前序列号，启动前已在剪贴板中的内容不会被处理
+type SequenceDetector struct {
+	mu          sync.Mutex
+	sequence    func() uint32
+	last        uint32
+	initialized bool
+}
+
+// NewSequenceDetector 创建基于序列号的变化检测器
+func NewSequenceDetector(sequence func() uint32) *SequenceDetector {
+	return &SequenceDetector{sequence: sequence}
+}
+
+// Changed 序列号与上次不同即视为变化
+func (d *SequenceDetector) Changed(ctx context.Context) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := d.sequence()
+	if !d.initialized {
+		d.initialized = true
+		d.last = current
+		return false, nil
+	}
+	if current == d.last {
+		return false, nil
+	}
+	d.last = current
+	return true, nil
+}
+
+// PollDetector 每次轮询都报告变化，由 Watcher 根据内容哈希判断是否为新内容
+// 用于无法获取剪贴板序列号的环境
+type PollDetector struct{}
+
+// Changed 总是返回 true
+func (PollDetector) Changed(ctx context.Context) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,69 @@
+package clipwatch
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// Trigger 判断剪贴板内容是否需要处理，返回 false 时附带跳过原因
+type Trigger func(content *models.ClipboardContent) (bool, string)
+
+// datePattern 常见的中英文日期和时间表达
+var datePattern = regexp.MustCompile(`(?i)` +
+	`\d{4}[-/.]\d{1,2}[-/.]\d{1,2}` + // 2025-01-15
+	`|\d{1,2}\s*月\s*\d{1,2}\s*[日号]` + // 1月15日
+	`|\d{1,2}[:：]\d{2}` + // 14:30
+	`|\d{1,2}\s*[点時时]` + // 3点
+	`|今天|明天|后天|大后天|今晚|明早|今早|下午|上午|晚上|中午` +
+	`|(本|这|下|下下)?(周|星期|礼拜)[一二三四五六日天]` +
+	`|下周|下个?月|月底|月初|周末` +
+	`|\b(today|tomorrow|tonight|monday|tuesday|wednesday|thursday|friday|saturday|sunday|next week|eod)\b` +
+	`|\b\d{1,2}\s*(am|pm)\b` +
+	`|\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+\d{1,2}\b`)
+
+// ContainsDate 文本中是否包含日期或时间表达
+func ContainsDate(text string) bool {
+	return datePattern.MatchString(text)
+}
+
+// NewTrigger 根据监听配置创建触发条件
+func NewTrigger(config models.WatchConfig) (Trigger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var pattern *regexp.Regexp
+	if config.TextPattern != "" {
+		pattern = regexp.MustCompile(config.TextPattern)
+	}
+	trigger := config.GetTrigger()
+
+	return func(content *models.ClipboardContent) (bool, string) {
+		switch content.Type {
+		case models.ContentTypeImage:
+			if trigger == models.WatchTriggerText || trigger == models.WatchTriggerTextWithDate {
+				return false, "触发条件只处理文本"
+			}
+			return true, ""
+		case models.ContentTypeText:
+			if trigger == models.WatchTriggerImage {
+				return false, "触发条件只处理图片"
+			}
+			if length := utf8.RuneCountInString(content.Text); length < config.MinTextLength {
+				return false, fmt.Sprintf("文本过短（%d 个字符）", length)
+			}
+			if trigger == models.WatchTriggerTextWithDate && !ContainsDate(content.Text) {
+				return false, "文本中没有日期或时间"
+			}
+			if pattern != nil && !pattern.MatchString(content.Text) {
+				return false, "文本不匹配 text_pattern"
+			}
+			return true, ""
+		default:
+			return false, fmt.Sprintf("不支持的内容类型: %s", content.Type)
+		}
+	}, nil
+}
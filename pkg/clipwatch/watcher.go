@@ -0,0 +1,265 @@
+package clipwatch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// ContentReader 剪贴板内容读取接口，services.ClipboardService 满足该接口
+type ContentReader interface {
+	ReadContent(ctx context.Context) (*models.ClipboardContent, error)
+}
+
+const (
+	// maxHandlerRetries 同一内容处理失败后的最大重试次数，超过后等待剪贴板内容变化
+	maxHandlerRetries = 5
+	// retryBaseDelay 处理失败后首次重试的等待时间，之后每次翻倍
+	retryBaseDelay = 5 * time.Second
+	// maxRetryDelay 重试等待时间的上限
+	maxRetryDelay = 5 * time.Minute
+)
+
+// Handler 处理一条新的剪贴板内容
+type Handler func(ctx context.Context, content *models.ClipboardContent) error
+
+// Stats 剪贴板监听统计
+type Stats struct {
+	Changes         int       `json:"changes"`    // 检测到的剪贴板变化次数
+	Processed       int       `json:"processed"`  // 处理成功的次数
+	Failed          int       `json:"failed"`     // 处理失败的次数
+	Skipped         int       `json:"skipped"`    // 被校验或触发条件过滤的次数
+	Duplicates      int       `json:"duplicates"` // 去重窗口内重复内容的次数
+	LastProcessedAt time.Time `json:"last_processed_at,omitempty"`
+	LastError       string    `json:"last_error,omitempty"`
+}
+
+// Watcher 剪贴板监听器
+// 检测到剪贴板变化后读取内容，经过校验、触发条件和去重后交给 Handler 处理
+type Watcher struct {
+	detector    ChangeDetector
+	reader      ContentReader
+	handler     Handler
+	trigger     Trigger
+	validate    func(content *models.ClipboardContent) error
+	onDuplicate func(content *models.ClipboardContent)
+	interval    time.Duration
+	dedupWindow time.Duration
+
+	mu         sync.Mutex
+	lastHash   string               // 最近一次处理完成（成功或被过滤）的内容，避免同一内容被反复处理
+	seen       map[string]time.Time // 已处理内容的哈希及处理时间
+	failedHash string               // 处理失败、等待重试的内容
+	failures   int                  // failedHash 连续失败的次数
+	retryAt    time.Time            // 下一次重试的时间
+	stats      Stats
+	now        func() time.Time
+}
+
+// NewWatcher 创建剪贴板监听器
+func NewWatcher(detector ChangeDetector, reader ContentReader, handler Handler, config models.WatchConfig) (*Watcher, error) {
+	if detector == nil || reader == nil || handler == nil {
+		return nil, fmt.Errorf("detector, reader and handler are required")
+	}
+
+	trigger, err := NewTrigger(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		detector:    detector,
+		reader:      reader,
+		handler:     handler,
+		trigger:     trigger,
+		interval:    config.GetInterval(),
+		dedupWindow: config.GetDedupWindow(),
+		seen:        make(map[string]time.Time),
+		now:         time.Now,
+	}, nil
+}
+
+// SetValidator 设置内容校验函数，例如 clipboard.ValidateClipboardContent
+func (w *Watcher) SetValidator(validate func(content *models.ClipboardContent) error) {
+	w.validate = validate
+}
+
+// SetDuplicateHandler 设置去重命中时的回调，例如记录处理指标
+func (w *Watcher) SetDuplicateHandler(onDuplicate func(content *models.ClipboardContent)) {
+	w.onDuplicate = onDuplicate
+}
+
+// Run 按轮询间隔持续监听，直到上下文取消
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	logger.Infof("开始监听剪贴板，轮询间隔: %v", w.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("剪贴板监听已停止")
+			return nil
+		case <-ticker.C:
+			if _, err := w.Poll(ctx); err != nil {
+				logger.Warnf("剪贴板监听轮询失败: %v", err)
+			}
+		}
+	}
+}
+
+// Poll 执行一次检测，返回本次是否处理了新内容
+// 处理失败不作为错误返回，只记录在统计中，同一内容按退避间隔重试（剪贴板未变化也会重试）；
+// 读取剪贴板失败时返回错误
+func (w *Watcher) Poll(ctx context.Context) (bool, error) {
+	changed, err := w.detector.Changed(ctx)
+	if err != nil {
+		return false, fmt.Errorf("检测剪贴板变化失败: %w", err)
+	}
+	if !changed && !w.retryDue() {
+		return false, nil
+	}
+
+	content, err := w.reader.ReadContent(ctx)
+	if err != nil {
+		return false, fmt.Errorf("读取剪贴板内容失败: %w", err)
+	}
+
+	hash := contentHash(content)
+
+	w.mu.Lock()
+	if hash == w.lastHash {
+		w.mu.Unlock()
+		return false, nil
+	}
+	retrying, attempt := hash == w.failedHash, w.failures
+	if retrying {
+		if w.now().Before(w.retryAt) {
+			w.mu.Unlock()
+			return false, nil
+		}
+	} else {
+		w.failedHash, w.failures = "", 0
+		w.stats.Changes++
+	}
+
+	if w.validate != nil {
+		if err := w.validate(content); err != nil {
+			w.stats.Skipped++
+			w.lastHash = hash
+			w.mu.Unlock()
+			logger.Debugf("跳过剪贴板内容: %v", err)
+			return false, nil
+		}
+	}
+
+	if ok, reason := w.trigger(content); !ok {
+		w.stats.Skipped++
+		w.lastHash = hash
+		w.mu.Unlock()
+		logger.Debugf("跳过剪贴板内容: %s", reason)
+		return false, nil
+	}
+
+	now := w.now()
+	w.pruneSeen(now)
+	if processedAt, ok := w.seen[hash]; ok {
+		w.stats.Duplicates++
+		w.lastHash = hash
+		w.mu.Unlock()
+		logger.Infof("剪贴板内容已于 %s 处理过，跳过", processedAt.Format("15:04:05"))
+		if w.onDuplicate != nil {
+			w.onDuplicate(content)
+		}
+		return false, nil
+	}
+	w.mu.Unlock()
+
+	if retrying {
+		logger.Infof("重新处理之前失败的剪贴板内容（第 %d 次重试）", attempt)
+	} else {
+		logger.Infof("检测到新的剪贴板内容，类型: %s", content.Type)
+	}
+	err = w.handler(ctx, content)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.stats.Failed++
+		w.stats.LastError = err.Error()
+		w.recordFailure(hash)
+		logger.Errorf("处理剪贴板内容失败: %v", err)
+		return false, nil
+	}
+	w.lastHash = hash
+	w.failedHash, w.failures = "", 0
+	w.stats.Processed++
+	w.stats.LastProcessedAt = w.now()
+	w.stats.LastError = ""
+	if w.dedupWindow > 0 {
+		w.seen[hash] = w.stats.LastProcessedAt
+	}
+	return true, nil
+}
+
+// retryDue 是否有处理失败的内容到了重试时间
+func (w *Watcher) retryDue() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.failedHash != "" && !w.now().Before(w.retryAt)
+}
+
+// recordFailure 记录处理失败并安排重试，超过最大重试次数后放弃该内容，调用方需持有锁
+func (w *Watcher) recordFailure(hash string) {
+	w.failedHash = hash
+	w.failures++
+	if w.failures > maxHandlerRetries {
+		logger.Warnf("剪贴板内容连续处理失败 %d 次，不再重试该内容", w.failures)
+		w.lastHash = hash
+		w.failedHash, w.failures = "", 0
+		return
+	}
+
+	delay := retryBaseDelay << (w.failures - 1)
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	w.retryAt = w.now().Add(delay)
+	logger.Infof("%v 后重试处理该剪贴板内容", delay)
+}
+
+// Stats 获取监听统计
+func (w *Watcher) Stats() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// pruneSeen 清理超出去重窗口的记录，调用方需持有锁
+func (w *Watcher) pruneSeen(now time.Time) {
+	for hash, processedAt := range w.seen {
+		if now.Sub(processedAt) >= w.dedupWindow {
+			delete(w.seen, hash)
+		}
+	}
+}
+
+// contentHash 计算剪贴板内容的哈希
+func contentHash(content *models.ClipboardContent) string {
+	h := sha256.New()
+	h.Write([]byte(content.Type))
+	h.Write([]byte{0})
+	if content.Type == models.ContentTypeImage {
+		h.Write(content.Image)
+	} else {
+		h.Write([]byte(content.Text))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,276 @@
+package clipwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClipboard 模拟剪贴板：写入内容时递增序列号
+type fakeClipboard struct {
+	sequence uint32
+	content  *models.ClipboardContent
+	reads    int
+}
+
+func (f *fakeClipboard) copyText(text string) {
+	f.sequence++
+	f.content = &models.ClipboardContent{Type: models.ContentTypeText, Text: text}
+}
+
+func (f *fakeClipboard) copyImage(data []byte) {
+	f.sequence++
+	f.content = &models.ClipboardContent{Type: models.ContentTypeImage, Image: data}
+}
+
+func (f *fakeClipboard) ReadContent(ctx context.Context) (*models.ClipboardContent, error) {
+	f.reads++
+	if f.content == nil {
+		return nil, errors.New("clipboard is empty")
+	}
+	copied := *f.content
+	return &copied, nil
+}
+
+func newFakeWatcher(t *testing.T, config models.WatchConfig) (*Watcher, *fakeClipboard, *[]string) {
+	t.Helper()
+	clip := &fakeClipboard{}
+	var handled []string
+	handler := func(ctx context.Context, content *models.ClipboardContent) error {
+		if content.Type == models.ContentTypeImage {
+			handled = append(handled, fmt.Sprintf("image:%d", len(content.Image)))
+			return nil
+		}
+		handled = append(handled, content.Text)
+		return nil
+	}
+
+	watcher, err := NewWatcher(NewSequenceDetector(func() uint32 { return clip.sequence }), clip, handler, config)
+	require.NoError(t, err)
+	return watcher, clip, &handled
+}
+
+func TestWatcher_IgnoresExistingContentAndProcessesChanges(t *testing.T) {
+	watcher, clip, handled := newFakeWatcher(t, models.DefaultWatchConfig())
+	ctx := context.Background()
+
+	clip.copyText("copied before the watcher started")
+	processed, err := watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.False(t, processed)
+	assert.Zero(t, clip.reads, "the first poll only records the sequence number")
+
+	// 剪贴板未变化时不读取内容
+	_, err = watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, clip.reads)
+
+	clip.copyText("明天下午3点开会")
+	processed, err = watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	clip.copyImage([]byte{1, 2, 3})
+	processed, err = watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	assert.Equal(t, []string{"明天下午3点开会", "image:3"}, *handled)
+	assert.Equal(t, 2, watcher.Stats().Processed)
+}
+
+func TestWatcher_DeduplicatesWithinWindow(t *testing.T) {
+	watcher, clip, handled := newFakeWatcher(t, models.DefaultWatchConfig())
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	watcher.now = func() time.Time { return now }
+	var duplicates []string
+	watcher.SetDuplicateHandler(func(content *models.ClipboardContent) {
+		duplicates = append(duplicates, content.Text)
+	})
+	ctx := context.Background()
+	watcher.Poll(ctx)
+
+	clip.copyText("提交周报")
+	watcher.Poll(ctx)
+	clip.copyText("其他内容")
+	watcher.Poll(ctx)
+
+	// 再次复制相同内容，处于去重窗口内
+	clip.copyText("提交周报")
+	processed, err := watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.False(t, processed)
+	assert.Equal(t, 1, watcher.Stats().Duplicates)
+	assert.Equal(t, []string{"提交周报"}, duplicates)
+
+	// 窗口过后可以再次处理
+	now = now.Add(2 * time.Hour)
+	clip.copyText("其他内容")
+	watcher.Poll(ctx)
+	clip.copyText("提交周报")
+	processed, err = watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	assert.Equal(t, []string{"提交周报", "其他内容", "其他内容", "提交周报"}, *handled)
+}
+
+func TestWatcher_AppliesValidatorAndTrigger(t *testing.T) {
+	config := models.DefaultWatchConfig()
+	config.Trigger = models.WatchTriggerTextWithDate
+	watcher, clip, handled := newFakeWatcher(t, config)
+	watcher.SetValidator(func(content *models.ClipboardContent) error {
+		if content.Text == "invalid" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	ctx := context.Background()
+	watcher.Poll(ctx)
+
+	for _, copy := range []func(){
+		func() { clip.copyText("invalid") },
+		func() { clip.copyText("随便一段没有时间的文字") },
+		func() { clip.copyImage([]byte{1}) },
+		func() { clip.copyText("ok") },
+		func() { clip.copyText("Dentist tomorrow at 10am") },
+	} {
+		copy()
+		_, err := watcher.Poll(ctx)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"Dentist tomorrow at 10am"}, *handled)
+	assert.Equal(t, 4, watcher.Stats().Skipped)
+}
+
+func TestWatcher_RetriesFailedContentWithBackoff(t *testing.T) {
+	clip := &fakeClipboard{}
+	calls := 0
+	handler := func(ctx context.Context, content *models.ClipboardContent) error {
+		calls++
+		if calls < 3 {
+			return errors.New("dify unavailable")
+		}
+		return nil
+	}
+	watcher, err := NewWatcher(NewSequenceDetector(func() uint32 { return clip.sequence }), clip, handler, models.DefaultWatchConfig())
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	watcher.now = func() time.Time { return now }
+	ctx := context.Background()
+	watcher.Poll(ctx)
+
+	clip.copyText("周五交报告")
+	processed, err := watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.False(t, processed)
+	assert.Equal(t, "dify unavailable", watcher.Stats().LastError)
+
+	// 重试时间未到时不重新处理
+	watcher.Poll(ctx)
+	assert.Equal(t, 1, calls)
+
+	// 剪贴板未变化，到时间后也会重试；第二次失败后等待时间翻倍
+	now = now.Add(retryBaseDelay)
+	watcher.Poll(ctx)
+	assert.Equal(t, 2, calls)
+	now = now.Add(retryBaseDelay)
+	watcher.Poll(ctx)
+	assert.Equal(t, 2, calls)
+	now = now.Add(retryBaseDelay)
+	processed, err = watcher.Poll(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+	assert.Equal(t, 3, calls)
+
+	// 成功后同一内容不再处理
+	now = now.Add(time.Hour)
+	watcher.Poll(ctx)
+	assert.Equal(t, 3, calls)
+
+	stats := watcher.Stats()
+	assert.Equal(t, 1, stats.Changes)
+	assert.Equal(t, 2, stats.Failed)
+	assert.Equal(t, 1, stats.Processed)
+	assert.Empty(t, stats.LastError)
+}
+
+func TestWatcher_GivesUpAfterMaxRetries(t *testing.T) {
+	clip := &fakeClipboard{}
+	calls := 0
+	handler := func(ctx context.Context, content *models.ClipboardContent) error {
+		calls++
+		return errors.New("dify unavailable")
+	}
+	watcher, err := NewWatcher(PollDetector{}, clip, handler, models.DefaultWatchConfig())
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	watcher.now = func() time.Time { return now }
+
+	clip.copyText("周五交报告")
+	for i := 0; i < 20; i++ {
+		_, err := watcher.Poll(context.Background())
+		require.NoError(t, err)
+		now = now.Add(maxRetryDelay)
+	}
+
+	assert.Equal(t, maxHandlerRetries+1, calls)
+	assert.Equal(t, maxHandlerRetries+1, watcher.Stats().Failed)
+}
+
+func TestWatcher_RunStopsOnCancel(t *testing.T) {
+	config := models.DefaultWatchConfig()
+	config.IntervalMs = 1
+	watcher, _, handled := newFakeWatcher(t, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Empty(t, *handled)
+}
+
+func TestNewTrigger(t *testing.T) {
+	config := models.DefaultWatchConfig()
+	config.Trigger = models.WatchTriggerImage
+	trigger, err := NewTrigger(config)
+	require.NoError(t, err)
+
+	ok, _ := trigger(&models.ClipboardContent{Type: models.ContentTypeImage, Image: []byte{1}})
+	assert.True(t, ok)
+	ok, reason := trigger(&models.ClipboardContent{Type: models.ContentTypeText, Text: "明天开会"})
+	assert.False(t, ok)
+	assert.NotEmpty(t, reason)
+
+	config = models.DefaultWatchConfig()
+	config.TextPattern = `^TODO`
+	trigger, err = NewTrigger(config)
+	require.NoError(t, err)
+	ok, _ = trigger(&models.ClipboardContent{Type: models.ContentTypeText, Text: "TODO buy milk"})
+	assert.True(t, ok)
+	ok, _ = trigger(&models.ClipboardContent{Type: models.ContentTypeText, Text: "buy milk"})
+	assert.False(t, ok)
+
+	config.Trigger = "sometimes"
+	_, err = NewTrigger(config)
+	assert.Error(t, err)
+}
+
+func TestContainsDate(t *testing.T) {
+	for _, text := range []string{"2025-01-15 开会", "1月15日交房租", "周五前提交", "下周三评审", "明天", "meet at 14:30", "Call Bob on Friday", "3pm sync", "Jan 5 deadline"} {
+		assert.True(t, ContainsDate(text), text)
+	}
+	for _, text := range []string{"买牛奶", "hello world", "version 1.2"} {
+		assert.False(t, ContainsDate(text), text)
+	}
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/cache"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/services"
+)
+
+// cache 命令的子命令
+const (
+	CacheActionStats = "stats" // 显示缓存用量
+	CacheActionEvict = "evict" // 立即按配额执行一次淘汰
+)
+
+// CacheResult cache 命令结果
+type CacheResult struct {
+	Action     string                  `json:"action"`
+	ByType     bool                    `json:"by_type"`
+	TotalSize  int64                   `json:"total_size"`
+	TotalFiles int                     `json:"total_files"`
+	Usages     []cache.TypeUsage       `json:"usages"`
+	Evictions  []*cache.EvictionResult `json:"evictions,omitempty"`
+}
+
+// CacheCommand 缓存命令，查看各缓存类型的用量和配额
+type CacheCommand struct {
+	*BaseCommand
+	cacheService services.CacheService
+}
+
+// NewCacheCommand 创建缓存命令
+func NewCacheCommand(container ServiceContainer) *CacheCommand {
+	return &CacheCommand{
+		BaseCommand:  NewBaseCommand("cache", "查看缓存用量和配额"),
+		cacheService: container.GetCacheService(),
+	}
+}
+
+// Execute 执行缓存命令
+// 支持的参数: action (string) stats 或 evict，默认 stats; by_type (bool) 按缓存类型显示
+func (c *CacheCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	action, _ := req.Args["action"].(string)
+	if action == "" {
+		action = CacheActionStats
+	}
+	byType, _ := req.Args["by_type"].(bool)
+
+	quotaManager := c.cacheService.GetQuotaManager()
+	if quotaManager == nil {
+		return ErrorResponse(fmt.Errorf("缓存管理器未初始化")), nil
+	}
+
+	result := &CacheResult{Action: action, ByType: byType}
+	if action == CacheActionEvict {
+		evictions, err := quotaManager.EnforceAll()
+		if err != nil {
+			return ErrorResponse(fmt.Errorf("执行缓存淘汰失败: %w", err)), nil
+		}
+		result.Evictions = evictions
+	}
+
+	usages, err := quotaManager.Usage()
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("统计缓存用量失败: %w", err)), nil
+	}
+	result.Usages = usages
+	for _, usage := range usages {
+		result.TotalSize += usage.SizeBytes
+		result.TotalFiles += usage.Files
+	}
+
+	return SuccessResponse(result, map[string]interface{}{"cache_dir": c.cacheService.GetCacheDir()}), nil
+}
+
+// Validate 验证命令参数
+func (c *CacheCommand) Validate(args []string) error {
+	var positional []string
+	for _, arg := range args {
+		switch arg {
+		case "--by-type":
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("未知选项: %s", arg)
+			}
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) > 1 {
+		return fmt.Errorf("只能指定一个子命令")
+	}
+	if len(positional) == 1 && positional[0] != CacheActionStats && positional[0] != CacheActionEvict {
+		return fmt.Errorf("未知子命令: %s（支持 stats、evict）", positional[0])
+	}
+	return nil
+}
+
+// ShowResult 显示缓存用量（用于CLI调用）
+func (c *CacheCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	result, ok := data.(*CacheResult)
+	if !ok {
+		logger.Error("❌ 无效的结果数据")
+		return
+	}
+
+	for _, eviction := range result.Evictions {
+		logger.Infof("🧹 %s: 淘汰 %d 个条目，释放 %s，受保护 %d 个",
+			eviction.Type, eviction.Evicted, formatBytes(eviction.BytesFreed), eviction.Protected)
+		for _, msg := range eviction.Errors {
+			logger.Warnf("  ⚠️  %s", msg)
+		}
+	}
+
+	if cacheDir, ok := metadata["cache_dir"].(string); ok {
+		logger.Infof("📁 缓存目录: %s", cacheDir)
+	}
+	logger.Infof("📦 总计: %d 个文件，%s", result.TotalFiles, formatBytes(result.TotalSize))
+
+	if !result.ByType {
+		return
+	}
+
+	logger.Info("")
+	logger.Infof("%-10s %8s %10s %16s %14s %8s", "类型", "条目", "大小", "大小配额", "条目配额", "受保护")
+	for _, usage := range result.Usages {
+		sizeQuota, itemQuota := "-", "-"
+		if usage.Quota.MaxBytes > 0 {
+			sizeQuota = fmt.Sprintf("%s (%.0f%%)", formatBytes(usage.Quota.MaxBytes),
+				float64(usage.SizeBytes)*100/float64(usage.Quota.MaxBytes))
+		}
+		if usage.Quota.MaxItems > 0 {
+			itemQuota = fmt.Sprintf("%d (%.0f%%)", usage.Quota.MaxItems,
+				float64(usage.Items)*100/float64(usage.Quota.MaxItems))
+		}
+		marker := ""
+		if usage.OverQuota() {
+			marker = " ⚠️ 超出配额"
+		}
+		logger.Infof("%-10s %8d %10s %16s %14s %8d%s", usage.Type, usage.Items, formatBytes(usage.SizeBytes),
+			sizeQuota, itemQuota, usage.Protected, marker)
+	}
+}
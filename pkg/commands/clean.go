@@ -161,6 +161,16 @@ func (c *CleanCommand) ShowResult(data interface{}, metadata map[string]interfac
 	logger.Infof("  Total files: %d", totalFiles)
 	logger.Infof("  Total size: %s", formatBytes(totalSize))
 
+	// 启用 compress_old_tasks 时显示任务归档情况
+	if filesByType, ok := resultData["files_by_type"].(map[string]int64); ok {
+		if archived := filesByType["tasks_archived"]; archived > 0 {
+			logger.Infof("  Tasks archived: %d", archived)
+		}
+		if removed := filesByType["task_archives"]; removed > 0 {
+			logger.Infof("  Task archives removed: %d", removed)
+		}
+	}
+
 	// 如果是预览模式，显示额外信息
 	if dryRun, ok := metadata["dry_run"].(bool); ok && dryRun {
 		logger.Info("  📋 This was a dry run - no files were actually deleted")
@@ -2,39 +2,47 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/metrics"
 	"github.com/allanpk716/to_icalendar/pkg/models"
 	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/task"
 )
 
 // ClipUploadCommand 剪贴板上传命令
+// 服务在每次处理时从容器获取，watch 长时间运行时也能使用热加载后的配置
 type ClipUploadCommand struct {
 	*BaseCommand
-	clipboardService services.ClipboardService
-	todoService     services.TodoService
-	difyService     services.DifyService
+	container ServiceContainer
+	source    string // 处理来源，记录在处理指标中
 }
 
 // NewClipUploadCommand 创建剪贴板上传命令
 func NewClipUploadCommand(container ServiceContainer) *ClipUploadCommand {
 	return &ClipUploadCommand{
-		BaseCommand:     NewBaseCommand("clip-upload", "处理剪贴板内容并上传到 Microsoft Todo"),
-		clipboardService: container.GetClipboardService(),
-		todoService:     container.GetTodoService(),
-		difyService:     container.GetDifyService(),
+		BaseCommand: NewBaseCommand("clip-upload", "处理剪贴板内容并上传到 Microsoft Todo"),
+		container:   container,
+		source:      "clip-upload",
 	}
 }
 
+// SetSource 设置处理来源（如 watch、tray），用于区分处理指标
+func (c *ClipUploadCommand) SetSource(source string) {
+	c.source = source
+}
+
 // Execute 执行剪贴板上传命令
 func (c *ClipUploadCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
 	logger.Info("开始执行 clip-upload 命令")
 
 	// 1. 检查剪贴板是否有内容
 	logger.Info("检查剪贴板内容...")
-	hasContent, err := c.clipboardService.HasContent()
+	clipboardService := c.container.GetClipboardService()
+	hasContent, err := clipboardService.HasContent()
 	if err != nil {
 		logger.Error("检查剪贴板内容失败: %v", err)
 		return ErrorResponse(fmt.Errorf("检查剪贴板内容失败: %w", err)), nil
@@ -48,7 +56,7 @@ func (c *ClipUploadCommand) Execute(ctx context.Context, req *CommandRequest) (*
 	logger.Info("发现剪贴板内容，开始读取...")
 
 	// 2. 读取剪贴板内容
-	clipboardContent, err := c.clipboardService.ReadContent(ctx)
+	clipboardContent, err := clipboardService.ReadContent(ctx)
 	if err != nil {
 		logger.Error("读取剪贴板内容失败: %v", err)
 		return ErrorResponse(fmt.Errorf("读取剪贴板内容失败: %w", err)), nil
@@ -56,48 +64,87 @@ func (c *ClipUploadCommand) Execute(ctx context.Context, req *CommandRequest) (*
 
 	logger.Info("成功读取剪贴板内容，类型: %s", clipboardContent.Type)
 
+	return c.ProcessContent(ctx, clipboardContent)
+}
+
+// ProcessContent 处理已读取的剪贴板内容：调用 Dify 解析并创建 Microsoft Todo 任务
+// watch 命令检测到新内容时也通过该方法处理
+func (c *ClipUploadCommand) ProcessContent(ctx context.Context, clipboardContent *models.ClipboardContent) (*CommandResponse, error) {
+	var err error
+
 	// 3. 根据内容类型调用 Dify 服务处理
 	var difyResponse *models.DifyResponse
 	var originalContent string
+	var session *task.TaskSession
+	difyService := c.container.GetDifyService()
+	todoService := c.container.GetTodoService()
+	tracker := c.container.GetMetricsStore().Track(c.source, string(clipboardContent.Type))
+
+	// 图片的任务会话 ID 作为本次处理的关联 ID，文本没有任务会话时生成同格式的 ID
+	// 之后 Dify、解析和 Graph 的日志都带有同一个 task_id
+	runID := task.NewTaskID()
+	if clipboardContent.Type == models.ContentTypeImage {
+		if session = c.startImageSession(clipboardContent.Image); session != nil {
+			runID = session.TaskID
+		}
+	}
+	ctx = logger.ContextWithTaskID(ctx, runID)
+	runLog := logger.FromContext(ctx).With("source", c.source, "content_type", string(clipboardContent.Type))
 
 	switch clipboardContent.Type {
 	case models.ContentTypeText:
 		originalContent = clipboardContent.Text
-		logger.Info("调用 Dify 服务处理文本内容...")
-		difyResponse, err = c.difyService.ProcessText(ctx, clipboardContent.Text)
+		runLog.Info("调用 Dify 服务处理文本内容...")
+		difyResponse, err = difyService.ProcessText(ctx, clipboardContent.Text)
 	case models.ContentTypeImage:
 		originalContent = "[图片内容]"
-		logger.Info("调用 Dify 服务处理图像内容...")
-		difyResponse, err = c.difyService.ProcessImage(ctx, clipboardContent.Image)
+		runLog.Info("调用 Dify 服务处理图像内容...")
+		difyResponse, err = difyService.ProcessImage(ctx, clipboardContent.Image)
 	default:
 		err = fmt.Errorf("不支持的剪贴板内容类型: %s", clipboardContent.Type)
 	}
 
 	if err != nil {
-		logger.Error("Dify 服务处理失败: %v", err)
+		runLog.Errorf("Dify 服务处理失败: %v", err)
+		c.finishSession(session, err)
+		tracker.Fail(metrics.StageDify)
 		return ErrorResponse(fmt.Errorf("Dify 服务处理失败: %w", err)), nil
 	}
 
-	logger.Info("Dify 服务处理成功")
+	runLog.Info("Dify 服务处理成功")
+	c.recordDifyResponse(session, difyResponse)
+	tracker.SetDifyResponse(difyResponse)
+	if session != nil {
+		tracker.SetTaskID(session.TaskID)
+	}
 
 	// 4. 解析 Dify 响应为 Reminder 对象
-	reminder, err := ParseDifyResponseToReminder(difyResponse, string(clipboardContent.Type), originalContent)
+	reminder, err := ParseDifyResponseToReminder(ctx, difyResponse, string(clipboardContent.Type), originalContent)
 	if err != nil {
-		logger.Error("解析 Dify 响应失败: %v", err)
+		runLog.Errorf("解析 Dify 响应失败: %v", err)
+		c.finishSession(session, err)
+		tracker.Fail(metrics.StageParse)
 		return ErrorResponse(fmt.Errorf("解析 Dify 响应失败: %w", err)), nil
 	}
 
-	logger.Info("成功解析任务信息: %s", reminder.Title)
+	runLog.Infof("成功解析任务信息: %s", reminder.Title)
 
 	// 5. 创建 Microsoft Todo 任务
-	logger.Info("开始创建 Microsoft Todo 任务...")
-	err = c.todoService.CreateTask(ctx, reminder)
+	runLog.Info("开始创建 Microsoft Todo 任务...")
+	// 图片内容会按列表配置将源截图作为附件上传
+	tracker.TrackGraph(GraphRequestStats(todoService))
+	creation, err := todoService.CreateTaskWithAttachment(ctx, reminder, clipboardContent.Image)
 	if err != nil {
-		logger.Error("创建 Microsoft Todo 任务失败: %v", err)
+		runLog.Errorf("创建 Microsoft Todo 任务失败: %v", err)
+		c.finishSession(session, err)
+		tracker.Fail(metrics.StageTodo)
 		return ErrorResponse(fmt.Errorf("创建 Microsoft Todo 任务失败: %w", err)), nil
 	}
 
-	logger.Info("成功创建 Microsoft Todo 任务")
+	runLog.Info("成功创建 Microsoft Todo 任务")
+	c.recordTodoTask(session, reminder, creation)
+	c.finishSession(session, nil)
+	tracker.Succeed()
 
 	// 6. 构建成功响应
 	responseData := &services.ProcessClipboardResult{
@@ -106,6 +153,16 @@ func (c *ClipUploadCommand) Execute(ctx context.Context, req *CommandRequest) (*
 		Description: reminder.Description,
 		Message:     "剪贴板内容已成功处理并创建到 Microsoft Todo",
 	}
+	switch models.Kind(creation.Kind) {
+	case models.KindEvent:
+		responseData.Message = "剪贴板内容已成功处理并创建到 Outlook 日历"
+	case models.KindBoth:
+		responseData.Message = "剪贴板内容已成功处理并创建到 Microsoft Todo 和 Outlook 日历"
+	default:
+		if creation.Duplicate {
+			responseData.Message = "Microsoft Todo 中已存在相同任务，未重复创建"
+		}
+	}
 
 	// 添加元数据
 	metadata := map[string]interface{}{
@@ -115,6 +172,18 @@ func (c *ClipUploadCommand) Execute(ctx context.Context, req *CommandRequest) (*
 		"task_priority": reminder.Priority,
 		"processed_at": time.Now(),
 	}
+	if creation.AttachmentID != "" {
+		metadata["attachment_id"] = creation.AttachmentID
+	}
+	if creation.EventID != "" {
+		metadata["event_id"] = creation.EventID
+	}
+	if creation.Duplicate {
+		metadata["duplicate"] = true
+	}
+	if session != nil {
+		metadata["task_session_id"] = session.TaskID
+	}
 
 	// 根据内容类型添加额外信息
 	switch clipboardContent.Type {
@@ -128,10 +197,100 @@ func (c *ClipUploadCommand) Execute(ctx context.Context, req *CommandRequest) (*
 		}
 	}
 
-	logger.Info("clip-upload 命令执行完成")
+	runLog.Info("clip-upload 命令执行完成")
 	return SuccessResponse(responseData, metadata), nil
 }
 
+// GraphRequestStats 返回读取 Todo 服务所用 Graph 客户端请求统计的函数，客户端不可用时返回 nil
+func GraphRequestStats(todoService services.TodoService) metrics.GraphStatsFunc {
+	client := todoService.GetClient()
+	if client == nil {
+		return nil
+	}
+	return func() (int64, int64) {
+		stats := client.GetRetryStats()
+		return stats.Attempts, stats.Retries
+	}
+}
+
+// startImageSession 创建任务会话并保存剪贴板原始图片，失败时只记录警告
+func (c *ClipUploadCommand) startImageSession(imageData []byte) *task.TaskSession {
+	taskManager, err := c.container.GetTaskManager()
+	if err != nil {
+		logger.Warnf("获取任务管理器失败，跳过任务会话记录: %v", err)
+		return nil
+	}
+
+	session, err := taskManager.CreateTaskSession()
+	if err != nil {
+		logger.Warnf("创建任务会话失败: %v", err)
+		return nil
+	}
+
+	if err := taskManager.SaveFileToTask(session, task.FileTypeClipboardOriginal, imageData); err != nil {
+		logger.Warnf("保存剪贴板原始图片失败: %v", err)
+	}
+	return session
+}
+
+// recordDifyResponse 在任务会话中保存 Dify 响应，供 replay 命令重新解析
+func (c *ClipUploadCommand) recordDifyResponse(session *task.TaskSession, difyResponse *models.DifyResponse) {
+	if session == nil {
+		return
+	}
+	taskManager, err := c.container.GetTaskManager()
+	if err != nil {
+		return
+	}
+
+	data, err := json.MarshalIndent(difyResponse, "", "  ")
+	if err != nil {
+		logger.Warnf("序列化 Dify 响应失败: %v", err)
+		return
+	}
+	if err := taskManager.SetDifyResult(session, true, nil, data); err != nil {
+		logger.Warnf("保存 Dify 响应失败: %v", err)
+	}
+}
+
+// recordTodoTask 在任务会话中记录创建的任务和附件
+func (c *ClipUploadCommand) recordTodoTask(session *task.TaskSession, reminder *models.Reminder, creation *services.TaskCreationResult) {
+	if session == nil {
+		return
+	}
+	taskManager, err := c.container.GetTaskManager()
+	if err != nil {
+		return
+	}
+
+	session.Title = reminder.Title
+	session.Description = reminder.Description
+	session.DifySuccess = true
+	session.TodoSuccess = true
+	session.Metadata["list"] = creation.ListName
+	if creation.EventID != "" {
+		session.Metadata["event_id"] = creation.EventID
+	}
+	taskManager.SetTodoTaskInfo(session, creation.TaskID, creation.ListID, creation.AttachmentID)
+}
+
+// finishSession 结束任务会话，err 为 nil 表示成功
+func (c *ClipUploadCommand) finishSession(session *task.TaskSession, err error) {
+	if session == nil {
+		return
+	}
+	taskManager, tmErr := c.container.GetTaskManager()
+	if tmErr != nil {
+		return
+	}
+
+	if err != nil {
+		taskManager.UpdateTaskStatus(session, task.TaskStatusFailed, err.Error())
+		return
+	}
+	taskManager.UpdateTaskStatus(session, task.TaskStatusSuccess)
+}
+
 // Validate 验证命令参数
 func (c *ClipUploadCommand) Validate(args []string) error {
 	// clip-upload 命令通常不需要参数
@@ -188,6 +347,9 @@ func (c *ClipUploadCommand) ShowResult(data interface{}, metadata map[string]int
 			logger.Infof("⭐ 优先级: %s %s", priorityIcon, taskPriority)
 			logger.Debugf("优先级设置: %s (图标: %s)", taskPriority, priorityIcon)
 		}
+		if eventID, ok := metadata["event_id"].(string); ok && eventID != "" {
+			logger.Infof("📅 日历事件: %s", eventID)
+		}
 	} else {
 		logger.Errorf("❌ 剪贴板内容处理失败: %s", result.Message)
 		logger.Info("💡 请检查剪贴板内容或相关服务配置")
@@ -0,0 +1,384 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/config"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// config 命令的子命令
+const (
+	ConfigActionShow     = "show"     // 显示配置
+	ConfigActionValidate = "validate" // 校验配置文件，列出全部问题
+	ConfigActionMigrate  = "migrate"  // 将配置文件升级到当前版本
+	ConfigActionSchema   = "schema"   // 输出 JSON Schema
+)
+
+// schema 子命令支持的目标
+const (
+	ConfigSchemaServer = "server" // server.yaml
+	ConfigSchemaImage  = "image"  // image_processing.json
+)
+
+// ConfigShowResult config show 命令结果
+type ConfigShowResult struct {
+	Action   string `json:"action"`
+	Path     string `json:"path"`
+	Resolved bool   `json:"resolved"`
+	Redacted bool   `json:"redacted"`
+	Content  string `json:"content"` // YAML 格式的配置内容
+}
+
+// ConfigFileIssues 一个配置文件的校验结果
+type ConfigFileIssues struct {
+	Path    string         `json:"path"`
+	Missing bool           `json:"missing"`
+	Issues  []config.Issue `json:"issues"`
+}
+
+// ConfigValidateResult config validate 命令结果
+type ConfigValidateResult struct {
+	Action string              `json:"action"`
+	Files  []*ConfigFileIssues `json:"files"`
+}
+
+// HasErrors 是否有 error 级别的问题，用于决定退出码
+func (r *ConfigValidateResult) HasErrors() bool {
+	for _, file := range r.Files {
+		if config.HasErrors(file.Issues) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigMigrateResult config migrate 命令结果
+type ConfigMigrateResult struct {
+	Action  string                    `json:"action"`
+	DryRun  bool                      `json:"dry_run"`
+	Results []*config.MigrationResult `json:"results"`
+}
+
+// ConfigSchemaResult config schema 命令结果
+type ConfigSchemaResult struct {
+	Action  string `json:"action"`
+	Target  string `json:"target"`
+	Content string `json:"content"` // JSON Schema 内容
+}
+
+// ConfigCommand 配置命令，查看、校验和迁移配置文件，不依赖服务容器
+type ConfigCommand struct {
+	*BaseCommand
+	configManager *config.ConfigManager
+}
+
+// NewConfigCommand 创建配置命令
+func NewConfigCommand() *ConfigCommand {
+	return &ConfigCommand{
+		BaseCommand:   NewBaseCommand("config", "查看、校验和迁移配置文件"),
+		configManager: config.NewConfigManager(),
+	}
+}
+
+// Execute 执行配置命令
+// 支持的参数: action (string) 默认 show; config_dir (string) 配置目录;
+// show: resolved (bool) 显示展开环境变量、覆盖和密钥引用后的最终配置，否则显示文件原文; redacted (bool) 隐藏密钥字段
+// migrate: dry_run (bool) 只显示将要进行的修改; schema: target (string) server 或 image，默认 server
+func (c *ConfigCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	action, _ := req.Args["action"].(string)
+	if action == "" {
+		action = ConfigActionShow
+	}
+	configDir, _ := req.Args["config_dir"].(string)
+	if configDir == "" && action != ConfigActionSchema {
+		return ErrorResponse(fmt.Errorf("缺少配置目录")), nil
+	}
+
+	switch action {
+	case ConfigActionShow:
+		return c.executeShow(req, configDir)
+	case ConfigActionValidate:
+		return c.executeValidate(configDir)
+	case ConfigActionMigrate:
+		return c.executeMigrate(req, configDir)
+	case ConfigActionSchema:
+		return c.executeSchema(req)
+	default:
+		return ErrorResponse(fmt.Errorf("未知子命令: %s", action)), nil
+	}
+}
+
+// executeShow 显示 server.yaml 原文或最终生效配置
+func (c *ConfigCommand) executeShow(req *CommandRequest, configDir string) (*CommandResponse, error) {
+	path, _ := config.ConfigFilePaths(configDir)
+	resolved, _ := req.Args["resolved"].(bool)
+	redacted, _ := req.Args["redacted"].(bool)
+
+	var (
+		content []byte
+		err     error
+	)
+	if resolved {
+		content, err = c.resolvedContent(path, redacted)
+	} else {
+		content, err = c.rawContent(path, redacted)
+	}
+	if err != nil {
+		return ErrorResponse(err), nil
+	}
+
+	result := &ConfigShowResult{
+		Action:   ConfigActionShow,
+		Path:     path,
+		Resolved: resolved,
+		Redacted: redacted,
+		Content:  string(content),
+	}
+	return SuccessResponse(result, map[string]interface{}{"path": path}), nil
+}
+
+// executeValidate 校验 server.yaml 和 image_processing.json
+// server.yaml 必须存在；image_processing.json 不存在时使用默认配置，不算问题
+func (c *ConfigCommand) executeValidate(configDir string) (*CommandResponse, error) {
+	serverPath, imagePath := config.ConfigFilePaths(configDir)
+	files := []struct {
+		path     string
+		required bool
+		validate func(string) ([]config.Issue, error)
+	}{
+		{serverPath, true, config.ValidateServerConfigFile},
+		{imagePath, false, config.ValidateImageProcessingFile},
+	}
+
+	result := &ConfigValidateResult{Action: ConfigActionValidate}
+	for _, file := range files {
+		fileIssues := &ConfigFileIssues{Path: file.path}
+		if _, err := os.Stat(file.path); os.IsNotExist(err) {
+			fileIssues.Missing = true
+			if file.required {
+				fileIssues.Issues = []config.Issue{{
+					Severity: config.SeverityError,
+					Message:  "file not found, run 'init' or 'config migrate' to create it",
+				}}
+			}
+			result.Files = append(result.Files, fileIssues)
+			continue
+		}
+
+		issues, err := file.validate(file.path)
+		if err != nil {
+			return ErrorResponse(err), nil
+		}
+		fileIssues.Issues = issues
+		result.Files = append(result.Files, fileIssues)
+	}
+
+	return SuccessResponse(result, map[string]interface{}{"has_errors": result.HasErrors()}), nil
+}
+
+// executeMigrate 将配置目录中的文件升级到当前版本
+func (c *ConfigCommand) executeMigrate(req *CommandRequest, configDir string) (*CommandResponse, error) {
+	dryRun, _ := req.Args["dry_run"].(bool)
+	results, err := config.MigrateConfigDir(configDir, dryRun)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("迁移配置失败: %w", err)), nil
+	}
+
+	result := &ConfigMigrateResult{
+		Action:  ConfigActionMigrate,
+		DryRun:  dryRun,
+		Results: results,
+	}
+	return SuccessResponse(result, map[string]interface{}{"dry_run": dryRun}), nil
+}
+
+// executeSchema 输出配置文件的 JSON Schema
+func (c *ConfigCommand) executeSchema(req *CommandRequest) (*CommandResponse, error) {
+	target, _ := req.Args["target"].(string)
+	if target == "" {
+		target = ConfigSchemaServer
+	}
+
+	var schema *config.Schema
+	switch target {
+	case ConfigSchemaServer:
+		schema = config.ServerConfigSchema()
+	case ConfigSchemaImage:
+		schema = config.ImageProcessingSchema()
+	default:
+		return ErrorResponse(fmt.Errorf("未知 schema 目标: %s（支持 server、image）", target)), nil
+	}
+
+	content, err := config.MarshalSchema(schema)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("生成 schema 失败: %w", err)), nil
+	}
+	result := &ConfigSchemaResult{
+		Action:  ConfigActionSchema,
+		Target:  target,
+		Content: string(content),
+	}
+	return SuccessResponse(result, map[string]interface{}{"target": target}), nil
+}
+
+// resolvedContent 加载最终生效的配置（含默认值）并序列化为 YAML
+func (c *ConfigCommand) resolvedContent(path string, redacted bool) ([]byte, error) {
+	serverConfig, err := c.configManager.ResolveServerConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+	if redacted {
+		if serverConfig, err = config.RedactServerConfig(serverConfig); err != nil {
+			return nil, fmt.Errorf("配置脱敏失败: %w", err)
+		}
+	}
+	content, err := yaml.Marshal(serverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	return content, nil
+}
+
+// rawContent 读取配置文件原文，脱敏时保留注释和引用
+func (c *ConfigCommand) rawContent(path string, redacted bool) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	if redacted {
+		if content, err = config.RedactYAML(content); err != nil {
+			return nil, fmt.Errorf("配置脱敏失败: %w", err)
+		}
+	}
+	return content, nil
+}
+
+// Validate 验证命令参数
+func (c *ConfigCommand) Validate(args []string) error {
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case arg == "--resolved", arg == "--redacted", arg == "--dry-run":
+		case strings.HasPrefix(arg, "--"):
+			return fmt.Errorf("未知选项: %s", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) == 0 {
+		return nil
+	}
+	switch positional[0] {
+	case ConfigActionShow, ConfigActionValidate, ConfigActionMigrate:
+		if len(positional) > 1 {
+			return fmt.Errorf("只能指定一个子命令")
+		}
+	case ConfigActionSchema:
+		if len(positional) > 2 {
+			return fmt.Errorf("schema 只能指定一个目标")
+		}
+		if len(positional) == 2 && positional[1] != ConfigSchemaServer && positional[1] != ConfigSchemaImage {
+			return fmt.Errorf("未知 schema 目标: %s（支持 server、image）", positional[1])
+		}
+	default:
+		return fmt.Errorf("未知子命令: %s（支持 show、validate、migrate、schema）", positional[0])
+	}
+	return nil
+}
+
+// ShowResult 显示命令结果（用于CLI调用）
+func (c *ConfigCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	switch result := data.(type) {
+	case *ConfigShowResult:
+		c.showConfig(result)
+	case *ConfigValidateResult:
+		c.showIssues(result)
+	case *ConfigMigrateResult:
+		c.showMigration(result)
+	case *ConfigSchemaResult:
+		fmt.Print(result.Content)
+	default:
+		logger.Error("❌ 无效的结果数据")
+	}
+}
+
+// showConfig 以 YAML 显示配置
+func (c *ConfigCommand) showConfig(result *ConfigShowResult) {
+	source := "配置文件原文"
+	if result.Resolved {
+		source = "最终生效配置（已展开环境变量、覆盖和密钥引用）"
+	}
+	if result.Redacted {
+		source += "，密钥已隐藏"
+	}
+	logger.Infof("📁 配置文件: %s", result.Path)
+	logger.Infof("⚙️  %s:\n%s", source, result.Content)
+}
+
+// showIssues 按文件列出校验问题
+func (c *ConfigCommand) showIssues(result *ConfigValidateResult) {
+	for _, file := range result.Files {
+		switch {
+		case file.Missing && len(file.Issues) == 0:
+			logger.Infof("➖ %s: 不存在，使用默认配置", file.Path)
+			continue
+		case len(file.Issues) == 0:
+			logger.Infof("✅ %s: 没有发现问题", file.Path)
+			continue
+		}
+
+		logger.Infof("📁 %s:", file.Path)
+		for _, issue := range file.Issues {
+			if issue.Severity == config.SeverityError {
+				logger.Errorf("  ❌ %s", issue.String())
+			} else {
+				logger.Warnf("  ⚠️  %s", issue.String())
+			}
+		}
+	}
+
+	if result.HasErrors() {
+		logger.Error("❌ 配置校验失败")
+	} else {
+		logger.Info("✅ 配置校验通过")
+	}
+}
+
+// showMigration 显示每个文件的迁移情况
+func (c *ConfigCommand) showMigration(result *ConfigMigrateResult) {
+	if len(result.Results) == 0 {
+		logger.Info("➖ 没有找到需要迁移的配置文件")
+		return
+	}
+
+	for _, file := range result.Results {
+		if !file.Changed() {
+			logger.Infof("✅ %s: 已是最新版本 (v%d)", file.Path, file.ToVersion)
+			continue
+		}
+
+		logger.Infof("📁 %s: v%d → v%d", file.Path, file.FromVersion, file.ToVersion)
+		if file.Source != "" {
+			logger.Infof("  📦 从旧位置迁移: %s", file.Source)
+		}
+		for _, change := range file.Changes {
+			logger.Infof("  - %s", change)
+		}
+		if file.Backup != "" {
+			logger.Infof("  💾 已备份原文件: %s", filepath.Base(file.Backup))
+		}
+	}
+
+	if result.DryRun {
+		logger.Info("🔍 预览模式，未写入任何文件（去掉 --dry-run 执行迁移）")
+	} else {
+		logger.Info("✅ 配置迁移完成")
+	}
+}
@@ -3,7 +3,10 @@ package commands
 import (
 	"context"
 
+	"github.com/allanpk716/to_icalendar/pkg/metrics"
 	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/task"
+	"github.com/allanpk716/to_icalendar/pkg/todosync"
 )
 
 // CommandExecutor 命令执行器接口
@@ -38,6 +41,10 @@ type ServiceContainer interface {
 	GetCleanupService() services.CleanupService
 	GetTodoService() services.TodoService
 	GetDifyService() services.DifyService
+	GetDifyBackend(name string) (services.DifyService, error)
+	GetTaskManager() (*task.TaskManager, error)
+	GetMetricsStore() *metrics.Store
+	GetSyncStore() (*todosync.Store, error)
 	GetLogger() interface{}
 }
 
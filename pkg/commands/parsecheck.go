@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/dify"
+	"github.com/allanpk716/to_icalendar/pkg/ical"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// ParseCheckResult parse-check 命令结果
+type ParseCheckResult struct {
+	File     string           `json:"file"`          // 样本文件路径
+	Reminder *models.Reminder `json:"reminder"`      // 解析得到的提醒
+	ICS      string           `json:"ics,omitempty"` // iCalendar 输出（--ics）
+}
+
+// ParseCheckCommand 解析检查命令，使用统一的响应解析器解析一个 Dify 回答样本
+type ParseCheckCommand struct {
+	*BaseCommand
+}
+
+// NewParseCheckCommand 创建解析检查命令，不依赖服务容器
+func NewParseCheckCommand() *ParseCheckCommand {
+	return &ParseCheckCommand{
+		BaseCommand: NewBaseCommand("parse-check", "使用响应解析器解析 Dify 回答样本"),
+	}
+}
+
+// Execute 执行解析检查命令
+// 支持的参数: file (string) 样本文件，可以是回答文本或保存的 Dify 响应 JSON;
+// ics (bool) 同时输出 iCalendar 格式
+func (c *ParseCheckCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	file, _ := req.Args["file"].(string)
+	if file == "" {
+		return ErrorResponse(fmt.Errorf("缺少样本文件")), nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("读取样本文件失败: %w", err)), nil
+	}
+
+	reminder, err := dify.NewResponseParser().ParseReminder(ctx, string(data))
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("解析失败: %w", err)), nil
+	}
+
+	result := &ParseCheckResult{
+		File:     file,
+		Reminder: reminder,
+	}
+
+	if ics, _ := req.Args["ics"].(bool); ics {
+		parsed, err := models.ParseReminderTime(*reminder, time.Local)
+		if err != nil {
+			return ErrorResponse(fmt.Errorf("解析提醒时间失败: %w", err)), nil
+		}
+		var buf bytes.Buffer
+		if err := ical.NewEncoder().Encode(&buf, parsed); err != nil {
+			return ErrorResponse(fmt.Errorf("生成 iCalendar 失败: %w", err)), nil
+		}
+		result.ICS = buf.String()
+	}
+
+	return SuccessResponse(result, map[string]interface{}{"file": file}), nil
+}
+
+// Validate 验证命令参数
+func (c *ParseCheckCommand) Validate(args []string) error {
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case arg == "--ics":
+		case strings.HasPrefix(arg, "--"):
+			return fmt.Errorf("未知选项: %s", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) != 1 {
+		return fmt.Errorf("需要且只能指定一个样本文件")
+	}
+	return nil
+}
+
+// ShowResult 显示解析结果（用于CLI调用）
+func (c *ParseCheckCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	result, ok := data.(*ParseCheckResult)
+	if !ok {
+		logger.Error("❌ 无效的结果数据")
+		return
+	}
+
+	reminder := result.Reminder
+	logger.Infof("🔍 样本: %s", result.File)
+	logger.Infof("📝 任务标题: %s", reminder.Title)
+	if reminder.Description != "" {
+		logger.Infof("📄 任务描述: %s", reminder.Description)
+	}
+	logger.Infof("📅 日期时间: %s %s", reminder.Date, reminder.Time)
+	if reminder.EndDate != "" || reminder.EndTime != "" {
+		logger.Infof("🏁 结束时间: %s %s", reminder.EndDate, reminder.EndTime)
+	}
+	if reminder.Duration != "" {
+		logger.Infof("⌛ 持续时间: %s", reminder.Duration)
+	}
+	logger.Infof("⏰ 提前提醒: %s", reminder.RemindBefore)
+	logger.Infof("📋 任务列表: %s", reminder.List)
+	if reminder.Kind != "" {
+		logger.Infof("🗂️ 类型: %s", reminder.Kind)
+	}
+	if reminder.Location != "" {
+		logger.Infof("📍 地点: %s", reminder.Location)
+	}
+	if len(reminder.Attendees) > 0 {
+		logger.Infof("👥 参会人: %s", strings.Join(reminder.Attendees, ", "))
+	}
+	logger.Infof("⚡ 优先级: %s", reminder.Priority)
+
+	if output, err := json.MarshalIndent(reminder, "", "  "); err == nil {
+		logger.Info("")
+		logger.Infof("Reminder JSON:\n%s", output)
+	}
+
+	if result.ICS != "" {
+		logger.Info("")
+		logger.Infof("iCalendar:\n%s", strings.ReplaceAll(result.ICS, "\r\n", "\n"))
+	}
+}
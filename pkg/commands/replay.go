@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/task"
+)
+
+// 重放模式
+const (
+	ReplayModeReparse = "reparse" // 使用保存的 Dify 响应重新解析
+	ReplayModeResend  = "resend"  // 将保存的截图重新发送给 AI
+)
+
+// ReplayResult replay 命令结果
+type ReplayResult struct {
+	TaskID        string           `json:"task_id"`                // 被重放的任务ID
+	Mode          string           `json:"mode"`                   // 重放模式
+	Backend       string           `json:"backend,omitempty"`      // 重新发送时使用的 Dify 后端
+	AIAnswer      string           `json:"ai_answer,omitempty"`    // AI 回答
+	Reminder      *models.Reminder `json:"reminder"`               // 使用当前解析代码得到的提醒
+	OriginalTitle string           `json:"original_title"`         // 任务会话中记录的原标题
+	OriginalError string           `json:"original_error"`         // 任务会话中记录的错误
+	Submitted     bool             `json:"submitted"`              // 是否已重新提交到 Microsoft Todo
+	NewTaskID     string           `json:"new_task_id,omitempty"`  // 重新提交时创建的任务会话ID
+	TodoTaskID    string           `json:"todo_task_id,omitempty"` // 重新提交时创建的 Microsoft Todo 任务ID
+	EventID       string           `json:"event_id,omitempty"`     // 重新提交时创建的 Outlook 日历事件ID
+	Warnings      []string         `json:"warnings,omitempty"`
+}
+
+// ReplayCommand 重放命令，使用保存的任务会话重新解析或重新调用 AI，并可重新提交
+type ReplayCommand struct {
+	*BaseCommand
+	container  ServiceContainer
+	clipUpload *ClipUploadCommand
+}
+
+// NewReplayCommand 创建重放命令
+func NewReplayCommand(container ServiceContainer) *ReplayCommand {
+	return &ReplayCommand{
+		BaseCommand: NewBaseCommand("replay", "重放保存的任务会话"),
+		container:   container,
+		clipUpload:  NewClipUploadCommand(container),
+	}
+}
+
+// Execute 执行重放命令
+// 支持的参数: task_id (string); reparse (bool) 只使用保存的 Dify 响应;
+// resubmit (bool) 创建到 Microsoft Todo; backend (string) 重新发送截图使用的 Dify 后端
+func (c *ReplayCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	taskID, _ := req.Args["task_id"].(string)
+	reparse, _ := req.Args["reparse"].(bool)
+	resubmit, _ := req.Args["resubmit"].(bool)
+	backend, _ := req.Args["backend"].(string)
+
+	if taskID == "" {
+		return ErrorResponse(fmt.Errorf("缺少任务ID")), nil
+	}
+	if reparse && backend != "" {
+		return ErrorResponse(fmt.Errorf("--reparse 与 --backend 不能同时使用")), nil
+	}
+
+	logger.Infof("开始重放任务 %s", taskID)
+
+	taskManager, err := c.container.GetTaskManager()
+	if err != nil {
+		return ErrorResponse(err), nil
+	}
+	session, err := taskManager.GetTaskSession(taskID)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("任务不存在: %s", taskID)), nil
+	}
+
+	image, imageErr := taskManager.ReadTaskFile(session, task.FileTypeClipboardOriginal)
+	savedResponse, responseErr := taskManager.ReadTaskFile(session, task.FileTypeDifyResponse)
+
+	// 未指定模式时优先重新解析，没有保存 Dify 响应时重新发送截图
+	mode := ReplayModeReparse
+	switch {
+	case reparse:
+		if responseErr != nil {
+			return ErrorResponse(fmt.Errorf("任务没有保存 Dify 响应，无法重新解析: %w", responseErr)), nil
+		}
+	case backend != "" || responseErr != nil:
+		mode = ReplayModeResend
+		if imageErr != nil {
+			return ErrorResponse(fmt.Errorf("任务没有保存原始截图，无法重新发送: %w", imageErr)), nil
+		}
+	}
+
+	var difyResponse *models.DifyResponse
+	if mode == ReplayModeReparse {
+		difyResponse = &models.DifyResponse{}
+		if err := json.Unmarshal(savedResponse, difyResponse); err != nil {
+			return ErrorResponse(fmt.Errorf("解析保存的 Dify 响应失败: %w", err)), nil
+		}
+	} else {
+		difyService, err := c.container.GetDifyBackend(backend)
+		if err != nil {
+			return ErrorResponse(err), nil
+		}
+		logger.Infof("将原始截图重新发送到 Dify 后端: %s", backendName(backend))
+		difyResponse, err = difyService.ProcessImage(ctx, image)
+		if err != nil {
+			return ErrorResponse(fmt.Errorf("Dify 服务处理失败: %w", err)), nil
+		}
+	}
+
+	reminder, err := ParseDifyResponseToReminder(ctx, difyResponse, string(models.ContentTypeImage), "[图片内容]")
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("解析 Dify 响应失败: %w", err)), nil
+	}
+
+	result := &ReplayResult{
+		TaskID:        taskID,
+		Mode:          mode,
+		AIAnswer:      difyAnswerText(difyResponse),
+		Reminder:      reminder,
+		OriginalTitle: session.Title,
+		OriginalError: session.ErrorMessage,
+	}
+	if mode == ReplayModeResend {
+		result.Backend = backendName(backend)
+	}
+
+	if resubmit {
+		if err := c.resubmit(ctx, taskManager, result, difyResponse, image); err != nil {
+			return ErrorResponse(err), nil
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"task_id":  taskID,
+		"mode":     mode,
+		"resubmit": resubmit,
+	}
+
+	logger.Info("replay 命令执行完成")
+	return SuccessResponse(result, metadata), nil
+}
+
+// resubmit 创建新的任务会话并将提醒创建到 Microsoft Todo
+func (c *ReplayCommand) resubmit(ctx context.Context, taskManager *task.TaskManager, result *ReplayResult, difyResponse *models.DifyResponse, image []byte) error {
+	session, err := taskManager.CreateTaskSession()
+	if err != nil {
+		logger.Warnf("创建任务会话失败，跳过任务会话记录: %v", err)
+		session = nil
+	} else {
+		session.Metadata["replay_of"] = result.TaskID
+		session.Metadata["replay_mode"] = result.Mode
+		if len(image) > 0 {
+			if err := taskManager.SaveFileToTask(session, task.FileTypeClipboardOriginal, image); err != nil {
+				logger.Warnf("保存原始截图失败: %v", err)
+			}
+		}
+		c.clipUpload.recordDifyResponse(session, difyResponse)
+		result.NewTaskID = session.TaskID
+	}
+
+	logger.Info("开始创建 Microsoft Todo 任务...")
+	creation, err := c.container.GetTodoService().CreateTaskWithAttachment(ctx, result.Reminder, image)
+	if err != nil {
+		c.clipUpload.finishSession(session, err)
+		return fmt.Errorf("创建 Microsoft Todo 任务失败: %w", err)
+	}
+
+	c.clipUpload.recordTodoTask(session, result.Reminder, creation)
+	c.clipUpload.finishSession(session, nil)
+
+	result.Submitted = true
+	result.TodoTaskID = creation.TaskID
+	result.EventID = creation.EventID
+	result.Warnings = creation.Warnings
+	return nil
+}
+
+// backendName 返回用于显示的 Dify 后端名称
+func backendName(backend string) string {
+	if backend == "" {
+		return models.DefaultDifyBackend
+	}
+	return backend
+}
+
+// Validate 验证命令参数
+func (c *ReplayCommand) Validate(args []string) error {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--reparse", "--resubmit":
+		case "--backend":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return fmt.Errorf("--backend 需要指定后端名称")
+			}
+			i++
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return fmt.Errorf("未知选项: %s", args[i])
+			}
+			positional = append(positional, args[i])
+		}
+	}
+
+	if len(positional) != 1 {
+		return fmt.Errorf("需要且只能指定一个任务ID")
+	}
+	return nil
+}
+
+// ShowResult 显示重放结果（用于CLI调用）
+func (c *ReplayCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	result, ok := data.(*ReplayResult)
+	if !ok {
+		logger.Error("❌ 无效的结果数据")
+		return
+	}
+
+	logger.Infof("🔁 重放任务: %s", result.TaskID)
+	if result.Mode == ReplayModeResend {
+		logger.Infof("  模式: 重新发送截图 (后端: %s)", result.Backend)
+	} else {
+		logger.Info("  模式: 使用保存的 Dify 响应重新解析")
+	}
+	if result.OriginalError != "" {
+		logger.Infof("  原错误: %s", result.OriginalError)
+	}
+	if result.AIAnswer != "" {
+		logger.Infof("  AI 回答: %s", result.AIAnswer)
+	}
+
+	logger.Info("")
+	reminder := result.Reminder
+	if result.OriginalTitle != "" && result.OriginalTitle != reminder.Title {
+		logger.Infof("📝 任务标题: %s (原标题: %s)", reminder.Title, result.OriginalTitle)
+	} else {
+		logger.Infof("📝 任务标题: %s", reminder.Title)
+	}
+	if reminder.Description != "" && reminder.Description != reminder.Title {
+		logger.Infof("📄 任务描述: %s", reminder.Description)
+	}
+	logger.Infof("📅 日期时间: %s %s", reminder.Date, reminder.Time)
+	logger.Infof("📋 任务列表: %s", reminder.List)
+	logger.Infof("⚡ 优先级: %s", reminder.Priority)
+
+	logger.Info("")
+	if !result.Submitted {
+		logger.Info("ℹ️  未提交到 Microsoft Todo，使用 --resubmit 创建任务")
+		return
+	}
+	logger.Info("✓ 已重新提交到 Microsoft Todo")
+	if result.NewTaskID != "" {
+		logger.Infof("  新任务会话: %s", result.NewTaskID)
+	}
+	if result.EventID != "" {
+		logger.Infof("  日历事件: %s", result.EventID)
+	}
+	for _, warning := range result.Warnings {
+		logger.Warnf("  ⚠️ %s", warning)
+	}
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/metrics"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// DefaultStatsSince stats 命令默认统计的时间范围
+const DefaultStatsSince = "7d"
+
+// StatsCommand 处理指标统计命令
+type StatsCommand struct {
+	*BaseCommand
+	metricsStore *metrics.Store
+}
+
+// NewStatsCommand 创建统计命令
+func NewStatsCommand(container ServiceContainer) *StatsCommand {
+	return &StatsCommand{
+		BaseCommand:  NewBaseCommand("stats", "查看处理指标统计"),
+		metricsStore: container.GetMetricsStore(),
+	}
+}
+
+// Execute 执行统计命令
+// 支持的参数: since (string) 统计范围，如 7d、24h，默认 7d
+func (c *StatsCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	sinceSpec, _ := req.Args["since"].(string)
+	if sinceSpec == "" {
+		sinceSpec = DefaultStatsSince
+	}
+	span, err := models.ParseSpan(sinceSpec)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("无效的 --since: %w", err)), nil
+	}
+
+	// 查询前先按保留天数清理过期指标
+	if _, err := c.metricsStore.Prune(); err != nil {
+		logger.Warnf("清理过期指标失败: %v", err)
+	}
+
+	until := time.Now()
+	since := until.Add(-span)
+	runs, err := c.metricsStore.Query(since)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("读取处理指标失败: %w", err)), nil
+	}
+
+	metadata := map[string]interface{}{
+		"since_spec":     sinceSpec,
+		"enabled":        c.metricsStore.Enabled(),
+		"retention_days": int(c.metricsStore.Retention() / (24 * time.Hour)),
+		"metrics_dir":    c.metricsStore.Dir(),
+	}
+	return SuccessResponse(metrics.Summarize(runs, since, until), metadata), nil
+}
+
+// Validate 验证命令参数
+func (c *StatsCommand) Validate(args []string) error {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--since":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--since 需要指定时间范围，如 7d")
+			}
+			if _, err := models.ParseSpan(args[i+1]); err != nil {
+				return fmt.Errorf("无效的 --since: %w", err)
+			}
+			i++
+		case strings.HasPrefix(args[i], "--since="):
+			if _, err := models.ParseSpan(strings.TrimPrefix(args[i], "--since=")); err != nil {
+				return fmt.Errorf("无效的 --since: %w", err)
+			}
+		default:
+			return fmt.Errorf("未知参数: %s", args[i])
+		}
+	}
+	return nil
+}
+
+// ShowResult 显示处理指标统计（用于CLI调用）
+func (c *StatsCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	summary, ok := data.(*metrics.Summary)
+	if !ok {
+		logger.Error("❌ 无效的结果数据")
+		return
+	}
+
+	sinceSpec, _ := metadata["since_spec"].(string)
+	logger.Infof("📊 处理统计（最近 %s，自 %s）", sinceSpec, summary.Since.Format("2006-01-02 15:04"))
+	if enabled, ok := metadata["enabled"].(bool); ok && !enabled {
+		logger.Warn("⚠️  指标记录已关闭（cache.enable_cache_metrics: false），以下仅为历史数据")
+	}
+
+	if summary.Runs == 0 {
+		logger.Info("  暂无处理记录")
+		return
+	}
+
+	logger.Infof("  总计: %d 次，成功 %d，失败 %d，去重跳过 %d，成功率 %.1f%%",
+		summary.Runs, summary.Succeeded, summary.Failed, summary.Duplicates, summary.SuccessRate()*100)
+	logger.Infof("  平均处理耗时: %v", time.Duration(summary.AvgDurationMs)*time.Millisecond)
+	logger.Infof("  去重命中率: %.1f%%", summary.DedupHitRate()*100)
+
+	logger.Info("")
+	logger.Infof("%-10s %6s %6s %6s %8s %8s", "内容类型", "次数", "成功", "失败", "去重", "成功率")
+	contentTypes := make([]string, 0, len(summary.ByContentType))
+	for contentType := range summary.ByContentType {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+	for _, contentType := range contentTypes {
+		counts := summary.ByContentType[contentType]
+		logger.Infof("%-10s %6d %6d %6d %8d %7.1f%%", contentType, counts.Runs, counts.Succeeded,
+			counts.Failed, counts.Duplicates, counts.SuccessRate()*100)
+	}
+
+	if len(summary.FailedStages) > 0 {
+		stages := make([]string, 0, len(summary.FailedStages))
+		for stage, count := range summary.FailedStages {
+			stages = append(stages, fmt.Sprintf("%s %d", stage, count))
+		}
+		sort.Strings(stages)
+		logger.Infof("  失败阶段: %s", strings.Join(stages, "，"))
+	}
+
+	logger.Info("")
+	logger.Infof("🤖 Dify: %d 次调用，平均 %dms，P95 %dms，最长 %dms",
+		summary.Dify.Calls, summary.Dify.AvgLatencyMs, summary.Dify.P95LatencyMs, summary.Dify.MaxLatencyMs)
+	logger.Infof("   Token: 共 %d，平均每次 %d", summary.Dify.TotalTokens, summary.Dify.AvgTokens)
+	logger.Infof("📮 Graph: %d 次请求，%d 次重试", summary.Graph.Calls, summary.Graph.Retries)
+
+	logger.Info("")
+	logger.Infof("%-12s %6s %6s %6s %6s %8s", "日期", "次数", "成功", "失败", "去重", "Token")
+	for _, day := range summary.Daily {
+		logger.Infof("%-12s %6d %6d %6d %6d %8d", day.Date, day.Runs, day.Succeeded, day.Failed, day.Duplicates, day.Tokens)
+	}
+}
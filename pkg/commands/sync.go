@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/services"
+	"github.com/allanpk716/to_icalendar/pkg/todosync"
+)
+
+// SyncCommand 同步命令，将 Microsoft Todo 中的任务增量同步到本地
+type SyncCommand struct {
+	*BaseCommand
+	todoService services.TodoService
+	container   ServiceContainer
+}
+
+// NewSyncCommand 创建同步命令
+func NewSyncCommand(container ServiceContainer) *SyncCommand {
+	return &SyncCommand{
+		BaseCommand: NewBaseCommand("sync", "从 Microsoft Todo 增量同步任务到本地"),
+		todoService: container.GetTodoService(),
+		container:   container,
+	}
+}
+
+// Execute 执行同步命令
+// 支持的参数: lists ([]string) 只同步指定列表; full (bool) 忽略增量令牌全量同步
+func (c *SyncCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	logger.Info("开始执行 sync 命令")
+
+	var listNames []string
+	if lists, ok := req.Args["lists"].([]string); ok {
+		listNames = lists
+	}
+	fullSync, _ := req.Args["full"].(bool)
+
+	client := c.todoService.GetClient()
+	if client == nil {
+		return ErrorResponse(fmt.Errorf("创建 Microsoft Todo 客户端失败")), nil
+	}
+
+	// 与创建任务前的去重检查共用同一个存储
+	store, err := c.container.GetSyncStore()
+	if err != nil {
+		return ErrorResponse(err), nil
+	}
+
+	result, err := todosync.NewSyncer(client, store).SyncAll(ctx, listNames, fullSync)
+	if err != nil {
+		logger.Errorf("同步失败: %v", err)
+		return ErrorResponse(fmt.Errorf("同步失败: %w", err)), nil
+	}
+
+	metadata := map[string]interface{}{
+		"full_sync":   fullSync,
+		"list_count":  len(result.Lists),
+		"synced_at":   time.Now(),
+		"duration_ms": result.Duration.Milliseconds(),
+	}
+
+	logger.Info("sync 命令执行完成")
+	return SuccessResponse(result, metadata), nil
+}
+
+// Validate 验证命令参数
+func (c *SyncCommand) Validate(args []string) error {
+	return nil
+}
+
+// ShowResult 显示同步结果（用于CLI调用）
+func (c *SyncCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	result, ok := data.(*todosync.SyncResult)
+	if !ok {
+		logger.Error("❌ 无效的结果数据")
+		return
+	}
+
+	if len(result.Lists) == 0 {
+		logger.Info("没有需要同步的任务列表")
+		return
+	}
+
+	logger.Info("🔄 同步结果:")
+	for _, list := range result.Lists {
+		if list.Error != "" {
+			logger.Errorf("  ❌ %s: %s", list.ListName, list.Error)
+			continue
+		}
+
+		mode := "增量"
+		if list.FullSync {
+			mode = "全量"
+		}
+		logger.Infof("  ✓ %s (%s): 新增 %d, 更新 %d, 完成 %d, 删除 %d, 共 %d 个任务",
+			list.ListName, mode, list.Added, list.Updated, list.Completed, list.Deleted, list.TotalTasks)
+	}
+
+	logger.Infof("⏱️  耗时: %v", result.Duration.Round(time.Millisecond))
+}
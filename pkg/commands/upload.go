@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/config"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/services"
+)
+
+// UploadCommand 上传命令，将提醒事项 JSON 文件批量创建到 Microsoft Todo
+type UploadCommand struct {
+	*BaseCommand
+	todoService services.TodoService
+}
+
+// NewUploadCommand 创建上传命令
+func NewUploadCommand(container ServiceContainer) *UploadCommand {
+	return &UploadCommand{
+		BaseCommand: NewBaseCommand("upload", "批量上传提醒事项文件到 Microsoft Todo"),
+		todoService: container.GetTodoService(),
+	}
+}
+
+// Execute 执行上传命令
+// 支持的参数: patterns ([]string) 提醒事项文件路径或 glob 模式
+func (c *UploadCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	logger.Info("开始执行 upload 命令")
+
+	patterns, _ := req.Args["patterns"].([]string)
+	if len(patterns) == 0 {
+		return ErrorResponse(fmt.Errorf("请指定至少一个提醒事项文件")), nil
+	}
+
+	configManager := config.NewConfigManager()
+	var reminders []*models.Reminder
+	for _, pattern := range patterns {
+		loaded, err := configManager.LoadRemindersFromPattern(pattern)
+		if err != nil {
+			logger.Errorf("加载提醒事项失败: %v", err)
+			return ErrorResponse(fmt.Errorf("加载提醒事项失败: %w", err)), nil
+		}
+		reminders = append(reminders, loaded...)
+	}
+
+	logger.Infof("共加载 %d 个提醒事项，开始批量上传...", len(reminders))
+
+	result, err := c.todoService.CreateTasks(ctx, reminders)
+	if err != nil {
+		logger.Errorf("批量上传失败: %v", err)
+		return ErrorResponse(fmt.Errorf("批量上传失败: %w", err)), nil
+	}
+
+	metadata := map[string]interface{}{
+		"total":       len(reminders),
+		"uploaded_at": time.Now(),
+		"duration_ms": result.Duration.Milliseconds(),
+	}
+
+	logger.Info("upload 命令执行完成")
+	return SuccessResponse(result, metadata), nil
+}
+
+// Validate 验证命令参数
+func (c *UploadCommand) Validate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定至少一个提醒事项文件")
+	}
+	return nil
+}
+
+// ShowResult 显示上传结果（用于CLI调用）
+func (c *UploadCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	result, ok := data.(*services.BatchCreateResult)
+	if !ok {
+		logger.Error("❌ 无效的结果数据")
+		return
+	}
+
+	logger.Info("📤 上传结果:")
+	for _, item := range result.Items {
+		if item.Error != "" {
+			logger.Errorf("  ❌ %s: %s", item.Title, item.Error)
+			continue
+		}
+
+		retryInfo := ""
+		if item.Attempts > 1 {
+			retryInfo = fmt.Sprintf("（重试 %d 次）", item.Attempts-1)
+		}
+		target := item.ListName
+		if item.EventID != "" {
+			if target == "" {
+				target = "日历"
+			} else {
+				target += " + 日历"
+			}
+		}
+		logger.Infof("  ✓ %s → %s%s", item.Title, target, retryInfo)
+		for _, warning := range item.Warnings {
+			logger.Warnf("    ⚠️  %s", warning)
+		}
+	}
+
+	logger.Infof("成功 %d, 失败 %d, 耗时 %v", result.SuccessCount, result.FailedCount, result.Duration.Round(time.Millisecond))
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/clipboard"
+	"github.com/allanpk716/to_icalendar/pkg/clipwatch"
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/metrics"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/services"
+)
+
+// WatchCommand 剪贴板监听命令，检测到新的剪贴板内容后自动处理并上传
+type WatchCommand struct {
+	*BaseCommand
+	clipboardService services.ClipboardService
+	configService    services.ConfigService
+	metricsStore     *metrics.Store
+	clipUpload       *ClipUploadCommand
+}
+
+// NewWatchCommand 创建剪贴板监听命令
+func NewWatchCommand(container ServiceContainer) *WatchCommand {
+	command := &WatchCommand{
+		BaseCommand:      NewBaseCommand("watch", "监听剪贴板并自动处理新内容"),
+		clipboardService: container.GetClipboardService(),
+		configService:    container.GetConfigService(),
+		metricsStore:     container.GetMetricsStore(),
+		clipUpload:       NewClipUploadCommand(container),
+	}
+	command.clipUpload.SetSource("watch")
+	return command
+}
+
+// Execute 执行监听命令，阻塞直到上下文取消
+// 支持的参数: trigger (string) 覆盖配置中的触发条件
+func (c *WatchCommand) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	logger.Info("开始执行 watch 命令")
+
+	serverConfig, err := c.configService.LoadServerConfig(ctx)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("加载配置失败: %w", err)), nil
+	}
+
+	watchConfig := serverConfig.Watch
+	if trigger, ok := req.Args["trigger"].(string); ok && trigger != "" {
+		watchConfig.Trigger = trigger
+	}
+
+	watcher, err := clipwatch.NewWatcher(
+		clipwatch.NewSequenceDetector(clipboard.SequenceNumber),
+		c.clipboardService,
+		c.handleContent,
+		watchConfig,
+	)
+	if err != nil {
+		return ErrorResponse(fmt.Errorf("创建剪贴板监听器失败: %w", err)), nil
+	}
+	watcher.SetValidator(clipboard.ValidateClipboardContent)
+	watcher.SetDuplicateHandler(func(content *models.ClipboardContent) {
+		c.metricsStore.RecordDuplicate("watch", string(content.Type))
+	})
+
+	startedAt := time.Now()
+	logger.Infof("👀 正在监听剪贴板（触发条件: %s），按 Ctrl+C 停止", watchConfig.GetTrigger())
+	if err := watcher.Run(ctx); err != nil {
+		return ErrorResponse(fmt.Errorf("剪贴板监听失败: %w", err)), nil
+	}
+
+	metadata := map[string]interface{}{
+		"trigger":    watchConfig.GetTrigger(),
+		"started_at": startedAt,
+		"duration":   time.Since(startedAt),
+	}
+
+	logger.Info("watch 命令执行完成")
+	return SuccessResponse(watcher.Stats(), metadata), nil
+}
+
+// handleContent 通过 clip-upload 流程处理一条剪贴板内容
+func (c *WatchCommand) handleContent(ctx context.Context, content *models.ClipboardContent) error {
+	resp, err := c.clipUpload.ProcessContent(ctx, content)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	c.clipUpload.ShowResult(resp.Data, resp.Metadata)
+	return nil
+}
+
+// Validate 验证命令参数
+func (c *WatchCommand) Validate(args []string) error {
+	return nil
+}
+
+// ShowResult 显示监听统计（用于CLI调用）
+func (c *WatchCommand) ShowResult(data interface{}, metadata map[string]interface{}) {
+	stats, ok := data.(clipwatch.Stats)
+	if !ok {
+		logger.Error("❌ 无效的结果数据")
+		return
+	}
+
+	logger.Info("📊 剪贴板监听统计:")
+	if duration, ok := metadata["duration"].(time.Duration); ok {
+		logger.Infof("  监听时长: %v", duration.Round(time.Second))
+	}
+	logger.Infof("  检测到变化: %d", stats.Changes)
+	logger.Infof("  处理成功: %d, 失败: %d", stats.Processed, stats.Failed)
+	logger.Infof("  已过滤: %d, 重复跳过: %d", stats.Skipped, stats.Duplicates)
+	if stats.LastError != "" {
+		logger.Warnf("  最近错误: %s", stats.LastError)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"time"
 
 	"github.com/allanpk716/to_icalendar/pkg/logger"
@@ -22,23 +21,31 @@ func NewConfigManager() *ConfigManager {
 }
 
 // LoadServerConfig 加载服务器配置文件
+// 配置值支持 ${ENV_VAR} 展开、TO_ICALENDAR_* 环境变量覆盖以及 file:/cmd: 密钥引用
 func (cm *ConfigManager) LoadServerConfig(configPath string) (*models.ServerConfig, error) {
-	// 检查文件是否存在
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("server config file not found: %s", configPath)
+	config, err := cm.ResolveServerConfig(configPath)
+	if err != nil {
+		return nil, err
 	}
 
-	// 读取文件
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read server config file: %w", err)
+	// 添加配置状态日志
+	logger.Infof("提醒配置加载完成:")
+	logger.Infof("  默认提醒时间: %s", config.Reminder.DefaultRemindBefore)
+	logger.Infof("  智能提醒功能: %t", config.Reminder.EnableSmartReminder)
+	logger.Infof("  日期任务默认时间: %s", config.Reminder.GetDefaultTime())
+	if config.Reminder.Schedule.Enabled {
+		logger.Infof("  调度规则: 工作时间 %s，免打扰 %s，节假日 %s",
+			config.Reminder.Schedule.WorkingHours, config.Reminder.Schedule.QuietHours, config.Reminder.Schedule.HolidaysFile)
 	}
 
-	// 解析YAML
-	var config models.ServerConfig
-	err = yaml.Unmarshal(data, &config)
+	return config, nil
+}
+
+// ResolveServerConfig 解析出最终生效的服务器配置：展开引用、应用覆盖、校验并填充默认值
+func (cm *ConfigManager) ResolveServerConfig(configPath string) (*models.ServerConfig, error) {
+	config, err := cm.ReadServerConfig(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse server config file: %w", err)
+		return nil, err
 	}
 
 	// 验证配置完整性 - 需要 Microsoft Todo 配置
@@ -53,19 +60,11 @@ func (cm *ConfigManager) LoadServerConfig(configPath string) (*models.ServerConf
 		config.MicrosoftTodo.Timezone = "UTC" // 默认UTC时区
 	}
 
-	// 验证提醒配置
-	if err := config.Reminder.Validate(); err != nil {
-		return nil, fmt.Errorf("reminder configuration validation failed: %w", err)
-	}
-
-	// 验证去重配置
-	if err := config.Deduplication.Validate(); err != nil {
-		return nil, fmt.Errorf("deduplication configuration validation failed: %w", err)
-	}
-
-	// 验证缓存配置
-	if err := config.Cache.Validate(); err != nil {
-		return nil, fmt.Errorf("cache configuration validation failed: %w", err)
+	// 按段验证配置（附件、提醒、去重、缓存、监听、指标、日志）
+	for _, check := range serverConfigChecks {
+		if err := check.validate(config); err != nil {
+			return nil, fmt.Errorf("%s configuration validation failed: %w", check.name, err)
+		}
 	}
 
 	// 设置默认日志配置（如果没有配置的话）
@@ -79,13 +78,36 @@ func (cm *ConfigManager) LoadServerConfig(configPath string) (*models.ServerConf
 	if config.Logging.LogDir == "" {
 		config.Logging.LogDir = "./Logs"
 	}
+	if config.Logging.Format == "" {
+		config.Logging.Format = models.LogFormatText
+	}
 
-	// 添加配置状态日志
-	logger.Infof("提醒配置加载完成:")
-	logger.Infof("  默认提醒时间: %s", config.Reminder.DefaultRemindBefore)
-	logger.Infof("  智能提醒功能: %t", config.Reminder.EnableSmartReminder)
+	return config, nil
+}
+
+// sectionCheck 一个配置段的校验，path 为该段在 YAML 中的路径，用于定位行号
+type sectionCheck struct {
+	name     string
+	path     []string
+	validate func(config *models.ServerConfig) error
+}
 
-	return &config, nil
+// serverConfigChecks 加载和 config validate 共用的分段校验，Validate 方法会填充默认值
+var serverConfigChecks = []sectionCheck{
+	{"attachments", []string{"microsoft_todo", "attachments"}, func(c *models.ServerConfig) error { return c.MicrosoftTodo.Attachments.Validate() }},
+	{"reminder", []string{"reminder"}, func(c *models.ServerConfig) error { return c.Reminder.Validate() }},
+	{"deduplication", []string{"deduplication"}, func(c *models.ServerConfig) error { return c.Deduplication.Validate() }},
+	{"cache", []string{"cache"}, func(c *models.ServerConfig) error { return c.Cache.Validate() }},
+	{"watch", []string{"watch"}, func(c *models.ServerConfig) error { return c.Watch.Validate() }},
+	{"metrics", []string{"metrics"}, func(c *models.ServerConfig) error { return c.Metrics.Validate() }},
+	{"logging", []string{"logging"}, func(c *models.ServerConfig) error {
+		switch c.Logging.Format {
+		case "", models.LogFormatText, models.LogFormatJSON:
+			return nil
+		default:
+			return fmt.Errorf("invalid format %q, must be %q or %q", c.Logging.Format, models.LogFormatText, models.LogFormatJSON)
+		}
+	}},
 }
 
 // LoadReminder 加载提醒事项JSON文件
@@ -115,9 +137,6 @@ func (cm *ConfigManager) LoadReminder(reminderPath string) (*models.Reminder, er
 	if reminder.Date == "" {
 		return nil, fmt.Errorf("date is required in reminder")
 	}
-	if reminder.Time == "" {
-		return nil, fmt.Errorf("time is required in reminder")
-	}
 
 	// 验证日期格式
 	_, err = time.Parse("2006-01-02", reminder.Date)
@@ -125,11 +144,33 @@ func (cm *ConfigManager) LoadReminder(reminderPath string) (*models.Reminder, er
 		return nil, fmt.Errorf("invalid date format, expected YYYY-MM-DD: %w", err)
 	}
 
-	// 验证时间格式（支持时间范围）
-	processedTime := parseTimeFromRange(reminder.Time)
-	_, err = time.Parse("15:04", processedTime)
-	if err != nil {
-		return nil, fmt.Errorf("invalid time format, expected HH:MM or time range like HH:MM - HH:MM: %w", err)
+	// 验证时间格式（支持时间范围；为空时创建任务会使用 default_time）
+	if reminder.Time != "" {
+		processedTime, _ := models.SplitTimeRange(reminder.Time)
+		_, err = time.Parse("15:04", processedTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time format, expected HH:MM or time range like HH:MM - HH:MM: %w", err)
+		}
+	}
+
+	// 验证结束时间（end_date / end_time / duration，可选）
+	if reminder.EndDate != "" {
+		if _, err := time.Parse("2006-01-02", reminder.EndDate); err != nil {
+			return nil, fmt.Errorf("invalid end_date format, expected YYYY-MM-DD: %w", err)
+		}
+	}
+	if reminder.EndTime != "" {
+		if _, err := time.Parse("15:04", reminder.EndTime); err != nil {
+			return nil, fmt.Errorf("invalid end_time format, expected HH:MM: %w", err)
+		}
+	}
+	if reminder.Duration != "" {
+		if _, err := models.ParseSpan(reminder.Duration); err != nil {
+			return nil, fmt.Errorf("invalid duration format, expected like 90m, 1h30m or 2d: %w", err)
+		}
+	}
+	if _, err := models.ParseReminderTime(reminder, time.UTC); err != nil {
+		return nil, fmt.Errorf("invalid time range: %w", err)
 	}
 
 	// 验证优先级
@@ -142,6 +183,16 @@ func (cm *ConfigManager) LoadReminder(reminderPath string) (*models.Reminder, er
 		}
 	}
 
+	// 验证类型
+	if reminder.Kind != "" {
+		switch reminder.Kind {
+		case models.KindTask, models.KindEvent, models.KindBoth:
+			// 有效类型
+		default:
+			return nil, fmt.Errorf("invalid kind, must be one of: task, event, both")
+		}
+	}
+
 	return &reminder, nil
 }
 
@@ -169,43 +220,8 @@ func (cm *ConfigManager) LoadRemindersFromPattern(pattern string) ([]*models.Rem
 	return reminders, nil
 }
 
-// CreateServerConfigTemplate 创建服务器配置模板文件
+// CreateServerConfigTemplate 创建服务器配置模板文件，并在同一目录写入供编辑器补全使用的 schema
 func (cm *ConfigManager) CreateServerConfigTemplate(configPath string) error {
-	template := models.ServerConfig{}
-
-	// Microsoft Todo 配置
-	template.MicrosoftTodo.TenantID = "YOUR_TENANT_ID"
-	template.MicrosoftTodo.ClientID = "YOUR_CLIENT_ID"
-	template.MicrosoftTodo.ClientSecret = "YOUR_CLIENT_SECRET"
-	template.MicrosoftTodo.Timezone = "Asia/Shanghai"
-
-	// 提醒配置
-	template.Reminder.DefaultRemindBefore = "15m"
-	template.Reminder.EnableSmartReminder = true
-
-	// 去重配置
-	template.Deduplication.Enabled = true
-	template.Deduplication.TimeWindowMinutes = 5
-	template.Deduplication.SimilarityThreshold = 80
-	template.Deduplication.CheckIncompleteOnly = true
-	template.Deduplication.EnableLocalCache = true
-	template.Deduplication.EnableRemoteQuery = true
-
-	// 缓存配置
-	template.Cache = models.DefaultCacheConfig()
-
-	// 日志配置
-	template.Logging.Level = "info"
-	template.Logging.ConsoleOutput = true
-	template.Logging.FileOutput = true
-	template.Logging.LogDir = "./Logs"
-
-	// 序列化为YAML
-	data, err := yaml.Marshal(template)
-	if err != nil {
-		return fmt.Errorf("failed to marshal server config template: %w", err)
-	}
-
 	// Ensure directory exists with secure permissions
 	dir := filepath.Dir(configPath)
 	if err := os.MkdirAll(dir, 0700); err != nil {
@@ -213,12 +229,11 @@ func (cm *ConfigManager) CreateServerConfigTemplate(configPath string) error {
 	}
 
 	// Write file with restricted permissions (owner read/write only)
-	err = os.WriteFile(configPath, data, 0600)
-	if err != nil {
+	if err := os.WriteFile(configPath, ServerConfigTemplate(), 0600); err != nil {
 		return fmt.Errorf("failed to write server config template: %w", err)
 	}
 
-	return nil
+	return WriteSchemaFiles(dir)
 }
 
 // SaveServerConfig 保存服务器配置文件
@@ -282,49 +297,3 @@ func (cm *ConfigManager) SaveReminderTemplate(reminderPath string, reminder *mod
 	return nil
 }
 
-// parseTimeFromRange 从时间字符串中解析时间，支持时间范围格式
-// 如果输入是时间范围（如"14:30 - 16:30"），返回开始时间"14:30"
-// 如果输入是单个时间，直接返回
-func parseTimeFromRange(timeStr string) string {
-	// 定义时间范围分隔符模式
-	rangePatterns := []string{
-		`^(\d{1,2}:\d{2})\s*[-~~到至]\s*(\d{1,2}:\d{2})$`,     // 14:30-16:30, 14:30~16:30, 14:30到16:30, 14:30至16:30
-		`^(\d{1,2}:\d{2})\s*[-~到至]\s*(\d{1,2}:\d{2})$`,       // 14:30 - 16:30 (带空格)
-		`^(\d{1,2}:\d{2})\s*-\s*(\d{1,2}:\d{2})$`,             // 14:30 - 16:30
-	}
-	
-	for _, pattern := range rangePatterns {
-		if re, err := regexp.Compile(pattern); err == nil {
-			if matches := re.FindStringSubmatch(timeStr); len(matches) > 1 {
-				startTime := matches[1]
-				// 验证开始时间格式是否有效
-				if isValidTimeFormat(startTime) {
-					return startTime
-				}
-			}
-		}
-	}
-	
-	// 尝试更宽松的匹配：只提取第一个有效的时间格式
-	timeRegex := regexp.MustCompile(`\d{1,2}:\d{2}`)
-	matches := timeRegex.FindAllString(timeStr, 2)
-	if len(matches) > 0 {
-		// 返回第一个匹配的时间（开始时间）
-		if isValidTimeFormat(matches[0]) {
-			return matches[0]
-		}
-	}
-	
-	return timeStr // 不是时间范围格式，返回原始字符串
-}
-
-// isValidTimeFormat 验证时间格式是否有效 (HH:MM 或 H:MM)
-func isValidTimeFormat(timeStr string) bool {
-	formats := []string{"15:04", "3:04"}
-	for _, format := range formats {
-		if _, err := time.Parse(format, timeStr); err == nil {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scalarTags schema 类型对应的 YAML 标签，只有这些类型的键可以通过 SetServerConfigValues 修改
+var scalarTags = map[string]string{
+	"string":  "!!str",
+	"integer": "!!int",
+	"number":  "!!float",
+	"boolean": "!!bool",
+}
+
+// ParseSetValue 解析 key=value 形式的配置项，key 为点分隔的路径（如 microsoft_todo.tenant_id）
+func ParseSetValue(arg string) (string, string, error) {
+	key, value, ok := strings.Cut(arg, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid setting %q, expected key=value", arg)
+	}
+	return key, value, nil
+}
+
+// ServerConfigKeyType 返回 server.yaml 中点分隔键的 schema 类型，键不存在时返回错误
+func ServerConfigKeyType(key string) (string, error) {
+	schema := ServerConfigSchema()
+	for _, name := range strings.Split(key, ".") {
+		if schema.Type != "object" || schema.Properties[name] == nil {
+			return "", fmt.Errorf("unknown config key %q", key)
+		}
+		schema = schema.Properties[name]
+	}
+	return schema.Type, nil
+}
+
+// ServerConfigValue 读取 server.yaml 内容中点分隔键的标量值，不存在或无法解析时返回空字符串
+func ServerConfigValue(data []byte, key string) string {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return ""
+	}
+	_, node := lookupLine(root.Content[0], strings.Split(key, "."))
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// SetServerConfigValues 按点分隔的键修改 server.yaml 内容，保留注释、格式和其他键
+// 值与原值相同的键不修改；返回修改后的内容和实际修改的键（按键名排序），没有修改时原样返回内容
+func SetServerConfigValues(data []byte, values map[string]string) ([]byte, []string, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// 先校验所有键和值，避免只修改了一部分
+	tags := make(map[string]string, len(keys))
+	normalized := make(map[string]string, len(keys))
+	for _, key := range keys {
+		keyType, err := ServerConfigKeyType(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		tag, ok := scalarTags[keyType]
+		if !ok {
+			return nil, nil, fmt.Errorf("config key %q is not a scalar value", key)
+		}
+		value, err := normalizeScalar(tag, values[key])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		tags[key] = tag
+		normalized[key] = value
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse server config file: %w", err)
+	}
+	if len(root.Content) == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("server config file must be a YAML mapping")
+	}
+
+	var changed []string
+	for _, key := range keys {
+		path := strings.Split(key, ".")
+		mapping := root.Content[0]
+		for _, name := range path[:len(path)-1] {
+			mapping = ensureMapping(mapping, name)
+		}
+		if setScalarValue(mapping, path[len(path)-1], normalized[key], tags[key]) {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode server config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode server config: %w", err)
+	}
+	return buf.Bytes(), changed, nil
+}
+
+// WriteFileAtomic 先写入同目录的临时文件再重命名替换，写入失败时原文件不变
+// 文件已存在时保留原有权限，新建时使用 perm
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tempPath := temp.Name()
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// normalizeScalar 按标签类型解析值并返回规范写法（如 TRUE 写为 true），字符串原样返回
+func normalizeScalar(tag, value string) (string, error) {
+	switch tag {
+	case "!!int":
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%q is not a valid integer", value)
+		}
+		return strconv.Itoa(n), nil
+	case "!!float":
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a valid number", value)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case "!!bool":
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%q is not a valid boolean", value)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return value, nil
+}
+
+// setScalarValue 设置映射中的标量值，保留字符串原有的引号风格和行尾注释，值未变化时返回 false
+// 新增的字符串值使用双引号，与配置模板一致
+func setScalarValue(mapping *yaml.Node, key, value, tag string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		node := mapping.Content[i+1]
+		if node.Kind == yaml.ScalarNode && node.Value == value {
+			return false
+		}
+		if node.Kind != yaml.ScalarNode || tag != "!!str" {
+			node.Style = 0
+			if tag == "!!str" {
+				node.Style = yaml.DoubleQuotedStyle
+			}
+		}
+		node.Kind = yaml.ScalarNode
+		node.Tag = tag
+		node.Value = value
+		node.Content = nil
+		return true
+	}
+
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	if tag == "!!str" {
+		valueNode.Style = yaml.DoubleQuotedStyle
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+	return true
+}
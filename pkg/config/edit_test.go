@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSetServerConfigValues(t *testing.T) {
+	values := map[string]string{
+		"microsoft_todo.tenant_id":     "tenant",
+		"microsoft_todo.client_id":     "YOUR_CLIENT_ID", // 与原值相同，不修改
+		"microsoft_todo.client_secret": "${TODO_SECRET}",
+		"reminder.default_list":        "Work",
+		"dify.timeout":                 " 30",
+		"metrics.enabled":              "TRUE",
+	}
+	data, changed, err := SetServerConfigValues(ServerConfigTemplate(), values)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"dify.timeout",
+		"metrics.enabled",
+		"microsoft_todo.client_secret",
+		"microsoft_todo.tenant_id",
+		"reminder.default_list",
+	}, changed)
+
+	// 注释保留，字符串保持引号
+	text := string(data)
+	assert.Contains(t, text, schemaModeline)
+	assert.Contains(t, text, "# Microsoft Todo 配置")
+	assert.Contains(t, text, `tenant_id: "tenant"`)
+	assert.Contains(t, text, "# Azure 租户 ID")
+
+	var config models.ServerConfig
+	require.NoError(t, yaml.Unmarshal(data, &config))
+	assert.Equal(t, "tenant", config.MicrosoftTodo.TenantID)
+	assert.Equal(t, "${TODO_SECRET}", config.MicrosoftTodo.ClientSecret)
+	assert.Equal(t, "Work", config.Reminder.DefaultList)
+	assert.Equal(t, 30, config.Dify.Timeout)
+	assert.True(t, config.Metrics.Enabled)
+	assert.Equal(t, "Asia/Shanghai", config.MicrosoftTodo.Timezone)
+
+	assert.Equal(t, "Work", ServerConfigValue(data, "reminder.default_list"))
+	assert.Empty(t, ServerConfigValue(data, "microsoft_todo.attachments"))
+	assert.Empty(t, ServerConfigValue(data, "microsoft_todo.unknown"))
+
+	// 再次设置相同的值不修改内容
+	again, changed, err := SetServerConfigValues(data, values)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Equal(t, data, again)
+}
+
+func TestSetServerConfigValues_AddsMissingKeys(t *testing.T) {
+	data := []byte("# 只有日志配置\nlogging:\n  level: info\n")
+
+	updated, changed, err := SetServerConfigValues(data, map[string]string{
+		"microsoft_todo.timezone": "UTC",
+		"dify.api_key":            "true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dify.api_key", "microsoft_todo.timezone"}, changed)
+	assert.Contains(t, string(updated), "# 只有日志配置")
+
+	var config models.ServerConfig
+	require.NoError(t, yaml.Unmarshal(updated, &config))
+	assert.Equal(t, "UTC", config.MicrosoftTodo.Timezone)
+	assert.Equal(t, "true", config.Dify.APIKey)
+	assert.Equal(t, "info", config.Logging.Level)
+}
+
+func TestSetServerConfigValues_InvalidKeys(t *testing.T) {
+	data := ServerConfigTemplate()
+
+	tests := map[string]map[string]string{
+		"unknown key":     {"microsoft_todo.tenant": "x"},
+		"not a scalar":    {"microsoft_todo.attachments": "x"},
+		"invalid integer": {"dify.timeout": "soon"},
+		"invalid boolean": {"metrics.enabled": "maybe"},
+	}
+	for name, values := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := SetServerConfigValues(data, values)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseSetValue(t *testing.T) {
+	key, value, err := ParseSetValue("dify.api_endpoint=https://api.dify.ai/v1?a=b")
+	require.NoError(t, err)
+	assert.Equal(t, "dify.api_endpoint", key)
+	assert.Equal(t, "https://api.dify.ai/v1?a=b", value)
+
+	_, _, err = ParseSetValue("dify.api_endpoint")
+	assert.Error(t, err)
+	_, _, err = ParseSetValue("=value")
+	assert.Error(t, err)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.yaml")
+
+	// 新建时使用指定权限
+	require.NoError(t, WriteFileAtomic(path, []byte("a: 1\n"), 0600))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a: 1\n", string(data))
+
+	// 已有文件保留原有权限
+	require.NoError(t, os.Chmod(path, 0640))
+	require.NoError(t, WriteFileAtomic(path, []byte("a: 2\n"), 0600))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a: 2\n", string(data))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	}
+
+	// 不留下临时文件
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// 目录不存在时返回错误
+	assert.Error(t, WriteFileAtomic(filepath.Join(t.TempDir(), "missing", "server.yaml"), []byte("a: 1\n"), 0600))
+}
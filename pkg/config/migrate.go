@@ -0,0 +1,368 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/image"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaModeline server.yaml 顶部引用 schema 的注释，yaml-language-server 据此提供补全
+const schemaModeline = "# yaml-language-server: $schema=./" + ServerSchemaFile
+
+// MigrationResult 一个配置文件的迁移结果
+type MigrationResult struct {
+	Path        string   `json:"path"`             // 目标配置文件
+	Source      string   `json:"source,omitempty"` // 从旧位置迁移时的原文件
+	FromVersion int      `json:"from_version"`
+	ToVersion   int      `json:"to_version"`
+	Changes     []string `json:"changes"`          // 所做修改的说明，为空表示已是最新
+	Backup      string   `json:"backup,omitempty"` // 覆盖前的备份文件
+}
+
+// Changed 是否需要写入
+func (r *MigrationResult) Changed() bool {
+	return r.Source != "" || len(r.Changes) > 0
+}
+
+// serverMigration 将 server.yaml 升级到 version 的一个步骤，root 为文档节点，返回修改说明
+type serverMigration struct {
+	version int
+	apply   func(root *yaml.Node) []string
+}
+
+// serverMigrations 按版本排列的 server.yaml 迁移步骤
+var serverMigrations = []serverMigration{
+	{version: 1, apply: migrateServerV1},
+}
+
+// MigrateServerConfigData 将 server.yaml 内容升级到当前版本，保留注释
+// 返回升级后的内容、原版本号和修改说明；已是最新版本时原样返回内容且修改说明为空
+func MigrateServerConfigData(data []byte) ([]byte, int, []string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to parse server config file: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, 0, nil, fmt.Errorf("server config file must be a YAML mapping")
+	}
+	doc := root.Content[0]
+
+	from := 0
+	if _, node := lookupLine(doc, []string{"config_version"}); node != nil {
+		version, err := strconv.Atoi(node.Value)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("invalid config_version %q", node.Value)
+		}
+		from = version
+	}
+	if from > models.CurrentConfigVersion {
+		return nil, from, nil, fmt.Errorf("config_version %d is newer than the supported version %d", from, models.CurrentConfigVersion)
+	}
+	if from == models.CurrentConfigVersion {
+		return data, from, nil, nil
+	}
+
+	var changes []string
+	for _, migration := range serverMigrations {
+		if migration.version <= from {
+			continue
+		}
+		changes = append(changes, migration.apply(&root)...)
+		setMappingValue(doc, "config_version", strconv.Itoa(migration.version), "!!int", true)
+	}
+	changes = append(changes, fmt.Sprintf("config_version: %d -> %d", from, models.CurrentConfigVersion))
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, from, nil, fmt.Errorf("failed to encode server config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, from, nil, fmt.Errorf("failed to encode server config: %w", err)
+	}
+	return buf.Bytes(), from, changes, nil
+}
+
+// migrateServerV1 版本 1：图片缓存限制移到 cache.quotas.images，并在文件头引用 schema
+func migrateServerV1(root *yaml.Node) []string {
+	var changes []string
+	doc := root.Content[0]
+
+	if !strings.Contains(root.HeadComment+doc.HeadComment, "yaml-language-server") {
+		if root.HeadComment == "" {
+			root.HeadComment = schemaModeline
+		} else {
+			root.HeadComment = schemaModeline + "\n" + root.HeadComment
+		}
+		changes = append(changes, "added the yaml-language-server schema reference")
+	}
+
+	_, cache := lookupLine(doc, []string{"cache"})
+	if cache == nil || cache.Kind != yaml.MappingNode {
+		return changes
+	}
+	legacy := []struct {
+		key   string
+		quota string
+	}{
+		{"image_cache_max_size", "max_size_mb"},
+		{"image_cache_max_files", "max_items"},
+	}
+	for _, field := range legacy {
+		value := removeMappingKey(cache, field.key)
+		if value == nil {
+			continue
+		}
+		if value.Value == "" || value.Value == "0" {
+			changes = append(changes, fmt.Sprintf("removed cache.%s (0 means unlimited)", field.key))
+			continue
+		}
+		images := ensureMapping(ensureMapping(cache, "quotas"), "images")
+		if setMappingValue(images, field.quota, value.Value, "!!int", false) {
+			changes = append(changes, fmt.Sprintf("moved cache.%s to cache.quotas.images.%s", field.key, field.quota))
+		} else {
+			changes = append(changes, fmt.Sprintf("removed cache.%s, cache.quotas.images.%s is already set", field.key, field.quota))
+		}
+	}
+	return changes
+}
+
+// MigrateImageProcessingData 将 image_processing.json 升级到当前版本，缺失的字段使用默认值补全
+func MigrateImageProcessingData(data []byte) ([]byte, int, []string, error) {
+	var existing map[string]interface{}
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to parse image processing config file: %w", err)
+	}
+
+	config := image.DefaultImageProcessingConfig()
+	config.ConfigVersion = 0
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to parse image processing config file: %w", err)
+	}
+	from := config.ConfigVersion
+	if from > image.CurrentConfigVersion {
+		return nil, from, nil, fmt.Errorf("config_version %d is newer than the supported version %d", from, image.CurrentConfigVersion)
+	}
+	if from == image.CurrentConfigVersion {
+		return data, from, nil, nil
+	}
+
+	var changes []string
+	if config.Normalization == nil {
+		config.Normalization = image.DefaultNormalizationConfig()
+	}
+	config.ConfigVersion = image.CurrentConfigVersion
+
+	migrated, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, from, nil, fmt.Errorf("failed to marshal image processing config: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(migrated, &fields); err != nil {
+		return nil, from, nil, err
+	}
+	for _, key := range missingKeys(existing, fields, "") {
+		if key != "config_version" {
+			changes = append(changes, fmt.Sprintf("added %s with the default value", key))
+		}
+	}
+	changes = append(changes, fmt.Sprintf("config_version: %d -> %d", from, image.CurrentConfigVersion))
+	return append(migrated, '\n'), from, changes, nil
+}
+
+// LegacyConfigPaths 返回旧版本使用过的配置文件位置：工作目录和程序目录下的 name 及 config/name
+func LegacyConfigPaths(name string) []string {
+	dirs := []string{"."}
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exe))
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, dir := range dirs {
+		for _, candidate := range []string{filepath.Join(dir, name), filepath.Join(dir, "config", name)} {
+			abs, err := filepath.Abs(candidate)
+			if err != nil || seen[abs] {
+				continue
+			}
+			seen[abs] = true
+			paths = append(paths, abs)
+		}
+	}
+	return paths
+}
+
+// FindLegacyConfigFile 配置目录中缺少 name 时，返回第一个存在的旧位置文件
+func FindLegacyConfigFile(configDir, name string) string {
+	target, err := filepath.Abs(filepath.Join(configDir, name))
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(target); err == nil {
+		return ""
+	}
+	for _, path := range LegacyConfigPaths(name) {
+		if path == target {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// MigrateConfigDir 升级配置目录中的 server.yaml 和 image_processing.json
+// 配置目录中缺少的文件会从旧位置（见 LegacyConfigPaths）迁移过来；覆盖已有文件前先备份为 <文件>.v<旧版本>.bak；
+// dryRun 为 true 时只返回将要进行的修改，不写入任何文件
+func MigrateConfigDir(configDir string, dryRun bool) ([]*MigrationResult, error) {
+	serverPath, imagePath := ConfigFilePaths(configDir)
+	files := []struct {
+		path    string
+		perm    os.FileMode
+		migrate func([]byte) ([]byte, int, []string, error)
+		current int
+	}{
+		{serverPath, 0600, MigrateServerConfigData, models.CurrentConfigVersion},
+		{imagePath, 0644, MigrateImageProcessingData, image.CurrentConfigVersion},
+	}
+
+	var results []*MigrationResult
+	for _, file := range files {
+		result := &MigrationResult{Path: file.path, ToVersion: file.current}
+		source := file.path
+		if legacy := FindLegacyConfigFile(configDir, filepath.Base(file.path)); legacy != "" {
+			source = legacy
+			result.Source = legacy
+		}
+
+		data, err := os.ReadFile(source)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+
+		migrated, from, changes, err := file.migrate(data)
+		if err != nil {
+			return results, fmt.Errorf("failed to migrate %s: %w", source, err)
+		}
+		result.FromVersion = from
+		result.Changes = changes
+		results = append(results, result)
+
+		if dryRun || !result.Changed() {
+			continue
+		}
+		if result.Source == "" {
+			result.Backup = fmt.Sprintf("%s.v%d.bak", file.path, from)
+			if err := os.WriteFile(result.Backup, data, file.perm); err != nil {
+				return results, fmt.Errorf("failed to back up %s: %w", file.path, err)
+			}
+		}
+		if err := os.MkdirAll(configDir, 0700); err != nil {
+			return results, fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(file.path, migrated, file.perm); err != nil {
+			return results, fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+	}
+
+	if !dryRun && len(results) > 0 {
+		if err := WriteSchemaFiles(configDir); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// setMappingValue 设置映射中的标量值，返回是否写入
+// first 为 true 时覆盖已有值、新键插入到最前面（用于 config_version）；为 false 时保留已有值、新键追加到末尾
+func setMappingValue(mapping *yaml.Node, key, value, tag string, first bool) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			if !first {
+				return false
+			}
+			mapping.Content[i+1].Value = value
+			mapping.Content[i+1].Tag = tag
+			mapping.Content[i+1].Style = 0
+			return true
+		}
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	if first {
+		mapping.Content = append([]*yaml.Node{keyNode, valueNode}, mapping.Content...)
+	} else {
+		mapping.Content = append(mapping.Content, keyNode, valueNode)
+	}
+	return true
+}
+
+// removeMappingKey 删除映射中的键，返回原值节点，不存在时返回 nil
+func removeMappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// ensureMapping 返回映射中 key 对应的子映射，不存在或为空值时创建
+func ensureMapping(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			if value.Kind != yaml.MappingNode {
+				*value = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: value.LineComment}
+			}
+			return value
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// missingKeys 返回 fields 中存在而 existing 中没有的键（按 encoding/json 规则不区分大小写），嵌套键以点连接
+func missingKeys(existing, fields map[string]interface{}, prefix string) []string {
+	var missing []string
+	for key, value := range fields {
+		var found interface{}
+		ok := false
+		for existingKey, existingValue := range existing {
+			if strings.EqualFold(existingKey, key) {
+				found, ok = existingValue, true
+				break
+			}
+		}
+		switch {
+		case !ok:
+			missing = append(missing, prefix+key)
+		default:
+			nested, isMap := value.(map[string]interface{})
+			existingNested, existingIsMap := found.(map[string]interface{})
+			if isMap && existingIsMap {
+				missing = append(missing, missingKeys(existingNested, nested, prefix+key+".")...)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/allanpk716/to_icalendar/pkg/image"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const legacyServerConfig = `# Microsoft Todo 配置
+microsoft_todo:
+  tenant_id: "tenant"
+  client_id: "client"
+  client_secret: "secret" # 客户端密钥
+
+# 缓存配置
+cache:
+  auto_cleanup_days: 30
+  image_cache_max_size: 200
+  image_cache_max_files: 0
+`
+
+func TestMigrateServerConfigData(t *testing.T) {
+	migrated, from, changes, err := MigrateServerConfigData([]byte(legacyServerConfig))
+	require.NoError(t, err)
+	assert.Equal(t, 0, from)
+	assert.NotEmpty(t, changes)
+
+	text := string(migrated)
+	assert.Contains(t, text, schemaModeline)
+	assert.Contains(t, text, "# Microsoft Todo 配置")
+	assert.Contains(t, text, "# 客户端密钥")
+	assert.NotContains(t, text, "image_cache_max")
+
+	var config models.ServerConfig
+	require.NoError(t, yaml.Unmarshal(migrated, &config))
+	assert.Equal(t, models.CurrentConfigVersion, config.ConfigVersion)
+	assert.Equal(t, int64(200), config.Cache.Quotas["images"].MaxSizeMB)
+	assert.Equal(t, 30, config.Cache.AutoCleanupDays)
+	assert.Equal(t, "secret", config.MicrosoftTodo.ClientSecret)
+
+	// 迁移后的文件没有警告以外的问题，再次迁移不做修改
+	assert.False(t, HasErrors(ValidateServerConfigData(migrated)))
+	again, _, changes, err := MigrateServerConfigData(migrated)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, migrated, again)
+}
+
+func TestMigrateServerConfigData_KeepsExistingQuota(t *testing.T) {
+	data := []byte("cache:\n  image_cache_max_files: 50\n  quotas:\n    images:\n      max_items: 100\n")
+
+	migrated, _, _, err := MigrateServerConfigData(data)
+	require.NoError(t, err)
+
+	var config models.ServerConfig
+	require.NoError(t, yaml.Unmarshal(migrated, &config))
+	assert.Equal(t, 100, config.Cache.Quotas["images"].MaxItems)
+	assert.Zero(t, config.Cache.ImageCacheMaxFiles)
+}
+
+func TestMigrateServerConfigData_TemplateIsCurrent(t *testing.T) {
+	template := ServerConfigTemplate()
+	migrated, from, changes, err := MigrateServerConfigData(template)
+	require.NoError(t, err)
+	assert.Equal(t, models.CurrentConfigVersion, from)
+	assert.Empty(t, changes)
+	assert.Equal(t, template, migrated)
+}
+
+func TestMigrateImageProcessingData(t *testing.T) {
+	data := []byte(`{
+  "normalization": {
+    "MaxWidth": 1280,
+    "MaxHeight": 720,
+    "JPEGQuality": 80,
+    "OutputFormat": "jpg",
+    "MaxFileSize": 1048576,
+    "KeepAspectRatio": true
+  },
+  "enable_normalization": true,
+  "debug_mode": false,
+  "debug_output_dir": "debug/images"
+}`)
+
+	migrated, from, changes, err := MigrateImageProcessingData(data)
+	require.NoError(t, err)
+	assert.Equal(t, 0, from)
+	assert.Contains(t, changes, "added enable_cache with the default value")
+	assert.Contains(t, changes, "added max_cache_files with the default value")
+
+	config := &image.ImageProcessingConfig{}
+	require.NoError(t, json.Unmarshal(migrated, config))
+	assert.Equal(t, image.CurrentConfigVersion, config.ConfigVersion)
+	assert.Equal(t, 1280, config.Normalization.MaxWidth)
+	assert.Equal(t, "jpg", config.Normalization.OutputFormat)
+	assert.True(t, config.EnableCache)
+	assert.Empty(t, ValidateImageProcessingData(migrated))
+}
+
+func TestMigrateConfigDir(t *testing.T) {
+	configDir := t.TempDir()
+	serverPath, imagePath := ConfigFilePaths(configDir)
+	require.NoError(t, os.WriteFile(serverPath, []byte(legacyServerConfig), 0600))
+
+	// dry run 不写入任何文件
+	results, err := MigrateConfigDir(configDir, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, serverPath, results[0].Path)
+	data, err := os.ReadFile(serverPath)
+	require.NoError(t, err)
+	assert.Equal(t, legacyServerConfig, string(data))
+
+	results, err = MigrateConfigDir(configDir, false)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, serverPath+".v0.bak", results[0].Backup)
+
+	backup, err := os.ReadFile(results[0].Backup)
+	require.NoError(t, err)
+	assert.Equal(t, legacyServerConfig, string(backup))
+	assert.FileExists(t, filepath.Join(configDir, ServerSchemaFile))
+	assert.FileExists(t, filepath.Join(configDir, ImageProcessingSchemaFile))
+
+	// image_processing.json 不存在时从旧位置（工作目录）迁移
+	if FindLegacyConfigFile(configDir, filepath.Base(imagePath)) != "" {
+		assert.FileExists(t, imagePath)
+	}
+}
+
+func TestFindLegacyConfigFile(t *testing.T) {
+	workDir := t.TempDir()
+	configDir := filepath.Join(workDir, "home")
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "config", "server.yaml"), []byte(legacyServerConfig), 0600))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	legacy := FindLegacyConfigFile(configDir, "server.yaml")
+	expected, err := filepath.EvalSymlinks(filepath.Join(workDir, "config", "server.yaml"))
+	require.NoError(t, err)
+	actual, err := filepath.EvalSymlinks(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	results, err := MigrateConfigDir(configDir, false)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, legacy, results[0].Source)
+	assert.Empty(t, results[0].Backup)
+
+	migrated, err := os.ReadFile(filepath.Join(configDir, "server.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(migrated), "config_version: 1")
+	assert.Empty(t, FindLegacyConfigFile(configDir, "server.yaml"))
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// ChangedSections 比较新旧服务器配置，按 server.yaml 中的顺序返回发生变化的顶层配置段（如 dify、logging）
+// config_version 不算配置变化；旧配置为 nil 时返回全部配置段
+func ChangedSections(oldConfig, newConfig *models.ServerConfig) []string {
+	if newConfig == nil {
+		return nil
+	}
+
+	newValue := reflect.ValueOf(newConfig).Elem()
+	var oldValue reflect.Value
+	if oldConfig != nil {
+		oldValue = reflect.ValueOf(oldConfig).Elem()
+	}
+
+	var changed []string
+	t := newValue.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := schemaFieldName(t.Field(i), "yaml")
+		if name == "" || name == "config_version" {
+			continue
+		}
+		if oldConfig != nil && reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		changed = append(changed, name)
+	}
+	return changed
+}
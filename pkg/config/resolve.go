@@ -0,0 +1,441 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvOverridePrefix 环境变量覆盖前缀，如 TO_ICALENDAR_MICROSOFT_TODO_CLIENT_SECRET
+	EnvOverridePrefix = "TO_ICALENDAR"
+
+	// SecretRefFile 从文件读取密钥，如 file:~/.secrets/todo
+	SecretRefFile = "file:"
+	// SecretRefCmd 执行命令读取密钥，如 cmd:pass show azure/todo
+	SecretRefCmd = "cmd:"
+
+	// RedactedValue 脱敏后显示的占位符
+	RedactedValue = "[REDACTED]"
+
+	// secretCmdTimeout 密钥命令的执行超时
+	secretCmdTimeout = 10 * time.Second
+)
+
+// envVarPattern 匹配 ${VAR} 和 ${VAR:-默认值}
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ReadServerConfig 读取服务器配置文件，依次展开 ${ENV_VAR}、应用 TO_ICALENDAR_* 环境变量覆盖、
+// 解析 file:/cmd: 密钥引用，不做校验和默认值填充
+func (cm *ConfigManager) ReadServerConfig(configPath string) (*models.ServerConfig, error) {
+	// 检查文件是否存在
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("server config file not found: %s", configPath)
+	}
+
+	// 读取文件
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server config file: %w", err)
+	}
+
+	// 先解析为节点树，只在值中展开环境变量
+	var config models.ServerConfig
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse server config file: %w", err)
+	}
+	var expandErr error
+	expandEnvNode(&root, func(node *yaml.Node, err error) {
+		if expandErr == nil {
+			expandErr = fmt.Errorf("line %d: %w", node.Line, err)
+		}
+	})
+	if expandErr != nil {
+		return nil, fmt.Errorf("failed to expand environment variables: %w", expandErr)
+	}
+	if len(root.Content) > 0 {
+		if err := root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("failed to parse server config file: %w", err)
+		}
+	}
+
+	if err := ApplyEnvOverrides(&config); err != nil {
+		return nil, err
+	}
+	if err := ResolveSecretRefs(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// ExpandEnv 展开字符串中的 ${VAR} 和 ${VAR:-默认值}，未设置且没有默认值的变量返回错误
+func ExpandEnv(value string) (string, error) {
+	var missing []string
+	expanded := envVarPattern.ReplaceAllStringFunc(value, func(match string) string {
+		groups := envVarPattern.FindStringSubmatch(match)
+		if v, ok := os.LookupEnv(groups[1]); ok && (v != "" || groups[2] == "") {
+			return v
+		}
+		if groups[2] != "" {
+			return groups[3]
+		}
+		missing = append(missing, groups[1])
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// expandEnvNode 只在标量值中展开环境变量，键名保持不变；无法展开的值交给 onError 处理并保持原样
+func expandEnvNode(node *yaml.Node, onError func(node *yaml.Node, err error)) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "${") {
+			return
+		}
+		expanded, err := ExpandEnv(node.Value)
+		if err != nil {
+			onError(node, err)
+			return
+		}
+		node.Value = expanded
+		// 非引号值重新推断类型，使 enabled: ${FLAG} 能解析为布尔值
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			expandEnvNode(node.Content[i], onError)
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			expandEnvNode(child, onError)
+		}
+	}
+}
+
+// EnvVarName 返回配置路径对应的覆盖环境变量名，如 [microsoft_todo client_secret] -> TO_ICALENDAR_MICROSOFT_TODO_CLIENT_SECRET
+func EnvVarName(path []string) string {
+	parts := make([]string, 0, len(path)+1)
+	parts = append(parts, EnvOverridePrefix)
+	for _, p := range path {
+		parts = append(parts, strings.ToUpper(strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, p)))
+	}
+	return strings.Join(parts, "_")
+}
+
+// ApplyEnvOverrides 用 TO_ICALENDAR_* 环境变量覆盖配置字段
+// 变量名由 YAML 路径转为大写并以下划线连接；列表字段使用逗号分隔；
+// map 类型字段（如 dify_backends、cache.quotas）只能覆盖配置文件中已存在的键
+func ApplyEnvOverrides(config *models.ServerConfig) error {
+	_, err := walkConfig(reflect.ValueOf(config).Elem(), nil, false, func(v reflect.Value, path []string, _ bool) (bool, error) {
+		name := EnvVarName(path)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			return false, nil
+		}
+		if err := setFromString(v, raw); err != nil {
+			return false, fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+		return true, nil
+	})
+	return err
+}
+
+// ResolveSecretRefs 解析密钥字段（secret:"true"）中的 file: 和 cmd: 引用
+func ResolveSecretRefs(config *models.ServerConfig) error {
+	_, err := walkConfig(reflect.ValueOf(config).Elem(), nil, false, func(v reflect.Value, path []string, secret bool) (bool, error) {
+		if !secret || v.Kind() != reflect.String || !IsSecretRef(v.String()) {
+			return false, nil
+		}
+		value, err := resolveSecretRef(v.String())
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve %s: %w", strings.Join(path, "."), err)
+		}
+		v.SetString(value)
+		return true, nil
+	})
+	return err
+}
+
+// RedactServerConfig 返回脱敏后的配置副本，密钥字段替换为 [REDACTED]
+// 尚未解析的引用（file:、cmd:、${VAR}）本身不含密钥，原样保留以便排查
+func RedactServerConfig(config *models.ServerConfig) (*models.ServerConfig, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy server config: %w", err)
+	}
+	var redacted models.ServerConfig
+	if err := yaml.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to copy server config: %w", err)
+	}
+
+	_, err = walkConfig(reflect.ValueOf(&redacted).Elem(), nil, false, func(v reflect.Value, _ []string, secret bool) (bool, error) {
+		if !secret || v.Kind() != reflect.String || v.String() == "" ||
+			IsSecretRef(v.String()) || strings.Contains(v.String(), "${") {
+			return false, nil
+		}
+		v.SetString(RedactedValue)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &redacted, nil
+}
+
+// RedactYAML 在保留注释和格式的前提下隐藏 YAML 文本中的密钥字段
+// 密钥字段按 ServerConfig 中带 secret:"true" 标签的键名匹配，引用和占位符原样保留
+func RedactYAML(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse server config file: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return data, nil
+	}
+
+	keys := secretKeys()
+	var redact func(node *yaml.Node)
+	redact = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if keys[key.Value] && value.Kind == yaml.ScalarNode && value.Value != "" &&
+					!IsSecretRef(value.Value) && !strings.Contains(value.Value, "${") {
+					value.Value = RedactedValue
+					value.Tag = "!!str"
+					value.Style = yaml.DoubleQuotedStyle
+				}
+			}
+		}
+		for _, child := range node.Content {
+			redact(child)
+		}
+	}
+	redact(&root)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to encode server config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode server config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// secretKeys 返回带 secret:"true" 标签字段的 YAML 键名
+func secretKeys() map[string]bool {
+	keys := make(map[string]bool)
+	var config models.ServerConfig
+	walkConfig(reflect.ValueOf(&config).Elem(), nil, false, func(_ reflect.Value, path []string, secret bool) (bool, error) {
+		if secret {
+			keys[path[len(path)-1]] = true
+		}
+		return false, nil
+	})
+	return keys
+}
+
+// IsSecretRef 判断值是否为 file: 或 cmd: 密钥引用
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefFile) || strings.HasPrefix(value, SecretRefCmd)
+}
+
+// resolveSecretRef 读取文件或执行命令获取密钥，去掉末尾换行
+func resolveSecretRef(ref string) (string, error) {
+	if strings.HasPrefix(ref, SecretRefFile) {
+		path := strings.TrimSpace(strings.TrimPrefix(ref, SecretRefFile))
+		if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("failed to get home directory: %w", err)
+			}
+			path = filepath.Join(homeDir, path[1:])
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	command := strings.TrimSpace(strings.TrimPrefix(ref, SecretRefCmd))
+	if command == "" {
+		return "", fmt.Errorf("empty secret command")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretCmdTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("secret command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("secret command failed: %w", err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// leafFunc 处理配置中的一个叶子字段，返回是否修改了该字段
+type leafFunc func(v reflect.Value, path []string, secret bool) (bool, error)
+
+// walkConfig 按 YAML 路径遍历配置的叶子字段
+// 指针结构体为 nil 时先在临时值上遍历，只有被修改时才赋值，避免凭空生成 token_manager 等可选段
+func walkConfig(v reflect.Value, path []string, secret bool, fn leafFunc) (bool, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		changed := false
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			fieldChanged, err := walkConfig(v.Field(i), appendPath(path, name), field.Tag.Get("secret") == "true", fn)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || fieldChanged
+		}
+		return changed, nil
+
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return fn(v, path, secret)
+		}
+		if !v.IsNil() {
+			return walkConfig(v.Elem(), path, secret, fn)
+		}
+		tmp := reflect.New(v.Type().Elem())
+		changed, err := walkConfig(tmp.Elem(), path, secret, fn)
+		if err != nil || !changed {
+			return false, err
+		}
+		v.Set(tmp)
+		return true, nil
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return false, nil
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+
+		changed := false
+		for _, key := range keys {
+			mapKey := reflect.ValueOf(key).Convert(v.Type().Key())
+			// map 元素不可寻址，复制后修改再写回
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(mapKey))
+			elemChanged, err := walkConfig(elem, appendPath(path, key), secret, fn)
+			if err != nil {
+				return false, err
+			}
+			if elemChanged {
+				v.SetMapIndex(mapKey, elem)
+				changed = true
+			}
+		}
+		return changed, nil
+
+	default:
+		return fn(v, path, secret)
+	}
+}
+
+// appendPath 复制路径后追加，避免共享底层数组
+func appendPath(path []string, name string) []string {
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, name)
+}
+
+// setFromString 按字段类型解析字符串并赋值
+func setFromString(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", v.Type())
+		}
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = reflect.Append(items, reflect.ValueOf(item).Convert(v.Type().Elem()))
+			}
+		}
+		v.Set(items)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const resolveTestConfig = `microsoft_todo:
+  tenant_id: "${TEST_TENANT}"
+  client_id: ${TEST_CLIENT:-default-client}
+  client_secret: "file:%s"
+  timezone: "Asia/Shanghai"
+dify:
+  api_endpoint: "https://dify.example.com/v1"
+  api_key: "app-raw"
+  timeout: 60
+dify_backends:
+  staging:
+    api_endpoint: "https://staging.example.com/v1"
+    api_key: "app-staging"
+metrics:
+  enabled: ${TEST_METRICS_ENABLED}
+  listen: "127.0.0.1:9464"
+`
+
+func writeResolveTestConfig(t *testing.T) string {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "secret.txt")
+	require.NoError(t, os.WriteFile(secretPath, []byte("s3cret-from-file\n"), 0600))
+
+	configPath := filepath.Join(dir, "server.yaml")
+	content := []byte(fmt.Sprintf(resolveTestConfig, filepath.ToSlash(secretPath)))
+	require.NoError(t, os.WriteFile(configPath, content, 0600))
+	return configPath
+}
+
+func TestReadServerConfig_Resolve(t *testing.T) {
+	configPath := writeResolveTestConfig(t)
+	t.Setenv("TEST_TENANT", "tenant-from-env")
+	t.Setenv("TEST_METRICS_ENABLED", "true")
+	t.Setenv("TO_ICALENDAR_DIFY_TIMEOUT", "120")
+	t.Setenv("TO_ICALENDAR_DIFY_BACKENDS_STAGING_API_KEY", "app-staging-env")
+	t.Setenv("TO_ICALENDAR_REMINDER_SCHEDULE_WORKING_DAYS", "mon, tue,wed")
+	t.Setenv("TO_ICALENDAR_TOKEN_MANAGER_CHECK_INTERVAL", "3")
+
+	cfg, err := NewConfigManager().ReadServerConfig(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tenant-from-env", cfg.MicrosoftTodo.TenantID)
+	assert.Equal(t, "default-client", cfg.MicrosoftTodo.ClientID)
+	assert.Equal(t, "s3cret-from-file", cfg.MicrosoftTodo.ClientSecret)
+	assert.True(t, cfg.Metrics.Enabled)
+	assert.Equal(t, 120, cfg.Dify.Timeout)
+	assert.Equal(t, "app-staging-env", cfg.DifyBackends["staging"].APIKey)
+	assert.Equal(t, "https://staging.example.com/v1", cfg.DifyBackends["staging"].APIEndpoint)
+	assert.Equal(t, []string{"mon", "tue", "wed"}, cfg.Reminder.Schedule.WorkingDays)
+	require.NotNil(t, cfg.TokenManager)
+	assert.Equal(t, 3, cfg.TokenManager.CheckInterval)
+}
+
+func TestReadServerConfig_MissingEnv(t *testing.T) {
+	configPath := writeResolveTestConfig(t)
+	t.Setenv("TEST_METRICS_ENABLED", "false")
+
+	_, err := NewConfigManager().ReadServerConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TEST_TENANT")
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestApplyEnvOverrides_InvalidValue(t *testing.T) {
+	configPath := writeResolveTestConfig(t)
+	t.Setenv("TEST_TENANT", "tenant")
+	t.Setenv("TEST_METRICS_ENABLED", "false")
+	t.Setenv("TO_ICALENDAR_METRICS_ENABLED", "maybe")
+
+	_, err := NewConfigManager().ReadServerConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TO_ICALENDAR_METRICS_ENABLED")
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("TEST_SET", "value")
+	t.Setenv("TEST_EMPTY", "")
+
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"plain", "plain", false},
+		{"${TEST_SET}", "value", false},
+		{"prefix-${TEST_SET}-suffix", "prefix-value-suffix", false},
+		{"${TEST_EMPTY}", "", false},
+		{"${TEST_EMPTY:-fallback}", "fallback", false},
+		{"${TEST_UNSET_VAR:-fallback}", "fallback", false},
+		{"${TEST_UNSET_VAR}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ExpandEnv(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestResolveSecretRef_Cmd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	value, err := resolveSecretRef("cmd:printf 'from-cmd\\n'")
+	require.NoError(t, err)
+	assert.Equal(t, "from-cmd", value)
+
+	_, err = resolveSecretRef("cmd:echo boom >&2; exit 3")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestRedactServerConfig(t *testing.T) {
+	configPath := writeResolveTestConfig(t)
+	t.Setenv("TEST_TENANT", "tenant")
+	t.Setenv("TEST_METRICS_ENABLED", "false")
+
+	resolved, err := NewConfigManager().ReadServerConfig(configPath)
+	require.NoError(t, err)
+	redacted, err := RedactServerConfig(resolved)
+	require.NoError(t, err)
+	assert.Equal(t, RedactedValue, redacted.MicrosoftTodo.ClientSecret)
+	assert.Equal(t, RedactedValue, redacted.Dify.APIKey)
+	assert.Equal(t, RedactedValue, redacted.DifyBackends["staging"].APIKey)
+	assert.Equal(t, "tenant", redacted.MicrosoftTodo.TenantID)
+
+	// 原配置不受影响
+	assert.Equal(t, "s3cret-from-file", resolved.MicrosoftTodo.ClientSecret)
+	assert.Equal(t, "app-staging", resolved.DifyBackends["staging"].APIKey)
+}
+
+func TestRedactYAML(t *testing.T) {
+	data := []byte(`# Microsoft Todo 配置
+microsoft_todo:
+  client_id: "client"
+  client_secret: "cmd:pass show azure/todo" # 从密码管理器读取
+dify:
+  api_key: "app-plain"
+dify_backends:
+  staging:
+    api_key: ${STAGING_KEY}
+`)
+
+	redacted, err := RedactYAML(data)
+	require.NoError(t, err)
+
+	text := string(redacted)
+	assert.Contains(t, text, "# Microsoft Todo 配置")
+	assert.Contains(t, text, `client_id: "client"`)
+	assert.Contains(t, text, `client_secret: "cmd:pass show azure/todo" # 从密码管理器读取`)
+	assert.Contains(t, text, `api_key: "[REDACTED]"`)
+	assert.Contains(t, text, "api_key: ${STAGING_KEY}")
+	assert.NotContains(t, text, "app-plain")
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/image"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+const (
+	// jsonSchemaDraft 生成的 JSON Schema 版本
+	jsonSchemaDraft = "https://json-schema.org/draft-07/schema#"
+
+	// ServerSchemaFile 放在配置目录中、供编辑器补全 server.yaml 使用的 schema 文件名
+	ServerSchemaFile = "server.schema.json"
+	// ImageProcessingSchemaFile 供编辑器补全 image_processing.json 使用的 schema 文件名
+	ImageProcessingSchemaFile = "image_processing.schema.json"
+)
+
+// Schema JSON Schema 的子集，足以描述配置结构并校验 YAML/JSON 节点
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false 或 *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+
+	// caseInsensitive 属性名不区分大小写（encoding/json 的匹配规则）
+	caseInsensitive bool
+}
+
+// ServerConfigSchema 根据 models.ServerConfig 生成 server.yaml 的 JSON Schema
+func ServerConfigSchema() *Schema {
+	schema := GenerateSchema(reflect.TypeOf(models.ServerConfig{}), "yaml")
+	schema.Schema = jsonSchemaDraft
+	schema.Title = "to_icalendar server.yaml"
+	return schema
+}
+
+// ImageProcessingSchema 根据 image.ImageProcessingConfig 生成 image_processing.json 的 JSON Schema
+func ImageProcessingSchema() *Schema {
+	schema := GenerateSchema(reflect.TypeOf(image.ImageProcessingConfig{}), "json")
+	schema.Schema = jsonSchemaDraft
+	schema.Title = "to_icalendar image_processing.json"
+	return schema
+}
+
+// MarshalSchema 将 schema 序列化为缩进的 JSON，末尾带换行
+func MarshalSchema(schema *Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// GenerateSchema 按字段类型和 tagName 指定的结构体标签（yaml 或 json）生成 schema
+// 未写标签的字段按 tagName 对应库的规则命名：yaml 使用小写字段名，json 使用字段名且匹配时不区分大小写
+func GenerateSchema(t reflect.Type, tagName string) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &Schema{
+			Type:                 "object",
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: false,
+			caseInsensitive:      tagName == "json",
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := schemaFieldName(field, tagName)
+			if name == "" {
+				continue
+			}
+			property := GenerateSchema(field.Type, tagName)
+			property.Deprecated = field.Tag.Get("deprecated") == "true"
+			schema.Properties[name] = property
+		}
+		return schema
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: GenerateSchema(t.Elem(), tagName)}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: GenerateSchema(t.Elem(), tagName)}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	default:
+		return &Schema{}
+	}
+}
+
+// schemaFieldName 返回字段在配置文件中的键名，忽略的字段返回空字符串
+func schemaFieldName(field reflect.StructField, tagName string) string {
+	name := strings.Split(field.Tag.Get(tagName), ",")[0]
+	switch {
+	case name == "-":
+		return ""
+	case name != "":
+		return name
+	case tagName == "yaml":
+		return strings.ToLower(field.Name)
+	default:
+		return field.Name
+	}
+}
+
+// property 按键名查找属性 schema
+func (s *Schema) property(key string) (*Schema, bool) {
+	if property, ok := s.Properties[key]; ok {
+		return property, true
+	}
+	if s.caseInsensitive {
+		for name, property := range s.Properties {
+			if strings.EqualFold(name, key) {
+				return property, true
+			}
+		}
+	}
+	return nil, false
+}
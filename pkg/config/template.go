@@ -0,0 +1,37 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// serverConfigTemplate init 命令生成的 server.yaml 模板，带注释说明
+//
+//go:embed templates/server.yaml
+var serverConfigTemplate []byte
+
+// ServerConfigTemplate 返回 server.yaml 模板内容的副本
+func ServerConfigTemplate() []byte {
+	return append([]byte(nil), serverConfigTemplate...)
+}
+
+// WriteSchemaFiles 在配置目录中写入 server.yaml 和 image_processing.json 的 JSON Schema
+// server.yaml 模板通过 yaml-language-server 注释引用 server.schema.json，编辑器据此提供补全和校验
+func WriteSchemaFiles(configDir string) error {
+	schemas := map[string]*Schema{
+		ServerSchemaFile:          ServerConfigSchema(),
+		ImageProcessingSchemaFile: ImageProcessingSchema(),
+	}
+	for name, schema := range schemas {
+		data, err := MarshalSchema(schema)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(configDir, name), data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
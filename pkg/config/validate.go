@@ -0,0 +1,437 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/image"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// 校验问题的级别
+const (
+	SeverityError   = "error"   // 配置无法加载或不会按预期生效
+	SeverityWarning = "warning" // 配置可以加载，但建议修改（未知键、已弃用字段、旧版本）
+)
+
+// templatePlaceholders init 模板中需要替换的占位符
+var templatePlaceholders = map[string]bool{
+	"YOUR_TENANT_ID":     true,
+	"YOUR_CLIENT_ID":     true,
+	"YOUR_CLIENT_SECRET": true,
+	"YOUR_DIFY_API_KEY":  true,
+}
+
+// yamlErrorLinePattern 从 yaml 语法错误中提取行号
+var yamlErrorLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// Issue 配置文件中的一个问题
+type Issue struct {
+	Line     int    `json:"line"`     // 行号，0 表示无法定位到具体行
+	Path     string `json:"path"`     // 配置路径，如 cache.eviction_policy
+	Severity string `json:"severity"` // error 或 warning
+	Message  string `json:"message"`
+}
+
+// String 格式化为 "line 12: cache.eviction_policy: 信息"
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// HasErrors 是否包含 error 级别的问题
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateServerConfigFile 校验 server.yaml，返回全部问题
+func ValidateServerConfigFile(configPath string) ([]Issue, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server config file: %w", err)
+	}
+	return ValidateServerConfigData(data), nil
+}
+
+// ValidateServerConfigData 校验 server.yaml 内容：语法、环境变量、结构与类型（schema）、
+// TO_ICALENDAR_* 覆盖、密钥引用、占位符、版本以及各配置段的取值，按行号排序返回全部问题
+func ValidateServerConfigData(data []byte) []Issue {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []Issue{syntaxIssue(err)}
+	}
+	if len(root.Content) == 0 {
+		return []Issue{{Line: 1, Severity: SeverityError, Message: "config file is empty"}}
+	}
+	doc := root.Content[0]
+
+	var issues []Issue
+	expandEnvNode(doc, func(node *yaml.Node, err error) {
+		issues = append(issues, Issue{Line: node.Line, Severity: SeverityError, Message: err.Error()})
+	})
+	issues = append(issues, checkNode(doc, ServerConfigSchema(), nil)...)
+
+	// 类型错误已由 schema 检查报告，这里使用部分解析结果继续校验取值
+	var config models.ServerConfig
+	_ = doc.Decode(&config)
+	if err := ApplyEnvOverrides(&config); err != nil {
+		issues = append(issues, Issue{Severity: SeverityError, Message: err.Error()})
+	}
+
+	issues = append(issues, checkConfigVersion(doc, config.ConfigVersion, models.CurrentConfigVersion)...)
+	issues = append(issues, checkServerValues(doc, &config)...)
+
+	sortIssues(issues)
+	return issues
+}
+
+// checkServerValues 校验必填项、占位符、密钥引用以及各配置段的取值
+func checkServerValues(doc *yaml.Node, config *models.ServerConfig) []Issue {
+	var issues []Issue
+
+	required := []struct {
+		key   string
+		value string
+	}{
+		{"tenant_id", config.MicrosoftTodo.TenantID},
+		{"client_id", config.MicrosoftTodo.ClientID},
+		{"client_secret", config.MicrosoftTodo.ClientSecret},
+	}
+	for _, field := range required {
+		path := []string{"microsoft_todo", field.key}
+		switch {
+		case field.value == "":
+			issues = append(issues, issueAt(doc, path, SeverityError, "is required"))
+		case templatePlaceholders[field.value]:
+			issues = append(issues, issueAt(doc, path, SeverityError, fmt.Sprintf("still uses the template placeholder %s", field.value)))
+		}
+	}
+
+	// 只检查 file: 引用的文件是否存在，cmd: 引用不在校验时执行
+	walkConfig(reflect.ValueOf(config).Elem(), nil, false, func(v reflect.Value, path []string, secret bool) (bool, error) {
+		if !secret || v.Kind() != reflect.String || !strings.HasPrefix(v.String(), SecretRefFile) {
+			return false, nil
+		}
+		if _, err := resolveSecretRef(v.String()); err != nil {
+			issues = append(issues, issueAt(doc, path, SeverityError, err.Error()))
+		}
+		return false, nil
+	})
+
+	for _, check := range serverConfigChecks {
+		if err := check.validate(config); err != nil {
+			issues = append(issues, locateIssue(doc, check.path, err))
+		}
+	}
+
+	// Dify 在使用时才校验，这里只检查已配置的后端
+	if config.Dify.APIEndpoint != "" || config.Dify.APIKey != "" {
+		if err := config.Dify.Validate(); err != nil {
+			issues = append(issues, locateIssue(doc, []string{"dify"}, err))
+		}
+	}
+	names := make([]string, 0, len(config.DifyBackends))
+	for name := range config.DifyBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		backend := config.DifyBackends[name]
+		if err := backend.Validate(); err != nil {
+			issues = append(issues, locateIssue(doc, []string{"dify_backends", name}, err))
+		}
+	}
+
+	return issues
+}
+
+// ValidateImageProcessingFile 校验 image_processing.json，返回全部问题
+func ValidateImageProcessingFile(configPath string) ([]Issue, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image processing config file: %w", err)
+	}
+	return ValidateImageProcessingData(data), nil
+}
+
+// ValidateImageProcessingData 校验 image_processing.json 内容
+// JSON 是 YAML 的子集，解析为 YAML 节点以获得行号
+func ValidateImageProcessingData(data []byte) []Issue {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return []Issue{{Line: offsetLine(data, syntaxErr.Offset), Severity: SeverityError, Message: err.Error()}}
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []Issue{syntaxIssue(err)}
+	}
+	if len(root.Content) == 0 {
+		return []Issue{{Line: 1, Severity: SeverityError, Message: "config file is empty"}}
+	}
+	doc := root.Content[0]
+
+	issues := checkNode(doc, ImageProcessingSchema(), nil)
+
+	config := image.DefaultImageProcessingConfig()
+	config.ConfigVersion = 0
+	// 类型错误已由 schema 检查报告，encoding/json 遇到类型错误时仍会解析其余字段
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(data, config); err == nil || errors.As(err, &typeErr) {
+		issues = append(issues, checkConfigVersion(doc, config.ConfigVersion, image.CurrentConfigVersion)...)
+		if err := config.Validate(); err != nil {
+			issues = append(issues, locateIssue(doc, []string{"normalization"}, err))
+		}
+	}
+
+	sortIssues(issues)
+	return issues
+}
+
+// checkNode 按 schema 检查节点的结构和类型，path 为节点在配置中的路径
+func checkNode(node *yaml.Node, schema *Schema, path []string) []Issue {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil
+	}
+
+	mismatch := func() []Issue {
+		return []Issue{{
+			Line:     node.Line,
+			Path:     strings.Join(path, "."),
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("expected %s, got %s", schema.Type, describeNode(node)),
+		}}
+	}
+
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			return mismatch()
+		}
+		var issues []Issue
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := appendPath(path, key.Value)
+			property, ok := schema.property(key.Value)
+			if !ok {
+				if additional, isSchema := schema.AdditionalProperties.(*Schema); isSchema {
+					property, ok = additional, true
+				}
+			}
+			if !ok {
+				issues = append(issues, Issue{
+					Line:     key.Line,
+					Path:     strings.Join(childPath, "."),
+					Severity: SeverityWarning,
+					Message:  "unknown key, it will be ignored",
+				})
+				continue
+			}
+			if property.Deprecated {
+				issues = append(issues, Issue{
+					Line:     key.Line,
+					Path:     strings.Join(childPath, "."),
+					Severity: SeverityWarning,
+					Message:  "deprecated, run 'to_icalendar config migrate' to upgrade",
+				})
+			}
+			issues = append(issues, checkNode(value, property, childPath)...)
+		}
+		return issues
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			return mismatch()
+		}
+		var issues []Issue
+		for i, item := range node.Content {
+			issues = append(issues, checkNode(item, schema.Items, appendPath(path, strconv.Itoa(i)))...)
+		}
+		return issues
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+			return mismatch()
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!int" {
+			return mismatch()
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.ShortTag() != "!!int" && node.ShortTag() != "!!float") {
+			return mismatch()
+		}
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			return mismatch()
+		}
+	}
+	return nil
+}
+
+// checkConfigVersion 检查 config_version 是否为当前版本
+func checkConfigVersion(doc *yaml.Node, version, current int) []Issue {
+	path := []string{"config_version"}
+	switch {
+	case version > current:
+		return []Issue{issueAt(doc, path, SeverityError,
+			fmt.Sprintf("version %d is newer than the supported version %d, please upgrade to_icalendar", version, current))}
+	case version < current:
+		return []Issue{issueAt(doc, path, SeverityWarning,
+			fmt.Sprintf("version %d is older than the current version %d, run 'to_icalendar config migrate'", version, current))}
+	}
+	return nil
+}
+
+// issueAt 创建定位到 path 的问题，路径不存在时定位到最近的已存在父级
+func issueAt(doc *yaml.Node, path []string, severity, message string) Issue {
+	line, _ := lookupLine(doc, path)
+	return Issue{Line: line, Path: strings.Join(path, "."), Severity: severity, Message: message}
+}
+
+// locateIssue 将配置段的校验错误定位到该段中被错误信息提及的最深层键
+func locateIssue(doc *yaml.Node, sectionPath []string, err error) Issue {
+	issue := issueAt(doc, sectionPath, SeverityError, err.Error())
+	_, section := lookupLine(doc, sectionPath)
+	if section == nil {
+		return issue
+	}
+
+	message := err.Error()
+	bestDepth := 0
+	var search func(node *yaml.Node, path []string)
+	search = func(node *yaml.Node, path []string) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := appendPath(path, key.Value)
+			if mentionsKey(message, key.Value) && len(childPath) > bestDepth {
+				bestDepth = len(childPath)
+				issue.Line = key.Line
+				issue.Path = strings.Join(childPath, ".")
+			}
+			search(value, childPath)
+		}
+	}
+	search(section, sectionPath)
+	return issue
+}
+
+// lookupLine 按路径查找键所在行，返回找到的最深一级的行号和对应的值节点（完整路径不存在时值节点为 nil）
+func lookupLine(doc *yaml.Node, path []string) (int, *yaml.Node) {
+	line := 0
+	node := doc
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return line, nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line = node.Content[i].Line
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return line, nil
+		}
+		node = next
+	}
+	return line, node
+}
+
+// mentionsKey 错误信息中是否以完整单词形式提到了键名
+func mentionsKey(message, key string) bool {
+	for start := 0; ; {
+		idx := strings.Index(message[start:], key)
+		if idx < 0 {
+			return false
+		}
+		idx += start
+		end := idx + len(key)
+		if (idx == 0 || !isKeyChar(message[idx-1])) && (end == len(message) || !isKeyChar(message[end])) {
+			return true
+		}
+		start = idx + 1
+	}
+}
+
+// isKeyChar 是否为键名中可出现的字符
+func isKeyChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// describeNode 描述节点的类型，用于类型不匹配的提示
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+// syntaxIssue 将 YAML 语法错误转换为问题
+func syntaxIssue(err error) Issue {
+	issue := Issue{Severity: SeverityError, Message: err.Error()}
+	if m := yamlErrorLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		issue.Line, _ = strconv.Atoi(m[1])
+	}
+	return issue
+}
+
+// offsetLine 将字节偏移转换为行号
+func offsetLine(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// sortIssues 按行号排序，无法定位的问题排在最后
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Line, issues[j].Line
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+}
+
+// ConfigFilePaths 返回配置目录中 server.yaml 和 image_processing.json 的路径
+func ConfigFilePaths(configDir string) (serverPath, imagePath string) {
+	return filepath.Join(configDir, "server.yaml"), image.GetConfigPath(configDir)
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 使用 go test ./pkg/config -run Schema -update 重新生成 docs/schema 下的文件
+var updateSchema = flag.Bool("update", false, "update JSON schema files in docs/schema")
+
+func TestValidateServerConfigData_ReportsEveryIssueWithLine(t *testing.T) {
+	data := []byte(`config_version: 1
+microsoft_todo:
+  tenant_id: "YOUR_TENANT_ID"
+  client_id: "client"
+  client_secret: "secret"
+  attachments:
+    max_size_mb: 30
+cache:
+  auto_cleanup_days: "thirty"
+  eviction_policy: "fifo"
+  image_cache_max_size: 10
+watch:
+  trigger: "always"
+metrics:
+  enabled: yes please
+logging:
+  formt: "json"
+`)
+
+	issues := ValidateServerConfigData(data)
+	require.True(t, HasErrors(issues))
+
+	byPath := make(map[string]Issue)
+	for _, issue := range issues {
+		byPath[issue.Path] = issue
+	}
+
+	expected := map[string]struct {
+		line     int
+		severity string
+	}{
+		"microsoft_todo.tenant_id":               {3, SeverityError},
+		"microsoft_todo.attachments.max_size_mb": {7, SeverityError},
+		"cache.auto_cleanup_days":                {9, SeverityError},
+		"cache.eviction_policy":                  {10, SeverityError},
+		"cache.image_cache_max_size":             {11, SeverityWarning},
+		"watch.trigger":                          {13, SeverityError},
+		"metrics.enabled":                        {15, SeverityError},
+		"logging.formt":                          {17, SeverityWarning},
+	}
+	for path, want := range expected {
+		issue, ok := byPath[path]
+		if assert.True(t, ok, "missing issue for %s in %v", path, issues) {
+			assert.Equal(t, want.line, issue.Line, path)
+			assert.Equal(t, want.severity, issue.Severity, path)
+		}
+	}
+
+	for i := 1; i < len(issues); i++ {
+		if issues[i].Line > 0 {
+			assert.LessOrEqual(t, issues[i-1].Line, issues[i].Line, "issues should be sorted by line")
+		}
+	}
+}
+
+func TestValidateServerConfigData_Template(t *testing.T) {
+	data := ServerConfigTemplate()
+	issues := ValidateServerConfigData(data)
+
+	// 模板只应提示占位符
+	for _, issue := range issues {
+		assert.Contains(t, issue.Message, "placeholder", issue.String())
+	}
+	assert.Len(t, issues, 3)
+}
+
+func TestValidateServerConfigData_SyntaxAndVersion(t *testing.T) {
+	issues := ValidateServerConfigData([]byte("microsoft_todo:\n  tenant_id: [\n"))
+	require.Len(t, issues, 1)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+	assert.Greater(t, issues[0].Line, 0)
+
+	issues = ValidateServerConfigData([]byte("config_version: 99\nmicrosoft_todo:\n  tenant_id: t\n  client_id: c\n  client_secret: s\n"))
+	require.Len(t, issues, 1)
+	assert.Equal(t, "config_version", issues[0].Path)
+	assert.Equal(t, 1, issues[0].Line)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+}
+
+func TestValidateImageProcessingData(t *testing.T) {
+	data := []byte(`{
+  "config_version": 1,
+  "normalization": {
+    "MaxWidth": 0,
+    "MaxHeight": 1080,
+    "JPEGQuality": 85,
+    "OutputFormat": "png",
+    "MaxFileSize": 5242880
+  },
+  "enable_normalization": "yes",
+  "unknown_field": 1
+}
+`)
+
+	issues := ValidateImageProcessingData(data)
+	byPath := make(map[string]Issue)
+	for _, issue := range issues {
+		byPath[issue.Path] = issue
+	}
+
+	assert.Equal(t, 10, byPath["enable_normalization"].Line)
+	assert.Equal(t, SeverityWarning, byPath["unknown_field"].Severity)
+	assert.Equal(t, 3, byPath["normalization"].Line)
+}
+
+func TestSchemaFilesUpToDate(t *testing.T) {
+	schemas := map[string]*Schema{
+		ServerSchemaFile:          ServerConfigSchema(),
+		ImageProcessingSchemaFile: ImageProcessingSchema(),
+	}
+
+	for name, schema := range schemas {
+		data, err := MarshalSchema(schema)
+		require.NoError(t, err)
+
+		path := filepath.Join("..", "..", "docs", "schema", name)
+		if *updateSchema {
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, data, 0644))
+			continue
+		}
+
+		expected, err := os.ReadFile(path)
+		require.NoError(t, err, "缺少 schema 文件，使用 -update 生成")
+		assert.Equal(t, string(expected), string(data), "%s 已过期，使用 -update 重新生成", name)
+	}
+}
+
+func TestServerConfigSchema(t *testing.T) {
+	data, err := MarshalSchema(ServerConfigSchema())
+	require.NoError(t, err)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]interface{})
+
+	assert.Contains(t, properties, "config_version")
+	assert.Contains(t, properties, "microsoft_todo")
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	backends := properties["dify_backends"].(map[string]interface{})
+	assert.Equal(t, "object", backends["type"])
+	assert.Contains(t, backends["additionalProperties"].(map[string]interface{})["properties"], "api_key")
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval 配置文件的默认轮询间隔
+const DefaultWatchInterval = 2 * time.Second
+
+// fileState 文件在一次轮询时的状态，内容摘要用于忽略只修改时间变化的保存
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	digest  []byte
+}
+
+func (s fileState) equal(other fileState) bool {
+	return s.exists == other.exists && s.modTime.Equal(other.modTime) && s.size == other.size &&
+		bytes.Equal(s.digest, other.digest)
+}
+
+// sameContent 内容是否相同（不比较修改时间）
+func (s fileState) sameContent(other fileState) bool {
+	return s.exists == other.exists && bytes.Equal(s.digest, other.digest)
+}
+
+// FileWatcher 轮询配置文件，内容变化后回调
+// 编辑器保存时可能分多次写入，文件在连续两次轮询中保持不变后才回调，避免读到写了一半的文件；
+// 文件被删除时不回调，继续使用上一次的配置
+type FileWatcher struct {
+	paths    []string
+	interval time.Duration
+	onChange func(path string)
+
+	applied map[string]fileState // 上一次回调（或启动）时的状态
+	seen    map[string]fileState // 上一次轮询的状态
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	done     chan struct{}
+}
+
+// NewFileWatcher 创建配置文件监听器，interval <= 0 时使用 DefaultWatchInterval
+func NewFileWatcher(paths []string, interval time.Duration, onChange func(path string)) *FileWatcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	w := &FileWatcher{
+		paths:    paths,
+		interval: interval,
+		onChange: onChange,
+		applied:  make(map[string]fileState),
+		seen:     make(map[string]fileState),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, path := range paths {
+		state := statFile(path)
+		w.applied[path] = state
+		w.seen[path] = state
+	}
+	return w
+}
+
+// Start 在后台开始轮询
+func (w *FileWatcher) Start() {
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stopCh:
+				return
+			case <-ticker.C:
+				w.poll()
+			}
+		}
+	}()
+}
+
+// Stop 停止轮询并等待正在执行的回调结束
+func (w *FileWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.done
+	})
+}
+
+// poll 检查一次所有文件
+func (w *FileWatcher) poll() {
+	for _, path := range w.paths {
+		state := statFile(path)
+		previous := w.seen[path]
+		w.seen[path] = state
+
+		// 仍在变化，等下一次轮询
+		if !state.equal(previous) {
+			continue
+		}
+		if !state.exists || state.sameContent(w.applied[path]) {
+			w.applied[path] = state
+			continue
+		}
+
+		w.applied[path] = state
+		w.onChange(path)
+	}
+}
+
+// statFile 读取文件状态，文件不存在或无法读取时 exists 为 false
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileState{}
+	}
+	digest := sha256.Sum256(data)
+	return fileState{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		digest:  digest[:],
+	}
+}
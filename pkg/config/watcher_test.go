@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("a: 1\n"), 0600))
+
+	var changed []string
+	watcher := NewFileWatcher([]string{path}, time.Second, func(p string) {
+		changed = append(changed, p)
+	})
+
+	// 没有变化时不回调
+	watcher.poll()
+	assert.Empty(t, changed)
+
+	// 文件刚写入时先等待一次轮询，内容稳定后回调一次
+	require.NoError(t, os.WriteFile(path, []byte("a: 2\n"), 0600))
+	watcher.poll()
+	assert.Empty(t, changed)
+	watcher.poll()
+	assert.Equal(t, []string{path}, changed)
+	watcher.poll()
+	assert.Len(t, changed, 1)
+
+	// 只修改时间变化不回调
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	watcher.poll()
+	watcher.poll()
+	assert.Len(t, changed, 1)
+
+	// 删除文件不回调，重新创建后回调
+	require.NoError(t, os.Remove(path))
+	watcher.poll()
+	watcher.poll()
+	assert.Len(t, changed, 1)
+	require.NoError(t, os.WriteFile(path, []byte("a: 2\n"), 0600))
+	watcher.poll()
+	watcher.poll()
+	assert.Len(t, changed, 2)
+}
+
+func TestFileWatcher_StartStop(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image_processing.json")
+
+	changed := make(chan string, 1)
+	watcher := NewFileWatcher([]string{path}, 10*time.Millisecond, func(p string) {
+		changed <- p
+	})
+	watcher.Start()
+	defer watcher.Stop()
+
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	select {
+	case p := <-changed:
+		assert.Equal(t, path, p)
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有检测到文件变化")
+	}
+
+	watcher.Stop()
+	watcher.Stop()
+}
+
+func TestChangedSections(t *testing.T) {
+	oldConfig := &models.ServerConfig{
+		ConfigVersion: 1,
+		MicrosoftTodo: models.MicrosoftTodoConfig{TenantID: "t", ClientID: "c", ClientSecret: "s"},
+		Logging:       models.LoggingConfig{Level: "info"},
+	}
+
+	newConfig := *oldConfig
+	assert.Empty(t, ChangedSections(oldConfig, &newConfig))
+
+	newConfig.ConfigVersion = 2
+	newConfig.Logging.Level = "debug"
+	newConfig.Dify.APIKey = "key"
+	newConfig.TokenManager = &models.TokenManagerConfig{Enabled: true}
+	assert.Equal(t, []string{"dify", "logging", "token_manager"}, ChangedSections(oldConfig, &newConfig))
+
+	assert.Contains(t, ChangedSections(nil, oldConfig), "microsoft_todo")
+}
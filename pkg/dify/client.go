@@ -6,13 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/allanpk716/to_icalendar/pkg/logger"
 	"github.com/allanpk716/to_icalendar/pkg/models"
 	"github.com/go-resty/resty/v2"
 )
@@ -80,22 +80,23 @@ func (c *Client) ProcessText(ctx context.Context, text string, userID string) (*
 
 // ProcessImage processes image content using Dify workflow API
 func (c *Client) ProcessImage(ctx context.Context, imageData []byte, fileName string, userID string) (*models.DifyResponse, error) {
-	log.Printf("[DifyClient] 开始处理图片: %s, 大小: %d bytes, 用户ID: %s", fileName, len(imageData), userID)
-	log.Printf("[DifyClient] API 端点: %s", c.apiEndpoint)
+	log := logger.FromContext(ctx)
+	log.Infof("[DifyClient] 开始处理图片: %s, 大小: %d bytes, 用户ID: %s", fileName, len(imageData), userID)
+	log.Infof("[DifyClient] API 端点: %s", c.apiEndpoint)
 
 	if len(imageData) == 0 {
 		return nil, fmt.Errorf("image data cannot be empty")
 	}
 
 	// 按照正确的流程：先上传文件，再运行工作流
-	log.Printf("[DifyClient] 开始上传文件...")
+	log.Infof("[DifyClient] 开始上传文件...")
 	fileID, err := c.uploadFile(ctx, imageData, fileName, userID)
 	if err != nil {
 		return nil, fmt.Errorf("文件上传失败: %w", err)
 	}
 
-	log.Printf("[DifyClient] 文件上传成功，ID: %s", fileID)
-	log.Printf("[DifyClient] 开始运行工作流...")
+	log.Infof("[DifyClient] 文件上传成功，ID: %s", fileID)
+	log.Infof("[DifyClient] 开始运行工作流...")
 
 	// 使用文件ID运行工作流
 	difyResp, err := c.runWorkflowWithFile(ctx, fileID, userID)
@@ -103,13 +104,14 @@ func (c *Client) ProcessImage(ctx context.Context, imageData []byte, fileName st
 		return nil, fmt.Errorf("工作流运行失败: %w", err)
 	}
 
-	log.Printf("[DifyClient] 工作流运行成功")
+	log.Infof("[DifyClient] 工作流运行成功")
 	return difyResp, nil
 }
 
 // uploadFile 上传文件到 Dify
 func (c *Client) uploadFile(ctx context.Context, fileData []byte, fileName string, userID string) (string, error) {
-	log.Printf("[DifyClient] 上传文件: %s, 大小: %d bytes", fileName, len(fileData))
+	log := logger.FromContext(ctx)
+	log.Infof("[DifyClient] 上传文件: %s, 大小: %d bytes", fileName, len(fileData))
 
 	// 创建 multipart form data
 	var buf bytes.Buffer
@@ -163,8 +165,8 @@ func (c *Client) uploadFile(ctx context.Context, fileData []byte, fileName strin
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 
-	log.Printf("[DifyClient] 发送文件上传请求...")
-	log.Printf("[DifyClient] Content-Type: %s", writer.FormDataContentType())
+	log.Infof("[DifyClient] 发送文件上传请求...")
+	log.Infof("[DifyClient] Content-Type: %s", writer.FormDataContentType())
 
 	// 发送请求
 	httpClient := &http.Client{Timeout: c.timeout}
@@ -180,8 +182,8 @@ func (c *Client) uploadFile(ctx context.Context, fileData []byte, fileName strin
 		return "", fmt.Errorf("读取上传响应失败: %w", err)
 	}
 
-	log.Printf("[DifyClient] 上传响应状态码: %d", resp.StatusCode)
-	log.Printf("[DifyClient] 上传响应内容: %s", string(body))
+	log.Infof("[DifyClient] 上传响应状态码: %d", resp.StatusCode)
+	log.Infof("[DifyClient] 上传响应内容: %s", string(body))
 
 	// 检查状态码
 	if resp.StatusCode != 201 { // 201 表示创建成功
@@ -209,7 +211,8 @@ func (c *Client) uploadFile(ctx context.Context, fileData []byte, fileName strin
 
 // runWorkflowWithFile 使用文件ID运行工作流
 func (c *Client) runWorkflowWithFile(ctx context.Context, fileID string, userID string) (*models.DifyResponse, error) {
-	log.Printf("[DifyClient] 使用文件ID运行工作流: %s", fileID)
+	log := logger.FromContext(ctx)
+	log.Infof("[DifyClient] 使用文件ID运行工作流: %s", fileID)
 
 	// 构建请求数据，根据错误信息调整格式
 	inputs := map[string]interface{}{
@@ -227,7 +230,7 @@ func (c *Client) runWorkflowWithFile(ctx context.Context, fileID string, userID
 		AutoGenerateName: false,
 	}
 
-	log.Printf("[DifyClient] 工作流请求数据: %+v", inputs)
+	log.Infof("[DifyClient] 工作流请求数据: %+v", inputs)
 
 	// 发送工作流请求
 	response, err := c.httpClient.R().
@@ -241,8 +244,8 @@ func (c *Client) runWorkflowWithFile(ctx context.Context, fileID string, userID
 		return nil, fmt.Errorf("发送工作流请求失败: %w", err)
 	}
 
-	log.Printf("[DifyClient] 工作流响应状态码: %d", response.StatusCode())
-	log.Printf("[DifyClient] 工作流响应内容: %s", string(response.Body()))
+	log.Infof("[DifyClient] 工作流响应状态码: %d", response.StatusCode())
+	log.Infof("[DifyClient] 工作流响应内容: %s", string(response.Body()))
 
 	// 检查响应状态
 	if response.StatusCode() != http.StatusOK {
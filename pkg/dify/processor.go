@@ -2,14 +2,13 @@ package dify
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/allanpk716/to_icalendar/pkg/logger"
 	"github.com/allanpk716/to_icalendar/pkg/models"
 )
 
@@ -18,6 +17,7 @@ type Processor struct {
 	client    *Client
 	options   *ProcessingOptions
 	userID    string
+	parser    *ResponseParserImpl
 }
 
 // NewProcessor creates a new content processor
@@ -30,14 +30,32 @@ func NewProcessor(client *Client, userID string, options *ProcessingOptions) *Pr
 		client:  client,
 		options: options,
 		userID:  userID,
+		parser:  newProcessingParser(options),
 	}
 }
 
+// newProcessingParser 根据处理选项创建响应解析器
+func newProcessingParser(options *ProcessingOptions) *ResponseParserImpl {
+	defaults := DefaultReminderDefaults()
+	if options.DefaultList != "" {
+		defaults.List = options.DefaultList
+	}
+	if options.DefaultRemindBefore != "" {
+		defaults.RemindBefore = options.DefaultRemindBefore
+	}
+	if options.DefaultPriority != "" {
+		defaults.Priority = models.Priority(options.DefaultPriority)
+	}
+	defaults.Time = options.DefaultTime
+	return NewResponseParserWithDefaults(defaults)
+}
+
 // ProcessImage processes image content using Dify OCR and semantic understanding
 func (p *Processor) ProcessImage(ctx context.Context, imageData []byte, fileName string) (*ProcessingResponse, error) {
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 
-	log.Printf("开始处理图片内容，文件名: %s, 大小: %d bytes", fileName, len(imageData))
+	log.Infof("开始处理图片内容，文件名: %s, 大小: %d bytes", fileName, len(imageData))
 
 	// 验证输入
 	if len(imageData) == 0 {
@@ -55,7 +73,7 @@ func (p *Processor) ProcessImage(ctx context.Context, imageData []byte, fileName
 	// 调用Dify API处理图片
 	difyResp, err := p.client.ProcessImage(ctx, imageData, fileName, p.userID)
 	if err != nil {
-		log.Printf("Dify API调用失败: %v", err)
+		log.Errorf("Dify API调用失败: %v", err)
 		return &ProcessingResponse{
 			Success:        false,
 			ErrorMessage:   fmt.Sprintf("Dify API调用失败: %v", err),
@@ -63,16 +81,17 @@ func (p *Processor) ProcessImage(ctx context.Context, imageData []byte, fileName
 		}, err
 	}
 
-	log.Printf("Dify API响应: %s", difyResp.Answer)
+	log.Debugf("Dify API响应长度: %d", len(difyResp.Answer))
 
 	// 解析Dify响应 - 使用工作流响应解析器
-	parsedInfo, err := p.parseDifyWorkflowResponse(difyResp)
+	parsedInfo, err := p.parseDifyWorkflowResponse(ctx, difyResp)
 	if err != nil {
-		log.Printf("解析Dify响应失败: %v", err)
+		log.Errorf("解析Dify响应失败: %v", err)
 		return &ProcessingResponse{
 			Success:        false,
 			ErrorMessage:   fmt.Sprintf("解析响应失败: %v", err),
 			ProcessingTime: time.Since(startTime),
+			Workflow:       difyResp.Data,
 		}, err
 	}
 
@@ -82,7 +101,7 @@ func (p *Processor) ProcessImage(ctx context.Context, imageData []byte, fileName
 	// 生成提醒事项
 	var reminder *models.Reminder
 	if validation.IsValid && parsedInfo.Confidence >= p.options.ConfidenceThreshold {
-		reminder = p.createReminderFromParsedInfo(parsedInfo)
+		reminder = p.createReminderFromParsedInfo(ctx, parsedInfo)
 	}
 
 	return &ProcessingResponse{
@@ -94,14 +113,16 @@ func (p *Processor) ProcessImage(ctx context.Context, imageData []byte, fileName
 		RequestID:      fmt.Sprintf("img_%d", time.Now().Unix()),
 		Timestamp:      time.Now(),
 		ErrorMessage:   getErrorMessage(validation, parsedInfo),
+		Workflow:       difyResp.Data,
 	}, nil
 }
 
 // ProcessText processes text content using Dify semantic understanding
 func (p *Processor) ProcessText(ctx context.Context, text string) (*ProcessingResponse, error) {
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 
-	log.Printf("开始处理文字内容，长度: %d", len(text))
+	log.Infof("开始处理文字内容，长度: %d", len(text))
 
 	// 验证输入
 	if strings.TrimSpace(text) == "" {
@@ -115,7 +136,7 @@ func (p *Processor) ProcessText(ctx context.Context, text string) (*ProcessingRe
 	// 调用Dify API处理文字
 	difyResp, err := p.client.ProcessText(ctx, text, p.userID)
 	if err != nil {
-		log.Printf("Dify API调用失败: %v", err)
+		log.Errorf("Dify API调用失败: %v", err)
 		return &ProcessingResponse{
 			Success:        false,
 			ErrorMessage:   fmt.Sprintf("Dify API调用失败: %v", err),
@@ -123,16 +144,17 @@ func (p *Processor) ProcessText(ctx context.Context, text string) (*ProcessingRe
 		}, err
 	}
 
-	log.Printf("Dify API响应: %s", difyResp.Answer)
+	log.Debugf("Dify API响应长度: %d", len(difyResp.Answer))
 
 	// 解析Dify响应 - 使用工作流响应解析器
-	parsedInfo, err := p.parseDifyWorkflowResponse(difyResp)
+	parsedInfo, err := p.parseDifyWorkflowResponse(ctx, difyResp)
 	if err != nil {
-		log.Printf("解析Dify响应失败: %v", err)
+		log.Errorf("解析Dify响应失败: %v", err)
 		return &ProcessingResponse{
 			Success:        false,
 			ErrorMessage:   fmt.Sprintf("解析响应失败: %v", err),
 			ProcessingTime: time.Since(startTime),
+			Workflow:       difyResp.Data,
 		}, err
 	}
 
@@ -142,7 +164,7 @@ func (p *Processor) ProcessText(ctx context.Context, text string) (*ProcessingRe
 	// 生成提醒事项
 	var reminder *models.Reminder
 	if validation.IsValid && parsedInfo.Confidence >= p.options.ConfidenceThreshold {
-		reminder = p.createReminderFromParsedInfo(parsedInfo)
+		reminder = p.createReminderFromParsedInfo(ctx, parsedInfo)
 	}
 
 	return &ProcessingResponse{
@@ -154,102 +176,35 @@ func (p *Processor) ProcessText(ctx context.Context, text string) (*ProcessingRe
 		RequestID:      fmt.Sprintf("txt_%d", time.Now().Unix()),
 		Timestamp:      time.Now(),
 		ErrorMessage:   getErrorMessage(validation, parsedInfo),
+		Workflow:       difyResp.Data,
 	}, nil
 }
 
-// parseDifyResponse parses the response from Dify API
-func (p *Processor) parseDifyResponse(response string) (*models.ParsedTaskInfo, error) {
-	// 尝试解析JSON响应
-	var parsedInfo models.ParsedTaskInfo
-
-	// 首先尝试直接解析JSON
-	if err := json.Unmarshal([]byte(response), &parsedInfo); err == nil {
-		return &parsedInfo, nil
-	}
-
-	// 如果直接解析失败，尝试从响应中提取JSON
-	jsonStart := strings.Index(response, "{")
-	jsonEnd := strings.LastIndex(response, "}")
-
-	if jsonStart >= 0 && jsonEnd > jsonStart {
-		jsonStr := response[jsonStart : jsonEnd+1]
-		if err := json.Unmarshal([]byte(jsonStr), &parsedInfo); err == nil {
-			return &parsedInfo, nil
-		}
-	}
-
-	// 如果仍然无法解析，创建一个错误响应
-	return &models.ParsedTaskInfo{
-		OriginalText: response,
-		Confidence:   0.0,
-		Description:  fmt.Sprintf("无法解析Dify响应: %s", response),
-	}, fmt.Errorf("failed to parse Dify response as JSON")
-}
-
 // parseDifyWorkflowResponse parses the response from Dify workflow API
-func (p *Processor) parseDifyWorkflowResponse(difyResp *models.DifyResponse) (*models.ParsedTaskInfo, error) {
-	// 首先检查Answer字段（适用于chat-messages）
-	if difyResp.Answer != "" {
-		log.Printf("从Answer字段解析响应")
-		return p.parseDifyResponse(difyResp.Answer)
+func (p *Processor) parseDifyWorkflowResponse(ctx context.Context, difyResp *models.DifyResponse) (*models.ParsedTaskInfo, error) {
+	// Answer 字段适用于 chat-messages，Data.Outputs.Text 适用于工作流
+	text := difyResp.Answer
+	if text == "" && difyResp.Data != nil && difyResp.Data.Outputs != nil {
+		text = difyResp.Data.Outputs.Text
 	}
 
-	// 检查工作流响应的Data.Outputs.Text字段
-	if difyResp.Data != nil && difyResp.Data.Outputs != nil && difyResp.Data.Outputs.Text != "" {
-		log.Printf("从工作流Data.Outputs.Text字段解析响应")
-		return p.parseTaskJSON(difyResp.Data.Outputs.Text)
-	}
-
-	// 如果两者都为空，返回错误
-	return &models.ParsedTaskInfo{
-		OriginalText: "",
-		Confidence:   0.0,
-		Description:  "Dify响应中没有找到有效内容",
-	}, fmt.Errorf("no valid content found in Dify response")
-}
-
-// parseTaskJSON 解析任务JSON字符串（处理转义字符）
-func (p *Processor) parseTaskJSON(jsonStr string) (*models.ParsedTaskInfo, error) {
-	log.Printf("尝试解析任务JSON，原始长度: %d", len(jsonStr))
-	log.Printf("原始JSON字符串: %s", jsonStr)
-
-	// 清理JSON字符串
-	jsonStr = strings.TrimSpace(jsonStr)
-	if jsonStr == "" {
-		return nil, fmt.Errorf("empty JSON string")
-	}
-
-	// 如果JSON字符串被引号包围，去掉引号
-	if len(jsonStr) >= 2 && jsonStr[0] == '"' && jsonStr[len(jsonStr)-1] == '"' {
-		jsonStr = jsonStr[1 : len(jsonStr)-1]
-		log.Printf("去除引号后: %s", jsonStr)
-
-		// 处理转义字符 - 使用更完整的转义处理
-		jsonStr = strings.ReplaceAll(jsonStr, "\\\"", "\"")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\\\", "\\")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\n", "\n")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\t", "\t")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\r", "\r")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\f", "\f")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\b", "\b")
-
-		log.Printf("处理转义字符后: %s", jsonStr)
+	if strings.TrimSpace(text) == "" {
+		return &models.ParsedTaskInfo{
+			OriginalText: "",
+			Confidence:   0.0,
+			Description:  "Dify响应中没有找到有效内容",
+		}, fmt.Errorf("no valid content found in Dify response")
 	}
 
-	// 尝试解析JSON
-	var taskInfo models.ParsedTaskInfo
-	if err := json.Unmarshal([]byte(jsonStr), &taskInfo); err != nil {
-		log.Printf("JSON解析失败: %v, 原始内容: %s", err, jsonStr)
-		return nil, fmt.Errorf("failed to parse task JSON: %w", err)
+	parsedInfo, err := p.parser.withContext(ctx).extractTaskInfo(text)
+	if err != nil {
+		return &models.ParsedTaskInfo{
+			OriginalText: text,
+			Confidence:   0.0,
+			Description:  fmt.Sprintf("无法解析Dify响应: %s", text),
+		}, err
 	}
-
-	log.Printf("任务JSON解析成功: 标题='%s', 日期='%s', 时间='%s'",
-		taskInfo.Title, taskInfo.Date, taskInfo.Time)
-
-	// 对于成功解析的JSON，设置高置信度
-	taskInfo.Confidence = 0.9
-
-	return &taskInfo, nil
+	return parsedInfo, nil
 }
 
 // validateParsedInfo validates the parsed task information
@@ -283,8 +238,8 @@ func (p *Processor) validateParsedInfo(info *models.ParsedTaskInfo) *ValidationR
 		}
 	}
 
-	// 检查日期时间格式
-	if info.Date == "" || info.Time == "" {
+	// 检查日期时间格式（配置了默认时间时允许只有日期）
+	if info.Date == "" || (info.Time == "" && p.parser.defaults.Time == "") {
 		return &ValidationResult{
 			IsValid:   false,
 			ErrorType: "missing_datetime",
@@ -304,7 +259,7 @@ func (p *Processor) validateParsedInfo(info *models.ParsedTaskInfo) *ValidationR
 	}
 
 	// 验证时间格式
-	if !isValidTime(info.Time) {
+	if info.Time != "" && !isValidTime(info.Time) {
 		return &ValidationResult{
 			IsValid:   false,
 			ErrorType: "invalid_time_format",
@@ -323,42 +278,8 @@ func (p *Processor) validateParsedInfo(info *models.ParsedTaskInfo) *ValidationR
 }
 
 // createReminderFromParsedInfo creates a Reminder from parsed task info
-func (p *Processor) createReminderFromParsedInfo(info *models.ParsedTaskInfo) *models.Reminder {
-	reminder := &models.Reminder{
-		Title:        info.Title,
-		Description:  info.Description,
-		Date:         info.Date,
-		Time:         info.Time,
-		RemindBefore: info.RemindBefore,
-		List:         info.List,
-	}
-
-	// 保留图片识别的提醒时间，仅在缺失时使用默认值
-
-	// 设置默认值（作为最后的保险）
-	if reminder.RemindBefore == "" {
-		if p.options != nil && p.options.DefaultRemindBefore != "" {
-			reminder.RemindBefore = p.options.DefaultRemindBefore
-		} else {
-			reminder.RemindBefore = "15m"
-		}
-	}
-
-	if reminder.List == "" {
-		reminder.List = p.options.DefaultList
-	}
-
-	// 转换优先级
-	switch info.Priority {
-	case "high", "高", "紧急":
-		reminder.Priority = models.PriorityHigh
-	case "low", "低", "一般":
-		reminder.Priority = models.PriorityLow
-	default:
-		reminder.Priority = models.PriorityMedium
-	}
-
-	return reminder
+func (p *Processor) createReminderFromParsedInfo(ctx context.Context, info *models.ParsedTaskInfo) *models.Reminder {
+	return BuildReminder(ctx, info, p.parser.defaults)
 }
 
 // isValidDate checks if the date string is in valid format (YYYY-MM-DD)
@@ -495,6 +416,7 @@ func getErrorMessage(validation *ValidationResult, info *models.ParsedTaskInfo)
 func (p *Processor) SetOptions(options *ProcessingOptions) {
 	if options != nil {
 		p.options = options
+		p.parser = newProcessingParser(options)
 	}
 }
 
@@ -1,135 +1,201 @@
 package dify
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/allanpk716/to_icalendar/pkg/logger"
 	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/allanpk716/to_icalendar/pkg/nlp/datetime"
 )
 
 // ResponseParser defines the interface for parsing Dify responses
 type ResponseParser interface {
 	// ParseReminderResponse parses Dify response and extracts task information
-	ParseReminderResponse(response string) (*models.ParsedTaskInfo, error)
+	ParseReminderResponse(ctx context.Context, response string) (*models.ParsedTaskInfo, error)
+	// ParseReminder parses Dify response into a Reminder with defaults applied
+	ParseReminder(ctx context.Context, response string) (*models.Reminder, error)
+}
+
+// ReminderDefaults 解析结果缺少字段时使用的默认值
+type ReminderDefaults struct {
+	List         string           // 默认任务列表
+	RemindBefore string           // 默认提前提醒时间
+	Time         string           // 只识别出日期时使用的时间（如 09:00），为空时使用当前时间
+	Priority     models.Priority  // 默认优先级
+	Now          func() time.Time // 当前时间，用于缺失的日期时间和"明天/下周三"等相对日期
+	Location     *time.Location   // 解析相对日期使用的时区，为空时使用本地时区
+}
+
+// DefaultReminderDefaults 返回默认的提醒默认值
+func DefaultReminderDefaults() ReminderDefaults {
+	return ReminderDefaults{
+		List:         "Tasks",
+		RemindBefore: "15m",
+		Priority:     models.PriorityMedium,
+		Now:          time.Now,
+	}
+}
+
+// resolver 创建以默认值中的时钟和时区为基准的日期时间解析器
+func (d ReminderDefaults) resolver() *datetime.Resolver {
+	return datetime.NewResolver(&datetime.Options{Now: d.Now, Location: d.Location})
 }
 
 // ResponseParserImpl implements ResponseParser for Dify workflow responses
-type ResponseParserImpl struct{}
+type ResponseParserImpl struct {
+	defaults ReminderDefaults
+	log      logger.Logger // 当前解析使用的日志器，由 withContext 设置
+}
 
 // NewResponseParser creates a new ResponseParser instance
 func NewResponseParser() ResponseParser {
-	return &ResponseParserImpl{}
+	return NewResponseParserWithDefaults(DefaultReminderDefaults())
+}
+
+// NewResponseParserWithDefaults creates a ResponseParser with custom defaults
+func NewResponseParserWithDefaults(defaults ReminderDefaults) *ResponseParserImpl {
+	return &ResponseParserImpl{defaults: defaults}
+}
+
+// withContext 返回使用 ctx 日志器的解析器副本，日志会带上 ctx 中的任务 ID
+func (p *ResponseParserImpl) withContext(ctx context.Context) *ResponseParserImpl {
+	parser := *p
+	parser.log = logger.FromContext(ctx)
+	return &parser
+}
+
+// logger 返回当前解析使用的日志器，未设置时使用全局日志器
+func (p *ResponseParserImpl) logger() logger.Logger {
+	if p.log != nil {
+		return p.log
+	}
+	return logger.FromContext(context.Background())
 }
 
+// 解析用的正则表达式
+var (
+	fencedBlockRegex     = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*(.*?)```")
+	priorityKeywordRegex = regexp.MustCompile(`(?i)紧急|重要|不急|urgent|asap`)
+)
+
 // ParseReminderResponse parses Dify workflow response and extracts task information
-func (p *ResponseParserImpl) ParseReminderResponse(response string) (*models.ParsedTaskInfo, error) {
-	log.Printf("开始解析Dify响应，长度: %d", len(response))
-	log.Printf("原始响应内容: %s", response)
+func (p *ResponseParserImpl) ParseReminderResponse(ctx context.Context, response string) (*models.ParsedTaskInfo, error) {
+	log := logger.FromContext(ctx)
+	log.Debugf("开始解析Dify响应，长度: %d", len(response))
+
+	taskInfo, err := p.ParseTaskInfo(ctx, response)
+	if err != nil {
+		return nil, err
+	}
+
+	// 验证解析结果
+	if !p.validateTaskInfo(taskInfo) {
+		return nil, fmt.Errorf("parsed task info is incomplete or invalid")
+	}
 
-	// 清理响应内容
+	log.Infof("成功解析任务信息: 标题='%s', 日期='%s', 时间='%s'",
+		taskInfo.Title, taskInfo.Date, taskInfo.Time)
+
+	return taskInfo, nil
+}
+
+// ParseTaskInfo 从 Dify 回答中提取任务信息，不校验必需字段，缺失的日期时间保持为空
+func (p *ResponseParserImpl) ParseTaskInfo(ctx context.Context, response string) (*models.ParsedTaskInfo, error) {
 	cleanedResponse := strings.TrimSpace(response)
 	if cleanedResponse == "" {
 		return nil, fmt.Errorf("empty response from Dify")
 	}
 
-	// 首先尝试直接解析为JSON
-	var taskInfo models.ParsedTaskInfo
-	if err := json.Unmarshal([]byte(cleanedResponse), &taskInfo); err == nil {
-		// 验证关键字段是否存在
-		if p.validateTaskInfo(&taskInfo) {
-			log.Printf("直接JSON解析成功")
-			return &taskInfo, nil
-		}
+	return p.withContext(ctx).extractTaskInfo(cleanedResponse)
+}
+
+// ParseReminder 将 Dify 回答解析为 Reminder，缺失的字段使用默认值补全
+func (p *ResponseParserImpl) ParseReminder(ctx context.Context, response string) (*models.Reminder, error) {
+	taskInfo, err := p.ParseTaskInfo(ctx, response)
+	if err != nil {
+		return nil, err
 	}
 
-	// 尝试解析为Dify工作流响应结构
-	var difyResp models.DifyResponse
-	if err := json.Unmarshal([]byte(cleanedResponse), &difyResp); err == nil {
-		log.Printf("识别为Dify工作流响应")
-
-		// 尝试从Answer字段获取任务信息
-		if difyResp.Answer != "" {
-			log.Printf("从Answer字段解析，内容: %s", difyResp.Answer)
-			if taskInfo, err := p.parseTaskJSON(difyResp.Answer); err == nil {
-				return taskInfo, nil
-			}
-		}
+	return BuildReminder(ctx, taskInfo, p.defaults), nil
+}
 
-		// 尝试从Data.Outputs.Text字段获取任务信息
-		if difyResp.Data != nil && difyResp.Data.Outputs != nil && difyResp.Data.Outputs.Text != "" {
-			log.Printf("从Data.Outputs.Text字段解析，内容: %s", difyResp.Data.Outputs.Text)
-			if taskInfo, err := p.parseTaskJSON(difyResp.Data.Outputs.Text); err == nil {
-				return taskInfo, nil
-			}
-		}
+// extractTaskInfo 从回答中提取任务信息，依次尝试 Dify 响应结构、JSON、代码块中的 JSON 和纯文本
+func (p *ResponseParserImpl) extractTaskInfo(response string) (*models.ParsedTaskInfo, error) {
+	response = unwrapDifyResponse(strings.TrimSpace(response))
+	if response == "" {
+		return nil, fmt.Errorf("no valid content found in Dify response")
 	}
 
-	// 如果直接解析失败，尝试从响应中提取JSON
-	taskInfoPtr, err := p.extractJSONFromResponse(cleanedResponse)
-	if err != nil {
-		log.Printf("JSON提取失败，尝试文本解析: %v", err)
-		// 如果无法提取有效JSON，尝试从文本中解析任务信息
-		return p.parseTaskFromText(cleanedResponse)
+	if taskInfo, err := p.parseTaskJSON(response); err == nil {
+		return taskInfo, nil
 	}
 
-	// 验证解析结果
-	if !p.validateTaskInfo(taskInfoPtr) {
-		return nil, fmt.Errorf("parsed task info is incomplete or invalid")
+	// 尝试从 markdown 代码块中解析
+	if matches := fencedBlockRegex.FindStringSubmatch(response); len(matches) > 1 {
+		if taskInfo, err := p.parseTaskJSON(matches[1]); err == nil {
+			return taskInfo, nil
+		}
 	}
 
-	log.Printf("成功解析任务信息: 标题='%s', 日期='%s', 时间='%s'",
-		taskInfoPtr.Title, taskInfoPtr.Date, taskInfoPtr.Time)
+	// 尝试从混合文本中提取 JSON
+	if taskInfo, err := p.extractJSONFromResponse(response); err == nil {
+		return taskInfo, nil
+	}
 
-	return taskInfoPtr, nil
+	p.logger().Debugf("未找到有效JSON，尝试文本解析")
+	return p.parseTaskFromText(response)
 }
 
-// parseTaskJSON 解析任务JSON字符串
-func (p *ResponseParserImpl) parseTaskJSON(jsonStr string) (*models.ParsedTaskInfo, error) {
-	log.Printf("尝试解析任务JSON，原始长度: %d", len(jsonStr))
-	log.Printf("原始JSON字符串: %s", jsonStr)
+// unwrapDifyResponse 如果内容是完整的 Dify 响应结构，返回其中的回答文本
+func unwrapDifyResponse(response string) string {
+	var difyResp models.DifyResponse
+	if err := json.Unmarshal([]byte(response), &difyResp); err != nil {
+		return response
+	}
+	if difyResp.Answer != "" {
+		return strings.TrimSpace(difyResp.Answer)
+	}
+	if difyResp.Data != nil && difyResp.Data.Outputs != nil && difyResp.Data.Outputs.Text != "" {
+		return strings.TrimSpace(difyResp.Data.Outputs.Text)
+	}
+	return response
+}
 
-	// 清理JSON字符串
+// parseTaskJSON 解析任务JSON字符串，支持被引号包围并转义的 JSON
+func (p *ResponseParserImpl) parseTaskJSON(jsonStr string) (*models.ParsedTaskInfo, error) {
 	jsonStr = strings.TrimSpace(jsonStr)
 	if jsonStr == "" {
 		return nil, fmt.Errorf("empty JSON string")
 	}
 
-	// 如果JSON字符串被引号包围，去掉引号
+	// 如果JSON字符串被引号包围，按 JSON 字符串反转义
 	if len(jsonStr) >= 2 && jsonStr[0] == '"' && jsonStr[len(jsonStr)-1] == '"' {
-		jsonStr = jsonStr[1 : len(jsonStr)-1]
-		log.Printf("去除引号后: %s", jsonStr)
-
-		// 处理转义字符 - 使用更完整的转义处理
-		jsonStr = strings.ReplaceAll(jsonStr, "\\\"", "\"")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\\\", "\\")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\n", "\n")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\t", "\t")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\r", "\r")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\f", "\f")
-		jsonStr = strings.ReplaceAll(jsonStr, "\\b", "\b")
-
-		log.Printf("处理转义字符后: %s", jsonStr)
+		var unquoted string
+		if err := json.Unmarshal([]byte(jsonStr), &unquoted); err == nil {
+			jsonStr = strings.TrimSpace(unquoted)
+		}
 	}
 
-	// 尝试解析JSON
 	var taskInfo models.ParsedTaskInfo
 	if err := json.Unmarshal([]byte(jsonStr), &taskInfo); err != nil {
-		log.Printf("JSON解析失败: %v, 原始内容: %s", err, jsonStr)
 		return nil, fmt.Errorf("failed to parse task JSON: %w", err)
 	}
-
-	if !p.validateTaskInfo(&taskInfo) {
-		log.Printf("任务信息验证失败: 标题='%s', 日期='%s', 时间='%s'",
-			taskInfo.Title, taskInfo.Date, taskInfo.Time)
-		return nil, fmt.Errorf("parsed task info is incomplete or invalid")
+	if strings.TrimSpace(taskInfo.Title) == "" {
+		return nil, fmt.Errorf("task JSON has no title")
 	}
 
-	log.Printf("任务JSON解析成功: 标题='%s', 日期='%s', 时间='%s'",
-		taskInfo.Title, taskInfo.Date, taskInfo.Time)
+	// 结构化结果未给出置信度时使用较高的默认值
+	if taskInfo.Confidence == 0 {
+		taskInfo.Confidence = 0.9
+	}
+	p.normalizeTaskInfo(&taskInfo)
 
 	return &taskInfo, nil
 }
@@ -144,68 +210,327 @@ func (p *ResponseParserImpl) extractJSONFromResponse(response string) (*models.P
 		return nil, fmt.Errorf("no valid JSON found in response")
 	}
 
-	jsonStr := response[jsonStart : jsonEnd+1]
-	var taskInfo models.ParsedTaskInfo
-
-	if err := json.Unmarshal([]byte(jsonStr), &taskInfo); err != nil {
-		return nil, fmt.Errorf("failed to parse extracted JSON: %w", err)
-	}
-
-	return &taskInfo, nil
+	return p.parseTaskJSON(response[jsonStart : jsonEnd+1])
 }
 
 // parseTaskFromText attempts to parse task information from plain text response
 func (p *ResponseParserImpl) parseTaskFromText(text string) (*models.ParsedTaskInfo, error) {
-	// 这是一个简单的文本解析实现，您可以根据需要扩展
-	// 尝试识别常见的任务描述模式
-
-	lines := strings.Split(text, "\n")
 	taskInfo := &models.ParsedTaskInfo{
 		OriginalText: text,
 		Confidence:   0.6, // 文本解析的置信度较低
 	}
 
-	for _, line := range lines {
+	var plainLines []string
+	for _, line := range strings.Split(text, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		if line == "" || strings.Trim(line, "{}[],`") == "" {
 			continue
 		}
 
-		// 尝试提取标题（通常是不包含日期时间的关键词行）
-		if taskInfo.Title == "" && p.looksLikeTitle(line) {
-			taskInfo.Title = line
+		// "标题：xxx" 形式的字段行
+		if p.applyLabeledLine(taskInfo, line) {
 			continue
 		}
 
-		// 尝试提取日期时间
-		if date, time := p.extractDateTime(line); date != "" && time != "" {
-			taskInfo.Date = date
-			taskInfo.Time = time
-			continue
+		if taskInfo.Date == "" {
+			taskInfo.Date = p.findDate(line)
 		}
-
-		// 如果还没有描述，将第一行作为描述
-		if taskInfo.Description == "" && line != taskInfo.Title {
-			taskInfo.Description = line
+		if taskInfo.Time == "" {
+			taskInfo.Time = p.findTime(line)
 		}
+		p.applyRange(taskInfo, line)
+		plainLines = append(plainLines, line)
 	}
 
-	// 如果没有找到标题，使用文本的第一行
+	// 标题优先使用看起来像标题的行，否则使用第一行
+	if taskInfo.Title == "" {
+		for _, line := range plainLines {
+			if p.looksLikeTitle(line) {
+				taskInfo.Title = line
+				break
+			}
+		}
+	}
 	if taskInfo.Title == "" {
 		taskInfo.Title = p.getFirstMeaningfulLine(text)
 	}
 
-	// 设置默认优先级
-	taskInfo.Priority = "medium"
-	taskInfo.RemindBefore = "15m"
+	// 其余文本作为描述
+	if taskInfo.Description == "" {
+		var rest []string
+		for _, line := range plainLines {
+			if line != taskInfo.Title {
+				rest = append(rest, line)
+			}
+		}
+		taskInfo.Description = strings.Join(rest, "\n")
+	}
 
-	if !p.validateTaskInfo(taskInfo) {
+	// 纯文本中的"紧急"、"urgent"等关键词作为优先级
+	if taskInfo.Priority == "" {
+		taskInfo.Priority = priorityKeywordRegex.FindString(text)
+	}
+	if taskInfo.RemindBefore == "" {
+		taskInfo.RemindBefore = p.defaults.RemindBefore
+	}
+	p.normalizeTaskInfo(taskInfo)
+
+	if strings.TrimSpace(taskInfo.Title) == "" {
 		return nil, fmt.Errorf("could not extract valid task information from text")
 	}
 
 	return taskInfo, nil
 }
 
+// applyLabeledLine 解析"字段：值"形式的行，返回是否识别为已知字段
+func (p *ResponseParserImpl) applyLabeledLine(taskInfo *models.ParsedTaskInfo, line string) bool {
+	sep := strings.IndexAny(line, ":：")
+	if sep <= 0 {
+		return false
+	}
+
+	key := strings.ToLower(strings.Trim(line[:sep], " \t\"'*-#"))
+	_, sepSize := utf8.DecodeRuneInString(line[sep:])
+	value := strings.Trim(strings.TrimSpace(line[sep+sepSize:]), " \t\"',")
+	if key == "" || value == "" {
+		return false
+	}
+
+	switch {
+	case matchesLabel(key, []string{"description", "note", "notes"}, []string{"描述", "备注", "说明", "详情"}):
+		taskInfo.Description = value
+	case matchesLabel(key, []string{"remind_before", "remind"}, []string{"提前"}):
+		taskInfo.RemindBefore = value
+	case matchesLabel(key, []string{"date", "due", "deadline"}, []string{"日期", "截止", "期限"}):
+		taskInfo.Date = p.findDate(value)
+		if taskInfo.Time == "" {
+			taskInfo.Time = p.findTime(value)
+		}
+		p.applyRange(taskInfo, value)
+	case matchesLabel(key, []string{"time"}, []string{"时间"}):
+		if date := p.findDate(value); date != "" {
+			taskInfo.Date = date
+		}
+		taskInfo.Time = p.findTime(value)
+		p.applyRange(taskInfo, value)
+	case matchesLabel(key, []string{"end_time", "end"}, []string{"结束"}):
+		if date := p.findDate(value); date != "" {
+			taskInfo.EndDate = date
+		}
+		taskInfo.EndTime = p.findTime(value)
+	case matchesLabel(key, []string{"duration"}, []string{"时长"}):
+		taskInfo.Duration = value
+	case matchesLabel(key, []string{"location", "where", "place"}, []string{"地点", "会议室", "地址"}):
+		taskInfo.Location = value
+	case matchesLabel(key, []string{"attendees", "participants"}, []string{"参会人", "参与人", "与会"}):
+		taskInfo.Attendees = models.SplitList(value)
+	case matchesLabel(key, []string{"kind", "type"}, []string{"类型"}):
+		taskInfo.Kind = value
+	case matchesLabel(key, []string{"priority"}, []string{"优先级"}):
+		taskInfo.Priority = value
+	case matchesLabel(key, []string{"list"}, []string{"列表", "清单"}):
+		taskInfo.List = value
+	case matchesLabel(key, []string{"title", "task", "subject"}, []string{"标题", "主题", "任务", "事项"}):
+		taskInfo.Title = value
+	default:
+		return false
+	}
+	return true
+}
+
+// matchesLabel 英文字段名完全匹配，中文字段名包含即可（如"会议主题"）
+func matchesLabel(key string, english, chinese []string) bool {
+	for _, name := range english {
+		if key == name {
+			return true
+		}
+	}
+	for _, name := range chinese {
+		if strings.Contains(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTaskInfo 将日期、时间和优先级统一为标准格式
+func (p *ResponseParserImpl) normalizeTaskInfo(taskInfo *models.ParsedTaskInfo) {
+	taskInfo.Title = strings.TrimSpace(taskInfo.Title)
+	taskInfo.Description = strings.TrimSpace(taskInfo.Description)
+	// "12月3日-12月5日"、"14:30 - 16:30" 等范围拆分为开始和结束
+	p.applyRange(taskInfo, strings.TrimSpace(taskInfo.Date+" "+taskInfo.Time))
+	if date := p.findDate(taskInfo.Date); date != "" {
+		taskInfo.Date = date
+	}
+	if t := p.findTime(taskInfo.Time); t != "" {
+		taskInfo.Time = t
+	}
+	if date := p.findDate(taskInfo.EndDate); date != "" {
+		taskInfo.EndDate = date
+	}
+	if t := p.findTime(taskInfo.EndTime); t != "" {
+		taskInfo.EndTime = t
+	}
+	if taskInfo.Priority != "" {
+		taskInfo.Priority = string(normalizePriority(taskInfo.Priority, models.PriorityMedium))
+	}
+	if taskInfo.Kind != "" {
+		taskInfo.Kind = string(models.NormalizeKind(taskInfo.Kind))
+	}
+	taskInfo.Location = strings.TrimSpace(taskInfo.Location)
+}
+
+// findDate 从文本中查找日期，返回 YYYY-MM-DD 格式
+func (p *ResponseParserImpl) findDate(text string) string {
+	result, ok := p.defaults.resolver().Resolve(text)
+	if !ok || !result.HasDate {
+		return ""
+	}
+	logAmbiguity(p.logger(), text, result)
+	return result.Date()
+}
+
+// findTime 从文本中查找时间，返回 HH:MM；时间范围返回开始时间
+func (p *ResponseParserImpl) findTime(text string) string {
+	resolver := p.defaults.resolver()
+
+	if rng, ok := resolver.ResolveRange(text); ok && rng.Start.HasTime {
+		return rng.Start.Clock()
+	}
+
+	result, ok := resolver.Resolve(text)
+	if !ok || !result.HasTime {
+		return ""
+	}
+	logAmbiguity(p.logger(), text, result)
+	return result.Clock()
+}
+
+// applyRange 从文本中查找日期或时间范围，补全尚未设置的结束日期和结束时间
+func (p *ResponseParserImpl) applyRange(taskInfo *models.ParsedTaskInfo, text string) {
+	rng, ok := p.defaults.resolver().ResolveRange(text)
+	if !ok {
+		return
+	}
+	if rng.End.HasDate && taskInfo.EndDate == "" {
+		taskInfo.EndDate = rng.End.Date()
+	}
+	if rng.End.HasTime && taskInfo.EndTime == "" {
+		taskInfo.EndTime = rng.End.Clock()
+	}
+}
+
+// logAmbiguity 记录存在歧义的日期时间表达式
+func logAmbiguity(log logger.Logger, text string, result datetime.Result) {
+	if result.Ambiguous() {
+		log.Warnf("日期时间表达式存在歧义: %q -> %s", text, result)
+	}
+}
+
+// normalizePriority 将中英文优先级转换为标准优先级
+func normalizePriority(priority string, fallback models.Priority) models.Priority {
+	switch strings.ToLower(strings.TrimSpace(priority)) {
+	case "high", "高", "紧急", "重要", "urgent", "asap":
+		return models.PriorityHigh
+	case "medium", "中", "普通", "normal":
+		return models.PriorityMedium
+	case "low", "低", "一般", "不急":
+		return models.PriorityLow
+	}
+	if fallback == "" {
+		return models.PriorityMedium
+	}
+	return fallback
+}
+
+// BuildReminder 根据解析出的任务信息创建 Reminder，并补全默认值
+func BuildReminder(ctx context.Context, info *models.ParsedTaskInfo, defaults ReminderDefaults) *models.Reminder {
+	return buildReminder(logger.FromContext(ctx), info, defaults)
+}
+
+// buildReminder 使用指定日志器实现 BuildReminder
+func buildReminder(log logger.Logger, info *models.ParsedTaskInfo, defaults ReminderDefaults) *models.Reminder {
+	reminder := &models.Reminder{
+		Title:        truncateRunes(strings.TrimSpace(info.Title), 100),
+		Description:  info.Description,
+		Date:         info.Date,
+		Time:         info.Time,
+		EndDate:      info.EndDate,
+		EndTime:      info.EndTime,
+		Duration:     info.Duration,
+		Kind:         kindOrEmpty(info.Kind),
+		Location:     info.Location,
+		Attendees:    info.Attendees,
+		RemindBefore: info.RemindBefore,
+		Priority:     normalizePriority(info.Priority, defaults.Priority),
+		List:         info.List,
+		Checklist:    info.Checklist,
+		Categories:   info.Categories,
+		Links:        info.Links,
+	}
+
+	// 保留识别出的提醒时间，仅在缺失时使用默认值
+	if reminder.RemindBefore == "" {
+		reminder.RemindBefore = defaults.RemindBefore
+	}
+	if reminder.List == "" {
+		reminder.List = defaults.List
+	}
+
+	// 缺失日期时间时先从标题和描述中解析相对日期，仍然没有时使用当前时间
+	resolver := defaults.resolver()
+	if reminder.Date == "" || reminder.Time == "" {
+		text := info.Title + "\n" + info.Description
+		if result, ok := resolver.Resolve(text); ok {
+			logAmbiguity(log, info.Title, result)
+			rng, hasRange := resolver.ResolveRange(text)
+			if reminder.Date == "" && result.HasDate {
+				reminder.Date = result.Date()
+				if hasRange && rng.End.HasDate && reminder.EndDate == "" {
+					reminder.EndDate = rng.End.Date()
+				}
+			}
+			if reminder.Time == "" && result.HasTime {
+				reminder.Time = result.Clock()
+				if hasRange && rng.End.HasTime && reminder.EndTime == "" {
+					reminder.EndTime = rng.End.Clock()
+				}
+			}
+		}
+	}
+
+	now := resolver.Now()
+	if reminder.Time == "" && reminder.Date != "" && defaults.Time != "" {
+		// 只有日期的任务使用配置的默认时间
+		reminder.Time = defaults.Time
+	}
+	if reminder.Date == "" {
+		reminder.Date = now.Format("2006-01-02")
+	}
+	if reminder.Time == "" {
+		reminder.Time = now.Format("15:04")
+	}
+
+	return reminder
+}
+
+// kindOrEmpty 标准化类型，未指定时保持为空（即默认的 task）
+func kindOrEmpty(kind string) models.Kind {
+	if strings.TrimSpace(kind) == "" {
+		return ""
+	}
+	return models.NormalizeKind(kind)
+}
+
+// truncateRunes 按字符截断字符串，超长时以 "..." 结尾
+func truncateRunes(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+	return string(runes[:maxLength-3]) + "..."
+}
+
 // validateTaskInfo validates that the task info contains required fields
 func (p *ResponseParserImpl) validateTaskInfo(taskInfo *models.ParsedTaskInfo) bool {
 	if taskInfo == nil {
@@ -241,7 +566,7 @@ func (p *ResponseParserImpl) looksLikeTitle(line string) bool {
 	lowerLine := strings.ToLower(line)
 
 	excludePatterns := []string{
-		"日期", "时间", "提醒", "priority", "date:", "time:",
+		"日期", "时间", "提醒", "优先级", "priority", "date:", "time:",
 		"上午", "下午", "明天", "今天", "紧急", "重要",
 	}
 
@@ -261,41 +586,18 @@ func (p *ResponseParserImpl) looksLikeTitle(line string) bool {
 
 // extractDateTime attempts to extract date and time from a line
 func (p *ResponseParserImpl) extractDateTime(line string) (string, string) {
-	// 简单的日期时间提取逻辑
-	// 您可以根据需要使用正则表达式来改进
-
-	// 查找日期格式 YYYY-MM-DD
-	words := strings.Fields(line)
-	for i, word := range words {
-		if p.isValidDateFormat(word) && i+1 < len(words) && p.isValidTimeFormat(words[i+1]) {
-			return word, words[i+1]
-		}
+	date := p.findDate(line)
+	t := p.findTime(line)
+	if date == "" || t == "" {
+		return "", ""
 	}
-
-	return "", ""
+	return date, t
 }
 
 // isValidDateFormat checks if the string is a valid date format (YYYY-MM-DD)
 func (p *ResponseParserImpl) isValidDateFormat(dateStr string) bool {
-	if len(dateStr) != 10 {
-		return false
-	}
-
-	if dateStr[4] != '-' || dateStr[7] != '-' {
-		return false
-	}
-
-	// 验证数字格式
-	for i, char := range dateStr {
-		if i == 4 || i == 7 {
-			continue
-		}
-		if char < '0' || char > '9' {
-			return false
-		}
-	}
-
-	return true
+	_, err := time.Parse("2006-01-02", dateStr)
+	return err == nil
 }
 
 // isValidTimeFormat checks if the string is a valid time format (HH:MM or HH:MM - HH:MM)
@@ -330,12 +632,12 @@ func (p *ResponseParserImpl) isValidSingleTimeFormat(timeStr string) bool {
 
 	// 支持多种时间格式
 	timeFormats := []string{
-		"15:04",      // 14:30
-		"3:04",       // 9:30
-		"下午3:04",    // 中文格式
-		"上午3:04",    // 中文格式
-		"3:04 PM",    // 英文格式
-		"3:04 AM",    // 英文格式
+		"15:04",   // 14:30
+		"3:04",    // 9:30
+		"下午3:04",  // 中文格式
+		"上午3:04",  // 中文格式
+		"3:04 PM", // 英文格式
+		"3:04 AM", // 英文格式
 	}
 
 	for _, format := range timeFormats {
@@ -390,11 +692,8 @@ func (p *ResponseParserImpl) getFirstMeaningfulLine(text string) string {
 		line = strings.TrimSpace(line)
 		if line != "" {
 			// 截断过长的行
-			if len(line) > 100 {
-				line = line[:100] + "..."
-			}
-			return line
+			return truncateRunes(line, 103)
 		}
 	}
 	return "未知任务"
-}
\ No newline at end of file
+}
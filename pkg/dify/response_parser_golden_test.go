@@ -0,0 +1,59 @@
+package dify
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 使用 go test ./pkg/dify -run Golden -update 重新生成期望结果
+var updateGolden = flag.Bool("update", false, "update golden files in testdata/parser")
+
+// goldenDefaults 固定时钟，保证相对日期和缺省日期时间可重复
+func goldenDefaults() ReminderDefaults {
+	defaults := DefaultReminderDefaults()
+	defaults.Now = func() time.Time {
+		return time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	}
+	return defaults
+}
+
+func TestResponseParser_Golden(t *testing.T) {
+	samples, err := filepath.Glob(filepath.Join("testdata", "parser", "*.txt"))
+	require.NoError(t, err)
+	require.NotEmpty(t, samples)
+
+	parser := NewResponseParserWithDefaults(goldenDefaults())
+
+	for _, sample := range samples {
+		name := strings.TrimSuffix(filepath.Base(sample), ".txt")
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(sample)
+			require.NoError(t, err)
+
+			reminder, err := parser.ParseReminder(context.Background(), string(input))
+			require.NoError(t, err)
+
+			actual, err := json.MarshalIndent(reminder, "", "  ")
+			require.NoError(t, err)
+			actual = append(actual, '\n')
+
+			goldenPath := strings.TrimSuffix(sample, ".txt") + ".golden.json"
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(goldenPath, actual, 0644))
+			}
+
+			expected, err := os.ReadFile(goldenPath)
+			require.NoError(t, err, "缺少期望结果，使用 -update 生成")
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package dify
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/allanpk716/to_icalendar/pkg/models"
@@ -22,7 +24,7 @@ func TestResponseParser_ParseReminderResponse_ValidJSON(t *testing.T) {
 		"confidence": 0.9
 	}`
 
-	result, err := parser.ParseReminderResponse(jsonResponse)
+	result, err := parser.ParseReminderResponse(context.Background(), jsonResponse)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, "团队会议", result.Title)
@@ -49,7 +51,7 @@ func TestResponseParser_ParseReminderResponse_JSONInText(t *testing.T) {
 	}
 	以上是识别的任务信息。`
 
-	result, err := parser.ParseReminderResponse(textWithJSON)
+	result, err := parser.ParseReminderResponse(context.Background(), textWithJSON)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, "代码评审", result.Title)
@@ -66,7 +68,7 @@ func TestResponseParser_ParseReminderResponse_OnlyText(t *testing.T) {
 	textResponse := `会议主题：团队讨论
 	时间：2025-11-14 15:00`
 
-	result, err := parser.ParseReminderResponse(textResponse)
+	result, err := parser.ParseReminderResponse(context.Background(), textResponse)
 	if err != nil {
 		t.Logf("文本解析失败（这是预期的，因为当前的文本解析功能有限）: %v", err)
 		t.SkipNow() // 跳过这个测试，因为当前文本解析功能有限
@@ -82,13 +84,13 @@ func TestResponseParser_ParseReminderResponse_EmptyResponse(t *testing.T) {
 	parser := NewResponseParser()
 
 	// 测试空响应
-	result, err := parser.ParseReminderResponse("")
+	result, err := parser.ParseReminderResponse(context.Background(), "")
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "empty response")
 
 	// 测试只有空白字符的响应
-	result, err = parser.ParseReminderResponse("   \n\t  ")
+	result, err = parser.ParseReminderResponse(context.Background(), "   \n\t  ")
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "empty response")
@@ -105,7 +107,7 @@ func TestResponseParser_ParseReminderResponse_InvalidJSON(t *testing.T) {
 		"invalid":  // 这里缺少值
 	}`
 
-	result, err := parser.ParseReminderResponse(invalidJSON)
+	result, err := parser.ParseReminderResponse(context.Background(), invalidJSON)
 	// 应该回退到文本解析
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -319,4 +321,54 @@ func TestResponseParser_getFirstMeaningfulLine(t *testing.T) {
 			assert.Equal(t, test.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestBuildReminder_DateOnlyUsesDefaultTime(t *testing.T) {
+	defaults := DefaultReminderDefaults()
+	defaults.Now = func() time.Time { return time.Date(2025, 3, 10, 16, 42, 0, 0, time.UTC) }
+	defaults.Location = time.UTC
+
+	// 只有日期时使用默认时间
+	defaults.Time = "09:00"
+	reminder := BuildReminder(context.Background(), &models.ParsedTaskInfo{Title: "提交周报", Date: "2025-03-14"}, defaults)
+	assert.Equal(t, "2025-03-14", reminder.Date)
+	assert.Equal(t, "09:00", reminder.Time)
+
+	// 日期和时间都没有时仍然使用当前时间
+	reminder = BuildReminder(context.Background(), &models.ParsedTaskInfo{Title: "整理桌面"}, defaults)
+	assert.Equal(t, "2025-03-10", reminder.Date)
+	assert.Equal(t, "16:42", reminder.Time)
+}
+
+func TestResponseParser_ParseTaskInfo_TimeRangeKeepsEnd(t *testing.T) {
+	parser := NewResponseParserWithDefaults(DefaultReminderDefaults())
+
+	tests := []struct {
+		name      string
+		input     string
+		startTime string
+		endTime   string
+	}{
+		{"带空格", `{"title": "会议", "date": "2025-11-15", "time": "14:30 - 16:30"}`, "14:30", "16:30"},
+		{"波浪号", `{"title": "会议", "date": "2025-11-15", "time": "14:30~16:30"}`, "14:30", "16:30"},
+		{"中文分隔", `{"title": "会议", "date": "2025-11-15", "time": "09:00到10:30"}`, "09:00", "10:30"},
+		{"包含文本", "会议时间：2025-11-15 14:30 - 16:30", "14:30", "16:30"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := parser.ParseTaskInfo(context.Background(), test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.startTime, result.Time)
+			assert.Equal(t, test.endTime, result.EndTime)
+		})
+	}
+
+	// 不校验必需字段，缺少日期时间时仍返回结果
+	result, err := parser.ParseTaskInfo(context.Background(), "购买生活用品")
+	assert.NoError(t, err)
+	assert.Equal(t, "购买生活用品", result.Title)
+	assert.Empty(t, result.Date)
+
+	_, err = parser.ParseTaskInfo(context.Background(), "  ")
+	assert.Error(t, err)
+}
@@ -3,11 +3,11 @@ package dify
 import (
 	"context"
 	"fmt"
-	"log"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/allanpk716/to_icalendar/pkg/logger"
 	"github.com/allanpk716/to_icalendar/pkg/models"
 )
 
@@ -69,7 +69,8 @@ func (p *ScreenshotProcessorImpl) ProcessScreenshot(ctx context.Context, screens
 	startTime := time.Now()
 	requestID := generateRequestID()
 
-	log.Printf("[RequestID: %s] 开始处理截图，文件: %s, 大小: %d bytes",
+	log := logger.FromContext(ctx)
+	log.Infof("[RequestID: %s] 开始处理截图，文件: %s, 大小: %d bytes",
 		requestID, screenshot.FileName, len(screenshot.Data))
 
 	// 1. 验证输入
@@ -88,34 +89,25 @@ func (p *ScreenshotProcessorImpl) ProcessScreenshot(ctx context.Context, screens
 	if responseText == "" && difyResponse.Data != nil && difyResponse.Data.Outputs != nil {
 		// 从工作流响应中获取文本
 		responseText = difyResponse.Data.Outputs.Text
-		log.Printf("[ScreenshotProcessor] 从工作流响应获取文本，长度: %d", len(responseText))
+		log.Debugf("[ScreenshotProcessor] 从工作流响应获取文本，长度: %d", len(responseText))
 	}
 
 	if responseText == "" {
 		return nil, fmt.Errorf("empty response from Dify")
 	}
 
-	log.Printf("[ScreenshotProcessor] Dify响应文本: %s", responseText)
-	log.Printf("[ScreenshotProcessor] 响应文本长度: %d", len(responseText))
+	log.Debugf("[ScreenshotProcessor] 响应文本长度: %d", len(responseText))
 
-	// 输出原始响应用于调试
-	log.Printf("[ScreenshotProcessor] 原始响应（前500字符）: %s", func() string {
-		if len(responseText) > 500 {
-			return responseText[:500] + "..."
-		}
-		return responseText
-	}())
-
-	parsedInfo, err := p.parser.ParseReminderResponse(responseText)
+	parsedInfo, err := p.parser.ParseReminderResponse(ctx, responseText)
 	if err != nil {
 		return nil, fmt.Errorf("response parsing failed: %w", err)
 	}
 
 	// 4. 转换为Reminder对象
-	reminder := p.convertToReminder(parsedInfo, screenshot)
+	reminder := p.convertToReminder(ctx, parsedInfo, screenshot)
 
 	processingTime := time.Since(startTime)
-	log.Printf("[RequestID: %s] 处理完成，耗时: %v", requestID, processingTime)
+	log.Infof("[RequestID: %s] 处理完成，耗时: %v", requestID, processingTime)
 
 	return reminder, nil
 }
@@ -161,36 +153,8 @@ func (p *ScreenshotProcessorImpl) GetProcessorInfo() *ProcessorInfo {
 }
 
 // 私有辅助方法
-func (p *ScreenshotProcessorImpl) convertToReminder(info *models.ParsedTaskInfo, screenshot *ScreenshotInput) *models.Reminder {
-	reminder := &models.Reminder{
-		Title:        info.Title,
-		Description:  info.Description,
-		Date:         info.Date,
-		Time:         info.Time,
-		RemindBefore: info.RemindBefore,
-		List:         info.List,
-	}
-
-	// 设置默认值
-	if reminder.RemindBefore == "" {
-		reminder.RemindBefore = "15m"
-	}
-
-	if reminder.List == "" {
-		reminder.List = "Default"
-	}
-
-	// 转换优先级
-	switch info.Priority {
-	case "high", "高", "紧急":
-		reminder.Priority = models.PriorityHigh
-	case "low", "低", "一般":
-		reminder.Priority = models.PriorityLow
-	default:
-		reminder.Priority = models.PriorityMedium
-	}
-
-	return reminder
+func (p *ScreenshotProcessorImpl) convertToReminder(ctx context.Context, info *models.ParsedTaskInfo, screenshot *ScreenshotInput) *models.Reminder {
+	return BuildReminder(ctx, info, DefaultReminderDefaults())
 }
 
 func (p *ScreenshotProcessorImpl) isSupportedFormat(format string) bool {
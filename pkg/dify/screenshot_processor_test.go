@@ -28,7 +28,7 @@ type MockResponseParser struct {
 	mock.Mock
 }
 
-func (m *MockResponseParser) ParseReminderResponse(response string) (*models.ParsedTaskInfo, error) {
+func (m *MockResponseParser) ParseReminderResponse(ctx context.Context, response string) (*models.ParsedTaskInfo, error) {
 	args := m.Called(response)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,6 +36,14 @@ func (m *MockResponseParser) ParseReminderResponse(response string) (*models.Par
 	return args.Get(0).(*models.ParsedTaskInfo), args.Error(1)
 }
 
+func (m *MockResponseParser) ParseReminder(ctx context.Context, response string) (*models.Reminder, error) {
+	args := m.Called(response)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reminder), args.Error(1)
+}
+
 func TestScreenshotProcessor_ProcessScreenshot_Success(t *testing.T) {
 	// 准备测试数据
 	config := &models.DifyConfig{
@@ -75,9 +75,16 @@ const TaskExtractionPrompt = `请分析以下内容并提取任务信息。如
   "description": "任务描述（可选）",
   "date": "YYYY-MM-DD",
   "time": "HH:MM",
-  "remind_before": "15m（可选，默认15分钟）",
+  "end_time": "HH:MM（可选，有结束时间时填写）",
+  "kind": "task/event/both（可选，会议、约会等有固定时间段的安排为 event）",
+  "location": "地点（可选）",
+  "attendees": ["参会人姓名或邮箱（可选）"],
+  "remind_before": "15m（可选，默认15分钟；多个提醒用逗号分隔，如 1d, 15m）",
   "priority": "low/medium/high（可选，默认medium）",
   "list": "任务列表名称（可选，默认Default）",
+  "checklist": ["子步骤1", "子步骤2"],
+  "categories": ["分类标签（可选）"],
+  "links": [{"url": "https://example.com", "title": "链接标题"}],
   "confidence": 0.95
 }
 
@@ -88,7 +95,10 @@ const TaskExtractionPrompt = `请分析以下内容并提取任务信息。如
   "summary": "内容摘要"
 }
 
-请确保日期时间格式准确，优先级使用明确的词汇。`
+请确保日期时间格式准确，优先级使用明确的词汇。
+如果内容包含多个步骤或清单项，请将每一步作为 checklist 中的一项；内容中出现的网址请放入 links。
+没有子步骤、分类或链接时，对应字段返回空数组。
+需要完成的事项 kind 为 task；会议、约会等需要出现在日历上的安排 kind 为 event；既要占用日历又有后续待办时为 both。`
 
 // ImageAnalysisPrompt defines the prompt for image analysis
 const ImageAnalysisPrompt = `请分析这张图片中的文字内容。如果是任务相关的截图（如会议通知、待办事项、日历事件等），请提取任务信息并按照以下JSON格式返回：
@@ -97,9 +107,16 @@ const ImageAnalysisPrompt = `请分析这张图片中的文字内容。如果是
   "description": "详细描述",
   "date": "YYYY-MM-DD",
   "time": "HH:MM",
-  "remind_before": "15m（可选）",
+  "end_time": "HH:MM（可选，有结束时间时填写）",
+  "kind": "task/event/both（可选，会议通知、日程邀请为 event）",
+  "location": "地点（可选）",
+  "attendees": ["参会人姓名或邮箱（可选）"],
+  "remind_before": "15m（可选，多个提醒用逗号分隔）",
   "priority": "low/medium/high（可选）",
   "list": "任务列表名称（可选）",
+  "checklist": ["子步骤1", "子步骤2"],
+  "categories": ["分类标签（可选）"],
+  "links": [{"url": "https://example.com", "title": "链接标题"}],
   "confidence": 0.95,
   "original_text": "图片中的原始文字"
 }
@@ -112,7 +129,10 @@ const ImageAnalysisPrompt = `请分析这张图片中的文字内容。如果是
   "original_text": "图片中的文字内容"
 }
 
-请仔细识别图片中的所有文字，包括手写文字，并准确提取时间信息。`
+请仔细识别图片中的所有文字，包括手写文字，并准确提取时间信息。
+如果截图是清单或包含多个步骤，请将每一项作为 checklist 中的一项；图片中出现的网址请放入 links。
+没有子步骤、分类或链接时，对应字段返回空数组。
+会议通知、日程邀请等 kind 为 event，并填写 end_time、location 和 attendees；普通待办 kind 为 task。`
 
 // ProcessingOptions defines options for content processing
 type ProcessingOptions struct {
@@ -124,6 +144,7 @@ type ProcessingOptions struct {
 	DefaultList     string        `json:"default_list"`      // 默认任务列表
 	DefaultPriority string        `json:"default_priority"`  // 默认优先级
 	DefaultRemindBefore string    `json:"default_remind_before"` // 默认提前提醒时间
+	DefaultTime     string        `json:"default_time"`      // 只有日期时使用的时间
 }
 
 // DefaultProcessingOptions returns default processing options
@@ -176,4 +197,5 @@ type ProcessingResponse struct {
 	RequestID      string                   `json:"request_id"`     // 请求ID
 	Timestamp      time.Time                `json:"timestamp"`      // 时间戳
 	ErrorMessage   string                   `json:"error_message,omitempty"` // 错误信息
+	Workflow       *models.DifyWorkflowData `json:"workflow,omitempty"`      // Dify 工作流数据（耗时、token 等）
 }
\ No newline at end of file
@@ -0,0 +1,178 @@
+// Package ical 将解析后的提醒导出为 iCalendar (RFC 5545) 日历数据。
+// 每条提醒对应一个 VEVENT，结束时间写入 DTEND，每个提前提醒写入一个 VALARM。
+package ical
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+const (
+	// DefaultProdID 默认的 PRODID
+	DefaultProdID = "-//allanpk716//to_icalendar//CN"
+
+	utcLayout   = "20060102T150405Z"
+	maxLineSize = 75 // RFC 5545 建议的最大行长度（字节）
+)
+
+// Encoder iCalendar 编码器
+type Encoder struct {
+	ProdID string           // 日历的 PRODID，为空时使用 DefaultProdID
+	Now    func() time.Time // 用于 DTSTAMP 的当前时间，为空时使用 time.Now
+}
+
+// NewEncoder 创建使用默认设置的编码器
+func NewEncoder() *Encoder {
+	return &Encoder{ProdID: DefaultProdID, Now: time.Now}
+}
+
+// Encode 将提醒编码为一个 VCALENDAR 写入 w
+func (e *Encoder) Encode(w io.Writer, reminders ...*models.ParsedReminder) error {
+	prodID := e.ProdID
+	if prodID == "" {
+		prodID = DefaultProdID
+	}
+	now := time.Now
+	if e.Now != nil {
+		now = e.Now
+	}
+	stamp := now().UTC().Format(utcLayout)
+
+	lw := &lineWriter{w: w}
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + prodID)
+	lw.line("CALSCALE:GREGORIAN")
+	for _, reminder := range reminders {
+		writeEvent(lw, reminder, stamp)
+	}
+	lw.line("END:VCALENDAR")
+	return lw.err
+}
+
+// writeEvent 写入单个 VEVENT
+func writeEvent(lw *lineWriter, reminder *models.ParsedReminder, stamp string) {
+	lw.line("BEGIN:VEVENT")
+	lw.line("UID:" + eventUID(reminder))
+	lw.line("DTSTAMP:" + stamp)
+	lw.line("DTSTART:" + reminder.DueTime.UTC().Format(utcLayout))
+	if reminder.HasEnd() {
+		lw.line("DTEND:" + reminder.EndTime.UTC().Format(utcLayout))
+	}
+	lw.line("SUMMARY:" + escapeText(reminder.Original.Title))
+	if reminder.Description != "" {
+		lw.line("DESCRIPTION:" + escapeText(reminder.Description))
+	}
+	if reminder.Original.Location != "" {
+		lw.line("LOCATION:" + escapeText(reminder.Original.Location))
+	}
+	if len(reminder.Original.Categories) > 0 {
+		categories := make([]string, 0, len(reminder.Original.Categories))
+		for _, category := range reminder.Original.Categories {
+			categories = append(categories, escapeText(category))
+		}
+		lw.line("CATEGORIES:" + strings.Join(categories, ","))
+	}
+	if reminder.PriorityValue > 0 {
+		lw.line(fmt.Sprintf("PRIORITY:%d", reminder.PriorityValue))
+	}
+
+	// 每个提醒时间对应一个 VALARM
+	alarms := reminder.Alarms
+	if len(alarms) == 0 && !reminder.AlarmTime.IsZero() {
+		alarms = []time.Time{reminder.AlarmTime}
+	}
+	for _, alarm := range alarms {
+		lw.line("BEGIN:VALARM")
+		lw.line("ACTION:DISPLAY")
+		lw.line("DESCRIPTION:" + escapeText(reminder.Original.Title))
+		lw.line("TRIGGER:" + formatTrigger(alarm.Sub(reminder.DueTime)))
+		lw.line("END:VALARM")
+	}
+	lw.line("END:VEVENT")
+}
+
+// eventUID 根据标题和开始时间生成稳定的 UID，重复导出同一提醒时 UID 不变
+func eventUID(reminder *models.ParsedReminder) string {
+	sum := sha1.Sum([]byte(reminder.Original.Title + "|" + reminder.DueTime.UTC().Format(utcLayout)))
+	return hex.EncodeToString(sum[:10]) + "@to_icalendar"
+}
+
+// formatTrigger 将相对开始时间的偏移格式化为 RFC 5545 DURATION，如 -PT15M、-P1D
+func formatTrigger(offset time.Duration) string {
+	sign := ""
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	days := offset / (24 * time.Hour)
+	offset -= days * 24 * time.Hour
+	hours := offset / time.Hour
+	offset -= hours * time.Hour
+	minutes := offset / time.Minute
+
+	var b strings.Builder
+	b.WriteString(sign + "P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 || hours == 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+	}
+	return b.String()
+}
+
+// escapeText 按 RFC 5545 转义 TEXT 值
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// lineWriter 以 CRLF 结尾写入内容行，超长的行按字节折行且不拆分 UTF-8 字符
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) line(content string) {
+	if lw.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	limit := maxLineSize
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// 续行以一个空格开头，占用一个字节
+		limit = maxLineSize - 1
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
@@ -0,0 +1,122 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+func newTestEncoder() *Encoder {
+	return &Encoder{Now: func() time.Time { return time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC) }}
+}
+
+func parse(t *testing.T, reminder models.Reminder) *models.ParsedReminder {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	parsed, err := models.ParseReminderTime(reminder, loc)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	parsed := parse(t, models.Reminder{
+		Title:        "架构评审会",
+		Description:  "评审方案; 第二轮",
+		Date:         "2025-03-11",
+		Time:         "14:30 - 16:30",
+		RemindBefore: "15m",
+		Priority:     models.PriorityHigh,
+		Categories:   []string{"工作", "会议"},
+		Location:     "3号会议室",
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, newTestEncoder().Encode(&buf, parsed))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTAMP:20250310T090000Z\r\n")
+	assert.Contains(t, out, "DTSTART:20250311T063000Z\r\n")
+	assert.Contains(t, out, "DTEND:20250311T083000Z\r\n")
+	assert.Contains(t, out, "SUMMARY:架构评审会\r\n")
+	assert.Contains(t, out, `DESCRIPTION:评审方案\; 第二轮`+"\r\n")
+	assert.Contains(t, out, "LOCATION:3号会议室\r\n")
+	assert.Contains(t, out, "CATEGORIES:工作,会议\r\n")
+	assert.Contains(t, out, "PRIORITY:1\r\n")
+	assert.Contains(t, out, "TRIGGER:-PT15M\r\n")
+}
+
+func TestEncoder_MultiDayAndNoEnd(t *testing.T) {
+	trip := parse(t, models.Reminder{Title: "深圳出差", Date: "2025-12-03", Time: "09:00", EndDate: "2025-12-05", EndTime: "18:00"})
+	call := parse(t, models.Reminder{Title: "电话", Date: "2025-12-03", Time: "10:00", RemindBefore: "1d"})
+
+	var buf bytes.Buffer
+	require.NoError(t, newTestEncoder().Encode(&buf, trip, call))
+	out := buf.String()
+
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+	assert.Contains(t, out, "DTEND:20251205T100000Z\r\n")
+	assert.Equal(t, 1, strings.Count(out, "DTEND:"), "没有结束时间的提醒不输出 DTEND")
+	assert.Contains(t, out, "TRIGGER:-P1D\r\n")
+}
+
+func TestFormatTrigger(t *testing.T) {
+	tests := map[time.Duration]string{
+		-15 * time.Minute:             "-PT15M",
+		-90 * time.Minute:             "-PT1H30M",
+		-2 * time.Hour:                "-PT2H",
+		-24 * time.Hour:               "-P1D",
+		-(26*time.Hour + time.Minute): "-P1DT2H1M",
+		0:                             "PT0M",
+	}
+	for offset, expected := range tests {
+		assert.Equal(t, expected, formatTrigger(offset), offset.String())
+	}
+}
+
+func TestLineWriter_Folding(t *testing.T) {
+	var buf bytes.Buffer
+	lw := &lineWriter{w: &buf}
+	lw.line("DESCRIPTION:" + strings.Repeat("中文", 40))
+	require.NoError(t, lw.err)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	require.Greater(t, len(lines), 1)
+	var joined strings.Builder
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), maxLineSize)
+		if i > 0 {
+			require.True(t, strings.HasPrefix(line, " "))
+			line = line[1:]
+		}
+		joined.WriteString(line)
+	}
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("中文", 40), joined.String())
+}
+
+func TestEncoder_MultipleAlarms(t *testing.T) {
+	parsed := parse(t, models.Reminder{
+		Title:        "圣诞晚餐",
+		Date:         "2024-12-25",
+		Time:         "19:00",
+		RemindBefore: "1w, 1d, 2024-12-24 20:00, 1h30m",
+	})
+	require.Len(t, parsed.Alarms, 4)
+
+	var buf bytes.Buffer
+	require.NoError(t, newTestEncoder().Encode(&buf, parsed))
+	out := buf.String()
+
+	assert.Equal(t, 4, strings.Count(out, "BEGIN:VALARM"))
+	assert.Contains(t, out, "TRIGGER:-P7D\r\n")
+	assert.Contains(t, out, "TRIGGER:-P1D\r\n")
+	assert.Contains(t, out, "TRIGGER:-PT23H\r\n")
+	assert.Contains(t, out, "TRIGGER:-PT1H30M\r\n")
+}
@@ -11,8 +11,14 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// CurrentConfigVersion image_processing.json 当前的配置格式版本
+// 版本 1：引入 config_version，补全缓存相关字段
+const CurrentConfigVersion = 1
+
 // ImageProcessingConfig 图片处理配置
 type ImageProcessingConfig struct {
+	// 配置格式版本
+	ConfigVersion int `json:"config_version"`
 	// 标准化配置
 	Normalization *NormalizationConfig `json:"normalization"`
 	// 是否启用标准化
@@ -32,6 +38,7 @@ type ImageProcessingConfig struct {
 // DefaultImageProcessingConfig 默认图片处理配置
 func DefaultImageProcessingConfig() *ImageProcessingConfig {
 	return &ImageProcessingConfig{
+		ConfigVersion:       CurrentConfigVersion,
 		Normalization:       DefaultNormalizationConfig(),
 		EnableNormalization: true,
 		DebugMode:           false,
@@ -1,6 +1,7 @@
 package image
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
@@ -130,6 +131,48 @@ func (n *ImageNormalizer) NormalizeFile(inputPath, outputPath string) error {
 	return n.encodeImage(normalizedImg, outputFile)
 }
 
+// NormalizeBytes 标准化内存中的图片数据，返回按输出格式编码后的数据
+func (n *ImageNormalizer) NormalizeBytes(data []byte) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	n.logger.Debugf("原始图片格式: %s, 尺寸: %dx%d", format, img.Bounds().Dx(), img.Bounds().Dy())
+
+	normalizedImg, err := n.NormalizeImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := n.encodeImage(normalizedImg, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// OutputContentType 获取标准化输出格式对应的 MIME 类型
+func (n *ImageNormalizer) OutputContentType() string {
+	switch strings.ToLower(n.config.OutputFormat) {
+	case "jpg", "jpeg":
+		return "image/jpeg"
+	default:
+		return "image/png"
+	}
+}
+
+// OutputExtension 获取标准化输出格式对应的文件扩展名
+func (n *ImageNormalizer) OutputExtension() string {
+	switch strings.ToLower(n.config.OutputFormat) {
+	case "jpg", "jpeg":
+		return ".jpg"
+	default:
+		return ".png"
+	}
+}
+
 // resizeImage 调整图片尺寸
 func (n *ImageNormalizer) resizeImage(img image.Image) image.Image {
 	bounds := img.Bounds()
@@ -0,0 +1,31 @@
+package logger
+
+import "context"
+
+// TaskIDKey 结构化日志中任务 ID（关联 ID）的字段名
+const TaskIDKey = "task_id"
+
+type taskIDContextKey struct{}
+
+// ContextWithTaskID 在 ctx 中记录任务 ID，之后通过该 ctx 输出的日志都会带上 task_id 字段
+// 一次剪贴板处理从读取剪贴板、调用 Dify、解析到创建 Graph 任务都使用同一个 ID
+func ContextWithTaskID(ctx context.Context, taskID string) context.Context {
+	if taskID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, taskIDContextKey{}, taskID)
+}
+
+// TaskIDFromContext 获取 ctx 中的任务 ID，没有时返回空字符串
+func TaskIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	taskID, _ := ctx.Value(taskIDContextKey{}).(string)
+	return taskID
+}
+
+// FromContext 获取附带 ctx 中任务 ID 的全局日志器
+func FromContext(ctx context.Context) Logger {
+	return GetInstance().WithContext(ctx)
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/WQGroup/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// LevelFatal 致命错误级别
+const LevelFatal = slog.Level(12)
+
+var (
+	// jsonOutput 是否以 JSON 格式输出，由 Initialize 根据配置设置
+	jsonOutput atomic.Bool
+	// textFormatter WQGroup/logger 原本的文本格式，从 json 切回 text 时恢复
+	textFormatter logrus.Formatter
+)
+
+// logrusHandler 将 slog 记录交给 WQGroup/logger（logrus）输出，沿用其控制台输出和文件轮转
+// 输出前会隐藏消息和字段中的敏感信息，并从 ctx 中补充任务 ID
+type logrusHandler struct {
+	fields logrus.Fields // With 添加的字段
+	prefix string        // WithGroup 产生的字段名前缀
+}
+
+// Enabled 按 logrus 的级别过滤
+func (h *logrusHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return logger.GetLogger().IsLevelEnabled(toLogrusLevel(level))
+}
+
+// Handle 输出一条日志
+func (h *logrusHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(logrus.Fields, len(h.fields)+record.NumAttrs()+1)
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addField(fields, h.prefix, attr)
+		return true
+	})
+	if _, exists := fields[TaskIDKey]; !exists {
+		if taskID := TaskIDFromContext(ctx); taskID != "" {
+			fields[TaskIDKey] = taskID
+		}
+	}
+
+	message := Redact(record.Message)
+	entry := logrus.NewEntry(logger.GetLogger()).WithTime(record.Time)
+	if jsonOutput.Load() {
+		entry = entry.WithFields(fields)
+	} else if len(fields) > 0 {
+		// 文本格式下字段以 key=value 附加在消息后
+		message += " " + formatFields(fields)
+	}
+	entry.Log(toLogrusLevel(record.Level), message)
+	return nil
+}
+
+// WithAttrs 返回附带字段的处理器
+func (h *logrusHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(logrus.Fields, len(h.fields)+len(attrs))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	for _, attr := range attrs {
+		addField(fields, h.prefix, attr)
+	}
+	return &logrusHandler{fields: fields, prefix: h.prefix}
+}
+
+// WithGroup 返回字段名带分组前缀的处理器
+func (h *logrusHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &logrusHandler{fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// addField 将 slog 字段转换为 logrus 字段，分组展开为 group.key，敏感字段的值被隐藏
+func addField(fields logrus.Fields, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			addField(fields, groupPrefix, groupAttr)
+		}
+		return
+	}
+
+	key := prefix + attr.Key
+	if isSensitiveKey(attr.Key) {
+		fields[key] = redacted
+		return
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		fields[key] = Redact(attr.Value.String())
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			fields[key] = Redact(err.Error())
+			return
+		}
+		fields[key] = attr.Value.Any()
+	default:
+		fields[key] = attr.Value.Any()
+	}
+}
+
+// formatFields 按字段名排序输出 key=value，含空白或引号的值会加引号
+func formatFields(fields logrus.Fields) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := fmt.Sprint(fields[key])
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// setJSONOutput 切换 WQGroup/logger 的输出格式
+func setJSONOutput(enabled bool) {
+	base := logger.GetLogger()
+	if _, isJSON := base.Formatter.(*logrus.JSONFormatter); !isJSON {
+		textFormatter = base.Formatter
+	}
+
+	if enabled {
+		base.SetFormatter(&logrus.JSONFormatter{})
+	} else if textFormatter != nil {
+		base.SetFormatter(textFormatter)
+	}
+	jsonOutput.Store(enabled)
+}
+
+// toLogrusLevel 将 slog 级别转换为 logrus 级别
+func toLogrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= LevelFatal:
+		return logrus.FatalLevel
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	default:
+		return logrus.DebugLevel
+	}
+}
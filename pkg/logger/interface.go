@@ -1,6 +1,10 @@
 package logger
 
-import "github.com/allanpk716/to_icalendar/pkg/models"
+import (
+	"context"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
 
 // Logger 日志接口
 type Logger interface {
@@ -17,4 +21,8 @@ type Logger interface {
 	IsDebugEnabled() bool
 	UpdateConfig(config *models.LoggingConfig) error
 	GetLogFilePath() string
-}
\ No newline at end of file
+	// With 返回附带结构化字段的日志器，args 为交替的键和值（与 slog.Logger.With 相同）
+	With(args ...interface{}) Logger
+	// WithContext 返回附带 ctx 中任务 ID 的日志器，ctx 中没有任务 ID 时返回自身
+	WithContext(ctx context.Context) Logger
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/WQGroup/logger"
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureOutput 将 WQGroup/logger 的输出重定向到缓冲区，测试结束后恢复
+func captureOutput(t *testing.T, jsonFormat bool) *bytes.Buffer {
+	t.Helper()
+	base := logger.GetLogger()
+	out, formatter, level := base.Out, base.Formatter, base.Level
+
+	var buf bytes.Buffer
+	base.SetOutput(&buf)
+	base.SetLevel(logrus.DebugLevel)
+	base.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+	setJSONOutput(jsonFormat)
+
+	t.Cleanup(func() {
+		setJSONOutput(false)
+		base.SetOutput(out)
+		base.SetFormatter(formatter)
+		base.SetLevel(level)
+	})
+	return &buf
+}
+
+func newTestManager() *Manager {
+	return NewManager(&models.LoggingConfig{Level: "debug", ConsoleOutput: true})
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Authorization: Bearer abc.def-123", "Authorization: Bearer [REDACTED]"},
+		{"api_key=secret123&user=1", "api_key=[REDACTED]&user=1"},
+		{`{"access_token":"eyJxx","expires_in":3600}`, `{"access_token":"[REDACTED]","expires_in":3600}`},
+		{"client_secret: abc", "client_secret: [REDACTED]"},
+		{"使用密钥 app-AbCdEfGhIjKlMnOpQrSt 调用", "使用密钥 app-[REDACTED] 调用"},
+		{"token eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiIxMjM0NTY3ODkwIn0.sig", "token [REDACTED]"},
+		{"dify_tokens: 120, 标题: 周会", "dify_tokens: 120, 标题: 周会"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Redact(tt.input), "Redact(%q)", tt.input)
+	}
+}
+
+func TestManager_TextFormatAppendsFields(t *testing.T) {
+	buf := captureOutput(t, false)
+	ctx := ContextWithTaskID(context.Background(), "2026-01-02_150405_abcd1234")
+
+	newTestManager().With("source", "watch").WithContext(ctx).Infof("创建任务: %s", "周会")
+
+	assert.Contains(t, buf.String(), "创建任务: 周会 source=watch task_id=2026-01-02_150405_abcd1234")
+}
+
+func TestManager_JSONFormatFieldsAndRedaction(t *testing.T) {
+	buf := captureOutput(t, true)
+	ctx := ContextWithTaskID(context.Background(), "task-1")
+
+	log := newTestManager().WithContext(ctx).With("api_key", "app-AbCdEfGhIjKlMnOpQrSt", "error", errors.New("Bearer abc123"))
+	log.Warnf("请求失败 password=hunter2")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &entry))
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "请求失败 password=[REDACTED]", entry["msg"])
+	assert.Equal(t, "task-1", entry[TaskIDKey])
+	assert.Equal(t, "[REDACTED]", entry["api_key"])
+	assert.Equal(t, "Bearer [REDACTED]", entry["error"])
+}
+
+func TestManager_WithContextWithoutTaskID(t *testing.T) {
+	m := newTestManager()
+	assert.Same(t, m, m.WithContext(context.Background()))
+	assert.Empty(t, TaskIDFromContext(ContextWithTaskID(context.Background(), "")))
+}
+
+func TestManager_StdLoggerAndLevels(t *testing.T) {
+	buf := captureOutput(t, false)
+	logger.GetLogger().SetLevel(logrus.InfoLevel)
+	m := newTestManager()
+
+	m.GetStdLogger().Printf("refresh_token=abc")
+	m.Debugf("不应输出")
+
+	output := buf.String()
+	assert.Contains(t, output, "refresh_token=[REDACTED]")
+	assert.NotContains(t, output, "不应输出")
+}
@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"log"
-	"os"
+	"log/slog"
 
 	"github.com/WQGroup/logger"
 	"github.com/sirupsen/logrus"
@@ -14,14 +16,17 @@ var (
 )
 
 // Manager 统一日志管理器
+// 日志经 slog 处理结构化字段、任务 ID 和敏感信息后，由 WQGroup/logger 输出到控制台和文件
 type Manager struct {
 	config *models.LoggingConfig
+	slog   *slog.Logger
 }
 
 // NewManager 创建新的日志管理器实例
 func NewManager(config *models.LoggingConfig) *Manager {
 	return &Manager{
 		config: config,
+		slog:   slog.New(&logrusHandler{}),
 	}
 }
 
@@ -59,79 +64,94 @@ func (m *Manager) Initialize() error {
 	// 应用设置
 	logger.SetLoggerSettings(settings)
 
+	// 设置输出格式
+	setJSONOutput(m.config.IsJSON())
+
+	// dify 等包直接使用标准库 log，让其输出也经过同一处理
+	slog.SetDefault(slog.New(&logrusHandler{}))
+
 	return nil
 }
 
 // Debug 调试级别日志
 func (m *Manager) Debug(args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Debug(args...)
-	}
+	m.output(slog.LevelDebug, fmt.Sprint(args...))
 }
 
 // Debugf 格式化调试日志
 func (m *Manager) Debugf(format string, args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Debugf(format, args...)
-	}
+	m.output(slog.LevelDebug, fmt.Sprintf(format, args...))
 }
 
 // Info 信息级别日志
 func (m *Manager) Info(args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Info(args...)
-	}
+	m.output(slog.LevelInfo, fmt.Sprint(args...))
 }
 
 // Infof 格式化信息日志
 func (m *Manager) Infof(format string, args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Infof(format, args...)
-	}
+	m.output(slog.LevelInfo, fmt.Sprintf(format, args...))
 }
 
 // Warn 警告级别日志
 func (m *Manager) Warn(args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Warn(args...)
-	}
+	m.output(slog.LevelWarn, fmt.Sprint(args...))
 }
 
 // Warnf 格式化警告日志
 func (m *Manager) Warnf(format string, args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Warnf(format, args...)
-	}
+	m.output(slog.LevelWarn, fmt.Sprintf(format, args...))
 }
 
 // Error 错误级别日志
 func (m *Manager) Error(args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Error(args...)
-	}
+	m.output(slog.LevelError, fmt.Sprint(args...))
 }
 
 // Errorf 格式化错误日志
 func (m *Manager) Errorf(format string, args ...interface{}) {
-	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Errorf(format, args...)
-	}
+	m.output(slog.LevelError, fmt.Sprintf(format, args...))
 }
 
 // Fatal 致命错误日志
 func (m *Manager) Fatal(args ...interface{}) {
 	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Fatal(args...)
+		m.output(LevelFatal, fmt.Sprint(args...))
+		logger.GetLogger().Exit(1)
 	}
 }
 
 // Fatalf 格式化致命错误日志
 func (m *Manager) Fatalf(format string, args ...interface{}) {
 	if m.config.FileOutput || m.config.ConsoleOutput {
-		logger.Fatalf(format, args...)
+		m.output(LevelFatal, fmt.Sprintf(format, args...))
+		logger.GetLogger().Exit(1)
+	}
+}
+
+// output 输出一条日志，未启用任何输出时忽略
+func (m *Manager) output(level slog.Level, message string) {
+	if m.config.FileOutput || m.config.ConsoleOutput {
+		m.slog.Log(context.Background(), level, message)
 	}
 }
 
+// With 返回附带结构化字段的日志器，args 为交替的键和值
+func (m *Manager) With(args ...interface{}) Logger {
+	return &Manager{
+		config: m.config,
+		slog:   m.slog.With(args...),
+	}
+}
+
+// WithContext 返回附带 ctx 中任务 ID 的日志器
+func (m *Manager) WithContext(ctx context.Context) Logger {
+	if taskID := TaskIDFromContext(ctx); taskID != "" {
+		return m.With(TaskIDKey, taskID)
+	}
+	return m
+}
+
 // IsDebugEnabled 检查是否启用了调试级别
 func (m *Manager) IsDebugEnabled() bool {
 	return m.config.Level == "debug"
@@ -207,6 +227,11 @@ func Fatalf(format string, args ...interface{}) {
 	GetInstance().Fatalf(format, args...)
 }
 
+// With 返回附带结构化字段的全局日志器
+func With(args ...interface{}) Logger {
+	return GetInstance().With(args...)
+}
+
 func Initialize(config *models.LoggingConfig) error {
 	instance = NewManager(config)
 	return instance.Initialize()
@@ -217,8 +242,7 @@ func GetLogger() *Manager {
 	return GetInstance()
 }
 
-// GetStdLogger 获取标准库 logger（为了兼容性）
+// GetStdLogger 获取标准库 logger（为了兼容性），输出以信息级别经过同一处理
 func (m *Manager) GetStdLogger() *log.Logger {
-	// 返回一个简单的标准 logger，实际日志会通过我们的管理器处理
-	return log.New(os.Stdout, "[to_icalendar] ", log.LstdFlags)
+	return slog.NewLogLogger(m.slog.Handler(), slog.LevelInfo)
 }
\ No newline at end of file
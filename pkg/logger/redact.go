@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redacted 替换敏感信息的占位符
+const redacted = "[REDACTED]"
+
+// sensitiveKeys 值需要整体隐藏的字段名（小写，- 视为 _）
+var sensitiveKeys = map[string]bool{
+	"api_key":       true,
+	"apikey":        true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"client_secret": true,
+	"secret":        true,
+	"password":      true,
+	"authorization": true,
+	"code_verifier": true,
+}
+
+// secretPatterns 消息文本中的敏感信息
+var secretPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	// Authorization: Bearer <token>
+	{regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`), "$1 " + redacted},
+	// api_key=xxx、"access_token": "xxx"、client_secret: xxx 等
+	{regexp.MustCompile(`(?i)("?\b(?:api[_-]?key|access[_-]?token|refresh[_-]?token|id[_-]?token|client[_-]?secret|password|code[_-]?verifier)"?\s*[:=]\s*"?)[^"\s,&}]+`), "${1}" + redacted},
+	// Dify 应用 API 密钥
+	{regexp.MustCompile(`\bapp-[A-Za-z0-9]{16,}`), "app-" + redacted},
+	// JWT（Microsoft Graph 访问令牌）
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*`), redacted},
+}
+
+// Redact 隐藏文本中的 API 密钥、令牌和密码
+func Redact(text string) string {
+	for _, pattern := range secretPatterns {
+		text = pattern.re.ReplaceAllString(text, pattern.replacement)
+	}
+	return text
+}
+
+// isSensitiveKey 字段名是否表示敏感信息
+func isSensitiveKey(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	return sensitiveKeys[key] || strings.HasSuffix(key, "_token") || strings.HasSuffix(key, "_secret")
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 直方图的桶上限（秒）
+var (
+	durationBuckets    = []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120}
+	difyLatencyBuckets = []float64{0.5, 1, 2, 5, 10, 20, 30, 60}
+)
+
+// histogram 累积直方图
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // 与 buckets 一一对应，不含 +Inf
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// observe 记录一个观测值
+func (h *histogram) observe(value float64) {
+	h.count++
+	h.sum += value
+	for i, upper := range h.buckets {
+		if value <= upper {
+			h.counts[i]++
+		}
+	}
+}
+
+// runKey 处理次数的标签
+type runKey struct {
+	source      string
+	contentType string
+	outcome     string
+}
+
+// Collector 进程内的处理指标，随每条 Run 累加，供 /metrics 端点输出
+// 与 Store 不同，Collector 不受 enable_cache_metrics 影响，进程重启后清零
+type Collector struct {
+	mutex        sync.Mutex
+	startedAt    time.Time
+	runs         map[runKey]uint64
+	failures     map[string]uint64
+	difyTokens   uint64
+	graphCalls   uint64
+	graphRetries uint64
+	duration     *histogram
+	difyLatency  *histogram
+}
+
+// NewCollector 创建处理指标收集器
+func NewCollector() *Collector {
+	return &Collector{
+		startedAt:   time.Now(),
+		runs:        make(map[runKey]uint64),
+		failures:    make(map[string]uint64),
+		duration:    newHistogram(durationBuckets),
+		difyLatency: newHistogram(difyLatencyBuckets),
+	}
+}
+
+// Observe 累加一条处理记录
+func (c *Collector) Observe(run Run) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.runs[runKey{source: run.Source, contentType: run.ContentType, outcome: run.Outcome}]++
+	if run.Outcome == OutcomeFailure && run.FailedStage != "" {
+		c.failures[run.FailedStage]++
+	}
+	if run.Outcome != OutcomeDuplicate {
+		c.duration.observe(float64(run.DurationMs) / 1000)
+	}
+	if run.DifyLatencyMs > 0 {
+		c.difyLatency.observe(float64(run.DifyLatencyMs) / 1000)
+	}
+	if run.DifyTokens > 0 {
+		c.difyTokens += uint64(run.DifyTokens)
+	}
+	if run.GraphCalls > 0 {
+		c.graphCalls += uint64(run.GraphCalls)
+	}
+	if run.GraphRetries > 0 {
+		c.graphRetries += uint64(run.GraphRetries)
+	}
+}
+
+// write 按 Prometheus 文本格式输出处理指标
+func (c *Collector) write(w *expositionWriter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	w.family("to_icalendar_start_time_seconds", "gauge", "Start time of the process since unix epoch in seconds.")
+	w.sample("to_icalendar_start_time_seconds", nil, float64(c.startedAt.Unix()))
+
+	w.family("to_icalendar_pipeline_runs_total", "counter", "Clipboard processing runs by source, content type and outcome.")
+	keys := make([]runKey, 0, len(c.runs))
+	for key := range c.runs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		if keys[i].contentType != keys[j].contentType {
+			return keys[i].contentType < keys[j].contentType
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+	for _, key := range keys {
+		w.sample("to_icalendar_pipeline_runs_total",
+			labels{"source", key.source, "content_type", key.contentType, "outcome", key.outcome}, float64(c.runs[key]))
+	}
+
+	w.family("to_icalendar_pipeline_failures_total", "counter", "Failed processing runs by pipeline stage.")
+	stages := make([]string, 0, len(c.failures))
+	for stage := range c.failures {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	for _, stage := range stages {
+		w.sample("to_icalendar_pipeline_failures_total", labels{"stage", stage}, float64(c.failures[stage]))
+	}
+
+	w.family("to_icalendar_pipeline_duration_seconds", "histogram", "End-to-end duration of processing runs.")
+	w.histogram("to_icalendar_pipeline_duration_seconds", c.duration)
+
+	w.family("to_icalendar_dify_latency_seconds", "histogram", "Dify workflow elapsed time.")
+	w.histogram("to_icalendar_dify_latency_seconds", c.difyLatency)
+
+	w.family("to_icalendar_dify_tokens_total", "counter", "Tokens consumed by Dify workflows.")
+	w.sample("to_icalendar_dify_tokens_total", nil, float64(c.difyTokens))
+
+	w.family("to_icalendar_graph_requests_total", "counter", "Microsoft Graph requests sent while processing runs.")
+	w.sample("to_icalendar_graph_requests_total", nil, float64(c.graphCalls))
+
+	w.family("to_icalendar_graph_retries_total", "counter", "Microsoft Graph retries while processing runs.")
+	w.sample("to_icalendar_graph_retries_total", nil, float64(c.graphRetries))
+}
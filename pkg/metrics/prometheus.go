@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/cache"
+	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
+)
+
+// ContentType Prometheus 文本格式的 Content-Type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// HealthChecker 剪贴板健康状态，clipboard.ClipboardHealthChecker 满足该接口
+type HealthChecker interface {
+	IsHealthy() bool
+	GetLastCheckTime() time.Time
+}
+
+// Sources /metrics 端点的数据来源，未设置的来源不输出对应指标
+type Sources struct {
+	Collector   *Collector                        // 处理计数和直方图
+	TokenStatus func() *microsofttodo.TokenStatus // Token 状态，返回 nil 表示不可用
+	Clipboard   HealthChecker                     // 剪贴板健康检查
+	CacheUsage  func() ([]cache.TypeUsage, error) // 各缓存类型的用量
+}
+
+// NewHandler 创建按 Prometheus 文本格式输出指标的 HTTP 处理器
+func NewHandler(sources Sources) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			rw.Header().Set("Allow", "GET, HEAD")
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		rw.Header().Set("Content-Type", ContentType)
+		w := &expositionWriter{w: bufio.NewWriter(rw)}
+		sources.write(w, time.Now())
+		w.w.Flush()
+	})
+}
+
+// write 输出所有来源的指标
+func (s Sources) write(w *expositionWriter, now time.Time) {
+	if s.Collector != nil {
+		s.Collector.write(w)
+	}
+
+	if s.TokenStatus != nil {
+		if status := s.TokenStatus(); status != nil {
+			writeTokenStatus(w, status, now)
+		}
+	}
+
+	if s.Clipboard != nil {
+		w.family("to_icalendar_clipboard_healthy", "gauge", "Whether the last clipboard health check succeeded.")
+		w.sample("to_icalendar_clipboard_healthy", nil, boolValue(s.Clipboard.IsHealthy()))
+		if lastCheck := s.Clipboard.GetLastCheckTime(); !lastCheck.IsZero() {
+			w.family("to_icalendar_clipboard_last_check_timestamp_seconds", "gauge", "Time of the last clipboard health check.")
+			w.sample("to_icalendar_clipboard_last_check_timestamp_seconds", nil, float64(lastCheck.Unix()))
+		}
+	}
+
+	if s.CacheUsage != nil {
+		usages, err := s.CacheUsage()
+		if err != nil {
+			w.comment(fmt.Sprintf("cache usage unavailable: %v", err))
+			return
+		}
+		writeCacheUsage(w, usages)
+	}
+}
+
+// writeTokenStatus 输出 Token 状态
+func writeTokenStatus(w *expositionWriter, status *microsofttodo.TokenStatus, now time.Time) {
+	w.family("to_icalendar_token_present", "gauge", "Whether a cached Microsoft Graph token exists.")
+	w.sample("to_icalendar_token_present", nil, boolValue(status.HasToken))
+
+	if status.HasToken {
+		w.family("to_icalendar_token_expires_in_seconds", "gauge", "Seconds until the access token expires (negative when expired).")
+		w.sample("to_icalendar_token_expires_in_seconds", nil, status.ExpiresAt.Sub(now).Round(time.Second).Seconds())
+	}
+
+	w.family("to_icalendar_token_needs_reauth", "gauge", "Whether interactive re-authentication is required.")
+	w.sample("to_icalendar_token_needs_reauth", nil, boolValue(status.NeedsReauth))
+
+	w.family("to_icalendar_token_refreshes_total", "counter", "Successful token refreshes since start.")
+	w.sample("to_icalendar_token_refreshes_total", nil, float64(status.RefreshCount))
+
+	if !status.LastRefreshAttempt.IsZero() {
+		w.family("to_icalendar_token_last_refresh_success", "gauge", "Whether the last token refresh attempt succeeded.")
+		w.sample("to_icalendar_token_last_refresh_success", nil, boolValue(status.LastRefreshError == ""))
+		w.family("to_icalendar_token_last_refresh_timestamp_seconds", "gauge", "Time of the last token refresh attempt.")
+		w.sample("to_icalendar_token_last_refresh_timestamp_seconds", nil, float64(status.LastRefreshAttempt.Unix()))
+	}
+}
+
+// writeCacheUsage 输出各缓存类型的用量和配额
+func writeCacheUsage(w *expositionWriter, usages []cache.TypeUsage) {
+	w.family("to_icalendar_cache_size_bytes", "gauge", "Size of each cache directory.")
+	for _, usage := range usages {
+		w.sample("to_icalendar_cache_size_bytes", labels{"type", string(usage.Type)}, float64(usage.SizeBytes))
+	}
+
+	w.family("to_icalendar_cache_files", "gauge", "Number of files in each cache directory.")
+	for _, usage := range usages {
+		w.sample("to_icalendar_cache_files", labels{"type", string(usage.Type)}, float64(usage.Files))
+	}
+
+	w.family("to_icalendar_cache_items", "gauge", "Number of top-level entries (files or task directories) in each cache directory.")
+	for _, usage := range usages {
+		w.sample("to_icalendar_cache_items", labels{"type", string(usage.Type)}, float64(usage.Items))
+	}
+
+	w.family("to_icalendar_cache_quota_bytes", "gauge", "Configured size quota of each cache type.")
+	for _, usage := range usages {
+		if usage.Quota.MaxBytes > 0 {
+			w.sample("to_icalendar_cache_quota_bytes", labels{"type", string(usage.Type)}, float64(usage.Quota.MaxBytes))
+		}
+	}
+}
+
+// labels 标签名和值交替排列
+type labels []string
+
+// expositionWriter Prometheus 文本格式输出
+type expositionWriter struct {
+	w *bufio.Writer
+}
+
+// family 输出指标的 HELP 和 TYPE
+func (e *expositionWriter) family(name, metricType, help string) {
+	fmt.Fprintf(e.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// comment 输出注释行
+func (e *expositionWriter) comment(text string) {
+	fmt.Fprintf(e.w, "# %s\n", strings.ReplaceAll(text, "\n", " "))
+}
+
+// sample 输出一个样本
+func (e *expositionWriter) sample(name string, l labels, value float64) {
+	e.w.WriteString(name)
+	if len(l) > 0 {
+		e.w.WriteByte('{')
+		for i := 0; i+1 < len(l); i += 2 {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			fmt.Fprintf(e.w, "%s=\"%s\"", l[i], escapeLabel(l[i+1]))
+		}
+		e.w.WriteByte('}')
+	}
+	e.w.WriteByte(' ')
+	e.w.WriteString(formatValue(value))
+	e.w.WriteByte('\n')
+}
+
+// histogram 输出直方图的各个桶、总和与计数
+func (e *expositionWriter) histogram(name string, h *histogram) {
+	for i, upper := range h.buckets {
+		e.sample(name+"_bucket", labels{"le", formatValue(upper)}, float64(h.counts[i]))
+	}
+	e.sample(name+"_bucket", labels{"le", "+Inf"}, float64(h.count))
+	e.sample(name+"_sum", nil, h.sum)
+	e.sample(name+"_count", nil, float64(h.count))
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatValue 格式化样本值
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// boolValue 将布尔值转换为 0 或 1
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
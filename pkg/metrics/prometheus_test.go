@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/cache"
+	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHealthChecker 固定结果的剪贴板健康检查
+type fakeHealthChecker struct {
+	healthy   bool
+	lastCheck time.Time
+}
+
+func (f fakeHealthChecker) IsHealthy() bool             { return f.healthy }
+func (f fakeHealthChecker) GetLastCheckTime() time.Time { return f.lastCheck }
+
+// scrape 请求 /metrics 并返回响应内容
+func scrape(t *testing.T, sources Sources) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	NewMux(sources).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestHandler_PipelineMetrics(t *testing.T) {
+	store := newTestStore(t, false, 7)
+	store.SetCollector(NewCollector())
+
+	require.NoError(t, store.Record(Run{Source: "watch", ContentType: "image", Outcome: OutcomeSuccess,
+		DurationMs: 1500, DifyLatencyMs: 800, DifyTokens: 120, GraphCalls: 2, GraphRetries: 1}))
+	require.NoError(t, store.Record(Run{Source: "watch", ContentType: "text", Outcome: OutcomeFailure, FailedStage: StageDify, DurationMs: 300}))
+	require.NoError(t, store.Record(Run{Source: "watch", ContentType: "text", Outcome: OutcomeDuplicate}))
+
+	body := scrape(t, Sources{Collector: store.Collector()})
+
+	assert.Contains(t, body, "# TYPE to_icalendar_pipeline_runs_total counter\n")
+	assert.Contains(t, body, `to_icalendar_pipeline_runs_total{source="watch",content_type="image",outcome="success"} 1`)
+	assert.Contains(t, body, `to_icalendar_pipeline_runs_total{source="watch",content_type="text",outcome="duplicate"} 1`)
+	assert.Contains(t, body, `to_icalendar_pipeline_failures_total{stage="dify"} 1`)
+
+	// 去重命中不计入耗时直方图
+	assert.Contains(t, body, `to_icalendar_pipeline_duration_seconds_bucket{le="0.5"} 1`)
+	assert.Contains(t, body, `to_icalendar_pipeline_duration_seconds_bucket{le="2"} 2`)
+	assert.Contains(t, body, `to_icalendar_pipeline_duration_seconds_bucket{le="+Inf"} 2`)
+	assert.Contains(t, body, "to_icalendar_pipeline_duration_seconds_sum 1.8\n")
+	assert.Contains(t, body, "to_icalendar_dify_latency_seconds_count 1\n")
+	assert.Contains(t, body, "to_icalendar_dify_tokens_total 120\n")
+	assert.Contains(t, body, "to_icalendar_graph_requests_total 2\n")
+	assert.Contains(t, body, "to_icalendar_graph_retries_total 1\n")
+
+	// 未启用文件记录时不写入指标文件
+	runs, err := store.Query(time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestHandler_TokenClipboardAndCache(t *testing.T) {
+	lastCheck := time.Unix(1700000000, 0)
+	sources := Sources{
+		TokenStatus: func() *microsofttodo.TokenStatus {
+			return &microsofttodo.TokenStatus{
+				HasToken:           true,
+				ExpiresAt:          time.Now().Add(time.Hour),
+				RefreshCount:       3,
+				LastRefreshAttempt: lastCheck,
+				LastRefreshError:   "network error",
+			}
+		},
+		Clipboard: fakeHealthChecker{healthy: true, lastCheck: lastCheck},
+		CacheUsage: func() ([]cache.TypeUsage, error) {
+			return []cache.TypeUsage{
+				{Type: cache.CacheTypeImages, SizeBytes: 2048, Files: 4, Items: 4, Quota: cache.Quota{MaxBytes: 4096}},
+				{Type: cache.CacheTypeTemp, SizeBytes: 10, Files: 1, Items: 1},
+			}, nil
+		},
+	}
+
+	body := scrape(t, sources)
+
+	assert.Contains(t, body, "to_icalendar_token_present 1\n")
+	assert.Regexp(t, `to_icalendar_token_expires_in_seconds (3600|35\d\d)\n`, body)
+	assert.Contains(t, body, "to_icalendar_token_refreshes_total 3\n")
+	assert.Contains(t, body, "to_icalendar_token_last_refresh_success 0\n")
+	assert.Contains(t, body, "to_icalendar_token_last_refresh_timestamp_seconds 1.7e+09\n")
+	assert.Contains(t, body, "to_icalendar_clipboard_healthy 1\n")
+	assert.Contains(t, body, `to_icalendar_cache_size_bytes{type="images"} 2048`)
+	assert.Contains(t, body, `to_icalendar_cache_files{type="temp"} 1`)
+	assert.Contains(t, body, `to_icalendar_cache_quota_bytes{type="images"} 4096`)
+	assert.NotContains(t, body, `to_icalendar_cache_quota_bytes{type="temp"}`)
+	assert.NotContains(t, body, "to_icalendar_pipeline_runs_total")
+}
+
+func TestHandler_UnavailableSources(t *testing.T) {
+	body := scrape(t, Sources{
+		TokenStatus: func() *microsofttodo.TokenStatus { return nil },
+		CacheUsage:  func() ([]cache.TypeUsage, error) { return nil, errors.New("disk\nerror") },
+	})
+
+	assert.NotContains(t, body, "to_icalendar_token_present")
+	assert.Contains(t, body, "# cache usage unavailable: disk error\n")
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(Sources{}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+}
+
+func TestServer_StartAndShutdown(t *testing.T) {
+	server, err := StartServer("127.0.0.1:0", Sources{Collector: NewCollector()}, nil)
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://" + server.Addr() + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "to_icalendar_start_time_seconds")
+
+	resp, err = http.Get("http://" + server.Addr() + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+
+	_, err = http.Get("http://" + server.Addr() + "/metrics")
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Server 本地 /metrics HTTP 服务
+type Server struct {
+	httpServer *http.Server
+	listener   net.Listener
+	done       chan struct{}
+}
+
+// NewMux 创建只提供 /metrics 的路由
+func NewMux(sources Sources) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", NewHandler(sources))
+	return mux
+}
+
+// StartServer 在 addr 上启动 /metrics 服务，监听失败时返回错误
+func StartServer(addr string, sources Sources, logger *log.Logger) (*Server, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("监听指标端点失败: %w", err)
+	}
+
+	server := &Server{
+		httpServer: &http.Server{
+			Handler:           NewMux(sources),
+			ReadHeaderTimeout: 5 * time.Second,
+			ErrorLog:          logger,
+		},
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(server.done)
+		if err := server.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Printf("指标端点异常退出: %v", err)
+		}
+	}()
+
+	logger.Printf("指标端点已启动: http://%s/metrics", listener.Addr())
+	return server, nil
+}
+
+// Addr 实际监听的地址（监听端口 0 时可获取分配的端口）
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Shutdown 停止服务并等待正在处理的请求结束
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.httpServer.Shutdown(ctx)
+	<-s.done
+	return err
+}
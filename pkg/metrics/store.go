@@ -0,0 +1,371 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// 一次处理的结果
+const (
+	OutcomeSuccess   = "success"   // 处理成功并创建了任务
+	OutcomeFailure   = "failure"   // 处理失败
+	OutcomeDuplicate = "duplicate" // 命中去重，未再次处理
+)
+
+// 处理失败的阶段
+const (
+	StageDify  = "dify"  // 调用 Dify 失败
+	StageParse = "parse" // 解析 Dify 响应失败
+	StageTodo  = "todo"  // 创建 Microsoft Todo 任务或日历事件失败
+)
+
+const (
+	// DirName 指标文件目录名，旧版本位于全局缓存目录下
+	DirName = "metrics"
+
+	filePrefix = "runs-"
+	fileSuffix = ".jsonl"
+	dateLayout = "2006-01-02"
+)
+
+// Run 一次剪贴板处理的指标
+type Run struct {
+	Time          time.Time `json:"time"`
+	Source        string    `json:"source"` // clip-upload、watch 或 tray
+	ContentType   string    `json:"content_type"`
+	Outcome       string    `json:"outcome"`
+	FailedStage   string    `json:"failed_stage,omitempty"`
+	TaskID        string    `json:"task_id,omitempty"`
+	DurationMs    int64     `json:"duration_ms"`
+	DifyLatencyMs int64     `json:"dify_latency_ms,omitempty"` // Dify 工作流耗时
+	DifyTokens    int       `json:"dify_tokens,omitempty"`     // Dify 工作流消耗的 token
+	GraphCalls    int64     `json:"graph_calls,omitempty"`     // 本次处理发出的 Graph 请求数
+	GraphRetries  int64     `json:"graph_retries,omitempty"`   // 本次处理的 Graph 重试次数
+}
+
+// SetDifyWorkflow 从 Dify 工作流数据中记录耗时和 token，data 为 nil 时不做处理
+func (r *Run) SetDifyWorkflow(data *models.DifyWorkflowData) {
+	if data == nil {
+		return
+	}
+	r.DifyLatencyMs = int64(data.ElapsedTime * 1000)
+	r.DifyTokens = data.TotalTokens
+}
+
+// Store 指标存储，每天一个 JSON Lines 文件，超过保留天数的文件会被清理
+type Store struct {
+	dir       string
+	enabled   bool
+	retention time.Duration
+	logger    *log.Logger
+	collector *Collector // 进程内指标，不受 enabled 影响
+
+	mutex      sync.Mutex
+	prunedDate string // 最近一次清理的日期，每天最多清理一次
+	now        func() time.Time
+}
+
+// NewStore 根据缓存配置创建指标存储，enable_cache_metrics 关闭时 Record 不写入文件
+func NewStore(dir string, cacheConfig models.CacheConfig, logger *log.Logger) *Store {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Store{
+		dir:       dir,
+		enabled:   cacheConfig.EnableCacheMetrics,
+		retention: cacheConfig.GetMetricsRetention(),
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetConfig 按新的缓存配置更新是否记录和保留天数，用于配置热加载；没有指标目录时保持关闭
+func (s *Store) SetConfig(cacheConfig models.CacheConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.enabled = cacheConfig.EnableCacheMetrics && s.dir != ""
+	s.retention = cacheConfig.GetMetricsRetention()
+	// 保留天数可能缩短，下次记录时重新清理
+	s.prunedDate = ""
+}
+
+// Enabled 是否将指标写入文件
+func (s *Store) Enabled() bool {
+	if s == nil {
+		return false
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.enabled
+}
+
+// Dir 指标文件目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Retention 指标保留时长
+func (s *Store) Retention() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.retention
+}
+
+// SetCollector 设置进程内指标收集器，每条处理指标都会同时累加到收集器
+func (s *Store) SetCollector(collector *Collector) {
+	s.collector = collector
+}
+
+// Collector 获取进程内指标收集器
+func (s *Store) Collector() *Collector {
+	if s == nil {
+		return nil
+	}
+	return s.collector
+}
+
+// Record 追加一条处理指标，未启用时只累加到进程内收集器
+func (s *Store) Record(run Run) error {
+	if s == nil {
+		return nil
+	}
+
+	now := s.now()
+	if run.Time.IsZero() {
+		run.Time = now
+	}
+	if s.collector != nil {
+		s.collector.Observe(run)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.enabled {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("创建指标目录失败: %w", err)
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("序列化指标失败: %w", err)
+	}
+
+	file, err := os.OpenFile(s.filePath(run.Time), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开指标文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入指标失败: %w", err)
+	}
+
+	// 每天第一次记录时顺带清理过期文件
+	if today := now.Format(dateLayout); s.prunedDate != today {
+		s.prunedDate = today
+		if _, err := s.prune(now); err != nil {
+			s.logger.Printf("清理过期指标失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// RecordQuietly 追加一条处理指标，失败时只记录日志，不影响处理流程
+func (s *Store) RecordQuietly(run Run) {
+	if err := s.Record(run); err != nil {
+		s.logger.Printf("记录处理指标失败: %v", err)
+	}
+}
+
+// Prune 删除超过保留天数的指标文件，返回删除的文件数
+func (s *Store) Prune() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.prune(s.now())
+}
+
+// prune 删除早于保留期的指标文件（调用方需持有锁）
+func (s *Store) prune(now time.Time) (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	dates, err := s.listDates()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := startOfDay(now.Add(-s.retention))
+	removed := 0
+	for _, date := range dates {
+		if !date.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(s.filePath(date)); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("删除指标文件失败: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Query 读取 since 之后的处理指标，按时间排序；since 为零值时读取全部
+func (s *Store) Query(since time.Time) ([]Run, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dates, err := s.listDates()
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []Run
+	for _, date := range dates {
+		// 文件按天存储，早于 since 当天的文件可以整体跳过
+		if !since.IsZero() && date.Before(startOfDay(since)) {
+			continue
+		}
+
+		fileRuns, err := readRuns(s.filePath(date))
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range fileRuns {
+			if since.IsZero() || !run.Time.Before(since) {
+				runs = append(runs, run)
+			}
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Time.Before(runs[j].Time) })
+	return runs, nil
+}
+
+// listDates 列出已有指标文件对应的日期，按日期排序
+func (s *Store) listDates() ([]time.Time, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取指标目录失败: %w", err)
+	}
+
+	var dates []time.Time
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		date, err := time.ParseInLocation(dateLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), time.Local)
+		if err != nil {
+			continue
+		}
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates, nil
+}
+
+// MigrateDir 将旧目录中的指标文件移动到 dir，迁移后删除旧目录；旧目录不存在时不做处理
+func MigrateDir(oldDir, dir string) error {
+	entries, err := os.ReadDir(oldDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取旧指标目录失败: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建指标目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		if err := moveRunsFile(filepath.Join(oldDir, name), filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("迁移指标文件失败: %w", err)
+		}
+	}
+	return os.RemoveAll(oldDir)
+}
+
+// moveRunsFile 移动指标文件，目标已存在时把旧文件的内容追加到目标文件
+func moveRunsFile(src, dst string) error {
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		return os.Rename(src, dst)
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(dst, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// filePath 指定日期的指标文件路径
+func (s *Store) filePath(t time.Time) string {
+	return filepath.Join(s.dir, filePrefix+t.In(time.Local).Format(dateLayout)+fileSuffix)
+}
+
+// readRuns 读取一个指标文件，无法解析的行会被跳过
+func readRuns(path string) ([]Run, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("打开指标文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取指标文件失败: %w", err)
+	}
+	return runs, nil
+}
+
+// startOfDay 当天零点（本地时间）
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore 创建使用临时目录的指标存储
+func newTestStore(t *testing.T, enabled bool, retentionDays int) *Store {
+	t.Helper()
+	cacheConfig := models.DefaultCacheConfig()
+	cacheConfig.EnableCacheMetrics = enabled
+	cacheConfig.MetricsRetentionDays = retentionDays
+	return NewStore(filepath.Join(t.TempDir(), DirName), cacheConfig, nil)
+}
+
+func TestStore_RecordAndQuery(t *testing.T) {
+	store := newTestStore(t, true, 7)
+	now := time.Now()
+
+	require.NoError(t, store.Record(Run{Time: now.Add(-3 * 24 * time.Hour), ContentType: "text", Outcome: OutcomeSuccess}))
+	require.NoError(t, store.Record(Run{Time: now.Add(-time.Hour), ContentType: "image", Outcome: OutcomeFailure, FailedStage: StageDify}))
+	require.NoError(t, store.Record(Run{Time: now, ContentType: "image", Outcome: OutcomeSuccess}))
+
+	runs, err := store.Query(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, "text", runs[0].ContentType)
+
+	runs, err = store.Query(now.Add(-2 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, StageDify, runs[0].FailedStage)
+}
+
+func TestStore_DisabledDoesNotWrite(t *testing.T) {
+	store := newTestStore(t, false, 7)
+	require.NoError(t, store.Record(Run{Outcome: OutcomeSuccess}))
+	assert.NoDirExists(t, store.Dir())
+
+	// nil 存储也可以安全调用
+	var nilStore *Store
+	nilStore.RecordDuplicate("watch", "text")
+	nilStore.Track("watch", "text").Succeed()
+	assert.False(t, nilStore.Enabled())
+}
+
+func TestStore_PruneByRetention(t *testing.T) {
+	store := newTestStore(t, true, 2)
+	now := time.Now()
+
+	require.NoError(t, os.MkdirAll(store.Dir(), 0755))
+	for _, days := range []int{5, 1} {
+		require.NoError(t, os.WriteFile(store.filePath(now.Add(-time.Duration(days)*24*time.Hour)), []byte("{}\n"), 0644))
+	}
+
+	removed, err := store.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	// 每天第一次记录时也会清理过期文件
+	require.NoError(t, os.WriteFile(store.filePath(now.Add(-3*24*time.Hour)), []byte("{}\n"), 0644))
+	require.NoError(t, store.Record(Run{Outcome: OutcomeSuccess}))
+
+	entries, err := os.ReadDir(store.Dir())
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestStore_SetConfig(t *testing.T) {
+	store := newTestStore(t, false, 7)
+	require.NoError(t, store.Record(Run{Outcome: OutcomeSuccess}))
+	assert.NoDirExists(t, store.Dir())
+
+	// 热加载开启记录并缩短保留天数，过期文件在下次记录时清理
+	cacheConfig := models.DefaultCacheConfig()
+	cacheConfig.MetricsRetentionDays = 2
+	store.SetConfig(cacheConfig)
+	assert.True(t, store.Enabled())
+	assert.Equal(t, 2*24*time.Hour, store.Retention())
+
+	require.NoError(t, os.MkdirAll(store.Dir(), 0755))
+	require.NoError(t, os.WriteFile(store.filePath(time.Now().Add(-5*24*time.Hour)), []byte("{}\n"), 0644))
+	require.NoError(t, store.Record(Run{Outcome: OutcomeSuccess}))
+	entries, err := os.ReadDir(store.Dir())
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// 没有指标目录时保持关闭
+	noDir := NewStore("", models.CacheConfig{}, nil)
+	noDir.SetConfig(cacheConfig)
+	assert.False(t, noDir.Enabled())
+}
+
+func TestTracker_RecordsDifyAndGraphDeltas(t *testing.T) {
+	store := newTestStore(t, true, 7)
+	calls, retries := int64(10), int64(2)
+
+	tracker := store.Track("clip-upload", "image")
+	tracker.SetDifyResponse(&models.DifyResponse{Data: &models.DifyWorkflowData{ElapsedTime: 1.5, TotalTokens: 320}})
+	tracker.TrackGraph(func() (int64, int64) { return calls, retries })
+	calls, retries = 13, 3
+	tracker.Succeed()
+	tracker.Fail(StageTodo) // 只有第一次结束生效
+
+	runs, err := store.Query(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, OutcomeSuccess, runs[0].Outcome)
+	assert.Equal(t, int64(1500), runs[0].DifyLatencyMs)
+	assert.Equal(t, 320, runs[0].DifyTokens)
+	assert.Equal(t, int64(3), runs[0].GraphCalls)
+	assert.Equal(t, int64(1), runs[0].GraphRetries)
+}
+
+func TestSummarize(t *testing.T) {
+	day := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	runs := []Run{
+		{Time: day, ContentType: "text", Outcome: OutcomeSuccess, DifyLatencyMs: 1000, DifyTokens: 100, GraphCalls: 2, DurationMs: 2000},
+		{Time: day.Add(time.Hour), ContentType: "image", Outcome: OutcomeFailure, FailedStage: StageTodo, DifyLatencyMs: 3000, DifyTokens: 300, GraphCalls: 4, GraphRetries: 3, DurationMs: 4000},
+		{Time: day.Add(24 * time.Hour), ContentType: "text", Outcome: OutcomeDuplicate},
+	}
+
+	summary := Summarize(runs, day, day.Add(48*time.Hour))
+	assert.Equal(t, 3, summary.Runs)
+	assert.Equal(t, 0.5, summary.SuccessRate())
+	assert.InDelta(t, 1.0/3, summary.DedupHitRate(), 0.001)
+	assert.Equal(t, 2, summary.ByContentType["text"].Runs)
+	assert.Equal(t, 1, summary.FailedStages[StageTodo])
+	assert.Equal(t, DifySummary{Calls: 2, AvgLatencyMs: 2000, P95LatencyMs: 3000, MaxLatencyMs: 3000, TotalTokens: 400, AvgTokens: 200}, summary.Dify)
+	assert.Equal(t, GraphSummary{Calls: 6, Retries: 3}, summary.Graph)
+	assert.Equal(t, int64(3000), summary.AvgDurationMs)
+	require.Len(t, summary.Daily, 2)
+	assert.Equal(t, "2025-03-10", summary.Daily[0].Date)
+	assert.Equal(t, 400, summary.Daily[0].Tokens)
+}
+
+func TestMigrateDir(t *testing.T) {
+	base := t.TempDir()
+	oldDir := filepath.Join(base, "global", DirName)
+	dir := filepath.Join(base, DirName)
+
+	// 旧目录不存在时不做处理
+	require.NoError(t, MigrateDir(oldDir, dir))
+	assert.NoDirExists(t, dir)
+
+	cacheConfig := models.DefaultCacheConfig()
+	now := time.Now()
+	require.NoError(t, NewStore(oldDir, cacheConfig, nil).Record(Run{Time: now.Add(-24 * time.Hour), Outcome: OutcomeSuccess}))
+	require.NoError(t, NewStore(oldDir, cacheConfig, nil).Record(Run{Time: now, Outcome: OutcomeFailure}))
+	require.NoError(t, NewStore(dir, cacheConfig, nil).Record(Run{Time: now, Outcome: OutcomeSuccess}))
+
+	require.NoError(t, MigrateDir(oldDir, dir))
+	assert.NoDirExists(t, oldDir)
+
+	// 同一天的文件合并，不丢失记录
+	runs, err := NewStore(dir, cacheConfig, nil).Query(time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+}
@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Counts 处理次数统计
+type Counts struct {
+	Runs       int `json:"runs"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	Duplicates int `json:"duplicates"`
+}
+
+// SuccessRate 成功率（不含去重跳过的次数），没有处理记录时返回 0
+func (c Counts) SuccessRate() float64 {
+	processed := c.Succeeded + c.Failed
+	if processed == 0 {
+		return 0
+	}
+	return float64(c.Succeeded) / float64(processed)
+}
+
+// add 累加一条处理记录
+func (c *Counts) add(run Run) {
+	c.Runs++
+	switch run.Outcome {
+	case OutcomeSuccess:
+		c.Succeeded++
+	case OutcomeFailure:
+		c.Failed++
+	case OutcomeDuplicate:
+		c.Duplicates++
+	}
+}
+
+// DifySummary Dify 调用统计
+type DifySummary struct {
+	Calls        int   `json:"calls"` // 记录到工作流数据的调用次数
+	AvgLatencyMs int64 `json:"avg_latency_ms"`
+	P95LatencyMs int64 `json:"p95_latency_ms"`
+	MaxLatencyMs int64 `json:"max_latency_ms"`
+	TotalTokens  int   `json:"total_tokens"`
+	AvgTokens    int   `json:"avg_tokens"`
+}
+
+// GraphSummary Microsoft Graph 请求统计
+type GraphSummary struct {
+	Calls   int64 `json:"calls"`
+	Retries int64 `json:"retries"`
+}
+
+// DailySummary 单日统计
+type DailySummary struct {
+	Date   string `json:"date"`
+	Counts        // 当天的处理次数
+	Tokens int    `json:"tokens"`
+}
+
+// Summary 一段时间内的处理指标汇总
+type Summary struct {
+	Since         time.Time          `json:"since"`
+	Until         time.Time          `json:"until"`
+	Counts                           // 总处理次数
+	ByContentType map[string]*Counts `json:"by_content_type"`
+	FailedStages  map[string]int     `json:"failed_stages"`
+	Dify          DifySummary        `json:"dify"`
+	Graph         GraphSummary       `json:"graph"`
+	AvgDurationMs int64              `json:"avg_duration_ms"`
+	Daily         []DailySummary     `json:"daily"`
+}
+
+// DedupHitRate 去重命中率
+func (s *Summary) DedupHitRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Duplicates) / float64(s.Runs)
+}
+
+// Summarize 汇总处理指标，runs 需按时间排序
+func Summarize(runs []Run, since, until time.Time) *Summary {
+	summary := &Summary{
+		Since:         since,
+		Until:         until,
+		ByContentType: make(map[string]*Counts),
+		FailedStages:  make(map[string]int),
+		Daily:         []DailySummary{},
+	}
+
+	var latencies []int64
+	var totalLatency, totalDuration int64
+	processed := 0
+	dailyIndex := make(map[string]int)
+
+	for _, run := range runs {
+		summary.Counts.add(run)
+
+		contentType := run.ContentType
+		if contentType == "" {
+			contentType = "unknown"
+		}
+		if summary.ByContentType[contentType] == nil {
+			summary.ByContentType[contentType] = &Counts{}
+		}
+		summary.ByContentType[contentType].add(run)
+
+		if run.Outcome == OutcomeFailure && run.FailedStage != "" {
+			summary.FailedStages[run.FailedStage]++
+		}
+
+		if run.DifyLatencyMs > 0 || run.DifyTokens > 0 {
+			summary.Dify.Calls++
+			summary.Dify.TotalTokens += run.DifyTokens
+			latencies = append(latencies, run.DifyLatencyMs)
+			totalLatency += run.DifyLatencyMs
+		}
+
+		summary.Graph.Calls += run.GraphCalls
+		summary.Graph.Retries += run.GraphRetries
+
+		if run.Outcome != OutcomeDuplicate {
+			processed++
+			totalDuration += run.DurationMs
+		}
+
+		date := run.Time.In(time.Local).Format(dateLayout)
+		index, ok := dailyIndex[date]
+		if !ok {
+			index = len(summary.Daily)
+			dailyIndex[date] = index
+			summary.Daily = append(summary.Daily, DailySummary{Date: date})
+		}
+		summary.Daily[index].Counts.add(run)
+		summary.Daily[index].Tokens += run.DifyTokens
+	}
+
+	if summary.Dify.Calls > 0 {
+		summary.Dify.AvgLatencyMs = totalLatency / int64(summary.Dify.Calls)
+		summary.Dify.AvgTokens = summary.Dify.TotalTokens / summary.Dify.Calls
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		summary.Dify.P95LatencyMs = percentile(latencies, 0.95)
+		summary.Dify.MaxLatencyMs = latencies[len(latencies)-1]
+	}
+	if processed > 0 {
+		summary.AvgDurationMs = totalDuration / int64(processed)
+	}
+
+	return summary
+}
+
+// percentile 取已排序数据的百分位数（最近秩法）
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
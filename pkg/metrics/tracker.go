@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+)
+
+// GraphStatsFunc 返回 Graph 客户端累计的请求数和重试数
+type GraphStatsFunc func() (calls, retries int64)
+
+// Tracker 跟踪一次处理，结束时写入一条指标
+// store 为 nil 或未启用指标时所有方法都可以安全调用，只是不会写入
+type Tracker struct {
+	store     *Store
+	run       Run
+	startedAt time.Time
+
+	graphStats   GraphStatsFunc
+	graphCalls   int64
+	graphRetries int64
+	finished     bool
+}
+
+// Track 开始跟踪一次处理
+func (s *Store) Track(source, contentType string) *Tracker {
+	now := time.Now()
+	return &Tracker{
+		store:     s,
+		run:       Run{Time: now, Source: source, ContentType: contentType},
+		startedAt: now,
+	}
+}
+
+// SetTaskID 关联任务会话ID
+func (t *Tracker) SetTaskID(taskID string) {
+	t.run.TaskID = taskID
+}
+
+// SetDifyResponse 记录 Dify 响应中的工作流耗时和 token
+func (t *Tracker) SetDifyResponse(resp *models.DifyResponse) {
+	if resp != nil {
+		t.run.SetDifyWorkflow(resp.Data)
+	}
+}
+
+// TrackGraph 记录 Graph 统计的基线，结束时以差值作为本次处理的请求数和重试数
+// Graph 客户端在多个处理之间共享，并发处理时该差值为近似值
+func (t *Tracker) TrackGraph(stats GraphStatsFunc) {
+	if stats == nil {
+		return
+	}
+	t.graphStats = stats
+	t.graphCalls, t.graphRetries = stats()
+}
+
+// Succeed 记录处理成功
+func (t *Tracker) Succeed() {
+	t.finish(OutcomeSuccess, "")
+}
+
+// Fail 记录在指定阶段处理失败
+func (t *Tracker) Fail(stage string) {
+	t.finish(OutcomeFailure, stage)
+}
+
+// finish 写入指标，只有第一次调用生效
+func (t *Tracker) finish(outcome, stage string) {
+	if t.finished {
+		return
+	}
+	t.finished = true
+
+	t.run.Outcome = outcome
+	t.run.FailedStage = stage
+	t.run.DurationMs = time.Since(t.startedAt).Milliseconds()
+	if t.graphStats != nil {
+		calls, retries := t.graphStats()
+		t.run.GraphCalls = calls - t.graphCalls
+		t.run.GraphRetries = retries - t.graphRetries
+	}
+	t.store.RecordQuietly(t.run)
+}
+
+// RecordDuplicate 记录一次去重命中
+func (s *Store) RecordDuplicate(source, contentType string) {
+	s.RecordQuietly(Run{Source: source, ContentType: contentType, Outcome: OutcomeDuplicate})
+}
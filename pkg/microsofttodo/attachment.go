@@ -0,0 +1,179 @@
+package microsofttodo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+)
+
+const (
+	// LargeAttachmentThreshold 超过该大小的附件需要通过上传会话分块上传（Graph 限制 3MB）
+	LargeAttachmentThreshold = 3 * 1024 * 1024
+	// MaxAttachmentSize Microsoft To Do 单个附件的最大大小
+	MaxAttachmentSize = 25 * 1024 * 1024
+	// uploadChunkSize 上传会话的分块大小，必须是 320 KiB 的整数倍
+	uploadChunkSize = 10 * 320 * 1024
+)
+
+// FileAttachment 待上传的任务文件附件
+type FileAttachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// AttachFile 将文件作为 taskFileAttachment 上传到指定任务，返回附件ID
+// 小于 3MB 的文件直接上传，较大的文件通过上传会话分块上传
+func (c *SimpleTodoClient) AttachFile(ctx context.Context, listID, taskID string, attachment *FileAttachment) (string, error) {
+	size := len(attachment.Data)
+	if size == 0 {
+		return "", fmt.Errorf("attachment %s is empty", attachment.Name)
+	}
+	if size > MaxAttachmentSize {
+		return "", fmt.Errorf("attachment %s is too large: %d bytes (max %d)", attachment.Name, size, MaxAttachmentSize)
+	}
+
+	logger.FromContext(ctx).Infof("上传任务附件: %s (%d bytes)", attachment.Name, size)
+
+	if size < LargeAttachmentThreshold {
+		return c.uploadSmallAttachment(ctx, listID, taskID, attachment)
+	}
+	return c.uploadLargeAttachment(ctx, listID, taskID, attachment)
+}
+
+// uploadSmallAttachment 以 base64 内容直接创建附件
+func (c *SimpleTodoClient) uploadSmallAttachment(ctx context.Context, listID, taskID string, attachment *FileAttachment) (string, error) {
+	endpoint := fmt.Sprintf("/me/todo/lists/%s/tasks/%s/attachments", listID, taskID)
+	body := map[string]interface{}{
+		"@odata.type":  "#microsoft.graph.taskFileAttachment",
+		"name":         attachment.Name,
+		"contentType":  attachment.ContentType,
+		"contentBytes": base64.StdEncoding.EncodeToString(attachment.Data),
+	}
+
+	resp, err := c.makeAPIRequest(ctx, "POST", endpoint, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload attachment: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", parseGraphAPIError(resp)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode attachment response: %v", err)
+	}
+
+	logger.FromContext(ctx).Infof("附件上传成功, ID: %s", created.ID)
+	return created.ID, nil
+}
+
+// uploadLargeAttachment 创建上传会话并分块上传附件
+func (c *SimpleTodoClient) uploadLargeAttachment(ctx context.Context, listID, taskID string, attachment *FileAttachment) (string, error) {
+	log := logger.FromContext(ctx)
+	endpoint := fmt.Sprintf("/me/todo/lists/%s/tasks/%s/attachments/createUploadSession", listID, taskID)
+	body := map[string]interface{}{
+		"attachmentInfo": map[string]interface{}{
+			"attachmentType": "file",
+			"name":           attachment.Name,
+			"size":           len(attachment.Data),
+		},
+	}
+
+	resp, err := c.makeAPIRequest(ctx, "POST", endpoint, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload session: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", parseGraphAPIError(resp)
+	}
+
+	var session struct {
+		UploadURL string `json:"uploadUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return "", fmt.Errorf("failed to decode upload session response: %v", err)
+	}
+	if session.UploadURL == "" {
+		return "", fmt.Errorf("upload session response has no uploadUrl")
+	}
+
+	log.Debugf("已创建附件上传会话，开始分块上传")
+
+	total := len(attachment.Data)
+	for start := 0; start < total; start += uploadChunkSize {
+		end := start + uploadChunkSize
+		if end > total {
+			end = total
+		}
+
+		attachmentID, done, err := c.uploadChunk(ctx, session.UploadURL, attachment.Data[start:end], start, total)
+		if err != nil {
+			return "", fmt.Errorf("failed to upload bytes %d-%d: %w", start, end-1, err)
+		}
+		if done {
+			log.Infof("附件分块上传完成, ID: %s", attachmentID)
+			return attachmentID, nil
+		}
+	}
+
+	return "", fmt.Errorf("upload session did not complete after sending %d bytes", total)
+}
+
+// uploadChunk 上传单个分块，最后一块上传完成时返回附件ID
+// 上传地址已包含授权信息，不能再携带 Authorization 头
+func (c *SimpleTodoClient) uploadChunk(ctx context.Context, uploadURL string, chunk []byte, offset, total int) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", uploadURL, bytes.NewReader(chunk))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.ContentLength = int64(len(chunk))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+len(chunk)-1, total))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		io.Copy(io.Discard, resp.Body)
+		return "", false, nil
+	case http.StatusOK, http.StatusCreated:
+		return attachmentIDFromLocation(resp.Header.Get("Location")), true, nil
+	default:
+		return "", false, parseGraphAPIError(resp)
+	}
+}
+
+// attachmentIDFromLocation 从上传完成后的 Location 头中提取附件ID
+// 支持 .../attachments/{id} 与 .../attachments('{id}') 两种格式
+func attachmentIDFromLocation(location string) string {
+	if location == "" {
+		return ""
+	}
+	if i := strings.Index(location, "?"); i >= 0 {
+		location = location[:i]
+	}
+
+	id := location[strings.LastIndex(location, "/")+1:]
+	if strings.HasPrefix(id, "attachments(") {
+		id = strings.TrimSuffix(strings.TrimPrefix(id, "attachments("), ")")
+	}
+	return strings.Trim(id, "'")
+}
@@ -0,0 +1,114 @@
+package microsofttodo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachFile_SmallFileUploadsInline(t *testing.T) {
+	data := []byte("small screenshot")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/me/todo/lists/list-1/tasks/task-1/attachments", r.URL.Path)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "#microsoft.graph.taskFileAttachment", body["@odata.type"])
+		assert.Equal(t, "screenshot.png", body["name"])
+		assert.Equal(t, base64.StdEncoding.EncodeToString(data), body["contentBytes"])
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"att-1"}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	id, err := client.AttachFile(context.Background(), "list-1", "task-1", &FileAttachment{
+		Name:        "screenshot.png",
+		ContentType: "image/png",
+		Data:        data,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "att-1", id)
+}
+
+func TestAttachFile_LargeFileUsesUploadSession(t *testing.T) {
+	data := bytes.Repeat([]byte{0xAB}, uploadChunkSize+1024)
+	var received bytes.Buffer
+	var ranges []string
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/me/todo/lists/list-1/tasks/task-1/attachments/createUploadSession":
+			var body struct {
+				AttachmentInfo struct {
+					AttachmentType string `json:"attachmentType"`
+					Name           string `json:"name"`
+					Size           int    `json:"size"`
+				} `json:"attachmentInfo"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "file", body.AttachmentInfo.AttachmentType)
+			assert.Equal(t, len(data), body.AttachmentInfo.Size)
+			fmt.Fprintf(w, `{"uploadUrl":"%s/upload/session-1"}`, server.URL)
+		case r.Method == "PUT" && r.URL.Path == "/upload/session-1":
+			assert.Empty(t, r.Header.Get("Authorization"))
+			ranges = append(ranges, r.Header.Get("Content-Range"))
+			chunk, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			received.Write(chunk)
+
+			if received.Len() < len(data) {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.Header().Set("Location", "https://graph.microsoft.com/v1.0/me/todo/lists/list-1/tasks/task-1/attachments('att-large')")
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	id, err := client.AttachFile(context.Background(), "list-1", "task-1", &FileAttachment{
+		Name:        "screenshot.png",
+		ContentType: "image/png",
+		Data:        data,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "att-large", id)
+	assert.Equal(t, data, received.Bytes())
+	require.Len(t, ranges, 2)
+	assert.Equal(t, fmt.Sprintf("bytes 0-%d/%d", uploadChunkSize-1, len(data)), ranges[0])
+	assert.Equal(t, fmt.Sprintf("bytes %d-%d/%d", uploadChunkSize, len(data)-1, len(data)), ranges[1])
+}
+
+func TestAttachFile_RejectsOversizedFile(t *testing.T) {
+	client := &SimpleTodoClient{}
+	_, err := client.AttachFile(context.Background(), "list-1", "task-1", &FileAttachment{
+		Name: "huge.png",
+		Data: make([]byte, MaxAttachmentSize+1),
+	})
+	assert.Error(t, err)
+}
+
+func TestAttachmentIDFromLocation(t *testing.T) {
+	assert.Equal(t, "abc", attachmentIDFromLocation("https://graph.microsoft.com/v1.0/me/todo/lists/l/tasks/t/attachments/abc"))
+	assert.Equal(t, "abc", attachmentIDFromLocation("https://graph.microsoft.com/v1.0/me/todo/lists/l/tasks/t/attachments('abc')"))
+	assert.Equal(t, "abc", attachmentIDFromLocation("https://graph.microsoft.com/v1.0/attachments/abc?x=1"))
+	assert.Empty(t, attachmentIDFromLocation(""))
+}
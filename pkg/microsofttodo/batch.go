@@ -0,0 +1,343 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+)
+
+const (
+	// MaxBatchSize Graph JSON 批处理单次请求最多包含的子请求数
+	MaxBatchSize = 20
+	// defaultBatchRetries 失败子请求的默认重试次数
+	defaultBatchRetries = 3
+	// maxBatchRetryDelay 子请求 Retry-After 的最大等待时间
+	maxBatchRetryDelay = 30 * time.Second
+)
+
+// batchRetryBaseDelay 子响应未给出 Retry-After 时的重试基础间隔
+var batchRetryBaseDelay = time.Second
+
+// BatchRequest JSON 批处理中的单个子请求
+type BatchRequest struct {
+	ID        string            `json:"id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"` // 相对于 API 版本的路径，例如 /me/todo/lists
+	Body      interface{}       `json:"body,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	DependsOn []string          `json:"dependsOn,omitempty"` // 依赖的子请求ID，依赖成功后才会执行
+}
+
+// BatchResponse JSON 批处理中的单个子响应
+type BatchResponse struct {
+	ID       string            `json:"id"`
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     json.RawMessage   `json:"body,omitempty"`
+	Attempts int               `json:"-"` // 该子请求实际发送的次数
+}
+
+// IsSuccess 子请求是否成功
+func (r *BatchResponse) IsSuccess() bool {
+	return r.Status >= 200 && r.Status < 300
+}
+
+// Err 将失败的子响应转换为错误，成功时返回 nil
+func (r *BatchResponse) Err() error {
+	if r.IsSuccess() {
+		return nil
+	}
+
+	apiErr := &GraphAPIError{StatusCode: r.Status}
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(r.Body, &body); err == nil {
+		apiErr.Code = body.Error.Code
+		apiErr.Message = body.Error.Message
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(r.Status)
+	}
+	return apiErr
+}
+
+// DecodeID 解析子响应中创建的资源ID
+func (r *BatchResponse) DecodeID() (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(r.Body, &created); err != nil {
+		return "", fmt.Errorf("failed to decode batch response %s: %v", r.ID, err)
+	}
+	return created.ID, nil
+}
+
+// isRetryable 子请求失败后是否应该重试
+// 429/503 表示服务器未处理该请求；424 表示依赖的子请求失败，该请求未执行，依赖重试成功后它也需要重新发送。
+// 其他 5xx 时请求可能已经生效，只重试幂等的子请求，避免 POST 重复创建任务或列表
+func (r *BatchResponse) isRetryable(method string) bool {
+	switch {
+	case r.Status == http.StatusTooManyRequests,
+		r.Status == http.StatusServiceUnavailable,
+		r.Status == http.StatusFailedDependency:
+		return true
+	case r.Status >= 500:
+		return isIdempotentMethod(method)
+	}
+	return false
+}
+
+// retryAfter 子响应建议的重试等待时间
+func (r *BatchResponse) retryAfter() time.Duration {
+	for key, value := range r.Headers {
+		if http.CanonicalHeaderKey(key) != "Retry-After" {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// ExecuteBatch 执行批处理请求并返回每个子请求的结果（与请求顺序一致）
+// 超过 20 个子请求时自动拆分，依赖链上的请求保证在同一批次中；
+// 429、503、幂等子请求的其他 5xx 以及因依赖失败而未执行（424）的子请求会重新发送，最多重试 maxRetries 次
+func (c *SimpleTodoClient) ExecuteBatch(ctx context.Context, requests []BatchRequest, maxRetries int) ([]*BatchResponse, error) {
+	if maxRetries <= 0 {
+		maxRetries = defaultBatchRetries
+	}
+
+	chunks, err := splitBatch(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*BatchResponse, len(requests))
+	for _, chunk := range chunks {
+		if err := c.executeBatchChunk(ctx, chunk, maxRetries, results); err != nil {
+			return nil, err
+		}
+	}
+
+	ordered := make([]*BatchResponse, len(requests))
+	for i, req := range requests {
+		ordered[i] = results[req.ID]
+	}
+	return ordered, nil
+}
+
+// executeBatchChunk 执行单个批次（不超过 20 个子请求），失败的子请求按需重试
+func (c *SimpleTodoClient) executeBatchChunk(ctx context.Context, chunk []BatchRequest, maxRetries int, results map[string]*BatchResponse) error {
+	pending := chunk
+	attempts := make(map[string]int, len(chunk))
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		responses, err := c.postBatch(ctx, pending)
+		if err != nil {
+			return err
+		}
+
+		var retry []BatchRequest
+		var delay time.Duration
+		retrying := make(map[string]bool)
+		for _, req := range pending {
+			attempts[req.ID]++
+			resp, ok := responses[req.ID]
+			if !ok {
+				resp = &BatchResponse{ID: req.ID, Status: http.StatusInternalServerError}
+			}
+			resp.Attempts = attempts[req.ID]
+			results[req.ID] = resp
+
+			if !resp.isRetryable(req.Method) || attempt >= maxRetries {
+				continue
+			}
+			// 依赖失败且依赖本身不会重试时，该请求也不再重试
+			if resp.Status == http.StatusFailedDependency && !dependenciesRecoverable(req, results, retrying) {
+				continue
+			}
+			retrying[req.ID] = true
+			retry = append(retry, req)
+			if d := resp.retryAfter(); d > delay {
+				delay = d
+			}
+		}
+
+		if len(retry) == 0 {
+			return nil
+		}
+
+		// 重试时只保留仍在本轮重试集合中的依赖，已成功的依赖不再需要等待
+		retry = pruneDependencies(retry)
+
+		if delay == 0 {
+			delay = time.Duration(attempt+1) * batchRetryBaseDelay
+		}
+		if delay > maxBatchRetryDelay {
+			delay = maxBatchRetryDelay
+		}
+		logger.FromContext(ctx).Infof("批处理中有 %d 个子请求失败，%v 后重试 (%d/%d)", len(retry), delay, attempt+1, maxRetries)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		pending = retry
+	}
+
+	return nil
+}
+
+// postBatch 发送一次 /$batch 请求
+func (c *SimpleTodoClient) postBatch(ctx context.Context, requests []BatchRequest) (map[string]*BatchResponse, error) {
+	for i := range requests {
+		if requests[i].Body != nil {
+			if requests[i].Headers == nil {
+				requests[i].Headers = map[string]string{}
+			}
+			if _, ok := requests[i].Headers["Content-Type"]; !ok {
+				requests[i].Headers["Content-Type"] = "application/json"
+			}
+		}
+	}
+
+	resp, err := c.makeAPIRequestWithRetry(ctx, "POST", "/$batch", map[string]interface{}{"requests": requests}, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send batch request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseGraphAPIError(resp)
+	}
+
+	var payload struct {
+		Responses []*BatchResponse `json:"responses"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode batch response: %v", err)
+	}
+
+	responses := make(map[string]*BatchResponse, len(payload.Responses))
+	for _, r := range payload.Responses {
+		responses[r.ID] = r
+	}
+	return responses, nil
+}
+
+// splitBatch 将子请求拆分为不超过 20 个的批次，同一依赖链上的请求放在同一批次
+func splitBatch(requests []BatchRequest) ([][]BatchRequest, error) {
+	index := make(map[string]int, len(requests))
+	for i, req := range requests {
+		if req.ID == "" {
+			return nil, fmt.Errorf("batch request %d has no id", i)
+		}
+		if _, dup := index[req.ID]; dup {
+			return nil, fmt.Errorf("duplicate batch request id: %s", req.ID)
+		}
+		index[req.ID] = i
+	}
+
+	// 并查集：把存在依赖关系的请求归为一组
+	parent := make([]int, len(requests))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for i, req := range requests {
+		for _, dep := range req.DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("batch request %s depends on unknown request %s", req.ID, dep)
+			}
+			if j >= i {
+				return nil, fmt.Errorf("batch request %s must come after its dependency %s", req.ID, dep)
+			}
+			parent[find(i)] = find(j)
+		}
+	}
+
+	// 按首次出现顺序收集各组
+	var groups [][]BatchRequest
+	groupIndex := make(map[int]int)
+	for i, req := range requests {
+		root := find(i)
+		g, ok := groupIndex[root]
+		if !ok {
+			g = len(groups)
+			groupIndex[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], req)
+	}
+
+	var chunks [][]BatchRequest
+	var current []BatchRequest
+	for _, group := range groups {
+		if len(group) > MaxBatchSize {
+			return nil, fmt.Errorf("dependency chain of %d requests exceeds batch limit %d", len(group), MaxBatchSize)
+		}
+		if len(current)+len(group) > MaxBatchSize {
+			chunks = append(chunks, current)
+			current = nil
+		}
+		current = append(current, group...)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, nil
+}
+
+// dependenciesRecoverable 判断请求的所有依赖是否已成功或将被重试
+func dependenciesRecoverable(req BatchRequest, results map[string]*BatchResponse, retrying map[string]bool) bool {
+	for _, dep := range req.DependsOn {
+		if retrying[dep] {
+			continue
+		}
+		if resp, ok := results[dep]; ok && resp.IsSuccess() {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// pruneDependencies 去掉指向本轮之外（已成功）请求的依赖
+func pruneDependencies(requests []BatchRequest) []BatchRequest {
+	inRetry := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		inRetry[req.ID] = true
+	}
+
+	pruned := make([]BatchRequest, len(requests))
+	for i, req := range requests {
+		var deps []string
+		for _, dep := range req.DependsOn {
+			if inRetry[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		req.DependsOn = deps
+		pruned[i] = req
+	}
+	return pruned
+}
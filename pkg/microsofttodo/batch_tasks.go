@@ -0,0 +1,240 @@
+package microsofttodo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+)
+
+// BatchTaskResult 批量创建中单个任务的结果
+type BatchTaskResult struct {
+	Index    int      `json:"index"` // 在请求列表中的位置
+	Title    string   `json:"title"`
+	ListID   string   `json:"list_id"`
+	TaskID   string   `json:"task_id,omitempty"`
+	Status   int      `json:"status"`   // 创建任务子请求的 HTTP 状态码
+	Attempts int      `json:"attempts"` // 创建任务子请求的发送次数
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"` // 检查项或链接创建失败等非致命问题
+}
+
+// Success 任务是否创建成功
+func (r *BatchTaskResult) Success() bool {
+	return r.Error == "" && r.TaskID != ""
+}
+
+// cachedListID 从列表ID缓存中查找列表
+func (c *SimpleTodoClient) cachedListID(listName string) (string, bool) {
+	c.listMutex.RLock()
+	defer c.listMutex.RUnlock()
+	id, ok := c.listIDs[listName]
+	return id, ok
+}
+
+// cacheListID 缓存列表名称到ID的映射
+func (c *SimpleTodoClient) cacheListID(listName, listID string) {
+	c.listMutex.Lock()
+	defer c.listMutex.Unlock()
+	if c.listIDs == nil {
+		c.listIDs = make(map[string]string)
+	}
+	c.listIDs[listName] = listID
+}
+
+// ClearListIDCache 清空列表ID缓存
+func (c *SimpleTodoClient) ClearListIDCache() {
+	c.listMutex.Lock()
+	defer c.listMutex.Unlock()
+	c.listIDs = nil
+}
+
+// ResolveTaskListIDs 批量获取列表ID，不存在的列表会通过一次批处理请求创建
+// 列表创建请求通过 dependsOn 串联，按顺序执行，避免并发创建同名列表
+func (c *SimpleTodoClient) ResolveTaskListIDs(ctx context.Context, listNames []string) (map[string]string, error) {
+	log := logger.FromContext(ctx)
+	ids := make(map[string]string, len(listNames))
+	var missing []string
+	for _, name := range uniqueStrings(listNames) {
+		if id, ok := c.cachedListID(name); ok {
+			ids[name] = id
+			continue
+		}
+		missing = append(missing, name)
+	}
+	if len(missing) == 0 {
+		return ids, nil
+	}
+
+	lists, err := c.ListTaskLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		c.cacheListID(list.DisplayName, list.ID)
+	}
+
+	var toCreate []string
+	for _, name := range missing {
+		if id, ok := c.cachedListID(name); ok {
+			ids[name] = id
+			continue
+		}
+		toCreate = append(toCreate, name)
+	}
+	if len(toCreate) == 0 {
+		return ids, nil
+	}
+
+	log.Infof("批量创建 %d 个任务列表", len(toCreate))
+	requests := make([]BatchRequest, len(toCreate))
+	for i, name := range toCreate {
+		requests[i] = BatchRequest{
+			ID:     fmt.Sprintf("list-%d", i+1),
+			Method: "POST",
+			URL:    "/me/todo/lists",
+			Body:   map[string]interface{}{"displayName": name},
+		}
+		if i > 0 {
+			requests[i].DependsOn = []string{requests[i-1].ID}
+		}
+	}
+
+	responses, err := c.ExecuteBatch(ctx, requests, defaultBatchRetries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task lists: %w", err)
+	}
+
+	for i, resp := range responses {
+		if err := resp.Err(); err != nil {
+			return nil, fmt.Errorf("failed to create task list '%s': %w", toCreate[i], err)
+		}
+		id, err := resp.DecodeID()
+		if err != nil {
+			return nil, err
+		}
+		c.cacheListID(toCreate[i], id)
+		ids[toCreate[i]] = id
+		log.Infof("Successfully created task list '%s' with ID: %s", toCreate[i], id)
+	}
+
+	return ids, nil
+}
+
+// CreateTasksBatch 通过 JSON 批处理批量创建任务，每个请求需已设置 ListID
+// 先批量创建任务，再批量创建各任务的检查项和关联链接；单个任务失败不影响其他任务
+func (c *SimpleTodoClient) CreateTasksBatch(ctx context.Context, tasks []*TaskRequest) ([]*BatchTaskResult, error) {
+	results := make([]*BatchTaskResult, len(tasks))
+	requests := make([]BatchRequest, len(tasks))
+	for i, task := range tasks {
+		results[i] = &BatchTaskResult{Index: i, Title: task.Title, ListID: task.ListID}
+		requests[i] = BatchRequest{
+			ID:     fmt.Sprintf("task-%d", i+1),
+			Method: "POST",
+			URL:    fmt.Sprintf("/me/todo/lists/%s/tasks", task.ListID),
+			Body:   buildTaskBody(task),
+		}
+	}
+
+	logger.FromContext(ctx).Infof("批量创建 %d 个任务", len(tasks))
+	responses, err := c.ExecuteBatch(ctx, requests, defaultBatchRetries)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, resp := range responses {
+		results[i].Status = resp.Status
+		results[i].Attempts = resp.Attempts
+		if err := resp.Err(); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		id, err := resp.DecodeID()
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].TaskID = id
+	}
+
+	c.createSubResourcesBatch(ctx, tasks, results)
+	return results, nil
+}
+
+// createSubResourcesBatch 批量创建已成功任务的检查项和关联链接，失败记录为警告
+func (c *SimpleTodoClient) createSubResourcesBatch(ctx context.Context, tasks []*TaskRequest, results []*BatchTaskResult) {
+	var requests []BatchRequest
+	var owners []*BatchTaskResult
+	var labels []string
+
+	for i, task := range tasks {
+		result := results[i]
+		if !result.Success() {
+			continue
+		}
+		base := fmt.Sprintf("/me/todo/lists/%s/tasks/%s", task.ListID, result.TaskID)
+
+		for j, item := range task.Checklist {
+			requests = append(requests, BatchRequest{
+				ID:     fmt.Sprintf("task-%d-checklist-%d", i+1, j+1),
+				Method: "POST",
+				URL:    base + "/checklistItems",
+				Body:   map[string]interface{}{"displayName": item},
+			})
+			owners = append(owners, result)
+			labels = append(labels, fmt.Sprintf("checklist item '%s'", item))
+		}
+
+		for j, link := range task.Links {
+			displayName := link.Title
+			if displayName == "" {
+				displayName = link.URL
+			}
+			requests = append(requests, BatchRequest{
+				ID:     fmt.Sprintf("task-%d-link-%d", i+1, j+1),
+				Method: "POST",
+				URL:    base + "/linkedResources",
+				Body: map[string]interface{}{
+					"webUrl":          link.URL,
+					"applicationName": linkedResourceApplicationName,
+					"displayName":     displayName,
+				},
+			})
+			owners = append(owners, result)
+			labels = append(labels, fmt.Sprintf("linked resource '%s'", link.URL))
+		}
+	}
+
+	if len(requests) == 0 {
+		return
+	}
+
+	responses, err := c.ExecuteBatch(ctx, requests, defaultBatchRetries)
+	if err != nil {
+		logger.FromContext(ctx).Warnf("批量创建检查项和关联链接失败: %v", err)
+		for i := range requests {
+			owners[i].Warnings = append(owners[i].Warnings, fmt.Sprintf("%s: %v", labels[i], err))
+		}
+		return
+	}
+
+	for i, resp := range responses {
+		if err := resp.Err(); err != nil {
+			owners[i].Warnings = append(owners[i].Warnings, fmt.Sprintf("%s: %v", labels[i], err))
+		}
+	}
+}
+
+// uniqueStrings 去重并保持原有顺序，忽略空字符串
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
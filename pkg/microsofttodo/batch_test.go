@@ -0,0 +1,193 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchHandler 解析 /$batch 请求并按 respond 返回各子响应
+func batchHandler(t *testing.T, respond func(call int, req BatchRequest) (int, string)) (http.HandlerFunc, func() [][]BatchRequest) {
+	var mu sync.Mutex
+	var calls [][]BatchRequest
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/$batch", r.URL.Path)
+
+		var payload struct {
+			Requests []BatchRequest `json:"requests"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		call := len(calls)
+		calls = append(calls, payload.Requests)
+		mu.Unlock()
+
+		var responses []map[string]interface{}
+		for _, req := range payload.Requests {
+			status, body := respond(call, req)
+			responses = append(responses, map[string]interface{}{
+				"id":     req.ID,
+				"status": status,
+				"body":   json.RawMessage(body),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"responses": responses})
+	}
+
+	return handler, func() [][]BatchRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func TestSplitBatch_KeepsDependencyChainsTogether(t *testing.T) {
+	var requests []BatchRequest
+	for i := 1; i <= 18; i++ {
+		requests = append(requests, BatchRequest{ID: fmt.Sprintf("a%d", i)})
+	}
+	// 5 个请求组成依赖链，无法放进第一批剩余的 2 个位置
+	for i := 1; i <= 5; i++ {
+		req := BatchRequest{ID: fmt.Sprintf("chain%d", i)}
+		if i > 1 {
+			req.DependsOn = []string{fmt.Sprintf("chain%d", i-1)}
+		}
+		requests = append(requests, req)
+	}
+
+	chunks, err := splitBatch(requests)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Len(t, chunks[0], 18)
+	assert.Len(t, chunks[1], 5)
+	assert.Equal(t, "chain1", chunks[1][0].ID)
+
+	_, err = splitBatch([]BatchRequest{{ID: "x", DependsOn: []string{"missing"}}})
+	assert.Error(t, err)
+}
+
+func TestExecuteBatch_RetriesThrottledAndFailedDependencies(t *testing.T) {
+	batchRetryBaseDelay = time.Millisecond
+	defer func() { batchRetryBaseDelay = time.Second }()
+
+	handler, calls := batchHandler(t, func(call int, req BatchRequest) (int, string) {
+		switch {
+		case req.ID == "list-1" && call == 0:
+			return http.StatusTooManyRequests, `{"error":{"code":"TooManyRequests","message":"slow down"}}`
+		case req.ID == "list-2" && call == 0:
+			return http.StatusFailedDependency, `{}`
+		case req.ID == "bad":
+			return http.StatusBadRequest, `{"error":{"code":"invalidRequest","message":"bad body"}}`
+		default:
+			return http.StatusCreated, fmt.Sprintf(`{"id":"%s-created"}`, req.ID)
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	responses, err := client.ExecuteBatch(context.Background(), []BatchRequest{
+		{ID: "list-1", Method: "POST", URL: "/me/todo/lists", Body: map[string]string{"displayName": "A"}},
+		{ID: "list-2", Method: "POST", URL: "/me/todo/lists", Body: map[string]string{"displayName": "B"}, DependsOn: []string{"list-1"}},
+		{ID: "bad", Method: "POST", URL: "/me/todo/lists", Body: map[string]string{}},
+	}, 3)
+	require.NoError(t, err)
+	require.Len(t, responses, 3)
+
+	assert.True(t, responses[0].IsSuccess())
+	assert.Equal(t, 2, responses[0].Attempts)
+	id, err := responses[1].DecodeID()
+	require.NoError(t, err)
+	assert.Equal(t, "list-2-created", id)
+	assert.Equal(t, 2, responses[1].Attempts)
+
+	assert.Equal(t, 1, responses[2].Attempts)
+	assert.Contains(t, responses[2].Err().Error(), "bad body")
+
+	sent := calls()
+	require.Len(t, sent, 2)
+	assert.Len(t, sent[1], 2, "only throttled and dependent requests are retried")
+	assert.Equal(t, []string{"list-1"}, sent[1][1].DependsOn)
+	assert.Equal(t, "application/json", sent[0][0].Headers["Content-Type"])
+}
+
+func TestExecuteBatch_DoesNotRetryAmbiguousPostFailures(t *testing.T) {
+	batchRetryBaseDelay = time.Millisecond
+	defer func() { batchRetryBaseDelay = time.Second }()
+
+	handler, calls := batchHandler(t, func(call int, req BatchRequest) (int, string) {
+		switch {
+		case call == 0 && req.ID != "unavailable":
+			return http.StatusInternalServerError, `{"error":{"code":"generalException","message":"boom"}}`
+		case call == 0:
+			return http.StatusServiceUnavailable, `{}`
+		default:
+			return http.StatusOK, fmt.Sprintf(`{"id":"%s-ok"}`, req.ID)
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	responses, err := client.ExecuteBatch(context.Background(), []BatchRequest{
+		{ID: "create", Method: "POST", URL: "/me/todo/lists/l/tasks", Body: map[string]string{"title": "A"}},
+		{ID: "unavailable", Method: "POST", URL: "/me/todo/lists/l/tasks", Body: map[string]string{"title": "B"}},
+		{ID: "read", Method: "GET", URL: "/me/todo/lists"},
+	}, 3)
+	require.NoError(t, err)
+
+	// POST 的 500 可能已经生效，不重试；503 和 GET 的 500 重试
+	assert.Equal(t, http.StatusInternalServerError, responses[0].Status)
+	assert.Equal(t, 1, responses[0].Attempts)
+	assert.True(t, responses[1].IsSuccess())
+	assert.True(t, responses[2].IsSuccess())
+
+	sent := calls()
+	require.Len(t, sent, 2)
+	assert.Len(t, sent[1], 2)
+}
+
+func TestResolveTaskListIDs_CreatesMissingListsOnceAndCaches(t *testing.T) {
+	var batchCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/me/todo/lists":
+			fmt.Fprint(w, `{"value":[{"id":"id-tasks","displayName":"Tasks"}]}`)
+		case "/$batch":
+			batchCalls++
+			var payload struct {
+				Requests []BatchRequest `json:"requests"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			require.Len(t, payload.Requests, 2)
+			assert.Empty(t, payload.Requests[0].DependsOn)
+			assert.Equal(t, []string{payload.Requests[0].ID}, payload.Requests[1].DependsOn)
+			fmt.Fprintf(w, `{"responses":[{"id":"%s","status":201,"body":{"id":"id-work"}},{"id":"%s","status":201,"body":{"id":"id-home"}}]}`,
+				payload.Requests[0].ID, payload.Requests[1].ID)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	ids, err := client.ResolveTaskListIDs(context.Background(), []string{"Tasks", "Work", "Home", "Work"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Tasks": "id-tasks", "Work": "id-work", "Home": "id-home"}, ids)
+
+	// 第二次全部命中缓存，不再访问服务器
+	listID, err := client.GetOrCreateTaskList("Home")
+	require.NoError(t, err)
+	assert.Equal(t, "id-home", listID)
+	assert.Equal(t, 1, batchCalls)
+}
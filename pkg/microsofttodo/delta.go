@@ -0,0 +1,136 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+)
+
+// ErrDeltaTokenExpired 增量同步令牌已失效，需要重新进行全量同步
+var ErrDeltaTokenExpired = errors.New("delta token expired, full resync required")
+
+// TaskListInfo 任务列表信息
+type TaskListInfo struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// TaskDelta 单个任务的增量变更
+type TaskDelta struct {
+	Task    TaskInfo `json:"task"`
+	Removed bool     `json:"removed"` // 任务已在 To Do 中被删除
+}
+
+// DeltaResult 增量查询结果
+type DeltaResult struct {
+	Changes   []TaskDelta `json:"changes"`
+	DeltaLink string      `json:"delta_link"` // 下次增量查询使用的链接
+	Pages     int         `json:"pages"`
+}
+
+// ListTaskLists 获取当前用户的全部任务列表（跟随所有分页）
+func (c *SimpleTodoClient) ListTaskLists(ctx context.Context) ([]TaskListInfo, error) {
+	var lists []TaskListInfo
+
+	endpoint := "/me/todo/lists"
+	for endpoint != "" {
+		resp, err := c.makeAPIRequestWithRetry(ctx, "GET", endpoint, nil, 2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task lists: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := parseGraphAPIError(resp)
+			resp.Body.Close()
+			return nil, apiErr
+		}
+
+		var page struct {
+			Value    []TaskListInfo `json:"value"`
+			NextLink string         `json:"@odata.nextLink"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode task lists response: %v", err)
+		}
+
+		lists = append(lists, page.Value...)
+		endpoint = page.NextLink
+	}
+
+	return lists, nil
+}
+
+// GetTaskDelta 获取任务列表的增量变更
+// deltaLink 为空时从头开始（首次同步会返回列表中的全部任务），
+// 否则使用上次返回的 deltaLink 只获取之后的变更。
+func (c *SimpleTodoClient) GetTaskDelta(ctx context.Context, listID, deltaLink string) (*DeltaResult, error) {
+	log := logger.FromContext(ctx)
+	endpoint := deltaLink
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("/me/todo/lists/%s/tasks/delta", listID)
+	}
+
+	result := &DeltaResult{}
+	for endpoint != "" {
+		resp, err := c.makeAPIRequestWithRetry(ctx, "GET", endpoint, nil, 2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query task delta: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := parseGraphAPIError(resp)
+			resp.Body.Close()
+			if isDeltaTokenExpired(apiErr) {
+				log.Warnf("列表 %s 的增量同步令牌已失效: %s", listID, apiErr.Message)
+				return nil, ErrDeltaTokenExpired
+			}
+			return nil, apiErr
+		}
+
+		var page struct {
+			Value     []graphTask `json:"value"`
+			NextLink  string      `json:"@odata.nextLink"`
+			DeltaLink string      `json:"@odata.deltaLink"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode task delta response: %v", err)
+		}
+
+		result.Pages++
+		for i := range page.Value {
+			result.Changes = append(result.Changes, TaskDelta{
+				Task:    page.Value[i].toTaskInfo(),
+				Removed: page.Value[i].Removed != nil,
+			})
+		}
+
+		// 最后一页返回 deltaLink，之前的页返回 nextLink
+		if page.DeltaLink != "" {
+			result.DeltaLink = page.DeltaLink
+		}
+		endpoint = page.NextLink
+	}
+
+	log.Debugf("Fetched %d task changes in %d page(s) from list %s", len(result.Changes), result.Pages, listID)
+	return result, nil
+}
+
+// isDeltaTokenExpired 判断是否为增量令牌失效错误
+func isDeltaTokenExpired(apiErr *GraphAPIError) bool {
+	if apiErr.StatusCode == http.StatusGone {
+		return true
+	}
+	switch apiErr.Code {
+	case "syncStateNotFound", "resyncRequired", "SyncStateNotFound", "ResyncRequired":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	timezonepkg "github.com/allanpk716/to_icalendar/pkg/timezone"
+)
+
+// defaultEventDuration 没有结束时间时日历事件的默认时长
+const defaultEventDuration = 30 * time.Minute
+
+// EventAttendee 日历事件参会人
+type EventAttendee struct {
+	Email string
+	Name  string // 没有邮箱时只写入事件正文
+}
+
+// String 正文中显示的参会人，如 "张三 <zhangsan@example.com>"
+func (a EventAttendee) String() string {
+	switch {
+	case a.Email == "":
+		return a.Name
+	case a.Name == "":
+		return a.Email
+	}
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// EventRequest 创建 Outlook 日历事件的请求
+type EventRequest struct {
+	Subject         string
+	Body            string
+	Start           time.Time // UTC 时间
+	End             time.Time // UTC 时间，为零值时使用默认时长
+	Timezone        string    // 用户时区名称，用于 Graph API 时间转换
+	Location        string
+	Attendees       []EventAttendee
+	SendInvitations bool // 为 true 时有邮箱的参会人加入事件，Graph 会从用户账号发送会议邀请；否则参会人只写入正文
+	ReminderMinutes int  // 提前提醒分钟数，小于 0 表示不提醒
+	Importance      int  // 1 低, 5 中, 9 高
+	Categories      []string
+}
+
+// CreatedEvent 创建日历事件的结果
+type CreatedEvent struct {
+	ID      string `json:"id"`
+	WebLink string `json:"web_link,omitempty"`
+}
+
+// CreateEvent 在用户默认日历中创建事件
+func (c *SimpleTodoClient) CreateEvent(ctx context.Context, req *EventRequest) (*CreatedEvent, error) {
+	log := logger.FromContext(ctx)
+	log.Infof("Creating calendar event: %s", req.Subject)
+
+	// 与任务创建相同，结果不明确时先确认事件是否已创建
+	createCtx := WithIdempotencyCheck(ctx, c.createdEventCheck(req, time.Now()))
+
+	resp, err := c.makeAPIRequest(createCtx, "POST", "/me/events", buildEventBody(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		apiErr := parseGraphAPIError(resp)
+		return nil, fmt.Errorf("failed to create event with status: %d, error: %s", resp.StatusCode, apiErr.Message)
+	}
+
+	var created struct {
+		ID      string `json:"id"`
+		WebLink string `json:"webLink"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode created event response: %v", err)
+	}
+
+	log.Infof("Successfully created event '%s' with ID: %s", req.Subject, created.ID)
+	return &CreatedEvent{ID: created.ID, WebLink: created.WebLink}, nil
+}
+
+// createdEventCheck 查找本次请求是否已创建了同名事件
+// 创建时间允许一分钟的时钟偏差
+func (c *SimpleTodoClient) createdEventCheck(req *EventRequest, startedAt time.Time) IdempotencyCheck {
+	return func(ctx context.Context) (*http.Response, error) {
+		query := url.Values{}
+		query.Set("$filter", fmt.Sprintf("subject eq '%s'", escapeODataString(req.Subject)))
+		query.Set("$select", "id,subject,createdDateTime")
+		resp, err := c.makeAPIRequest(ctx, "GET", "/me/events?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, parseGraphAPIError(resp)
+		}
+
+		var result struct {
+			Value []struct {
+				ID              string `json:"id"`
+				Subject         string `json:"subject"`
+				CreatedDateTime string `json:"createdDateTime"`
+			} `json:"value"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, err
+		}
+		for _, event := range result.Value {
+			if event.Subject == req.Subject && !parseTime(event.CreatedDateTime).Before(startedAt.Add(-time.Minute)) {
+				return createdResponse(event.ID), nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// buildEventBody 构建创建事件的请求体
+func buildEventBody(req *EventRequest) map[string]interface{} {
+	end := req.End
+	if end.IsZero() || !end.After(req.Start) {
+		end = req.Start.Add(defaultEventDuration)
+	}
+
+	event := map[string]interface{}{
+		"subject": req.Subject,
+		"start": map[string]interface{}{
+			"dateTime": timezonepkg.FormatTimeForGraphAPI(req.Start, req.Timezone),
+			"timeZone": req.Timezone,
+		},
+		"end": map[string]interface{}{
+			"dateTime": timezonepkg.FormatTimeForGraphAPI(end, req.Timezone),
+			"timeZone": req.Timezone,
+		},
+	}
+
+	// 参会人来自剪贴板或截图识别结果，默认只写入正文，避免向识别出的地址发送邀请
+	var invited, listed []EventAttendee
+	for _, attendee := range req.Attendees {
+		if req.SendInvitations && attendee.Email != "" {
+			invited = append(invited, attendee)
+		} else {
+			listed = append(listed, attendee)
+		}
+	}
+
+	content := req.Body
+	if len(listed) > 0 {
+		names := make([]string, 0, len(listed))
+		for _, attendee := range listed {
+			names = append(names, attendee.String())
+		}
+		line := "参会人: " + strings.Join(names, ", ")
+		if content == "" {
+			content = line
+		} else {
+			content = content + "\n\n" + line
+		}
+	}
+	if content != "" {
+		event["body"] = map[string]interface{}{
+			"content":     content,
+			"contentType": "text",
+		}
+	}
+
+	if req.Location != "" {
+		event["location"] = map[string]interface{}{
+			"displayName": req.Location,
+		}
+	}
+
+	if len(invited) > 0 {
+		attendees := make([]map[string]interface{}, 0, len(invited))
+		for _, attendee := range invited {
+			address := map[string]interface{}{"address": attendee.Email}
+			if attendee.Name != "" {
+				address["name"] = attendee.Name
+			}
+			attendees = append(attendees, map[string]interface{}{
+				"emailAddress": address,
+				"type":         "required",
+			})
+		}
+		event["attendees"] = attendees
+	}
+
+	// 提前提醒
+	if req.ReminderMinutes >= 0 {
+		event["isReminderOn"] = true
+		event["reminderMinutesBeforeStart"] = req.ReminderMinutes
+	} else {
+		event["isReminderOn"] = false
+	}
+
+	switch req.Importance {
+	case 1:
+		event["importance"] = "low"
+	case 9:
+		event["importance"] = "high"
+	default:
+		event["importance"] = "normal"
+	}
+
+	if len(req.Categories) > 0 {
+		event["categories"] = req.Categories
+	}
+
+	return event
+}
+
+// TestCalendarAccess 检查是否有读写日历的权限（Calendars.ReadWrite）
+func (c *SimpleTodoClient) TestCalendarAccess(ctx context.Context) (string, error) {
+	resp, err := c.makeAPIRequest(ctx, "GET", "/me/calendar?$select=id,name,canEdit", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to access calendar: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := parseGraphAPIError(resp)
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+			return "", fmt.Errorf("no calendar permission (Calendars.ReadWrite required, re-authorize to grant it): %s", apiErr.Message)
+		}
+		return "", fmt.Errorf("calendar request failed with status: %d, error: %s", resp.StatusCode, apiErr.Message)
+	}
+
+	var calendar struct {
+		Name    string `json:"name"`
+		CanEdit bool   `json:"canEdit"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&calendar); err != nil {
+		return "", fmt.Errorf("failed to decode calendar response: %v", err)
+	}
+	if !calendar.CanEdit {
+		return "", fmt.Errorf("calendar '%s' is read-only", calendar.Name)
+	}
+	return strings.TrimSpace(calendar.Name), nil
+}
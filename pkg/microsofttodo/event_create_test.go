@@ -0,0 +1,115 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEvent_PostsEventBody(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/me/events", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"event-1","webLink":"https://outlook.office365.com/owa/?itemid=event-1"}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	start := time.Date(2025, 3, 11, 6, 30, 0, 0, time.UTC)
+	created, err := client.CreateEvent(context.Background(), &EventRequest{
+		Subject:         "架构评审会",
+		Body:            "评审消息队列迁移方案",
+		Start:           start,
+		End:             start.Add(2 * time.Hour),
+		Timezone:        "Asia/Shanghai",
+		Location:        "3号会议室",
+		Attendees:       []EventAttendee{{Email: "zhangsan@example.com", Name: "张三"}, {Name: "李四"}},
+		SendInvitations: true,
+		ReminderMinutes: 15,
+		Importance:      9,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "event-1", created.ID)
+	assert.Contains(t, created.WebLink, "event-1")
+
+	assert.Equal(t, "架构评审会", body["subject"])
+	assert.Equal(t, map[string]interface{}{"dateTime": "2025-03-11T14:30:00", "timeZone": "Asia/Shanghai"}, body["start"])
+	assert.Equal(t, map[string]interface{}{"dateTime": "2025-03-11T16:30:00", "timeZone": "Asia/Shanghai"}, body["end"])
+	assert.Equal(t, map[string]interface{}{"displayName": "3号会议室"}, body["location"])
+	assert.Equal(t, true, body["isReminderOn"])
+	assert.Equal(t, float64(15), body["reminderMinutesBeforeStart"])
+	assert.Equal(t, "high", body["importance"])
+
+	attendees, ok := body["attendees"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, attendees, 1)
+	attendee := attendees[0].(map[string]interface{})
+	assert.Equal(t, "required", attendee["type"])
+	assert.Equal(t, map[string]interface{}{"address": "zhangsan@example.com", "name": "张三"}, attendee["emailAddress"])
+	assert.Equal(t, "评审消息队列迁移方案\n\n参会人: 李四", body["body"].(map[string]interface{})["content"])
+}
+
+func TestBuildEventBody_ListsAttendeesWithoutInvitingByDefault(t *testing.T) {
+	body := buildEventBody(&EventRequest{
+		Subject:   "架构评审会",
+		Start:     time.Date(2025, 3, 11, 6, 30, 0, 0, time.UTC),
+		Timezone:  "UTC",
+		Attendees: []EventAttendee{{Email: "zhangsan@example.com", Name: "张三"}, {Email: "lisi@example.com"}, {Name: "王五"}},
+	})
+
+	assert.NotContains(t, body, "attendees")
+	assert.Equal(t, map[string]interface{}{
+		"content":     "参会人: 张三 <zhangsan@example.com>, lisi@example.com, 王五",
+		"contentType": "text",
+	}, body["body"])
+}
+
+func TestBuildEventBody_DefaultsEndAndDisablesReminder(t *testing.T) {
+	start := time.Date(2025, 3, 11, 6, 30, 0, 0, time.UTC)
+	body := buildEventBody(&EventRequest{
+		Subject:         "电话",
+		Start:           start,
+		Timezone:        "UTC",
+		ReminderMinutes: -1,
+	})
+
+	assert.Equal(t, map[string]interface{}{"dateTime": "2025-03-11T07:00:00", "timeZone": "UTC"}, body["end"])
+	assert.Equal(t, false, body["isReminderOn"])
+	assert.NotContains(t, body, "reminderMinutesBeforeStart")
+	assert.NotContains(t, body, "attendees")
+	assert.NotContains(t, body, "location")
+}
+
+func TestTestCalendarAccess(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/me/calendar", r.URL.Path)
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			fmt.Fprint(w, `{"id":"cal-1","name":"日历","canEdit":true}`)
+			return
+		}
+		fmt.Fprint(w, `{"error":{"code":"ErrorAccessDenied","message":"Access is denied."}}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	name, err := client.TestCalendarAccess(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "日历", name)
+
+	status = http.StatusForbidden
+	_, err = client.TestCalendarAccess(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Calendars.ReadWrite")
+}
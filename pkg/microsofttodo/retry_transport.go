@@ -0,0 +1,428 @@
+package microsofttodo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+)
+
+const (
+	// defaultTenantRate 每个租户默认的请求速率（次/秒）
+	defaultTenantRate = 10.0
+	// defaultTenantBurst 每个租户默认允许的突发请求数
+	defaultTenantBurst = 10
+	// clientRequestIDHeader Graph 用于关联请求的头，同一逻辑请求的重试保持不变
+	clientRequestIDHeader = "client-request-id"
+)
+
+// RetryPolicy Graph 请求的重试策略
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数（不含首次请求）
+	BaseDelay  time.Duration // 指数退避的基础间隔
+	MaxDelay   time.Duration // 单次等待的上限，同样作用于 Retry-After
+}
+
+// DefaultRetryPolicy 默认重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// backoff 计算第 attempt 次重试（从 0 开始）的等待时间：指数退避加随机抖动
+// 取 [d/2, d) 区间内的随机值，避免多个客户端同时重试
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << attempt
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(mathrand.Int64N(int64(half)))
+}
+
+// RateLimiter 令牌桶限流器
+type RateLimiter struct {
+	mu          sync.Mutex
+	rate        float64 // 每秒补充的令牌数
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // 服务器要求的暂停时间（Retry-After），期间所有请求等待
+}
+
+// NewRateLimiter 创建令牌桶限流器
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve 取出一个令牌，返回需要等待的时间
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+
+	var wait time.Duration
+	if l.tokens < 0 && l.rate > 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if pause := l.pausedUntil.Sub(now); pause > wait {
+		wait = pause
+	}
+	return wait
+}
+
+// Wait 等待直到可以发送下一个请求，返回实际等待的时间
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	wait := l.reserve()
+	if wait <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-timer.C:
+		return wait, nil
+	}
+}
+
+// PauseUntil 在指定时间之前暂停发放令牌
+func (l *RateLimiter) PauseUntil(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.pausedUntil) {
+		l.pausedUntil = t
+	}
+}
+
+var (
+	tenantLimitersMu sync.Mutex
+	tenantLimiters   = make(map[string]*RateLimiter)
+)
+
+// TenantRateLimiter 获取租户共享的限流器，同一租户下的所有客户端共用一个令牌桶
+func TenantRateLimiter(tenantID string) *RateLimiter {
+	tenantLimitersMu.Lock()
+	defer tenantLimitersMu.Unlock()
+
+	limiter, ok := tenantLimiters[tenantID]
+	if !ok {
+		limiter = NewRateLimiter(defaultTenantRate, defaultTenantBurst)
+		tenantLimiters[tenantID] = limiter
+	}
+	return limiter
+}
+
+// SetTenantRateLimit 设置租户的请求速率和突发数
+func SetTenantRateLimit(tenantID string, rate float64, burst int) {
+	tenantLimitersMu.Lock()
+	defer tenantLimitersMu.Unlock()
+	tenantLimiters[tenantID] = NewRateLimiter(rate, burst)
+}
+
+// RetryStats 重试统计信息
+type RetryStats struct {
+	Requests         int64         `json:"requests"`          // 逻辑请求数
+	Attempts         int64         `json:"attempts"`          // 实际发送次数
+	Retries          int64         `json:"retries"`           // 重试次数
+	Throttled        int64         `json:"throttled"`         // 收到 429 的次数
+	ServerErrors     int64         `json:"server_errors"`     // 收到 5xx 的次数
+	NetworkErrors    int64         `json:"network_errors"`    // 网络错误次数
+	GaveUp           int64         `json:"gave_up"`           // 重试用尽或不允许重试而放弃的次数
+	DuplicateAvoided int64         `json:"duplicate_avoided"` // 幂等检查发现请求已生效的次数
+	RetryWait        time.Duration `json:"retry_wait"`        // 退避与 Retry-After 的累计等待
+	LimiterWait      time.Duration `json:"limiter_wait"`      // 限流器的累计等待
+}
+
+// retryMetrics 重试计数器
+type retryMetrics struct {
+	requests         atomic.Int64
+	attempts         atomic.Int64
+	retries          atomic.Int64
+	throttled        atomic.Int64
+	serverErrors     atomic.Int64
+	networkErrors    atomic.Int64
+	gaveUp           atomic.Int64
+	duplicateAvoided atomic.Int64
+	retryWait        atomic.Int64
+	limiterWait      atomic.Int64
+}
+
+func (m *retryMetrics) snapshot() RetryStats {
+	return RetryStats{
+		Requests:         m.requests.Load(),
+		Attempts:         m.attempts.Load(),
+		Retries:          m.retries.Load(),
+		Throttled:        m.throttled.Load(),
+		ServerErrors:     m.serverErrors.Load(),
+		NetworkErrors:    m.networkErrors.Load(),
+		GaveUp:           m.gaveUp.Load(),
+		DuplicateAvoided: m.duplicateAvoided.Load(),
+		RetryWait:        time.Duration(m.retryWait.Load()),
+		LimiterWait:      time.Duration(m.limiterWait.Load()),
+	}
+}
+
+// IdempotencyCheck 非幂等请求结果不明确时调用，用于确认请求是否已在服务端生效
+// 已生效时返回代表该结果的响应，未生效时返回 nil，此时才会重新发送请求
+type IdempotencyCheck func(ctx context.Context) (*http.Response, error)
+
+type retryContextKey int
+
+const (
+	maxRetriesKey retryContextKey = iota
+	idempotencyCheckKey
+)
+
+// withMaxRetries 为单个请求覆盖最大重试次数
+func withMaxRetries(ctx context.Context, maxRetries int) context.Context {
+	return context.WithValue(ctx, maxRetriesKey, maxRetries)
+}
+
+// WithIdempotencyCheck 为 POST 等非幂等请求注册幂等检查
+// 未注册检查时，非幂等请求只在 429/503（服务器明确未处理）时重试
+func WithIdempotencyCheck(ctx context.Context, check IdempotencyCheck) context.Context {
+	return context.WithValue(ctx, idempotencyCheckKey, check)
+}
+
+// withoutIdempotencyCheck 去掉上下文中的幂等检查，避免检查请求递归触发检查
+func withoutIdempotencyCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotencyCheckKey, IdempotencyCheck(nil))
+}
+
+// RetryTransport 带限流和重试的 HTTP 传输层
+// 处理 429/503 的 Retry-After、5xx 和网络错误的指数退避，以及非幂等请求的重复保护
+type RetryTransport struct {
+	Base    http.RoundTripper
+	Policy  RetryPolicy
+	Limiter *RateLimiter
+	metrics retryMetrics
+}
+
+// NewRetryTransport 创建重试传输层，limiter 为 nil 时不限流
+func NewRetryTransport(base http.RoundTripper, policy RetryPolicy, limiter *RateLimiter) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{Base: base, Policy: policy, Limiter: limiter}
+}
+
+// Stats 获取重试统计信息
+func (t *RetryTransport) Stats() RetryStats {
+	return t.metrics.snapshot()
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	log := logger.FromContext(ctx)
+	t.metrics.requests.Add(1)
+
+	maxRetries := t.Policy.MaxRetries
+	if n, ok := ctx.Value(maxRetriesKey).(int); ok {
+		maxRetries = n
+	}
+	check, _ := ctx.Value(idempotencyCheckKey).(IdempotencyCheck)
+	idempotent := isIdempotentMethod(req.Method)
+
+	// 请求体无法重放时不能重试
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		maxRetries = 0
+	}
+	if req.Header.Get(clientRequestIDHeader) == "" {
+		req = req.Clone(ctx)
+		req.Header.Set(clientRequestIDHeader, newClientRequestID())
+	}
+
+	for attempt := 0; ; attempt++ {
+		if t.Limiter != nil {
+			waited, err := t.Limiter.Wait(ctx)
+			if err != nil {
+				return nil, err
+			}
+			t.metrics.limiterWait.Add(int64(waited))
+		}
+
+		outgoing := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %v", err)
+			}
+			outgoing = req.Clone(ctx)
+			outgoing.Body = body
+		}
+
+		t.metrics.attempts.Add(1)
+		resp, err := t.Base.RoundTrip(outgoing)
+
+		// 判断本次结果是否需要重试，以及服务器是否明确没有处理该请求
+		var retryAfter time.Duration
+		retryable, notProcessed := false, false
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			t.metrics.networkErrors.Add(1)
+			retryable = true
+		case resp.StatusCode == http.StatusTooManyRequests:
+			t.metrics.throttled.Add(1)
+			retryable, notProcessed = true, true
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		case resp.StatusCode == http.StatusServiceUnavailable:
+			t.metrics.serverErrors.Add(1)
+			retryable, notProcessed = true, true
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		case resp.StatusCode >= 500:
+			t.metrics.serverErrors.Add(1)
+			retryable = true
+		}
+
+		if !retryable {
+			return resp, nil
+		}
+
+		if attempt >= maxRetries {
+			t.metrics.gaveUp.Add(1)
+			return resp, err
+		}
+
+		// 非幂等请求结果不明确：只有确认请求未生效才重发
+		if !idempotent && !notProcessed {
+			if check == nil {
+				t.metrics.gaveUp.Add(1)
+				return resp, err
+			}
+			existing, checkErr := check(withoutIdempotencyCheck(ctx))
+			if checkErr != nil {
+				log.Warnf("幂等检查失败，放弃重试 %s %s: %v", req.Method, req.URL.Path, checkErr)
+				t.metrics.gaveUp.Add(1)
+				return resp, err
+			}
+			if existing != nil {
+				log.Infof("请求 %s %s 已在服务端生效，不再重复发送", req.Method, req.URL.Path)
+				t.metrics.duplicateAvoided.Add(1)
+				drainAndClose(resp)
+				return existing, nil
+			}
+		}
+
+		delay := t.Policy.backoff(attempt)
+		if retryAfter > 0 {
+			delay = retryAfter
+			if delay > t.Policy.MaxDelay {
+				delay = t.Policy.MaxDelay
+			}
+			if t.Limiter != nil {
+				t.Limiter.PauseUntil(time.Now().Add(delay))
+			}
+		}
+
+		// 等待时间超过上下文截止时间时直接返回本次结果
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			t.metrics.gaveUp.Add(1)
+			return resp, err
+		}
+
+		status := "network error"
+		if resp != nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		log.Infof("Graph 请求 %s %s 失败 (%s)，%v 后重试 (%d/%d)",
+			req.Method, req.URL.Path, status, delay.Round(time.Millisecond), attempt+1, maxRetries)
+		drainAndClose(resp)
+
+		t.metrics.retries.Add(1)
+		t.metrics.retryWait.Add(int64(delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数和 HTTP 日期两种格式
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// isIdempotentMethod 请求方法是否幂等，幂等请求在结果不明确时可以直接重试
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// newClientRequestID 生成 client-request-id
+func newClientRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// drainAndClose 读完并关闭响应体，使连接可以复用
+func drainAndClose(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
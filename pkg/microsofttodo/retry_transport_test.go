@@ -0,0 +1,203 @@
+package microsofttodo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetryPolicy 测试用的短间隔重试策略
+var fastRetryPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 20 * time.Millisecond}
+
+func newRetryTestClient(t *testing.T, server *httptest.Server) *SimpleTodoClient {
+	t.Helper()
+	client := newTestClient(t, server)
+	client.transport.Policy = fastRetryPolicy
+	client.transport.Limiter = nil
+	return client
+}
+
+func TestRetryTransport_HonoursRetryAfterOnThrottling(t *testing.T) {
+	var calls atomic.Int32
+	var requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get(clientRequestIDHeader))
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"displayName":"Work"}`, string(body), "retried POST must resend the body")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"list-1"}`)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server)
+	resp, err := client.makeAPIRequest(context.Background(), "POST", "/me/todo/lists", map[string]string{"displayName": "Work"})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Len(t, requestIDs, 2)
+	assert.NotEmpty(t, requestIDs[0])
+	assert.Equal(t, requestIDs[0], requestIDs[1], "client-request-id stays the same across retries")
+
+	stats := client.GetRetryStats()
+	assert.Equal(t, int64(1), stats.Requests)
+	assert.Equal(t, int64(2), stats.Attempts)
+	assert.Equal(t, int64(1), stats.Retries)
+	assert.Equal(t, int64(1), stats.Throttled)
+}
+
+func TestRetryTransport_RetriesServerErrorsForGet(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"value":[]}`)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server)
+	resp, err := client.makeAPIRequest(context.Background(), "GET", "/me/todo/lists", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, int64(2), client.GetRetryStats().ServerErrors)
+}
+
+func TestRetryTransport_DoesNotRetryAmbiguousPostWithoutCheck(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server)
+	resp, err := client.makeAPIRequest(context.Background(), "POST", "/me/todo/lists/l/tasks", map[string]string{"title": "x"})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int64(1), client.GetRetryStats().GaveUp)
+}
+
+func TestCreateTaskFromRequest_AvoidsDuplicateAfterAmbiguousFailure(t *testing.T) {
+	var posts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			// 服务器已创建任务，但响应在网关处失败
+			posts.Add(1)
+			w.WriteHeader(http.StatusGatewayTimeout)
+		case "GET":
+			assert.Equal(t, "title eq 'Pay rent'", r.URL.Query().Get("$filter"))
+			fmt.Fprintf(w, `{"value":[{"id":"task-existing","title":"Pay rent","status":"notStarted","createdDateTime":"%s"}]}`,
+				time.Now().UTC().Format(time.RFC3339))
+		}
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server)
+	created, err := client.CreateTaskFromRequest(context.Background(), &TaskRequest{Title: "Pay rent", ListID: "list-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "task-existing", created.ID)
+	assert.Equal(t, int32(1), posts.Load(), "the task must not be created twice")
+	assert.Equal(t, int64(1), client.GetRetryStats().DuplicateAvoided)
+}
+
+func TestCreateTaskFromRequest_ChecklistFailureIsNotMaskedByTaskCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/checklistItems"):
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"task-1"}`)
+		case r.Method == "GET":
+			// 任务存在检查不应用于检查项请求
+			fmt.Fprintf(w, `{"value":[{"id":"task-1","title":"Pay rent","status":"notStarted","createdDateTime":"%s"}]}`,
+				time.Now().UTC().Format(time.RFC3339))
+		}
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server)
+	created, err := client.CreateTaskFromRequest(context.Background(), &TaskRequest{
+		Title:     "Pay rent",
+		ListID:    "list-1",
+		Checklist: []string{"Transfer"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "task-1", created.ID)
+	assert.Empty(t, created.ChecklistItemIDs)
+	require.Len(t, created.Warnings, 1)
+	assert.Contains(t, created.Warnings[0], "Transfer")
+	assert.Zero(t, client.GetRetryStats().DuplicateAvoided)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("-1", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+}
+
+func TestRetryPolicy_BackoffGrowsWithJitterAndCap(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		d := policy.backoff(attempt)
+		max *= time.Millisecond
+		assert.GreaterOrEqual(t, d, max/2, "attempt %d", attempt)
+		assert.Less(t, d, max, "attempt %d", attempt)
+	}
+}
+
+func TestRateLimiter_WaitsWhenBucketEmpty(t *testing.T) {
+	limiter := NewRateLimiter(100, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		waited, err := limiter.Wait(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, waited)
+	}
+
+	waited, err := limiter.Wait(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, waited, time.Duration(0))
+
+	limiter.PauseUntil(time.Now().Add(time.Hour))
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = limiter.Wait(cancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTenantRateLimiter_SharedPerTenant(t *testing.T) {
+	assert.Same(t, TenantRateLimiter("tenant-a"), TenantRateLimiter("tenant-a"))
+	assert.NotSame(t, TenantRateLimiter("tenant-a"), TenantRateLimiter("tenant-b"))
+}
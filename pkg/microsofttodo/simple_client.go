@@ -20,9 +20,11 @@ import (
 	"time"
 
 	"github.com/allanpk716/to_icalendar/pkg/logger"
-	timezonepkg "github.com/allanpk716/to_icalendar/pkg/timezone"
 )
 
+// GraphScopes 授权时申请的权限：任务读写、日历读写、用户信息和刷新令牌
+const GraphScopes = "https://graph.microsoft.com/Tasks.ReadWrite https://graph.microsoft.com/Calendars.ReadWrite https://graph.microsoft.com/User.Read offline_access"
+
 // AuthConfig 包含 Microsoft Graph API 认证所需的配置
 type AuthConfig struct {
 	TenantID     string
@@ -128,8 +130,12 @@ func (qc *QueryCache) GetStats() map[string]interface{} {
 type SimpleTodoClient struct {
 	authConfig  *AuthConfig
 	httpClient  *http.Client
+	apiClient   *http.Client    // 访问 Graph API 的客户端，带限流与重试
+	transport   *RetryTransport
 	baseURL     string
 	queryCache  *QueryCache
+	listIDs     map[string]string // 列表名称到ID的缓存
+	listMutex   sync.RWMutex
 }
 
 // NewSimpleTodoClient 创建新的简化 Todo 客户端
@@ -139,6 +145,9 @@ func NewSimpleTodoClient(tenantID, clientID, clientSecret, userEmail string) (*S
 		return nil, fmt.Errorf("incomplete authentication configuration: tenant_id, client_id, and client_secret are all required")
 	}
 
+	// 同一租户共用一个令牌桶，避免多个客户端叠加触发 Graph 限流
+	transport := NewRetryTransport(http.DefaultTransport, DefaultRetryPolicy(), TenantRateLimiter(tenantID))
+
 	return &SimpleTodoClient{
 		authConfig: &AuthConfig{
 			TenantID:     tenantID,
@@ -147,6 +156,8 @@ func NewSimpleTodoClient(tenantID, clientID, clientSecret, userEmail string) (*S
 			UserEmail:    userEmail,
 		},
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiClient:  &http.Client{Transport: transport},
+		transport:  transport,
 		baseURL:    "https://graph.microsoft.com/v1.0",
 		queryCache: NewQueryCache(5 * time.Minute), // 查询缓存5分钟过期
 	}, nil
@@ -154,26 +165,27 @@ func NewSimpleTodoClient(tenantID, clientID, clientSecret, userEmail string) (*S
 
 // getAccessToken 获取访问令牌
 func (c *SimpleTodoClient) getAccessToken(ctx context.Context) (string, error) {
+	log := logger.FromContext(ctx)
 	// 首先尝试从缓存加载token
 	if token, err := c.loadCachedToken(); err == nil && token != nil {
 		if !token.isExpired() {
-			logger.Debugf("使用缓存的访问令牌")
+			log.Debugf("使用缓存的访问令牌")
 			return token.AccessToken, nil
 		}
 
 		// token过期但refresh token有效，尝试刷新
 		if token.RefreshToken != "" {
-			logger.Debugf("访问令牌已过期，尝试刷新...")
+			log.Debugf("访问令牌已过期，尝试刷新...")
 			if newToken, err := c.refreshAccessToken(ctx, token.RefreshToken); err == nil {
-				logger.Debugf("令牌刷新成功")
+				log.Debugf("令牌刷新成功")
 				return newToken, nil
 			}
-			logger.Warnf("令牌刷新失败: %v", err)
+			log.Warnf("令牌刷新失败: %v", err)
 		}
 	}
 
 	// 如果没有有效token或refresh失败，进行交互式认证
-	logger.Infof("未找到有效的缓存令牌，开始交互式认证")
+	log.Infof("未找到有效的缓存令牌，开始交互式认证")
 	return c.getAccessTokenInteractive(ctx)
 }
 
@@ -205,7 +217,7 @@ func (c *SimpleTodoClient) getAccessTokenInteractive(ctx context.Context) (strin
 	}
 
 	// 缓存token以备将来使用
-	logger.Infof("认证成功，缓存令牌以备将来使用")
+	logger.FromContext(ctx).Infof("认证成功，缓存令牌以备将来使用")
 	// 注意：这里简化处理，实际应该保存完整的token信息
 
 	return accessToken, nil
@@ -226,7 +238,7 @@ func (c *SimpleTodoClient) startDeviceCodeFlow(ctx context.Context) (*DeviceCode
 
 	data := url.Values{}
 	data.Set("client_id", c.authConfig.ClientID)
-	data.Set("scope", "https://graph.microsoft.com/Tasks.ReadWrite https://graph.microsoft.com/User.Read offline_access")
+	data.Set("scope", GraphScopes)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
@@ -256,6 +268,7 @@ func (c *SimpleTodoClient) startDeviceCodeFlow(ctx context.Context) (*DeviceCode
 
 // waitForDeviceCodeCompletion 等待设备码认证完成
 func (c *SimpleTodoClient) waitForDeviceCodeCompletion(ctx context.Context, deviceCode *DeviceCodeResponse) (string, error) {
+	log := logger.FromContext(ctx)
 	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", c.authConfig.TenantID)
 
 	interval := time.Duration(deviceCode.Interval) * time.Second
@@ -271,7 +284,7 @@ func (c *SimpleTodoClient) waitForDeviceCodeCompletion(ctx context.Context, devi
 			return "", fmt.Errorf("device code authentication timed out after %v", time.Since(start))
 		case <-time.After(interval):
 			// 尝试获取token
-			logger.Debugf("检查认证状态... (已用时间: %v)", time.Since(start))
+			log.Debugf("检查认证状态... (已用时间: %v)", time.Since(start))
 			data := url.Values{}
 			data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
 			data.Set("client_id", c.authConfig.ClientID)
@@ -291,7 +304,7 @@ func (c *SimpleTodoClient) waitForDeviceCodeCompletion(ctx context.Context, devi
 
 			if resp.StatusCode == http.StatusOK {
 				// 认证成功，解析token
-				logger.Infof("认证成功！ (已用时间: %v)", time.Since(start))
+				log.Infof("认证成功！ (已用时间: %v)", time.Since(start))
 				var tokenResp struct {
 					AccessToken string `json:"access_token"`
 					TokenType   string `json:"token_type"`
@@ -303,7 +316,7 @@ func (c *SimpleTodoClient) waitForDeviceCodeCompletion(ctx context.Context, devi
 				}
 			} else if resp.StatusCode != http.StatusBadRequest {
 				resp.Body.Close()
-				logger.Warnf("意外的状态码: %d", resp.StatusCode)
+				log.Warnf("意外的状态码: %d", resp.StatusCode)
 			} else {
 				// 400错误，读取详细错误信息
 				body, _ := io.ReadAll(resp.Body)
@@ -311,9 +324,9 @@ func (c *SimpleTodoClient) waitForDeviceCodeCompletion(ctx context.Context, devi
 				json.Unmarshal(body, &errorResp)
 				resp.Body.Close()
 
-				logger.Infof("Still waiting for authentication... (elapsed: %v)", time.Since(start))
+				log.Infof("Still waiting for authentication... (elapsed: %v)", time.Since(start))
 				if errorResp != nil {
-					logger.Infof("Error details: %+v", errorResp)
+					log.Infof("Error details: %+v", errorResp)
 				}
 			}
 		}
@@ -353,7 +366,7 @@ func (c *SimpleTodoClient) buildAuthURLWithPKCE(codeChallenge string) string {
 	params.Add("client_id", c.authConfig.ClientID)
 	params.Add("response_type", "code")
 	params.Add("redirect_uri", "http://localhost:8080/callback")
-	params.Add("scope", "https://graph.microsoft.com/Tasks.ReadWrite https://graph.microsoft.com/User.Read offline_access")
+	params.Add("scope", GraphScopes)
 	params.Add("state", "12345") // 简单的state值
 	params.Add("code_challenge", codeChallenge)
 	params.Add("code_challenge_method", "S256")
@@ -411,7 +424,7 @@ func (c *SimpleTodoClient) exchangeCodeForTokenWithPKCE(ctx context.Context, cod
 	// 缓存token以备将来使用
 	refreshToken := tokenResp.RefreshToken
 	if err := c.saveCachedToken(tokenResp.AccessToken, refreshToken, tokenResp.ExpiresIn); err != nil {
-		logger.Infof("Warning: Failed to cache token: %v", err)
+		logger.FromContext(ctx).Infof("Warning: Failed to cache token: %v", err)
 	}
 
 	return tokenResp.AccessToken, nil
@@ -423,7 +436,7 @@ func (c *SimpleTodoClient) buildAuthURL() string {
 	params.Add("client_id", c.authConfig.ClientID)
 	params.Add("response_type", "code")
 	params.Add("redirect_uri", "http://localhost:8080/callback")
-	params.Add("scope", "https://graph.microsoft.com/Tasks.ReadWrite https://graph.microsoft.com/User.Read offline_access")
+	params.Add("scope", GraphScopes)
 	params.Add("state", "12345") // 简单的state值
 
 	return fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/authorize?%s",
@@ -455,7 +468,7 @@ func (c *SimpleTodoClient) exchangeCodeForToken(ctx context.Context, code string
 	data.Set("code", code)
 	data.Set("grant_type", "authorization_code")
 	data.Set("redirect_uri", "http://localhost:8080/callback")
-	data.Set("scope", "https://graph.microsoft.com/Tasks.ReadWrite https://graph.microsoft.com/User.Read offline_access")
+	data.Set("scope", GraphScopes)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
@@ -497,7 +510,7 @@ func (c *SimpleTodoClient) exchangeCodeForToken(ctx context.Context, code string
 	// 缓存token以备将来使用
 	refreshToken := tokenResp.RefreshToken
 	if err := c.saveCachedToken(tokenResp.AccessToken, refreshToken, tokenResp.ExpiresIn); err != nil {
-		logger.Infof("Warning: Failed to cache token: %v", err)
+		logger.FromContext(ctx).Infof("Warning: Failed to cache token: %v", err)
 	}
 
 	return tokenResp.AccessToken, nil
@@ -511,8 +524,11 @@ func (c *SimpleTodoClient) makeAPIRequest(ctx context.Context, method, endpoint
 		return nil, err
 	}
 
-	// 准备请求
+	// 准备请求（分页返回的 @odata.nextLink 是完整 URL，直接使用）
 	url := c.baseURL + endpoint
+	if strings.HasPrefix(endpoint, "https://") || strings.HasPrefix(endpoint, "http://") {
+		url = endpoint
+	}
 	var body *bytes.Buffer
 
 	if requestBody != nil {
@@ -534,8 +550,8 @@ func (c *SimpleTodoClient) makeAPIRequest(ctx context.Context, method, endpoint
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	// 发送请求（限流、Retry-After 和退避重试由 RetryTransport 处理）
+	resp, err := c.apiClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
@@ -543,42 +559,12 @@ func (c *SimpleTodoClient) makeAPIRequest(ctx context.Context, method, endpoint
 	return resp, nil
 }
 
-// makeAPIRequestWithRetry 发起带重试机制的API请求
-func (c *SimpleTodoClient) makeAPIRequestWithRetry(ctx context.Context, method, endpoint string, requestBody interface{}, maxRetries int) (*http.Response, error) {
-	var lastErr error
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			logger.Infof("Retrying API request (attempt %d/%d): %s %s", attempt+1, maxRetries, method, endpoint)
-			// 指数退避策略
-			backoffDuration := time.Duration(attempt) * time.Second
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(backoffDuration):
-			}
-		}
-
-		resp, err := c.makeAPIRequest(ctx, method, endpoint, requestBody)
-		if err != nil {
-			lastErr = err
-			logger.Infof("API request failed (attempt %d/%d): %v", attempt+1, maxRetries, err)
-			continue
-		}
-
-		// 检查是否是服务器错误，如果是则重试
-		if resp.StatusCode >= 500 {
-			resp.Body.Close()
-			lastErr = fmt.Errorf("server error with status: %d", resp.StatusCode)
-			logger.Infof("Server error (attempt %d/%d): %d", attempt+1, maxRetries, resp.StatusCode)
-			continue
-		}
-
-		// 成功或客户端错误（4xx），直接返回
-		return resp, nil
+// makeAPIRequestWithRetry 发起API请求，maxAttempts 为包含首次请求在内的最多发送次数
+func (c *SimpleTodoClient) makeAPIRequestWithRetry(ctx context.Context, method, endpoint string, requestBody interface{}, maxAttempts int) (*http.Response, error) {
+	if maxAttempts > 0 {
+		ctx = withMaxRetries(ctx, maxAttempts-1)
 	}
-
-	return nil, fmt.Errorf("API request failed after %d attempts: %v", maxRetries, lastErr)
+	return c.makeAPIRequest(ctx, method, endpoint, requestBody)
 }
 
 // TestConnection 测试连接到 Microsoft Graph API
@@ -621,6 +607,11 @@ func (c *SimpleTodoClient) TestConnection() error {
 
 // GetOrCreateTaskList 获取或创建任务列表
 func (c *SimpleTodoClient) GetOrCreateTaskList(listName string) (string, error) {
+	if id, ok := c.cachedListID(listName); ok {
+		logger.Debugf("Using cached task list '%s' with ID: %s", listName, id)
+		return id, nil
+	}
+
 	logger.Infof("Getting or creating task list: %s", listName)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -653,6 +644,7 @@ func (c *SimpleTodoClient) GetOrCreateTaskList(listName string) (string, error)
 	for _, list := range response.Value {
 		if list.DisplayName == listName {
 			logger.Infof("Found existing task list '%s' with ID: %s", listName, list.ID)
+			c.cacheListID(listName, list.ID)
 			return list.ID, nil
 		}
 	}
@@ -664,7 +656,19 @@ func (c *SimpleTodoClient) GetOrCreateTaskList(listName string) (string, error)
 		"displayName": listName,
 	}
 
-	resp, err = c.makeAPIRequest(ctx, "POST", "/me/todo/lists", newList)
+	createCtx := WithIdempotencyCheck(ctx, func(ctx context.Context) (*http.Response, error) {
+		lists, err := c.ListTaskLists(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, list := range lists {
+			if list.DisplayName == listName {
+				return createdResponse(list.ID), nil
+			}
+		}
+		return nil, nil
+	})
+	resp, err = c.makeAPIRequest(createCtx, "POST", "/me/todo/lists", newList)
 	if err != nil {
 		return "", fmt.Errorf("failed to create task list: %v", err)
 	}
@@ -684,114 +688,25 @@ func (c *SimpleTodoClient) GetOrCreateTaskList(listName string) (string, error)
 	}
 
 	logger.Infof("Successfully created task list '%s' with ID: %s", listName, createdList.ID)
+	c.cacheListID(listName, createdList.ID)
 	return createdList.ID, nil
 }
 
 // CreateTaskWithDetails 创建带详细信息的任务
 func (c *SimpleTodoClient) CreateTaskWithDetails(title, description, listID string, dueTime, reminderTime time.Time, importance int, timezone string) error {
-	logger.Infof("Creating task: %s", title)
-	if description != "" {
-		logger.Infof("Task description: %s", description)
-	}
-	if !dueTime.IsZero() {
-		logger.Infof("Due time: %s", dueTime.Format("2006-01-02 15:04"))
-	}
-	if !reminderTime.IsZero() {
-		logger.Infof("Reminder time: %s", reminderTime.Format("2006-01-02 15:04"))
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// 创建任务请求体
-	newTask := map[string]interface{}{
-		"title": title,
-	}
-
-	if description != "" {
-		newTask["body"] = map[string]interface{}{
-			"content":     description,
-			"contentType": "text",
-		}
-	}
-
-	// 设置截止时间
-	if !dueTime.IsZero() {
-		// 使用UTC标准化处理：从UTC时间转换为目标时区
-		// 这避免了Windows系统的时区数据库问题和双重时区转换
-		targetTime := timezonepkg.ConvertToTargetTimezone(dueTime, timezone)
-		formattedTime := timezonepkg.FormatTimeForGraphAPI(dueTime, timezone)
-
-		newTask["dueDateTime"] = map[string]interface{}{
-			"dateTime": formattedTime,
-			"timeZone": timezone,
-		}
-		logger.Infof("设置截止时间: %s (原始UTC: %s, 目标时区: %s)",
-			targetTime.Format("2006-01-02 15:04:05"),
-			dueTime.UTC().Format("2006-01-02 15:04:05"),
-			timezone)
-	}
-
-	// 设置提醒时间
-	if !reminderTime.IsZero() {
-		// 使用UTC标准化处理：从UTC时间转换为目标时区
-		// 保持与dueTime处理的一致性
-		targetReminderTime := timezonepkg.ConvertToTargetTimezone(reminderTime, timezone)
-		formattedReminderTime := timezonepkg.FormatTimeForGraphAPI(reminderTime, timezone)
-
-		logger.Infof("设置提醒时间: %s (原始UTC: %s, 目标时区: %s)",
-			targetReminderTime.Format("2006-01-02 15:04:05"),
-			reminderTime.UTC().Format("2006-01-02 15:04:05"),
-			timezone)
-
-		// 使用标准化的时间格式，与dueDateTime保持一致
-		newTask["reminderDateTime"] = map[string]interface{}{
-			"dateTime": formattedReminderTime,
-			"timeZone": timezone,
-		}
-	} else {
-		logger.Warnf("提醒时间为空，Microsoft Todo不会创建提醒")
-	}
-
-	// 设置重要性
-	switch importance {
-	case 1: // 低优先级
-		newTask["importance"] = "low"
-	case 5: // 中等优先级
-		newTask["importance"] = "normal"
-	case 9: // 高优先级
-		newTask["importance"] = "high"
-	default:
-		newTask["importance"] = "normal"
-	}
-
-	// 发送创建任务请求
-	endpoint := fmt.Sprintf("/me/todo/lists/%s/tasks", listID)
-	resp, err := c.makeAPIRequest(ctx, "POST", endpoint, newTask)
-	if err != nil {
-		return fmt.Errorf("failed to create task: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		// 读取错误响应以获取更多调试信息
-		body, _ := io.ReadAll(resp.Body)
-		var errorResp map[string]interface{}
-		json.Unmarshal(body, &errorResp)
-		return fmt.Errorf("failed to create task with status: %d, error: %+v", resp.StatusCode, errorResp)
-	}
-
-	// 解析创建的任务响应
-	var createdTask struct {
-		ID string `json:"id"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&createdTask); err != nil {
-		return fmt.Errorf("failed to decode created task response: %v", err)
-	}
-
-	logger.Infof("Successfully created task '%s' with ID: %s", title, createdTask.ID)
-	return nil
+	_, err := c.CreateTaskFromRequest(ctx, &TaskRequest{
+		Title:        title,
+		Description:  description,
+		ListID:       listID,
+		DueTime:      dueTime,
+		ReminderTime: reminderTime,
+		Importance:   importance,
+		Timezone:     timezone,
+	})
+	return err
 }
 
 // CreateTask 创建任务（保持向后兼容）
@@ -812,6 +727,7 @@ func (c *SimpleTodoClient) GetServerInfo() (map[string]interface{}, error) {
 	} else {
 		info["status"] = "Connected"
 	}
+	info["retry_stats"] = c.GetRetryStats()
 
 	return info, nil
 }
@@ -930,7 +846,7 @@ func (c *SimpleTodoClient) refreshAccessToken(ctx context.Context, refreshToken
 	// 如果返回了新的refresh token，更新缓存
 	if tokenResp.RefreshToken != "" {
 		if err := c.saveCachedToken(tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn); err != nil {
-			logger.Infof("Warning: Failed to cache refreshed token: %v", err)
+			logger.FromContext(ctx).Infof("Warning: Failed to cache refreshed token: %v", err)
 		}
 	}
 
@@ -957,6 +873,8 @@ type TaskInfo struct {
 	DueDateTime   string    `json:"dueDateTime"`
 	Importance    string    `json:"importance"`
 	IsCompleted   bool      `json:"completed"`
+	LastModifiedAt time.Time `json:"lastModifiedDateTime,omitempty"`
+	CompletedAt    time.Time `json:"completedDateTime,omitempty"`
 }
 
 // generateQueryCacheKey 生成查询缓存键
@@ -979,106 +897,28 @@ func (c *SimpleTodoClient) QueryIncompleteTasks(listName string) ([]TaskInfo, er
 		return tasks, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	// 首先获取任务列表ID
 	listID, err := c.GetOrCreateTaskList(listName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task list: %v", err)
 	}
 
-	// 构建查询参数，获取所有任务，然后在客户端过滤
-	// 简化查询，避免复杂的 OData 过滤语法
-	endpoint := fmt.Sprintf("/me/todo/lists/%s/tasks", listID)
-	logger.Infof("Query endpoint: %s", endpoint) // 调试信息
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	resp, err := c.makeAPIRequestWithRetry(ctx, "GET", endpoint, nil, 2)
+	// 服务器端过滤未完成任务，不支持时降级为客户端过滤
+	tasks, err := c.ListTasks(ctx, listID, TaskQueryOptions{
+		Filter:  incompleteTaskFilter(),
+		OrderBy: "createdDateTime desc",
+		ClientFilter: func(task TaskInfo) bool {
+			return !task.IsCompleted
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tasks: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		var errorResp map[string]interface{}
-		if err := json.Unmarshal(body, &errorResp); err != nil {
-			logger.Infof("Failed to unmarshal error response: %v, raw body: %s", err, string(body))
-		}
-
-		// 记录详细的错误信息用于调试
-		logger.Infof("Query failed with status: %d, endpoint: %s", resp.StatusCode, endpoint)
-		logger.Infof("Error response body: %s", string(body))
-
-		// 检查特定的错误类型
-		if errorResp != nil {
-			if error, ok := errorResp["error"].(map[string]interface{}); ok {
-				if code, ok := error["code"].(string); ok {
-					switch code {
-					case "Request_ResourceNotFound":
-						return nil, fmt.Errorf("task list not found: %s", listName)
-					case "AuthenticationError":
-						return nil, fmt.Errorf("authentication failed, please re-authenticate")
-					case "InvalidFilterClause":
-						return nil, fmt.Errorf("invalid OData filter clause in query: %s", endpoint)
-					}
-				}
-			}
-		}
-
-		return nil, fmt.Errorf("failed to query tasks with status: %d, error: %+v", resp.StatusCode, errorResp)
-	}
-
-	// 解析响应
-	var response struct {
-		Value []struct {
-			ID            string                 `json:"id"`
-			Title         string                 `json:"title"`
-			Body          map[string]interface{} `json:"body,omitempty"`
-			Status        string                 `json:"status"`
-			CreatedDateTime string               `json:"createdDateTime"`
-			DueDateTime   map[string]interface{} `json:"dueDateTime,omitempty"`
-			Importance    string                 `json:"importance"`
-		} `json:"value"`
+		return nil, wrapTaskQueryError(err, listName, "failed to query tasks")
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode tasks response: %v", err)
-	}
-
-	// 转换为TaskInfo结构
-	var tasks []TaskInfo
-	for _, task := range response.Value {
-		taskInfo := TaskInfo{
-			ID:          task.ID,
-			Title:       task.Title,
-			Status:      task.Status,
-			CreatedAt:   parseTime(task.CreatedDateTime),
-			Importance:  task.Importance,
-			IsCompleted: task.Status == "completed",
-		}
-
-		// 提取描述信息
-		if task.Body != nil {
-			if content, ok := task.Body["content"].(string); ok {
-				taskInfo.Description = content
-			}
-		}
-
-		// 提取截止时间
-		if task.DueDateTime != nil {
-			if dateTime, ok := task.DueDateTime["dateTime"].(string); ok {
-				taskInfo.DueDateTime = dateTime
-			}
-		}
-
-			// 只包含未完成的任务
-		if !taskInfo.IsCompleted {
-			tasks = append(tasks, taskInfo)
-		}
-	}
-
-	logger.Infof("Found %d incomplete tasks in list '%s' (filtered from %d total)", len(tasks), listName, len(response.Value))
+	logger.Infof("Found %d incomplete tasks in list '%s'", len(tasks), listName)
 
 	// 将结果添加到缓存
 	c.queryCache.Set(cacheKey, tasks)
@@ -1104,121 +944,42 @@ func (c *SimpleTodoClient) QueryTasksByTitle(listName, titleKeyword string, inco
 		return tasks, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	// 获取任务列表ID
 	listID, err := c.GetOrCreateTaskList(listName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task list: %v", err)
 	}
 
-	// 构建查询参数 - 使用最简单的查询方式
-	endpoint := fmt.Sprintf("/me/todo/lists/%s/tasks", listID)
-	logger.Infof("Query endpoint: %s", endpoint) // 调试信息
-
-	resp, err := c.makeAPIRequestWithRetry(ctx, "GET", endpoint, nil, 2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tasks by title: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		var errorResp map[string]interface{}
-		if err := json.Unmarshal(body, &errorResp); err != nil {
-			logger.Infof("Failed to unmarshal error response: %v, raw body: %s", err, string(body))
-		}
-
-		// 记录详细的错误信息用于调试
-		logger.Infof("Query failed with status: %d, endpoint: %s", resp.StatusCode, endpoint)
-		logger.Infof("Error response body: %s", string(body))
-
-		// 检查特定的错误类型
-		if errorResp != nil {
-			if error, ok := errorResp["error"].(map[string]interface{}); ok {
-				if code, ok := error["code"].(string); ok {
-					switch code {
-					case "Request_ResourceNotFound":
-						return nil, fmt.Errorf("task list not found: %s", listName)
-					case "AuthenticationError":
-						return nil, fmt.Errorf("authentication failed, please re-authenticate")
-					case "InvalidFilterClause":
-						return nil, fmt.Errorf("invalid OData filter clause in query")
-					}
-				}
-			}
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-		return nil, fmt.Errorf("failed to query tasks by title with status: %d, error: %+v", resp.StatusCode, errorResp)
+	// 组合服务器端过滤条件
+	var filters []string
+	if incompleteOnly {
+		filters = append(filters, incompleteTaskFilter())
 	}
-
-	// 解析响应
-	var response struct {
-		Value []struct {
-			ID            string                 `json:"id"`
-			Title         string                 `json:"title"`
-			Body          map[string]interface{} `json:"body,omitempty"`
-			Status        string                 `json:"status"`
-			CreatedDateTime string               `json:"createdDateTime"`
-			DueDateTime   map[string]interface{} `json:"dueDateTime,omitempty"`
-			Importance    string                 `json:"importance"`
-		} `json:"value"`
+	if titleKeyword != "" {
+		filters = append(filters, titleContainsFilter(titleKeyword))
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode tasks response: %v", err)
-	}
-
-	// 转换为TaskInfo结构
-	var tasks []TaskInfo
-	for _, task := range response.Value {
-		taskInfo := TaskInfo{
-			ID:          task.ID,
-			Title:       task.Title,
-			Status:      task.Status,
-			CreatedAt:   parseTime(task.CreatedDateTime),
-			Importance:  task.Importance,
-			IsCompleted: task.Status == "completed",
-		}
-
-		// 提取描述信息
-		if task.Body != nil {
-			if content, ok := task.Body["content"].(string); ok {
-				taskInfo.Description = content
-			}
-		}
-
-		// 提取截止时间
-		if task.DueDateTime != nil {
-			if dateTime, ok := task.DueDateTime["dateTime"].(string); ok {
-				taskInfo.DueDateTime = dateTime
-			}
-		}
-
-		// 客户端过滤条件
-		shouldInclude := true
-
-		// 过滤已完成任务
-		if incompleteOnly && taskInfo.IsCompleted {
-			shouldInclude = false
-		}
-
-		// 按标题关键词过滤
-		if titleKeyword != "" {
-			titleLower := strings.ToLower(taskInfo.Title)
-			keywordLower := strings.ToLower(titleKeyword)
-			if !strings.Contains(titleLower, keywordLower) {
-				shouldInclude = false
+	keywordLower := strings.ToLower(titleKeyword)
+	tasks, err := c.ListTasks(ctx, listID, TaskQueryOptions{
+		Filter:  strings.Join(filters, " and "),
+		OrderBy: "createdDateTime desc",
+		ClientFilter: func(task TaskInfo) bool {
+			// 过滤已完成任务
+			if incompleteOnly && task.IsCompleted {
+				return false
 			}
-		}
-
-		if shouldInclude {
-			tasks = append(tasks, taskInfo)
-		}
+			// 按标题关键词过滤
+			return keywordLower == "" || strings.Contains(strings.ToLower(task.Title), keywordLower)
+		},
+	})
+	if err != nil {
+		return nil, wrapTaskQueryError(err, listName, "failed to query tasks by title")
 	}
 
-	logger.Infof("Found %d tasks matching '%s' in list '%s' (filtered from %d total)", len(tasks), titleKeyword, listName, len(response.Value))
+	logger.Infof("Found %d tasks matching '%s' in list '%s'", len(tasks), titleKeyword, listName)
 
 	// 将结果添加到缓存
 	c.queryCache.Set(cacheKey, tasks)
@@ -1227,6 +988,28 @@ func (c *SimpleTodoClient) QueryTasksByTitle(listName, titleKeyword string, inco
 	return tasks, nil
 }
 
+// wrapTaskQueryError 将任务查询错误转换为更友好的错误信息
+func wrapTaskQueryError(err error, listName, message string) error {
+	if apiErr, ok := err.(*GraphAPIError); ok {
+		switch apiErr.Code {
+		case "Request_ResourceNotFound", "ErrorItemNotFound":
+			return fmt.Errorf("task list not found: %s", listName)
+		case "AuthenticationError", "InvalidAuthenticationToken":
+			return fmt.Errorf("authentication failed, please re-authenticate")
+		}
+		return fmt.Errorf("%s with status: %d, error: %s", message, apiErr.StatusCode, apiErr.Message)
+	}
+	return fmt.Errorf("%s: %v", message, err)
+}
+
+// GetRetryStats 获取 Graph 请求的重试与限流统计
+func (c *SimpleTodoClient) GetRetryStats() RetryStats {
+	if c.transport == nil {
+		return RetryStats{}
+	}
+	return c.transport.Stats()
+}
+
 // GetQueryCacheStats 获取查询缓存统计信息
 func (c *SimpleTodoClient) GetQueryCacheStats() map[string]interface{} {
 	if c.queryCache == nil {
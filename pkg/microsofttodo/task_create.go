@@ -0,0 +1,246 @@
+package microsofttodo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	timezonepkg "github.com/allanpk716/to_icalendar/pkg/timezone"
+)
+
+// linkedResourceApplicationName 创建关联资源时使用的应用名称
+const linkedResourceApplicationName = "to_icalendar"
+
+// LinkedResource 任务关联资源（链接）
+type LinkedResource struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+// TaskRequest 创建任务的完整请求
+type TaskRequest struct {
+	Title        string
+	Description  string
+	ListID       string
+	DueTime      time.Time // UTC 时间
+	ReminderTime time.Time // UTC 时间
+	Importance   int       // 1 低, 5 中, 9 高
+	Timezone     string    // 用户时区名称，用于 Graph API 时间转换
+
+	Checklist  []string         // 子步骤，创建为 checklistItems
+	Categories []string         // 分类标签
+	Links      []LinkedResource // 关联链接，创建为 linkedResources
+}
+
+// CreatedTask 创建任务的结果
+type CreatedTask struct {
+	ID                string   `json:"id"`
+	ChecklistItemIDs  []string `json:"checklist_item_ids,omitempty"`
+	LinkedResourceIDs []string `json:"linked_resource_ids,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"` // 子资源创建失败等非致命问题
+}
+
+// CreateTaskFromRequest 根据完整请求创建任务，包括检查项、分类和关联链接
+// 任务本身创建失败时返回错误；检查项或链接创建失败只记录警告，不影响已创建的任务
+func (c *SimpleTodoClient) CreateTaskFromRequest(ctx context.Context, req *TaskRequest) (*CreatedTask, error) {
+	log := logger.FromContext(ctx)
+	log.Infof("Creating task: %s", req.Title)
+
+	// 创建请求结果不明确（5xx、网络错误）时，先确认任务是否已创建再决定是否重发
+	// 检查只针对任务本身，检查项和链接的创建请求不能使用
+	createCtx := WithIdempotencyCheck(ctx, c.createdTaskCheck(req, time.Now()))
+
+	endpoint := fmt.Sprintf("/me/todo/lists/%s/tasks", req.ListID)
+	resp, err := c.makeAPIRequest(createCtx, "POST", endpoint, buildTaskBody(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		apiErr := parseGraphAPIError(resp)
+		return nil, fmt.Errorf("failed to create task with status: %d, error: %s", resp.StatusCode, apiErr.Message)
+	}
+
+	var createdTask struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&createdTask); err != nil {
+		return nil, fmt.Errorf("failed to decode created task response: %v", err)
+	}
+
+	result := &CreatedTask{ID: createdTask.ID}
+	log.Infof("Successfully created task '%s' with ID: %s", req.Title, createdTask.ID)
+
+	// 创建检查项
+	for _, item := range req.Checklist {
+		id, err := c.createTaskSubResource(ctx, req.ListID, createdTask.ID, "checklistItems", map[string]interface{}{
+			"displayName": item,
+		})
+		if err != nil {
+			log.Warnf("创建检查项 '%s' 失败: %v", item, err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("checklist item '%s': %v", item, err))
+			continue
+		}
+		result.ChecklistItemIDs = append(result.ChecklistItemIDs, id)
+	}
+
+	// 创建关联链接
+	for _, link := range req.Links {
+		displayName := link.Title
+		if displayName == "" {
+			displayName = link.URL
+		}
+		id, err := c.createTaskSubResource(ctx, req.ListID, createdTask.ID, "linkedResources", map[string]interface{}{
+			"webUrl":          link.URL,
+			"applicationName": linkedResourceApplicationName,
+			"displayName":     displayName,
+		})
+		if err != nil {
+			log.Warnf("创建关联链接 '%s' 失败: %v", link.URL, err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("linked resource '%s': %v", link.URL, err))
+			continue
+		}
+		result.LinkedResourceIDs = append(result.LinkedResourceIDs, id)
+	}
+
+	if len(req.Checklist) > 0 || len(req.Links) > 0 {
+		log.Infof("任务 %s 已添加 %d 个检查项, %d 个关联链接",
+			createdTask.ID, len(result.ChecklistItemIDs), len(result.LinkedResourceIDs))
+	}
+
+	return result, nil
+}
+
+// createdTaskCheck 查找本次请求是否已创建了同名任务
+// 创建时间允许一分钟的时钟偏差
+func (c *SimpleTodoClient) createdTaskCheck(req *TaskRequest, startedAt time.Time) IdempotencyCheck {
+	return func(ctx context.Context) (*http.Response, error) {
+		matches := func(task TaskInfo) bool {
+			return task.Title == req.Title && !task.CreatedAt.Before(startedAt.Add(-time.Minute))
+		}
+		tasks, err := c.ListTasks(ctx, req.ListID, TaskQueryOptions{
+			Filter:       fmt.Sprintf("title eq '%s'", escapeODataString(req.Title)),
+			ClientFilter: func(task TaskInfo) bool { return task.Title == req.Title },
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			if matches(task) {
+				return createdResponse(task.ID), nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// createdResponse 构造表示资源已创建的响应，供幂等检查返回
+func createdResponse(id string) *http.Response {
+	body, _ := json.Marshal(map[string]string{"id": id})
+	return &http.Response{
+		StatusCode: http.StatusCreated,
+		Status:     "201 Created",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// createTaskSubResource 在任务下创建子资源（checklistItems / linkedResources），返回子资源ID
+func (c *SimpleTodoClient) createTaskSubResource(ctx context.Context, listID, taskID, resource string, body map[string]interface{}) (string, error) {
+	endpoint := fmt.Sprintf("/me/todo/lists/%s/tasks/%s/%s", listID, taskID, resource)
+	resp, err := c.makeAPIRequest(ctx, "POST", endpoint, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", parseGraphAPIError(resp)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode %s response: %v", resource, err)
+	}
+	return created.ID, nil
+}
+
+// buildTaskBody 构建创建任务的请求体
+func buildTaskBody(req *TaskRequest) map[string]interface{} {
+	newTask := map[string]interface{}{
+		"title": req.Title,
+	}
+
+	if req.Description != "" {
+		logger.Infof("Task description: %s", req.Description)
+		newTask["body"] = map[string]interface{}{
+			"content":     req.Description,
+			"contentType": "text",
+		}
+	}
+
+	// 设置截止时间
+	if !req.DueTime.IsZero() {
+		// 使用UTC标准化处理：从UTC时间转换为目标时区
+		// 这避免了Windows系统的时区数据库问题和双重时区转换
+		targetTime := timezonepkg.ConvertToTargetTimezone(req.DueTime, req.Timezone)
+		formattedTime := timezonepkg.FormatTimeForGraphAPI(req.DueTime, req.Timezone)
+
+		newTask["dueDateTime"] = map[string]interface{}{
+			"dateTime": formattedTime,
+			"timeZone": req.Timezone,
+		}
+		logger.Infof("设置截止时间: %s (原始UTC: %s, 目标时区: %s)",
+			targetTime.Format("2006-01-02 15:04:05"),
+			req.DueTime.UTC().Format("2006-01-02 15:04:05"),
+			req.Timezone)
+	}
+
+	// 设置提醒时间
+	if !req.ReminderTime.IsZero() {
+		// 使用UTC标准化处理：从UTC时间转换为目标时区
+		// 保持与dueTime处理的一致性
+		targetReminderTime := timezonepkg.ConvertToTargetTimezone(req.ReminderTime, req.Timezone)
+		formattedReminderTime := timezonepkg.FormatTimeForGraphAPI(req.ReminderTime, req.Timezone)
+
+		logger.Infof("设置提醒时间: %s (原始UTC: %s, 目标时区: %s)",
+			targetReminderTime.Format("2006-01-02 15:04:05"),
+			req.ReminderTime.UTC().Format("2006-01-02 15:04:05"),
+			req.Timezone)
+
+		// 使用标准化的时间格式，与dueDateTime保持一致
+		newTask["reminderDateTime"] = map[string]interface{}{
+			"dateTime": formattedReminderTime,
+			"timeZone": req.Timezone,
+		}
+	} else {
+		logger.Warnf("提醒时间为空，Microsoft Todo不会创建提醒")
+	}
+
+	// 设置重要性
+	switch req.Importance {
+	case 1: // 低优先级
+		newTask["importance"] = "low"
+	case 5: // 中等优先级
+		newTask["importance"] = "normal"
+	case 9: // 高优先级
+		newTask["importance"] = "high"
+	default:
+		newTask["importance"] = "normal"
+	}
+
+	// 设置分类
+	if len(req.Categories) > 0 {
+		newTask["categories"] = req.Categories
+	}
+
+	return newTask
+}
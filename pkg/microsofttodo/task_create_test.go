@@ -0,0 +1,96 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTaskFromRequest_CreatesChecklistAndLinks(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string][]map[string]interface{}{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		bodies[r.URL.Path] = append(bodies[r.URL.Path], body)
+		count := len(bodies[r.URL.Path])
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/me/todo/lists/list-1/tasks":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"task-1"}`)
+		case "/me/todo/lists/list-1/tasks/task-1/checklistItems":
+			if body["displayName"] == "broken" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"code":"invalidRequest","message":"bad item"}}`)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"item-%d"}`, count)
+		case "/me/todo/lists/list-1/tasks/task-1/linkedResources":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"link-%d"}`, count)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	created, err := client.CreateTaskFromRequest(context.Background(), &TaskRequest{
+		Title:      "准备周会",
+		ListID:     "list-1",
+		Importance: 9,
+		Timezone:   "UTC",
+		Checklist:  []string{"整理数据", "broken", "发送议程"},
+		Categories: []string{"工作", "会议"},
+		Links:      []LinkedResource{{URL: "https://example.com/doc", Title: "会议文档"}, {URL: "https://example.com/raw"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "task-1", created.ID)
+	assert.Equal(t, []string{"item-1", "item-3"}, created.ChecklistItemIDs)
+	assert.Equal(t, []string{"link-1", "link-2"}, created.LinkedResourceIDs)
+	require.Len(t, created.Warnings, 1)
+	assert.Contains(t, created.Warnings[0], "broken")
+
+	taskBody := bodies["/me/todo/lists/list-1/tasks"][0]
+	assert.Equal(t, "high", taskBody["importance"])
+	assert.Equal(t, []interface{}{"工作", "会议"}, taskBody["categories"])
+
+	links := bodies["/me/todo/lists/list-1/tasks/task-1/linkedResources"]
+	require.Len(t, links, 2)
+	assert.Equal(t, "https://example.com/doc", links[0]["webUrl"])
+	assert.Equal(t, "会议文档", links[0]["displayName"])
+	assert.Equal(t, "to_icalendar", links[0]["applicationName"])
+	assert.Equal(t, "https://example.com/raw", links[1]["displayName"])
+}
+
+func TestCreateTaskFromRequest_FailsWhenTaskRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error":{"code":"accessDenied","message":"no access"}}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	_, err := client.CreateTaskFromRequest(context.Background(), &TaskRequest{
+		Title:     "任务",
+		ListID:    "list-1",
+		Checklist: []string{"不应被创建"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no access")
+}
@@ -0,0 +1,292 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+)
+
+// defaultTaskSelectFields 查询任务时默认请求的字段，只取 TaskInfo 需要的部分
+var defaultTaskSelectFields = []string{"id", "title", "body", "status", "createdDateTime", "lastModifiedDateTime", "dueDateTime", "completedDateTime", "importance"}
+
+// defaultTaskPageSize 每页默认任务数量
+const defaultTaskPageSize = 100
+
+// TaskQueryOptions 任务查询选项，对应 Graph API 的 OData 查询参数
+type TaskQueryOptions struct {
+	Filter  string   // $filter 表达式，例如 status ne 'completed'
+	Top     int      // $top 每页数量，0 表示使用默认值
+	Select  []string // $select 字段列表，为空时使用默认字段
+	OrderBy string   // $orderby 排序表达式，例如 createdDateTime desc
+
+	// ClientFilter 客户端过滤条件
+	// 当服务器返回 InvalidFilterClause 时，去掉 $filter 并使用该函数在本地过滤
+	ClientFilter func(task TaskInfo) bool
+}
+
+// graphTask Graph API 返回的任务结构
+type graphTask struct {
+	ID              string                 `json:"id"`
+	Title           string                 `json:"title"`
+	Body            map[string]interface{} `json:"body,omitempty"`
+	Status          string                 `json:"status"`
+	CreatedDateTime string                 `json:"createdDateTime"`
+	DueDateTime     map[string]interface{} `json:"dueDateTime,omitempty"`
+	Importance      string                 `json:"importance"`
+
+	LastModifiedDateTime string                 `json:"lastModifiedDateTime,omitempty"`
+	CompletedDateTime    map[string]interface{} `json:"completedDateTime,omitempty"`
+
+	// Removed 增量查询中被删除的任务会带有 @removed 标记
+	Removed *struct {
+		Reason string `json:"reason"`
+	} `json:"@removed,omitempty"`
+}
+
+// toTaskInfo 转换为 TaskInfo 结构
+func (t *graphTask) toTaskInfo() TaskInfo {
+	taskInfo := TaskInfo{
+		ID:          t.ID,
+		Title:       t.Title,
+		Status:      t.Status,
+		CreatedAt:   parseTime(t.CreatedDateTime),
+		Importance:  t.Importance,
+		IsCompleted: t.Status == "completed",
+
+		LastModifiedAt: parseTime(t.LastModifiedDateTime),
+	}
+
+	// 提取描述信息
+	if t.Body != nil {
+		if content, ok := t.Body["content"].(string); ok {
+			taskInfo.Description = content
+		}
+	}
+
+	// 提取截止时间
+	if t.DueDateTime != nil {
+		if dateTime, ok := t.DueDateTime["dateTime"].(string); ok {
+			taskInfo.DueDateTime = dateTime
+		}
+	}
+
+	// 提取完成时间
+	if t.CompletedDateTime != nil {
+		if dateTime, ok := t.CompletedDateTime["dateTime"].(string); ok {
+			taskInfo.CompletedAt = parseTime(dateTime)
+		}
+	}
+
+	return taskInfo
+}
+
+// GraphAPIError Graph API 返回的错误
+type GraphAPIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error 实现 error 接口
+func (e *GraphAPIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("graph api error (status %d, code %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("graph api error (status %d)", e.StatusCode)
+}
+
+// IsInvalidFilter 是否为不支持的 $filter 子句错误
+func (e *GraphAPIError) IsInvalidFilter() bool {
+	return e.Code == "InvalidFilterClause" ||
+		(e.StatusCode == http.StatusBadRequest && strings.Contains(e.Message, "$filter"))
+}
+
+// parseGraphAPIError 从响应中解析 Graph API 错误
+func parseGraphAPIError(resp *http.Response) *GraphAPIError {
+	apiErr := &GraphAPIError{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(resp.Body)
+	var errorResp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err != nil {
+		apiErr.Message = string(body)
+		return apiErr
+	}
+
+	apiErr.Code = errorResp.Error.Code
+	apiErr.Message = errorResp.Error.Message
+	return apiErr
+}
+
+// TaskPageIterator 任务分页迭代器，自动跟随 @odata.nextLink
+type TaskPageIterator struct {
+	client   *SimpleTodoClient
+	nextLink string
+	options  TaskQueryOptions
+	done     bool
+	pages    int
+	fallback bool // 是否已降级为客户端过滤
+}
+
+// NewTaskPageIterator 创建任务分页迭代器
+func (c *SimpleTodoClient) NewTaskPageIterator(listID string, options TaskQueryOptions) *TaskPageIterator {
+	return &TaskPageIterator{
+		client:   c,
+		nextLink: buildTaskQueryEndpoint(listID, options),
+		options:  options,
+	}
+}
+
+// HasNext 是否还有下一页
+func (it *TaskPageIterator) HasNext() bool {
+	return !it.done
+}
+
+// Pages 已获取的页数
+func (it *TaskPageIterator) Pages() int {
+	return it.pages
+}
+
+// UsedClientFilter 是否因服务器不支持 $filter 而降级为客户端过滤
+func (it *TaskPageIterator) UsedClientFilter() bool {
+	return it.fallback
+}
+
+// Next 获取下一页任务
+func (it *TaskPageIterator) Next(ctx context.Context) ([]TaskInfo, error) {
+	if it.done {
+		return nil, io.EOF
+	}
+
+	resp, err := it.client.makeAPIRequestWithRetry(ctx, "GET", it.nextLink, nil, 2)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := parseGraphAPIError(resp)
+
+		// 服务器不支持过滤条件时，去掉 $filter 重新从第一页开始，改用客户端过滤
+		if apiErr.IsInvalidFilter() && it.pages == 0 && !it.fallback && it.options.Filter != "" {
+			logger.FromContext(ctx).Warnf("服务器不支持过滤条件 '%s'，降级为客户端过滤: %s", it.options.Filter, apiErr.Message)
+			it.fallback = true
+			it.nextLink = removeQueryParam(it.nextLink, "$filter")
+			return it.Next(ctx)
+		}
+
+		it.done = true
+		return nil, apiErr
+	}
+
+	var page struct {
+		Value    []graphTask `json:"value"`
+		NextLink string      `json:"@odata.nextLink"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		it.done = true
+		return nil, fmt.Errorf("failed to decode tasks response: %v", err)
+	}
+
+	it.pages++
+	it.nextLink = page.NextLink
+	if it.nextLink == "" {
+		it.done = true
+	}
+
+	tasks := make([]TaskInfo, 0, len(page.Value))
+	for i := range page.Value {
+		taskInfo := page.Value[i].toTaskInfo()
+		if it.fallback && it.options.ClientFilter != nil && !it.options.ClientFilter(taskInfo) {
+			continue
+		}
+		tasks = append(tasks, taskInfo)
+	}
+
+	return tasks, nil
+}
+
+// ListTasks 获取列表中满足条件的全部任务（跟随所有分页）
+func (c *SimpleTodoClient) ListTasks(ctx context.Context, listID string, options TaskQueryOptions) ([]TaskInfo, error) {
+	iterator := c.NewTaskPageIterator(listID, options)
+
+	var tasks []TaskInfo
+	for iterator.HasNext() {
+		page, err := iterator.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+	}
+
+	logger.FromContext(ctx).Debugf("Fetched %d tasks in %d page(s) from list %s (client-side filter: %t)",
+		len(tasks), iterator.Pages(), listID, iterator.UsedClientFilter())
+	return tasks, nil
+}
+
+// buildTaskQueryEndpoint 构建任务查询端点
+func buildTaskQueryEndpoint(listID string, options TaskQueryOptions) string {
+	params := url.Values{}
+	if options.Filter != "" {
+		params.Set("$filter", options.Filter)
+	}
+
+	top := options.Top
+	if top <= 0 {
+		top = defaultTaskPageSize
+	}
+	params.Set("$top", strconv.Itoa(top))
+
+	selectFields := options.Select
+	if len(selectFields) == 0 {
+		selectFields = defaultTaskSelectFields
+	}
+	params.Set("$select", strings.Join(selectFields, ","))
+
+	if options.OrderBy != "" {
+		params.Set("$orderby", options.OrderBy)
+	}
+
+	// Graph API 要求 OData 参数中的空格编码为 %20 而非 +
+	query := strings.ReplaceAll(params.Encode(), "+", "%20")
+	return fmt.Sprintf("/me/todo/lists/%s/tasks?%s", listID, query)
+}
+
+// removeQueryParam 从端点中移除指定查询参数
+func removeQueryParam(endpoint, name string) string {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+
+	params := parsed.Query()
+	params.Del(name)
+	parsed.RawQuery = strings.ReplaceAll(params.Encode(), "+", "%20")
+	return parsed.String()
+}
+
+// escapeODataString 转义 OData 字符串字面量中的单引号
+func escapeODataString(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// incompleteTaskFilter 未完成任务的过滤条件
+func incompleteTaskFilter() string {
+	return "status ne 'completed'"
+}
+
+// titleContainsFilter 标题包含关键词的过滤条件
+func titleContainsFilter(keyword string) string {
+	return fmt.Sprintf("contains(title,'%s')", escapeODataString(keyword))
+}
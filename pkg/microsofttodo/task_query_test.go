@@ -0,0 +1,122 @@
+package microsofttodo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient 创建指向测试服务器、带有效缓存 token 的客户端
+func newTestClient(t *testing.T, server *httptest.Server) *SimpleTodoClient {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("USERPROFILE", home)
+
+	client, err := NewSimpleTodoClient("tenant", "client-id", "secret", "")
+	require.NoError(t, err)
+	client.baseURL = server.URL
+
+	token := TokenData{
+		AccessToken:  "test-token",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		TokenType:    "Bearer",
+	}
+	data, err := json.Marshal(token)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(client.getTokenCachePath(), data, 0600))
+
+	return client
+}
+
+func TestListTasks_FollowsNextLink(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+
+		switch r.URL.Query().Get("$skiptoken") {
+		case "":
+			assert.Equal(t, "status ne 'completed'", r.URL.Query().Get("$filter"))
+			assert.Equal(t, "100", r.URL.Query().Get("$top"))
+			assert.NotEmpty(t, r.URL.Query().Get("$select"))
+			fmt.Fprintf(w, `{"value":[{"id":"1","title":"first","status":"notStarted"}],"@odata.nextLink":"%s/me/todo/lists/list-1/tasks?$skiptoken=page2"}`, server.URL)
+		case "page2":
+			fmt.Fprint(w, `{"value":[{"id":"2","title":"second","status":"inProgress","body":{"content":"note"}}]}`)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	iterator := client.NewTaskPageIterator("list-1", TaskQueryOptions{Filter: incompleteTaskFilter()})
+
+	var tasks []TaskInfo
+	for iterator.HasNext() {
+		page, err := iterator.Next(context.Background())
+		require.NoError(t, err)
+		tasks = append(tasks, page...)
+	}
+
+	require.Len(t, tasks, 2)
+	assert.Equal(t, 2, iterator.Pages())
+	assert.Equal(t, "first", tasks[0].Title)
+	assert.Equal(t, "note", tasks[1].Description)
+	assert.False(t, iterator.UsedClientFilter())
+}
+
+func TestListTasks_FallsBackToClientFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$filter") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":"InvalidFilterClause","message":"unsupported"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":[
+			{"id":"1","title":"Weekly Report","status":"notStarted"},
+			{"id":"2","title":"weekly sync","status":"completed"},
+			{"id":"3","title":"Lunch","status":"notStarted"}]}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	tasks, err := client.ListTasks(context.Background(), "list-1", TaskQueryOptions{
+		Filter: incompleteTaskFilter() + " and " + titleContainsFilter("weekly"),
+		ClientFilter: func(task TaskInfo) bool {
+			return !task.IsCompleted && strings.Contains(strings.ToLower(task.Title), "weekly")
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "1", tasks[0].ID)
+}
+
+func TestListTasks_ReturnsGraphError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"ErrorItemNotFound","message":"list missing"}}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	_, err := client.ListTasks(context.Background(), "missing", TaskQueryOptions{})
+
+	require.Error(t, err)
+	apiErr, ok := err.(*GraphAPIError)
+	require.True(t, ok)
+	assert.Equal(t, "ErrorItemNotFound", apiErr.Code)
+	assert.Equal(t, "task list not found: Tasks", wrapTaskQueryError(err, "Tasks", "query").Error())
+}
+
+func TestTitleContainsFilter_EscapesQuotes(t *testing.T) {
+	assert.Equal(t, "contains(title,'Bob''s task')", titleContainsFilter("Bob's task"))
+}
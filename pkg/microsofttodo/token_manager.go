@@ -17,6 +17,9 @@ type TokenStatus struct {
 	TimeToExpiry  time.Duration `json:"time_to_expiry"`
 	LastRefresh   time.Time     `json:"last_refresh"`
 	RefreshCount  int           `json:"refresh_count"`
+	// 最近一次刷新尝试的时间和结果，LastRefreshError 为空表示成功
+	LastRefreshAttempt time.Time `json:"last_refresh_attempt"`
+	LastRefreshError   string    `json:"last_refresh_error,omitempty"`
 }
 
 // TokenManagerConfig Token 管理器配置
@@ -50,6 +53,8 @@ type TokenManager struct {
 	status        *TokenStatus
 	refreshCount  int
 	lastRefresh   time.Time
+	lastAttempt   time.Time
+	lastError     string
 }
 
 // NewTokenManager 创建 Token 管理器
@@ -143,15 +148,12 @@ func (tm *TokenManager) updateStatus(ctx context.Context) {
 	tokenStatus, err := tm.client.GetTokenStatus()
 	if err != nil {
 		tm.logger.Debugf("获取 token 状态失败: %v", err)
-		tm.mutex.Lock()
-		tm.status = &TokenStatus{
+		tokenStatus = &TokenStatus{
 			HasToken:     false,
 			IsExpired:    true,
 			NeedsRefresh: false,
 			NeedsReauth:  true,
 		}
-		tm.mutex.Unlock()
-		return
 	}
 
 	tm.mutex.Lock()
@@ -161,6 +163,8 @@ func (tm *TokenManager) updateStatus(ctx context.Context) {
 	tm.status = tokenStatus
 	tm.status.LastRefresh = tm.lastRefresh
 	tm.status.RefreshCount = tm.refreshCount
+	tm.status.LastRefreshAttempt = tm.lastAttempt
+	tm.status.LastRefreshError = tm.lastError
 }
 
 // 事件回调设置方法
@@ -180,6 +184,8 @@ func (tm *TokenManager) OnReauthNeeded(callback func(error)) {
 func (tm *TokenManager) handleTokenRefreshed(token *TokenData) {
 	tm.mutex.Lock()
 	tm.lastRefresh = time.Now()
+	tm.lastAttempt = tm.lastRefresh
+	tm.lastError = ""
 	tm.refreshCount++
 	tm.mutex.Unlock()
 
@@ -197,6 +203,13 @@ func (tm *TokenManager) handleTokenRefreshed(token *TokenData) {
 func (tm *TokenManager) handleRefreshFailed(err error) {
 	tm.logger.Warnf("Token 刷新失败: %v", err)
 
+	tm.mutex.Lock()
+	tm.lastAttempt = time.Now()
+	tm.lastError = err.Error()
+	tm.status.LastRefreshAttempt = tm.lastAttempt
+	tm.status.LastRefreshError = tm.lastError
+	tm.mutex.Unlock()
+
 	// 调用回调
 	if tm.onRefreshFailed != nil {
 		tm.onRefreshFailed(err)
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 单个提醒时间的选择策略（Microsoft Todo 每个任务只支持一个 reminderDateTime）
+const (
+	AlarmPolicyEarliest = "earliest" // 使用最早的提醒（默认）
+	AlarmPolicyNearest  = "nearest"  // 使用最接近截止时间的提醒
+)
+
+// absoluteAlarmLayouts 绝对提醒时间支持的格式
+var absoluteAlarmLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006/01/02 15:04",
+}
+
+// spanUnitRegex 匹配持续时间开头的周、天部分，如 "1w"、"2d"
+var spanUnitRegex = regexp.MustCompile(`^(\d+)([wd])`)
+
+// SplitAlarmList 拆分提醒列表，如 "1d, 2h, 15m" -> ["1d", "2h", "15m"]
+func SplitAlarmList(value string) []string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '，' || r == ';' || r == '；' || r == '、'
+	})
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+// ParseAlarms 根据 remind_before 计算全部提醒时间，按时间先后排序并去重。
+// 每一项可以是相对截止时间的提前量（15m、1h30m、1d、1w），
+// 也可以是截止时间时区下的绝对时间（2024-12-24 20:00）。
+func ParseAlarms(due time.Time, remindBefore string) ([]time.Time, error) {
+	items := SplitAlarmList(remindBefore)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("empty remind_before")
+	}
+
+	alarms := make([]time.Time, 0, len(items))
+	for _, item := range items {
+		alarm, err := parseAlarm(due, item)
+		if err != nil {
+			return nil, err
+		}
+		alarms = append(alarms, alarm)
+	}
+	return sortAlarms(alarms), nil
+}
+
+// parseAlarm 解析单个提醒：先尝试绝对时间，再按提前量解析
+func parseAlarm(due time.Time, item string) (time.Time, error) {
+	for _, layout := range absoluteAlarmLayouts {
+		if alarm, err := time.ParseInLocation(layout, item, due.Location()); err == nil {
+			if alarm.After(due) {
+				return time.Time{}, fmt.Errorf("reminder time %s is after due time %s",
+					alarm.Format("2006-01-02 15:04"), due.Format("2006-01-02 15:04"))
+			}
+			return alarm, nil
+		}
+	}
+	return parseDuration(due, item)
+}
+
+// ValidateRemindBefore 验证提前提醒时间列表，只允许提前量（用于默认配置）
+func ValidateRemindBefore(remindBefore string) error {
+	items := SplitAlarmList(remindBefore)
+	if len(items) == 0 {
+		return fmt.Errorf("empty remind_before")
+	}
+	for _, item := range items {
+		if _, err := ParseSpan(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortAlarms 按时间先后排序并去掉重复的提醒时间
+func sortAlarms(alarms []time.Time) []time.Time {
+	sort.Slice(alarms, func(i, j int) bool { return alarms[i].Before(alarms[j]) })
+	unique := alarms[:0]
+	for i, alarm := range alarms {
+		if i == 0 || !alarm.Equal(alarms[i-1]) {
+			unique = append(unique, alarm)
+		}
+	}
+	return unique
+}
+
+// scheduleAlarms 按调度规则调整每个提醒时间，返回排序去重后的提醒时间、
+// 与之对应的调整前时间，以及合并后的调整原因
+func scheduleAlarms(alarms []time.Time, due, now time.Time, schedule *ScheduleConfig) ([]time.Time, []time.Time, string, error) {
+	type entry struct{ at, original time.Time }
+	entries := make([]entry, 0, len(alarms))
+	var reasons []string
+	for _, alarm := range alarms {
+		adjusted, reason, err := schedule.AdjustAlarm(alarm, due, now)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if reason != "" {
+			log.Printf("提醒时间调整: %s -> %s (%s)",
+				alarm.Format("2006-01-02 15:04"), adjusted.Format("2006-01-02 15:04"), reason)
+			if len(alarms) > 1 {
+				reason = alarm.Format("2006-01-02 15:04") + " 的提醒：" + reason
+			}
+			reasons = append(reasons, reason)
+		}
+		entries = append(entries, entry{at: adjusted, original: alarm})
+	}
+
+	// 调整后多个提醒可能落在同一时间
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].at.Before(entries[j].at) })
+	adjusted := make([]time.Time, 0, len(entries))
+	originals := make([]time.Time, 0, len(entries))
+	for i, e := range entries {
+		if i > 0 && e.at.Equal(entries[i-1].at) {
+			continue
+		}
+		adjusted = append(adjusted, e.at)
+		originals = append(originals, e.original)
+	}
+	return adjusted, originals, strings.Join(reasons, "；"), nil
+}
+
+// PickAlarm 按策略从已排序的提醒时间中选出一个，返回其下标。
+// 优先在尚未过去的提醒中选择，全部过去时在所有提醒中选择。
+func PickAlarm(alarms []time.Time, policy string, now time.Time) int {
+	if len(alarms) == 0 {
+		return -1
+	}
+
+	first := 0
+	for first < len(alarms) && alarms[first].Before(now) {
+		first++
+	}
+	if first == len(alarms) {
+		first = 0
+	}
+
+	if policy == AlarmPolicyNearest {
+		return len(alarms) - 1
+	}
+	return first
+}
+
+// ParseSpan 解析持续时间，支持 "90m"、"1h30m"、"2d"、"1d12h"、"1w" 等格式
+func ParseSpan(span string) (time.Duration, error) {
+	span = strings.TrimSpace(span)
+	if span == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	original := span
+	var total time.Duration
+	for {
+		match := spanUnitRegex.FindStringSubmatch(span)
+		if match == nil {
+			break
+		}
+		value, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", original)
+		}
+		unit := 24 * time.Hour
+		if match[2] == "w" {
+			unit *= 7
+		}
+		total += time.Duration(value) * unit
+		span = span[len(match[0]):]
+	}
+	if span == "" {
+		return total, nil
+	}
+
+	d, err := time.ParseDuration(span)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", original)
+	}
+	return total + d, nil
+}
+
+// parseDuration parses a duration string and calculates the reminder time from a given time.
+// Supports simple and compound formats like "15m", "1h30m", "2d", "1w".
+// Returns the calculated reminder time, or an error if the duration format is invalid.
+func parseDuration(from time.Time, duration string) (time.Time, error) {
+	d, err := ParseSpan(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return from.Add(-d), nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAlarms(t *testing.T) {
+	due := localTime("2024-12-25 19:00")
+
+	alarms, err := ParseAlarms(due, "1d, 2h，15m; 2024-12-24 20:00、1h30m, 15m")
+	if err != nil {
+		t.Fatalf("ParseAlarms() error = %v", err)
+	}
+
+	expected := []string{
+		"2024-12-24 19:00",
+		"2024-12-24 20:00",
+		"2024-12-25 17:00",
+		"2024-12-25 17:30",
+		"2024-12-25 18:45",
+	}
+	if len(alarms) != len(expected) {
+		t.Fatalf("ParseAlarms() returned %d alarms, want %d (重复的 15m 应去重)", len(alarms), len(expected))
+	}
+	for i, alarm := range alarms {
+		if got := alarm.Format("2006-01-02 15:04"); got != expected[i] {
+			t.Errorf("alarms[%d] = %s, want %s", i, got, expected[i])
+		}
+	}
+
+	for _, input := range []string{"", "soon", "15m, x", "2024-12-26 09:00"} {
+		if _, err := ParseAlarms(due, input); err == nil {
+			t.Errorf("ParseAlarms(%q) 期望失败", input)
+		}
+	}
+}
+
+func TestPickAlarm(t *testing.T) {
+	alarms := []time.Time{
+		localTime("2025-03-10 09:00"),
+		localTime("2025-03-11 09:00"),
+		localTime("2025-03-11 09:45"),
+	}
+
+	testCases := []struct {
+		policy   string
+		now      string
+		expected int
+	}{
+		{AlarmPolicyEarliest, "2025-03-01 00:00", 0},
+		{AlarmPolicyNearest, "2025-03-01 00:00", 2},
+		{"", "2025-03-01 00:00", 0},
+		{AlarmPolicyEarliest, "2025-03-10 12:00", 1}, // 已经过去的提醒不再选择
+		{AlarmPolicyEarliest, "2025-03-12 00:00", 0}, // 全部过去时仍然返回最早的
+	}
+	for _, tc := range testCases {
+		if got := PickAlarm(alarms, tc.policy, localTime(tc.now)); got != tc.expected {
+			t.Errorf("PickAlarm(%q, now=%s) = %d, want %d", tc.policy, tc.now, got, tc.expected)
+		}
+	}
+}
+
+func TestParseReminderTimeWithConfig_AlarmPolicy(t *testing.T) {
+	reminder := Reminder{Title: "年度体检", Date: "2099-06-01", Time: "08:30", RemindBefore: "1w, 1d, 30m"}
+
+	for policy, expected := range map[string]string{
+		AlarmPolicyEarliest: "2099-05-25 08:30",
+		AlarmPolicyNearest:  "2099-06-01 08:00",
+	} {
+		parsed, err := ParseReminderTimeWithConfig(reminder, time.UTC, &ReminderConfig{AlarmPolicy: policy})
+		if err != nil {
+			t.Fatalf("ParseReminderTimeWithConfig() error = %v", err)
+		}
+		if len(parsed.Alarms) != 3 {
+			t.Errorf("Alarms = %v, want 3 alarms", parsed.Alarms)
+		}
+		if got := parsed.AlarmTime.Format("2006-01-02 15:04"); got != expected {
+			t.Errorf("policy %s: AlarmTime = %s, want %s", policy, got, expected)
+		}
+		if !parsed.AlarmTimeUTC.Equal(parsed.AlarmTime) {
+			t.Errorf("policy %s: AlarmTimeUTC = %v, want %v", policy, parsed.AlarmTimeUTC, parsed.AlarmTime)
+		}
+	}
+
+	if err := (&ReminderConfig{AlarmPolicy: "latest"}).Validate(); err == nil {
+		t.Errorf("Validate() 期望拒绝未知的 alarm_policy")
+	}
+	if err := (&ReminderConfig{DefaultRemindBefore: "1d, 15m"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want default_remind_before list accepted", err)
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import "fmt"
+
+// AttachmentConfig 任务附件配置
+type AttachmentConfig struct {
+	AttachScreenshot bool            `yaml:"attach_screenshot"`         // 是否将源截图作为附件上传到任务
+	Lists            map[string]bool `yaml:"lists,omitempty"`           // 按任务列表覆盖 attach_screenshot，键为列表名称
+	NormalizeImage   *bool           `yaml:"normalize_image,omitempty"` // 上传前是否标准化图片，默认true
+	MaxSizeMB        int             `yaml:"max_size_mb"`               // 附件大小上限(MB)，0表示使用默认值25MB
+}
+
+// DefaultAttachmentConfig 返回默认附件配置
+func DefaultAttachmentConfig() AttachmentConfig {
+	return AttachmentConfig{
+		AttachScreenshot: true,
+		MaxSizeMB:        0, // 使用默认值
+	}
+}
+
+// Validate 验证附件配置
+func (ac *AttachmentConfig) Validate() error {
+	if ac.MaxSizeMB < 0 {
+		return fmt.Errorf("max_size_mb cannot be negative")
+	}
+
+	if ac.MaxSizeMB > 25 {
+		return fmt.Errorf("max_size_mb cannot exceed 25 (Microsoft To Do limit)")
+	}
+
+	return nil
+}
+
+// ShouldAttachScreenshot 判断指定列表的任务是否需要附加截图
+// 列表级配置优先于全局配置
+func (ac *AttachmentConfig) ShouldAttachScreenshot(listName string) bool {
+	if enabled, ok := ac.Lists[listName]; ok {
+		return enabled
+	}
+	return ac.AttachScreenshot
+}
+
+// ShouldNormalizeImage 上传前是否标准化图片
+func (ac *AttachmentConfig) ShouldNormalizeImage() bool {
+	return ac.NormalizeImage == nil || *ac.NormalizeImage
+}
+
+// GetMaxSizeBytes 获取附件大小上限(字节)
+func (ac *AttachmentConfig) GetMaxSizeBytes() int64 {
+	if ac.MaxSizeMB <= 0 {
+		return 25 * 1024 * 1024
+	}
+	return int64(ac.MaxSizeMB) * 1024 * 1024
+}
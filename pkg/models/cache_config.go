@@ -1,6 +1,39 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultCompressAfterDays 默认的任务归档天数
+const DefaultCompressAfterDays = 7
+
+// DefaultEvictionIntervalMinutes 默认的后台淘汰间隔（分钟）
+const DefaultEvictionIntervalMinutes = 10
+
+// DefaultMetricsRetentionDays 默认的处理指标保留天数
+const DefaultMetricsRetentionDays = 7
+
+// quotaCacheTypes 可以设置配额的缓存类型。global 和 config 保存索引和配置，metrics 按保留天数清理，不参与淘汰
+var quotaCacheTypes = map[string]bool{
+	"images":    true,
+	"tasks":     true,
+	"temp":      true,
+	"submitted": true,
+	"hashes":    true,
+}
+
+// CacheQuota 单个缓存类型的配额，0 表示不限制
+type CacheQuota struct {
+	MaxSizeMB  int64 `yaml:"max_size_mb"`  // 最大总大小(MB)
+	MaxItems   int   `yaml:"max_items"`    // 最大条目数（文件或任务目录）
+	MaxAgeDays int   `yaml:"max_age_days"` // 超过该天数未修改的条目直接淘汰
+}
+
+// IsZero 是否未设置任何限制
+func (q CacheQuota) IsZero() bool {
+	return q.MaxSizeMB <= 0 && q.MaxItems <= 0 && q.MaxAgeDays <= 0
+}
 
 // CacheConfig 缓存配置
 type CacheConfig struct {
@@ -12,17 +45,23 @@ type CacheConfig struct {
 	// 任务管理配置
 	TaskRetentionDays    int `yaml:"task_retention_days"`     // 任务保留天数，0表示使用auto_cleanup_days
 	MaxTaskDirectories   int `yaml:"max_task_directories"`    // 最大任务目录数量，0表示无限制
-	CompressOldTasks     bool `yaml:"compress_old_tasks"`      // 是否压缩旧任务
+	CompressOldTasks     bool `yaml:"compress_old_tasks"`      // 是否压缩旧任务（按月打包为 zip 归档）
+	CompressAfterDays    int  `yaml:"compress_after_days"`     // 任务目录超过多少天后打包归档，默认7天
 
 	// 图片缓存配置
-	ImageCacheMaxSize   int64 `yaml:"image_cache_max_size"`    // 图片缓存最大大小(MB)，0表示无限制
-	ImageCacheMaxFiles  int   `yaml:"image_cache_max_files"`   // 图片缓存最大文件数量，0表示无限制
+	ImageCacheMaxSize   int64 `yaml:"image_cache_max_size" deprecated:"true"`  // 图片缓存最大大小(MB)，0表示无限制（已由 quotas.images.max_size_mb 取代）
+	ImageCacheMaxFiles  int   `yaml:"image_cache_max_files" deprecated:"true"` // 图片缓存最大文件数量，0表示无限制（已由 quotas.images.max_items 取代）
 	EnableImageBackup   bool  `yaml:"enable_image_backup"`     // 是否启用图片备份
 
 	// 全局缓存配置
 	GlobalCacheEnabled  bool `yaml:"global_cache_enabled"`     // 是否启用全局缓存
-	EnableCacheMetrics  bool `yaml:"enable_cache_metrics"`     // 是否启用缓存统计
+	EnableCacheMetrics  bool `yaml:"enable_cache_metrics"`     // 是否记录处理指标（stats 命令和托盘统计面板）
 	MetricsRetentionDays int  `yaml:"metrics_retention_days"`  // 指标保留天数
+
+	// 配额与淘汰配置
+	Quotas                  map[string]CacheQuota `yaml:"quotas"`                    // 按缓存类型的配额，如 images、tasks、temp
+	EvictionPolicy          string                `yaml:"eviction_policy"`           // 淘汰策略: lru（默认）或 age
+	EvictionIntervalMinutes int                   `yaml:"eviction_interval_minutes"` // 后台淘汰间隔（分钟），默认10分钟
 }
 
 // DefaultCacheConfig 返回默认缓存配置
@@ -34,12 +73,15 @@ func DefaultCacheConfig() CacheConfig {
 		TaskRetentionDays:       0, // 使用AutoCleanupDays
 		MaxTaskDirectories:      0, // 无限制
 		CompressOldTasks:        false,
+		CompressAfterDays:       DefaultCompressAfterDays,
 		ImageCacheMaxSize:       0,  // 无限制
 		ImageCacheMaxFiles:      0,  // 无限制
 		EnableImageBackup:       true,
 		GlobalCacheEnabled:      true,
 		EnableCacheMetrics:      true,
-		MetricsRetentionDays:    7,
+		MetricsRetentionDays:    DefaultMetricsRetentionDays,
+		EvictionPolicy:          "lru",
+		EvictionIntervalMinutes: DefaultEvictionIntervalMinutes,
 	}
 }
 
@@ -65,10 +107,33 @@ func (cc *CacheConfig) Validate() error {
 		return fmt.Errorf("image_cache_max_files cannot be negative")
 	}
 
+	if cc.CompressAfterDays < 0 {
+		return fmt.Errorf("compress_after_days cannot be negative")
+	}
+
 	if cc.MetricsRetentionDays < 0 {
 		return fmt.Errorf("metrics_retention_days cannot be negative")
 	}
 
+	for cacheType, quota := range cc.Quotas {
+		if !quotaCacheTypes[cacheType] {
+			return fmt.Errorf("quotas.%s: unsupported cache type (supported: images, tasks, temp, submitted, hashes)", cacheType)
+		}
+		if quota.MaxSizeMB < 0 || quota.MaxItems < 0 || quota.MaxAgeDays < 0 {
+			return fmt.Errorf("quotas.%s: limits cannot be negative", cacheType)
+		}
+	}
+
+	switch cc.EvictionPolicy {
+	case "", "lru", "age":
+	default:
+		return fmt.Errorf("eviction_policy must be lru or age, got %q", cc.EvictionPolicy)
+	}
+
+	if cc.EvictionIntervalMinutes < 0 {
+		return fmt.Errorf("eviction_interval_minutes cannot be negative")
+	}
+
 	// 如果任务保留天数为0，使用自动清理天数
 	if cc.TaskRetentionDays == 0 {
 		cc.TaskRetentionDays = cc.AutoCleanupDays
@@ -85,6 +150,59 @@ func (cc *CacheConfig) GetEffectiveTaskRetentionDays() int {
 	return cc.AutoCleanupDays
 }
 
+// GetCompressAfterDays 获取任务归档天数，未配置时使用默认值
+func (cc *CacheConfig) GetCompressAfterDays() int {
+	if cc.CompressAfterDays > 0 {
+		return cc.CompressAfterDays
+	}
+	return DefaultCompressAfterDays
+}
+
+// GetQuotas 获取各缓存类型的有效配额。image_cache_max_size、image_cache_max_files
+// 和 max_task_directories 作为 images、tasks 配额中未设置字段的默认值
+func (cc *CacheConfig) GetQuotas() map[string]CacheQuota {
+	quotas := make(map[string]CacheQuota, len(cc.Quotas)+2)
+	for cacheType, quota := range cc.Quotas {
+		quotas[cacheType] = quota
+	}
+
+	images := quotas["images"]
+	if images.MaxSizeMB == 0 {
+		images.MaxSizeMB = cc.ImageCacheMaxSize
+	}
+	if images.MaxItems == 0 {
+		images.MaxItems = cc.ImageCacheMaxFiles
+	}
+	tasks := quotas["tasks"]
+	if tasks.MaxItems == 0 {
+		tasks.MaxItems = cc.MaxTaskDirectories
+	}
+
+	for cacheType, quota := range map[string]CacheQuota{"images": images, "tasks": tasks} {
+		if !quota.IsZero() {
+			quotas[cacheType] = quota
+		}
+	}
+	return quotas
+}
+
+// GetEvictionInterval 获取后台淘汰间隔
+func (cc *CacheConfig) GetEvictionInterval() time.Duration {
+	if cc.EvictionIntervalMinutes > 0 {
+		return time.Duration(cc.EvictionIntervalMinutes) * time.Minute
+	}
+	return DefaultEvictionIntervalMinutes * time.Minute
+}
+
+// GetMetricsRetention 获取处理指标的保留时长，未配置时使用默认值
+func (cc *CacheConfig) GetMetricsRetention() time.Duration {
+	days := cc.MetricsRetentionDays
+	if days <= 0 {
+		days = DefaultMetricsRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 // GetImageCacheMaxSizeBytes 获取图片缓存最大大小(字节)
 func (cc *CacheConfig) GetImageCacheMaxSizeBytes() int64 {
 	if cc.ImageCacheMaxSize <= 0 {
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCacheConfig_GetQuotas(t *testing.T) {
+	cfg := CacheConfig{
+		ImageCacheMaxSize:  100,
+		ImageCacheMaxFiles: 50,
+		MaxTaskDirectories: 20,
+		Quotas: map[string]CacheQuota{
+			"images": {MaxItems: 10},
+			"temp":   {MaxAgeDays: 3},
+		},
+	}
+
+	quotas := cfg.GetQuotas()
+	if got := quotas["images"]; got.MaxItems != 10 || got.MaxSizeMB != 100 {
+		t.Errorf("images quota = %+v, want max_items 10 from quotas and max_size_mb 100 from image_cache_max_size", got)
+	}
+	if got := quotas["tasks"]; got.MaxItems != 20 {
+		t.Errorf("tasks quota = %+v, want max_items from max_task_directories", got)
+	}
+	if got := quotas["temp"]; got.MaxAgeDays != 3 {
+		t.Errorf("temp quota = %+v, want max_age_days 3", got)
+	}
+	if _, exists := (&CacheConfig{}).GetQuotas()["images"]; exists {
+		t.Errorf("GetQuotas() 未配置时不应返回 images 配额")
+	}
+}
+
+func TestCacheConfig_ValidateQuotas(t *testing.T) {
+	testCases := map[string]CacheConfig{
+		"quotas.global":   {Quotas: map[string]CacheQuota{"global": {MaxItems: 1}}},
+		"quotas.images":   {Quotas: map[string]CacheQuota{"images": {MaxSizeMB: -1}}},
+		"eviction_policy": {EvictionPolicy: "fifo"},
+	}
+	for field, cfg := range testCases {
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("Validate() error = %v, want error mentioning %s", err, field)
+		}
+	}
+}
@@ -1,17 +1,20 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
 // DifyConfig represents the configuration for Dify API integration
 type DifyConfig struct {
-	APIEndpoint string `yaml:"api_endpoint"` // Dify API 端点
-	APIKey      string `yaml:"api_key"`      // Dify API 密钥
-	Timeout     int    `yaml:"timeout"`      // 请求超时时间（秒）
-	Model       string `yaml:"model"`        // Dify 模型名称
-	MaxTokens   int    `yaml:"max_tokens"`   // 最大令牌数
+	APIEndpoint string `yaml:"api_endpoint"`          // Dify API 端点
+	APIKey      string `yaml:"api_key" secret:"true"` // Dify API 密钥
+	Timeout     int    `yaml:"timeout"`               // 请求超时时间（秒）
+	Model       string `yaml:"model"`                 // Dify 模型名称
+	MaxTokens   int    `yaml:"max_tokens"`            // 最大令牌数
 }
 
 // Validate validates the Dify configuration
@@ -45,6 +48,28 @@ func (c *DifyConfig) Validate() error {
 	return nil
 }
 
+// DefaultDifyBackend 默认 Dify 后端名称，对应 server.yaml 中的 dify 配置
+const DefaultDifyBackend = "default"
+
+// GetDifyBackend 按名称获取 Dify 后端配置
+// 名称为空或 default 时返回 dify 配置，其他名称从 dify_backends 中查找
+func (c *ServerConfig) GetDifyBackend(name string) (DifyConfig, error) {
+	if name == "" || name == DefaultDifyBackend {
+		return c.Dify, nil
+	}
+
+	backend, exists := c.DifyBackends[name]
+	if !exists {
+		names := make([]string, 0, len(c.DifyBackends))
+		for n := range c.DifyBackends {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return DifyConfig{}, fmt.Errorf("unknown Dify backend %q (available: %s)", name, strings.Join(append([]string{DefaultDifyBackend}, names...), ", "))
+	}
+	return backend, nil
+}
+
 // DifyRequest represents a request to the Dify API for content processing.
 type DifyRequest struct {
 	Inputs       map[string]interface{} `json:"inputs"`                 // 输入数据
@@ -118,11 +143,52 @@ type ParsedTaskInfo struct {
 	Description  string    `json:"description,omitempty"`   // 任务描述
 	Date         string    `json:"date"`                    // 任务日期 (YYYY-MM-DD)
 	Time         string    `json:"time"`                    // 任务时间 (HH:MM)
+	EndDate      string    `json:"end_date,omitempty"`      // 结束日期 (YYYY-MM-DD)，跨天范围
+	EndTime      string    `json:"end_time,omitempty"`      // 结束时间 (HH:MM)
+	Duration     string    `json:"duration,omitempty"`      // 持续时间 (如 90m, 1h30m)
 	RemindBefore string    `json:"remind_before,omitempty"` // 提前提醒时间
 	Priority     string    `json:"priority,omitempty"`      // 优先级
 	List         string    `json:"list,omitempty"`          // 任务列表
+	Kind         string    `json:"kind,omitempty"`          // 类型 task/event/both
+	Location     string    `json:"location,omitempty"`      // 地点
+	Attendees    StringList `json:"attendees,omitempty"`    // 参会人
 	Confidence   float64   `json:"confidence"`              // 解析置信度 (0-1)
 	OriginalText string    `json:"original_text"`           // 原始识别文本
+	Checklist    []string       `json:"checklist,omitempty"`  // 子步骤
+	Categories   []string       `json:"categories,omitempty"` // 分类标签
+	Links        []ReminderLink `json:"links,omitempty"`      // 相关链接
+}
+
+// StringList is a list of strings that also accepts a single comma-separated string in JSON.
+type StringList []string
+
+// UnmarshalJSON accepts either ["a", "b"] or "a, b".
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var items []string
+	if err := json.Unmarshal(data, &items); err == nil {
+		*l = items
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*l = SplitList(value)
+	return nil
+}
+
+// SplitList splits a list separated by commas, enumeration commas or semicolons.
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == ';' || r == '；'
+	}) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // ClipboardContent represents the content read from clipboard.
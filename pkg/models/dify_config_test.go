@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestGetDifyBackend 测试按名称选择 Dify 后端
+func TestGetDifyBackend(t *testing.T) {
+	config := &ServerConfig{
+		Dify: DifyConfig{APIEndpoint: "https://api.dify.ai/v1", APIKey: "default-key", Timeout: 60},
+		DifyBackends: map[string]DifyConfig{
+			"staging": {APIEndpoint: "https://dify.staging.local/v1", APIKey: "staging-key", Timeout: 30},
+		},
+	}
+
+	for _, name := range []string{"", DefaultDifyBackend} {
+		backend, err := config.GetDifyBackend(name)
+		if err != nil {
+			t.Fatalf("GetDifyBackend(%q) 返回错误: %v", name, err)
+		}
+		if backend.APIKey != "default-key" {
+			t.Errorf("GetDifyBackend(%q) = %s, 期望默认后端", name, backend.APIKey)
+		}
+	}
+
+	backend, err := config.GetDifyBackend("staging")
+	if err != nil {
+		t.Fatalf("GetDifyBackend(staging) 返回错误: %v", err)
+	}
+	if backend.APIEndpoint != "https://dify.staging.local/v1" {
+		t.Errorf("GetDifyBackend(staging) 端点 = %s", backend.APIEndpoint)
+	}
+
+	_, err = config.GetDifyBackend("missing")
+	if err == nil {
+		t.Fatal("未知后端应返回错误")
+	}
+	if !strings.Contains(err.Error(), "default, staging") {
+		t.Errorf("错误信息应列出可用后端: %v", err)
+	}
+}
+
+// TestStringListUnmarshal 测试参会人字段同时支持数组和逗号分隔的字符串
+func TestStringListUnmarshal(t *testing.T) {
+	testCases := map[string][]string{
+		`{"title":"t","attendees":["张三","li@example.com"]}`: {"张三", "li@example.com"},
+		`{"title":"t","attendees":"张三、李四, 王五"}`:             {"张三", "李四", "王五"},
+		`{"title":"t"}`: nil,
+	}
+	for input, expected := range testCases {
+		var info ParsedTaskInfo
+		if err := json.Unmarshal([]byte(input), &info); err != nil {
+			t.Fatalf("解析失败 %s: %v", input, err)
+		}
+		if strings.Join(info.Attendees, "|") != strings.Join(expected, "|") {
+			t.Errorf("%s: 期望 %v, 实际 %v", input, expected, info.Attendees)
+		}
+	}
+}
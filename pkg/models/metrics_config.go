@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"net"
+)
+
+// DefaultMetricsListen 指标端点的默认监听地址，只绑定本机
+const DefaultMetricsListen = "127.0.0.1:9464"
+
+// MetricsConfig Prometheus 指标端点配置（托盘和 watch 等长时间运行的模式）
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否启用 /metrics 端点
+	Listen  string `yaml:"listen"`  // 监听地址，默认 127.0.0.1:9464
+}
+
+// Validate 验证指标端点配置
+func (mc *MetricsConfig) Validate() error {
+	if mc.Listen == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(mc.Listen); err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", mc.Listen, err)
+	}
+	return nil
+}
+
+// GetListen 获取监听地址，未配置或只配置端口时绑定本机
+func (mc *MetricsConfig) GetListen() string {
+	if mc.Listen == "" {
+		return DefaultMetricsListen
+	}
+	if host, port, err := net.SplitHostPort(mc.Listen); err == nil && host == "" {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return mc.Listen
+}
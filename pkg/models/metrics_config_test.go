@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestMetricsConfig_GetListen(t *testing.T) {
+	tests := []struct {
+		listen string
+		want   string
+	}{
+		{"", DefaultMetricsListen},
+		{":9100", "127.0.0.1:9100"},
+		{"0.0.0.0:9100", "0.0.0.0:9100"},
+		{"localhost:9100", "localhost:9100"},
+	}
+
+	for _, tt := range tests {
+		cfg := MetricsConfig{Listen: tt.listen}
+		if got := cfg.GetListen(); got != tt.want {
+			t.Errorf("GetListen(%q) = %q, want %q", tt.listen, got, tt.want)
+		}
+	}
+}
+
+func TestMetricsConfig_Validate(t *testing.T) {
+	for _, listen := range []string{"", "127.0.0.1:9464", ":9464"} {
+		cfg := MetricsConfig{Listen: listen}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate(%q) 不应返回错误: %v", listen, err)
+		}
+	}
+
+	cfg := MetricsConfig{Listen: "9464"}
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Validate(%q) 应返回错误", cfg.Listen)
+	}
+}
@@ -1,9 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -16,31 +18,107 @@ const (
 	PriorityHigh   Priority = "high"   // High priority
 )
 
+// Kind defines whether a reminder becomes a To Do task, a calendar event, or both.
+type Kind string
+
+const (
+	KindTask  Kind = "task"  // Microsoft Todo 任务（默认）
+	KindEvent Kind = "event" // Outlook 日历事件
+	KindBoth  Kind = "both"  // 同时创建任务和日历事件
+)
+
+// NormalizeKind 将中英文类型描述转换为标准类型，无法识别时返回 KindTask
+func NormalizeKind(kind string) Kind {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "event", "meeting", "calendar", "appointment", "日程", "事件", "会议", "日历":
+		return KindEvent
+	case "both", "task+event", "event+task", "全部", "两者":
+		return KindBoth
+	default:
+		return KindTask
+	}
+}
+
+// WantsTask 是否需要创建 Microsoft Todo 任务
+func (k Kind) WantsTask() bool {
+	return NormalizeKind(string(k)) != KindEvent
+}
+
+// WantsEvent 是否需要创建日历事件
+func (k Kind) WantsEvent() bool {
+	normalized := NormalizeKind(string(k))
+	return normalized == KindEvent || normalized == KindBoth
+}
+
 // Reminder represents a reminder task with title, description, timing, and priority.
 // It is used to serialize/deserialize reminder data from JSON configuration files.
 type Reminder struct {
 	Title        string   `json:"title"`                   // 提醒标题（必填）
 	Description  string   `json:"description,omitempty"`   // 备注信息（可选）
 	Date         string   `json:"date"`                    // 日期 YYYY-MM-DD（必填）
-	Time         string   `json:"time"`                    // 时间 HH:MM（必填）
-	RemindBefore string   `json:"remind_before,omitempty"` // 提前提醒时间（如 15m, 1h, 1d）
+	Time         string   `json:"time,omitempty"`          // 时间 HH:MM，也可以是时间范围 HH:MM - HH:MM；为空时使用 default_time
+	EndDate      string   `json:"end_date,omitempty"`      // 结束日期 YYYY-MM-DD（可选，跨天范围）
+	EndTime      string   `json:"end_time,omitempty"`      // 结束时间 HH:MM（可选）
+	Duration     string   `json:"duration,omitempty"`      // 持续时间（如 90m, 1h30m, 2d），未给出结束时间时使用
+	RemindBefore string   `json:"remind_before,omitempty"` // 提前提醒时间（如 15m, 1h30m, 1w），多个用逗号分隔，也可以是绝对时间 2024-12-24 20:00
 	Priority     Priority `json:"priority,omitempty"`      // 优先级 low/medium/high
 	List         string   `json:"list,omitempty"`          // 提醒事项列表名称
+	Kind         Kind     `json:"kind,omitempty"`          // 类型 task/event/both（默认 task）
+	Location     string   `json:"location,omitempty"`      // 地点（日历事件）
+	Attendees    []string `json:"attendees,omitempty"`     // 参会人，如 "张三 <zhangsan@example.com>"（日历事件）
+	Checklist    []string       `json:"checklist,omitempty"`  // 子步骤（Microsoft Todo 检查项）
+	Categories   []string       `json:"categories,omitempty"` // 分类标签
+	Links        []ReminderLink `json:"links,omitempty"`      // 相关链接
+}
+
+// ReminderLink represents a URL related to a reminder.
+type ReminderLink struct {
+	URL   string `json:"url"`             // 链接地址
+	Title string `json:"title,omitempty"` // 链接标题（可选）
+}
+
+// UnmarshalJSON accepts either a plain URL string or a {url, title} object.
+func (l *ReminderLink) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		l.URL = url
+		l.Title = ""
+		return nil
+	}
+
+	type reminderLink ReminderLink
+	var link reminderLink
+	if err := json.Unmarshal(data, &link); err != nil {
+		return err
+	}
+	*l = ReminderLink(link)
+	return nil
 }
 
 // MicrosoftTodoConfig represents the configuration for Microsoft Todo API integration.
 type MicrosoftTodoConfig struct {
-	TenantID     string `yaml:"tenant_id"`     // Microsoft Azure 租户ID
-	ClientID     string `yaml:"client_id"`     // 应用程序客户端ID
-	ClientSecret string `yaml:"client_secret"` // 客户端密钥
-	UserEmail    string `yaml:"user_email"`    // 目标用户邮箱（用于应用程序权限）
-	Timezone     string `yaml:"timezone"`      // 时区设置
+	TenantID     string `yaml:"tenant_id"`                   // Microsoft Azure 租户ID
+	ClientID     string `yaml:"client_id"`                   // 应用程序客户端ID
+	ClientSecret string `yaml:"client_secret" secret:"true"` // 客户端密钥
+	UserEmail    string `yaml:"user_email"`                  // 目标用户邮箱（用于应用程序权限）
+	Timezone     string `yaml:"timezone"`                    // 时区设置
+
+	// SendEventInvitations 日历事件是否将有邮箱的参会人加入事件并发送会议邀请
+	// 参会人来自 AI 识别结果，默认只写入事件正文
+	SendEventInvitations bool `yaml:"send_event_invitations,omitempty"`
+
+	Attachments AttachmentConfig `yaml:"attachments"` // 任务附件配置
 }
 
 // ReminderConfig represents the configuration for reminder settings.
 type ReminderConfig struct {
-	DefaultRemindBefore string `yaml:"default_remind_before"` // 默认提前提醒时间（如 15m, 1h, 1d）
-	EnableSmartReminder bool   `yaml:"enable_smart_reminder"` // 是否启用智能提醒（根据优先级自动调整）
+	DefaultRemindBefore string `yaml:"default_remind_before"`  // 默认提前提醒时间（如 15m, 1h, 1d，多个用逗号分隔）
+	EnableSmartReminder bool   `yaml:"enable_smart_reminder"`  // 是否启用智能提醒（根据优先级自动调整）
+	DefaultTime         string `yaml:"default_time,omitempty"` // 只有日期没有时间的任务使用的时间（如 09:00）
+	AlarmPolicy         string `yaml:"alarm_policy,omitempty"` // 多个提醒时 Microsoft Todo 使用哪一个: earliest（默认）, nearest
+	DefaultList         string `yaml:"default_list,omitempty"` // 未指定列表的任务写入的 Microsoft Todo 列表
+
+	Schedule ScheduleConfig `yaml:"schedule"` // 提醒时间调度规则（工作时间、免打扰、节假日）
 }
 
 // DeduplicationConfig represents the configuration for task deduplication settings.
@@ -53,12 +131,24 @@ type DeduplicationConfig struct {
 	EnableRemoteQuery    bool `yaml:"enable_remote_query"`    // 启用远程查询
 }
 
+// 日志输出格式
+const (
+	LogFormatText = "text" // 文本格式，结构化字段以 key=value 附加在消息后（默认）
+	LogFormatJSON = "json" // 每行一个 JSON 对象，结构化字段为独立的键
+)
+
 // LoggingConfig represents the configuration for logging settings.
 type LoggingConfig struct {
 	Level         string `yaml:"level"`           // 日志级别: debug, info, warn, error
 	ConsoleOutput bool   `yaml:"console_output"`  // 是否输出到控制台
 	FileOutput    bool   `yaml:"file_output"`     // 是否输出到文件
 	LogDir        string `yaml:"log_dir"`         // 日志目录（可选，默认 ./Logs/）
+	Format        string `yaml:"format"`          // 日志格式: text（默认）或 json
+}
+
+// IsJSON 是否以 JSON 格式输出日志
+func (lc *LoggingConfig) IsJSON() bool {
+	return lc.Format == LogFormatJSON
 }
 
 // TokenManagerConfig represents the configuration for token management and auto-refresh settings.
@@ -83,14 +173,43 @@ func (c *ReminderConfig) Validate() error {
 	}
 
 	// 验证默认提醒时间格式
-	_, err := parseDuration(time.Now(), c.DefaultRemindBefore)
-	if err != nil {
+	if err := ValidateRemindBefore(c.DefaultRemindBefore); err != nil {
 		return fmt.Errorf("invalid default_remind_before format: %w", err)
 	}
 
+	switch c.AlarmPolicy {
+	case "", AlarmPolicyEarliest, AlarmPolicyNearest:
+	default:
+		return fmt.Errorf("invalid alarm_policy, must be one of: earliest, nearest")
+	}
+
+	if c.DefaultTime != "" && !isValidTimeFormat(c.DefaultTime) {
+		return fmt.Errorf("invalid default_time format, expected HH:MM: %s", c.DefaultTime)
+	}
+
+	if err := c.Schedule.Validate(); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
 	return nil
 }
 
+// GetDefaultTime 获取只有日期的任务使用的时间
+func (c *ReminderConfig) GetDefaultTime() string {
+	if c == nil || c.DefaultTime == "" {
+		return DefaultDateOnlyTime
+	}
+	return c.DefaultTime
+}
+
+// GetDefaultList 返回未指定列表的任务使用的列表名称，未配置时返回 fallback
+func (c *ReminderConfig) GetDefaultList(fallback string) string {
+	if c == nil || c.DefaultList == "" {
+		return fallback
+	}
+	return c.DefaultList
+}
+
 // Validate validates the deduplication configuration
 func (c *DeduplicationConfig) Validate() error {
 	// 设置默认值
@@ -137,15 +256,23 @@ func (c *ReminderConfig) GetSmartRemindTime(priority Priority) string {
 	}
 }
 
+// CurrentConfigVersion server.yaml 当前的配置格式版本
+// 版本 1：引入 config_version，图片缓存限制由 cache.image_cache_max_* 移到 cache.quotas.images
+const CurrentConfigVersion = 1
+
 // ServerConfig contains configuration for Microsoft Todo, Dify integration, reminder settings, cache management, logging, and token management.
 // It includes Azure AD credentials, timezone settings, Dify API configuration, reminder defaults, cache configuration, logging configuration, and token management configuration.
 type ServerConfig struct {
+	ConfigVersion  int                   `yaml:"config_version"` // 配置格式版本，旧文件使用 config migrate 升级
 	MicrosoftTodo  MicrosoftTodoConfig   `yaml:"microsoft_todo"`
 	Reminder       ReminderConfig        `yaml:"reminder"`
 	Deduplication  DeduplicationConfig   `yaml:"deduplication"`
 	Dify           DifyConfig           `yaml:"dify"`
+	DifyBackends   map[string]DifyConfig `yaml:"dify_backends,omitempty"` // 额外的 Dify 后端，按名称选择
 	Cache          CacheConfig          `yaml:"cache"`
 	Logging        LoggingConfig        `yaml:"logging"`
+	Watch          WatchConfig          `yaml:"watch"`
+	Metrics        MetricsConfig        `yaml:"metrics"`
 	TokenManager   *TokenManagerConfig  `yaml:"token_manager,omitempty"`
 }
 
@@ -166,45 +293,91 @@ type ParsedReminder struct {
 	DueTimeUTC       time.Time      // 截止时间（UTC）
 	AlarmTimeUTC     time.Time      // 提醒时间（UTC）
 	UserTimezone     string         // 用户配置的时区名称
+	// 结束时间（可选），零值表示没有结束时间
+	EndTime          time.Time      // 结束时间（本地）
+	EndTimeUTC       time.Time      // 结束时间（UTC）
+	EndTimeStr       string         // 格式化的结束时间字符串
+	// 全部提醒时间（本地，按时间先后排序），AlarmTime 是按 alarm_policy 选出的其中一个
+	Alarms            []time.Time
+	// 调度规则调整（可选），提醒时间被调整时记录原始时间和原因
+	OriginalAlarmTime time.Time     // 调整前的提醒时间（本地），未调整时为零值
+	AlarmShiftReason  string        // 调整原因，如 "2025-03-15 是非工作日（周六），提前到上一个工作时段"
+}
+
+// AlarmShifted 提醒时间是否被调度规则调整过
+func (p *ParsedReminder) AlarmShifted() bool {
+	return !p.OriginalAlarmTime.IsZero()
+}
+
+// HasEnd 是否包含结束时间
+func (p *ParsedReminder) HasEnd() bool {
+	return !p.EndTime.IsZero()
+}
+
+// Duration 返回持续时间，没有结束时间时为 0
+func (p *ParsedReminder) Duration() time.Duration {
+	if !p.HasEnd() {
+		return 0
+	}
+	return p.EndTime.Sub(p.DueTime)
+}
+
+// RangeText 返回便于阅读的时间范围，如 "2025-12-03 09:00 - 17:00"，
+// 跨天时结束部分带日期；没有结束时间时只返回开始时间
+func (p *ParsedReminder) RangeText() string {
+	start := p.DueTime.Format("2006-01-02 15:04")
+	if !p.HasEnd() {
+		return start
+	}
+	if p.EndTime.Format("2006-01-02") == p.DueTime.Format("2006-01-02") {
+		return start + " - " + p.EndTime.Format("15:04")
+	}
+	return start + " - " + p.EndTime.Format("2006-01-02 15:04")
 }
 
-// ParseReminderTime parses time information from a reminder and creates a ParsedReminder.
-// It converts date/time strings, calculates alarm times, and formats priority values.
-// Returns a ParsedReminder with calculated times and formatted strings, or an error if parsing fails.
 // parseTimeFromRange 从时间字符串中解析时间，支持时间范围格式
 // 如果输入是时间范围（如"14:30 - 16:30"），返回开始时间"14:30"
 // 如果输入是单个时间，直接返回
 func parseTimeFromRange(timeStr string) string {
+	start, _ := SplitTimeRange(timeStr)
+	return start
+}
+
+// SplitTimeRange 将时间范围拆分为开始和结束时间
+// 如果输入是时间范围（如"14:30 - 16:30"），返回 "14:30" 和 "16:30"
+// 如果输入是单个时间，返回原始字符串和空的结束时间
+func SplitTimeRange(timeStr string) (string, string) {
 	// 定义时间范围分隔符模式
 	rangePatterns := []string{
-		`^(\d{1,2}:\d{2})\s*[-~~到至]\s*(\d{1,2}:\d{2})$`,     // 14:30-16:30, 14:30~16:30, 14:30到16:30, 14:30至16:30
-		`^(\d{1,2}:\d{2})\s*[-~到至]\s*(\d{1,2}:\d{2})$`,       // 14:30 - 16:30 (带空格)
-		`^(\d{1,2}:\d{2})\s*-\s*(\d{1,2}:\d{2})$`,             // 14:30 - 16:30
+		`^(\d{1,2}:\d{2})\s*[-~～到至]\s*(\d{1,2}:\d{2})$`, // 14:30-16:30, 14:30~16:30, 14:30到16:30, 14:30至16:30
 	}
-	
+
 	for _, pattern := range rangePatterns {
 		if re, err := regexp.Compile(pattern); err == nil {
-			if matches := re.FindStringSubmatch(timeStr); len(matches) > 1 {
-				startTime := matches[1]
+			if matches := re.FindStringSubmatch(strings.TrimSpace(timeStr)); len(matches) > 2 {
 				// 验证开始时间格式是否有效
-				if isValidTimeFormat(startTime) {
-					return startTime
+				if isValidTimeFormat(matches[1]) {
+					if isValidTimeFormat(matches[2]) {
+						return matches[1], matches[2]
+					}
+					return matches[1], ""
 				}
 			}
 		}
 	}
-	
-	// 尝试更宽松的匹配：只提取第一个有效的时间格式
+
+	// 尝试更宽松的匹配：提取前两个有效的时间格式
 	timeRegex := regexp.MustCompile(`\d{1,2}:\d{2}`)
 	matches := timeRegex.FindAllString(timeStr, 2)
-	if len(matches) > 0 {
-		// 返回第一个匹配的时间（开始时间）
-		if isValidTimeFormat(matches[0]) {
-			return matches[0]
+	if len(matches) > 0 && isValidTimeFormat(matches[0]) {
+		if len(matches) == 2 && isValidTimeFormat(matches[1]) {
+			return matches[0], matches[1]
 		}
+		// 返回第一个匹配的时间（开始时间）
+		return matches[0], ""
 	}
-	
-	return timeStr // 不是时间范围格式，返回原始字符串
+
+	return timeStr, "" // 不是时间范围格式，返回原始字符串
 }
 
 // isValidTimeFormat 验证时间格式是否有效 (HH:MM 或 H:MM)
@@ -218,14 +391,23 @@ func isValidTimeFormat(timeStr string) bool {
 	return false
 }
 
+// ParseReminderTime parses time information from a reminder and creates a ParsedReminder.
+// It converts date/time strings, calculates alarm times, and formats priority values.
+// Returns a ParsedReminder with calculated times and formatted strings, or an error if parsing fails.
 func ParseReminderTime(reminder Reminder, timezone *time.Location) (*ParsedReminder, error) {
 	return ParseReminderTimeWithConfig(reminder, timezone, nil)
 }
 
 // ParseReminderTimeWithConfig 使用配置信息解析提醒时间，采用UTC标准化处理
 func ParseReminderTimeWithConfig(reminder Reminder, timezone *time.Location, config *ReminderConfig) (*ParsedReminder, error) {
-	// 处理时间范围，提取开始时间
-	processedTime := parseTimeFromRange(reminder.Time)
+	// 只有日期时使用配置的默认时间
+	if strings.TrimSpace(reminder.Time) == "" {
+		reminder.Time = config.GetDefaultTime()
+		log.Printf("提醒没有指定时间，使用默认时间: %s", reminder.Time)
+	}
+
+	// 处理时间范围，拆分开始和结束时间
+	processedTime, rangeEnd := SplitTimeRange(reminder.Time)
 
 	// 获取用户时区名称字符串
 	userTimezone := "UTC"
@@ -247,6 +429,12 @@ func ParseReminderTimeWithConfig(reminder Reminder, timezone *time.Location, con
 		dueTimeUTC.Format("2006-01-02 15:04:05"),
 		userTimezone)
 
+	// 解析结束时间（可选）
+	localEndTime, err := parseEndTime(reminder, localDueTime, rangeEnd, timezone)
+	if err != nil {
+		return nil, err
+	}
+
 	// 解析提前提醒时间
 	remindBefore := reminder.RemindBefore
 	if remindBefore == "" {
@@ -262,12 +450,38 @@ func ParseReminderTimeWithConfig(reminder Reminder, timezone *time.Location, con
 		log.Printf("用户设置的提醒时间: %s，将优先使用用户设置", remindBefore)
 	}
 
-	// 计算提醒时间（基于本地时间）
-	localAlarmTime, err := parseDuration(localDueTime, remindBefore)
+	// 计算全部提醒时间（基于本地时间），支持多个提醒、复合时长和绝对时间
+	alarms, err := ParseAlarms(localDueTime, remindBefore)
 	if err != nil {
 		return nil, err
 	}
 
+	// 按调度规则调整提醒时间（免打扰、非工作时间、节假日、最小提前量）
+	now := time.Now().In(localDueTime.Location())
+	originals := alarms
+	var shiftReason string
+	policy := AlarmPolicyEarliest
+	if config != nil {
+		alarms, originals, shiftReason, err = scheduleAlarms(alarms, localDueTime, now, &config.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply schedule rules: %w", err)
+		}
+		if config.AlarmPolicy != "" {
+			policy = config.AlarmPolicy
+		}
+	}
+
+	// Microsoft Todo 只支持一个提醒，按策略选出主提醒时间
+	primary := PickAlarm(alarms, policy, now)
+	localAlarmTime := alarms[primary]
+	var originalAlarmTime time.Time
+	if !originals[primary].Equal(localAlarmTime) {
+		originalAlarmTime = originals[primary]
+	}
+	if len(alarms) > 1 {
+		log.Printf("共 %d 个提醒，按 %s 策略使用 %s", len(alarms), policy, localAlarmTime.Format("2006-01-02 15:04"))
+	}
+
 	// 转换提醒时间为UTC
 	alarmTimeUTC := localAlarmTime.UTC()
 	log.Printf("提醒时间: 本地时间 %s -> UTC时间 %s",
@@ -299,7 +513,7 @@ func ParseReminderTimeWithConfig(reminder Reminder, timezone *time.Location, con
 	dueTimeStr := localDueTime.Format("2006-01-02T15:04:05")
 	remindTimeStr := localAlarmTime.Format("2006-01-02T15:04:05")
 
-	return &ParsedReminder{
+	parsed := &ParsedReminder{
 		Original:         reminder,
 		DueTime:          localDueTime,      // 保留原有字段（向后兼容）
 		AlarmTime:        localAlarmTime,    // 保留原有字段（向后兼容）
@@ -314,46 +528,60 @@ func ParseReminderTimeWithConfig(reminder Reminder, timezone *time.Location, con
 		DueTimeUTC:       dueTimeUTC,        // 截止时间（UTC）
 		AlarmTimeUTC:     alarmTimeUTC,      // 提醒时间（UTC）
 		UserTimezone:     userTimezone,      // 用户配置的时区名称
-	}, nil
+		Alarms:           alarms,
+		// 调度规则调整
+		OriginalAlarmTime: originalAlarmTime,
+		AlarmShiftReason:  shiftReason,
+	}
+	if !localEndTime.IsZero() {
+		parsed.EndTime = localEndTime
+		parsed.EndTimeUTC = localEndTime.UTC()
+		parsed.EndTimeStr = localEndTime.Format("2006-01-02T15:04:05")
+		log.Printf("结束时间: 本地时间 %s (持续 %s)", localEndTime.Format("2006-01-02 15:04:05"), parsed.Duration())
+	}
+
+	return parsed, nil
 }
 
-// parseDuration parses a duration string and calculates the reminder time from a given time.
-// Supports formats like "15m", "1h", "2d" for minutes, hours, and days respectively.
-// Returns the calculated reminder time, or an error if the duration format is invalid.
-func parseDuration(from time.Time, duration string) (time.Time, error) {
-	var d time.Duration
-	var err error
-
-	// 简单解析持续时间字符串
-	if len(duration) < 2 {
-		return time.Time{}, &time.ParseError{
-			Layout: "duration",
-			Value:  duration,
+// parseEndTime 根据 end_date、end_time、时间范围或 duration 计算结束时间。
+// 优先级：明确的结束日期/时间 > 时间范围的结束部分 > 持续时间；都没有时返回零值。
+// 只给出结束时刻且早于开始时间时视为跨越午夜。
+func parseEndTime(reminder Reminder, start time.Time, rangeEnd string, timezone *time.Location) (time.Time, error) {
+	endClock := reminder.EndTime
+	if endClock == "" {
+		endClock = rangeEnd
+	}
+
+	if reminder.EndDate == "" && endClock == "" {
+		if reminder.Duration == "" {
+			return time.Time{}, nil
 		}
+		span, err := ParseSpan(reminder.Duration)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid duration: %w", err)
+		}
+		return start.Add(span), nil
 	}
 
-	unit := duration[len(duration)-1:]
-	valueStr := duration[:len(duration)-1]
+	endDate := reminder.EndDate
+	if endDate == "" {
+		endDate = reminder.Date
+	}
+	if endClock == "" {
+		// 跨天范围没有结束时刻时沿用开始时刻
+		endClock = start.Format("15:04")
+	}
 
-	var value int
-	_, err = fmt.Sscanf(valueStr, "%d", &value)
+	end, err := time.ParseInLocation("2006-01-02 15:04", endDate+" "+endClock, timezone)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, fmt.Errorf("invalid end time: %w", err)
 	}
-
-	switch unit {
-	case "m":
-		d = time.Duration(value) * time.Minute
-	case "h":
-		d = time.Duration(value) * time.Hour
-	case "d":
-		d = time.Duration(value) * 24 * time.Hour
-	default:
-		return time.Time{}, &time.ParseError{
-			Layout: "duration",
-			Value:  duration,
-		}
+	if end.Before(start) && reminder.EndDate == "" {
+		end = end.AddDate(0, 0, 1)
 	}
-
-	return from.Add(-d), nil
+	if end.Before(start) {
+		return time.Time{}, fmt.Errorf("end time %s is before start time %s",
+			end.Format("2006-01-02 15:04"), start.Format("2006-01-02 15:04"))
+	}
+	return end, nil
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -245,4 +246,50 @@ func TestParseDuration(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+// TestReminderLinkUnmarshal 测试链接字段同时支持字符串和对象两种格式
+func TestReminderLinkUnmarshal(t *testing.T) {
+	data := `{"title":"t","date":"2025-01-01","time":"10:00","links":["https://a.example",{"url":"https://b.example","title":"B"}]}`
+
+	var reminder Reminder
+	if err := json.Unmarshal([]byte(data), &reminder); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if len(reminder.Links) != 2 {
+		t.Fatalf("期望 2 个链接, 实际 %d", len(reminder.Links))
+	}
+	if reminder.Links[0].URL != "https://a.example" || reminder.Links[0].Title != "" {
+		t.Errorf("字符串链接解析错误: %+v", reminder.Links[0])
+	}
+	if reminder.Links[1].URL != "https://b.example" || reminder.Links[1].Title != "B" {
+		t.Errorf("对象链接解析错误: %+v", reminder.Links[1])
+	}
+}
+
+func TestNormalizeKind(t *testing.T) {
+	testCases := map[string]Kind{
+		"":        KindTask,
+		"task":    KindTask,
+		"Event":   KindEvent,
+		"会议":      KindEvent,
+		"meeting": KindEvent,
+		"both":    KindBoth,
+		"unknown": KindTask,
+	}
+	for input, expected := range testCases {
+		if actual := NormalizeKind(input); actual != expected {
+			t.Errorf("NormalizeKind(%q) = %q, 期望 %q", input, actual, expected)
+		}
+	}
+
+	if !Kind("").WantsTask() || Kind("").WantsEvent() {
+		t.Error("默认类型应只创建任务")
+	}
+	if Kind("event").WantsTask() || !Kind("event").WantsEvent() {
+		t.Error("event 类型应只创建日历事件")
+	}
+	if !KindBoth.WantsTask() || !KindBoth.WantsEvent() {
+		t.Error("both 类型应同时创建任务和日历事件")
+	}
+}
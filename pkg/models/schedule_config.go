@@ -0,0 +1,348 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDateOnlyTime 只有日期没有时间的任务默认使用的时间
+	DefaultDateOnlyTime = "09:00"
+
+	// maxShiftDays 向前查找工作时段的最大天数（覆盖春节等长假）
+	maxShiftDays = 31
+)
+
+// ScheduleConfig 提醒时间调度规则
+// 提醒时间落在免打扰时段、非工作时间、周末或节假日时，提前到上一个工作时段内
+type ScheduleConfig struct {
+	Enabled      bool     `yaml:"enabled"`                 // 是否启用调度规则
+	WorkingHours string   `yaml:"working_hours,omitempty"` // 工作时间，如 "09:00-18:00"，为空表示全天
+	WorkingDays  []string `yaml:"working_days,omitempty"`  // 工作日，如 [mon, tue, wed, thu, fri]，为空表示每天
+	QuietHours   string   `yaml:"quiet_hours,omitempty"`   // 免打扰时段，如 "22:00-08:00"，可跨越午夜
+	HolidaysFile string   `yaml:"holidays_file,omitempty"` // 节假日日历（本地 .ics 文件），其中的日期视为非工作日
+	MinLeadTime  string   `yaml:"min_lead_time,omitempty"` // 提醒至少提前多久（如 10m, 1h）
+
+	rules *scheduleRules // Validate 时编译的规则
+}
+
+// DefaultScheduleConfig 返回默认调度规则（默认不启用）
+func DefaultScheduleConfig() ScheduleConfig {
+	return ScheduleConfig{
+		Enabled:      false,
+		WorkingHours: "09:00-18:00",
+		WorkingDays:  []string{"mon", "tue", "wed", "thu", "fri"},
+		QuietHours:   "22:00-08:00",
+		MinLeadTime:  "5m",
+	}
+}
+
+// Validate 验证调度规则并加载节假日日历
+func (c *ScheduleConfig) Validate() error {
+	rules, err := c.compile()
+	if err != nil {
+		return err
+	}
+	c.rules = rules
+	return nil
+}
+
+// scheduleRules 编译后的调度规则
+type scheduleRules struct {
+	workStart, workEnd   int // 工作时间（当天分钟数），workEnd 含端点
+	hasWorkingHours      bool
+	quietStart, quietEnd int // 免打扰时段（当天分钟数），quietEnd 不含端点
+	hasQuietHours        bool
+	days                 map[time.Weekday]bool // 为空表示每天
+	holidays             map[string]bool       // YYYY-MM-DD
+	minLead              time.Duration
+}
+
+// compile 解析配置中的字符串规则
+func (c *ScheduleConfig) compile() (*scheduleRules, error) {
+	rules := &scheduleRules{}
+
+	if c.WorkingHours != "" {
+		start, end, err := parseClockRange(c.WorkingHours)
+		if err != nil {
+			return nil, fmt.Errorf("invalid working_hours: %w", err)
+		}
+		if start >= end {
+			return nil, fmt.Errorf("invalid working_hours: start must be before end")
+		}
+		rules.workStart, rules.workEnd, rules.hasWorkingHours = start, end, true
+	}
+
+	if c.QuietHours != "" {
+		start, end, err := parseClockRange(c.QuietHours)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet_hours: %w", err)
+		}
+		rules.quietStart, rules.quietEnd, rules.hasQuietHours = start, end, start != end
+	}
+
+	if len(c.WorkingDays) > 0 {
+		rules.days = make(map[time.Weekday]bool, len(c.WorkingDays))
+		for _, day := range c.WorkingDays {
+			weekday, ok := parseWeekday(day)
+			if !ok {
+				return nil, fmt.Errorf("invalid working_days entry: %s", day)
+			}
+			rules.days[weekday] = true
+		}
+	}
+
+	if c.HolidaysFile != "" {
+		holidays, err := LoadHolidays(c.HolidaysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load holidays_file: %w", err)
+		}
+		rules.holidays = holidays
+	}
+
+	if c.MinLeadTime != "" {
+		lead, err := ParseSpan(c.MinLeadTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_lead_time: %w", err)
+		}
+		rules.minLead = lead
+	}
+
+	return rules, nil
+}
+
+// compiled 返回编译后的规则，未经 Validate 时临时编译
+func (c *ScheduleConfig) compiled() (*scheduleRules, error) {
+	if c.rules != nil {
+		return c.rules, nil
+	}
+	return c.compile()
+}
+
+// AdjustAlarm 按调度规则调整提醒时间，返回调整后的时间和调整原因；
+// 不需要调整时原样返回且原因为空。now 用于避免把提醒提前到已经过去的时间。
+func (c *ScheduleConfig) AdjustAlarm(alarm, due, now time.Time) (time.Time, string, error) {
+	if !c.Enabled {
+		return alarm, "", nil
+	}
+	rules, err := c.compiled()
+	if err != nil {
+		return alarm, "", err
+	}
+	alarm, reason := rules.adjust(alarm, due, now)
+	return alarm, reason, nil
+}
+
+// adjust 先保证最小提前量，再把不允许的提醒时间提前到上一个工作时段
+func (r *scheduleRules) adjust(alarm, due, now time.Time) (time.Time, string) {
+	var reasons []string
+
+	if r.minLead > 0 && due.Sub(alarm) < r.minLead {
+		alarm = due.Add(-r.minLead)
+		reasons = append(reasons, fmt.Sprintf("提前量不足 %s", r.minLead))
+	}
+
+	if why := r.blockedReason(alarm); why != "" {
+		shifted, ok := r.previousAllowed(alarm)
+		switch {
+		case !ok:
+			reasons = append(reasons, fmt.Sprintf("%s，%d 天内没有可用的工作时段，保持原提醒时间", why, maxShiftDays))
+		case shifted.Before(now):
+			reasons = append(reasons, fmt.Sprintf("%s，上一个工作时段 %s 已过去，保持原提醒时间", why, shifted.Format("2006-01-02 15:04")))
+		default:
+			reasons = append(reasons, fmt.Sprintf("%s，提前到上一个工作时段", why))
+			alarm = shifted
+		}
+	}
+
+	return alarm, strings.Join(reasons, "；")
+}
+
+// blockedReason 返回提醒时间不被允许的原因，允许时返回空字符串
+func (r *scheduleRules) blockedReason(t time.Time) string {
+	if r.holidays[t.Format("2006-01-02")] {
+		return fmt.Sprintf("%s 是节假日", t.Format("2006-01-02"))
+	}
+	if r.days != nil && !r.days[t.Weekday()] {
+		return fmt.Sprintf("%s 是非工作日（%s）", t.Format("2006-01-02"), weekdayNames[t.Weekday()])
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if r.inQuietHours(minute) {
+		return fmt.Sprintf("%s 处于免打扰时段 %s-%s", t.Format("15:04"), formatMinutes(r.quietStart), formatMinutes(r.quietEnd))
+	}
+	if r.hasWorkingHours && (minute < r.workStart || minute > r.workEnd) {
+		return fmt.Sprintf("%s 不在工作时间 %s-%s 内", t.Format("15:04"), formatMinutes(r.workStart), formatMinutes(r.workEnd))
+	}
+	return ""
+}
+
+// inQuietHours 判断当天第 minute 分钟是否处于免打扰时段
+func (r *scheduleRules) inQuietHours(minute int) bool {
+	if !r.hasQuietHours {
+		return false
+	}
+	if r.quietStart < r.quietEnd {
+		return minute >= r.quietStart && minute < r.quietEnd
+	}
+	// 跨越午夜，如 22:00-08:00
+	return minute >= r.quietStart || minute < r.quietEnd
+}
+
+// previousAllowed 查找不晚于 t 的最近一个允许提醒的时间（精确到分钟）
+func (r *scheduleRules) previousAllowed(t time.Time) (time.Time, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	minute := t.Hour()*60 + t.Minute()
+
+	for i := 0; i <= maxShiftDays; i++ {
+		for m := minute; m >= 0; m-- {
+			candidate := day.Add(time.Duration(m) * time.Minute)
+			if r.blockedReason(candidate) == "" {
+				return candidate, true
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+		minute = 24*60 - 1
+	}
+	return time.Time{}, false
+}
+
+// weekdayNames 星期的中文名称
+var weekdayNames = map[time.Weekday]string{
+	time.Sunday:    "周日",
+	time.Monday:    "周一",
+	time.Tuesday:   "周二",
+	time.Wednesday: "周三",
+	time.Thursday:  "周四",
+	time.Friday:    "周五",
+	time.Saturday:  "周六",
+}
+
+// parseWeekday 解析星期名称，支持 mon/monday/周一/星期一
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for weekday, cn := range weekdayNames {
+		english := strings.ToLower(weekday.String())
+		if name == english || name == english[:3] || name == cn || name == "星期"+strings.TrimPrefix(cn, "周") {
+			return weekday, true
+		}
+	}
+	if name == "星期天" || name == "周天" {
+		return time.Sunday, true
+	}
+	return 0, false
+}
+
+// parseClockRange 解析 "09:00-18:00" 形式的时段，返回开始和结束的当天分钟数
+func parseClockRange(value string) (int, int, error) {
+	start, end := SplitTimeRange(value)
+	if end == "" {
+		return 0, 0, fmt.Errorf("expected HH:MM-HH:MM, got %q", value)
+	}
+	startMinutes, err := parseClockMinutes(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	endMinutes, err := parseClockMinutes(end)
+	if err != nil {
+		return 0, 0, err
+	}
+	return startMinutes, endMinutes, nil
+}
+
+// parseClockMinutes 将 HH:MM 转换为当天分钟数
+func parseClockMinutes(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatMinutes 将当天分钟数格式化为 HH:MM
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// LoadHolidays 从 iCalendar (.ics) 文件读取节假日，返回 YYYY-MM-DD 日期集合。
+// 每个 VEVENT 的 DTSTART 到 DTEND（全天事件不含 DTEND 当天）之间的日期都视为节假日。
+func LoadHolidays(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// 展开折行：以空格或制表符开头的行是上一行的续行
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	holidays := make(map[string]bool)
+	var inEvent bool
+	var start, end time.Time
+	var allDay bool
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		property := strings.ToUpper(strings.SplitN(name, ";", 2)[0])
+		switch {
+		case property == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent, start, end, allDay = true, time.Time{}, time.Time{}, false
+		case property == "DTSTART" && inEvent:
+			start, err = parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+			allDay = len(strings.TrimSpace(value)) == 8
+		case property == "DTEND" && inEvent:
+			end, err = parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+		case property == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+			if start.IsZero() {
+				continue
+			}
+			if !end.IsZero() && !allDay {
+				// 非全天事件包含结束当天
+				end = end.AddDate(0, 0, 1)
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays[day.Format("2006-01-02")] = true
+			}
+		}
+	}
+	return holidays, nil
+}
+
+// parseICSDate 解析 iCalendar 的 DATE 或 DATE-TIME 值，只保留日期部分
+func parseICSDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date value %q", value)
+	}
+	day, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date value %q", value)
+	}
+	return day, nil
+}
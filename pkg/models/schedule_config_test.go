@@ -0,0 +1,157 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustSchedule(t *testing.T, cfg ScheduleConfig) ScheduleConfig {
+	t.Helper()
+	cfg.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	return cfg
+}
+
+func localTime(value string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02 15:04", value, time.UTC)
+	return t
+}
+
+func TestScheduleConfig_AdjustAlarm(t *testing.T) {
+	cfg := mustSchedule(t, DefaultScheduleConfig())
+	now := localTime("2025-03-01 00:00")
+
+	testCases := []struct {
+		desc     string
+		alarm    string
+		due      string
+		expected string
+		reason   string
+	}{
+		{"工作时间内不调整", "2025-03-11 10:00", "2025-03-11 10:15", "2025-03-11 10:00", ""},
+		{"凌晨3点提前到前一天下班前", "2025-03-11 03:00", "2025-03-11 03:15", "2025-03-10 18:00", "免打扰"},
+		{"周六提前到周五", "2025-03-15 09:45", "2025-03-15 10:00", "2025-03-14 18:00", "非工作日"},
+		{"早于上班时间提前到前一天", "2025-03-11 08:30", "2025-03-11 08:45", "2025-03-10 18:00", "不在工作时间"},
+		{"提前量不足时补足", "2025-03-11 10:13", "2025-03-11 10:15", "2025-03-11 10:10", "提前量不足"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			alarm, reason, err := cfg.AdjustAlarm(localTime(tc.alarm), localTime(tc.due), now)
+			if err != nil {
+				t.Fatalf("AdjustAlarm() error = %v", err)
+			}
+			if got := alarm.Format("2006-01-02 15:04"); got != tc.expected {
+				t.Errorf("AdjustAlarm() = %s, want %s", got, tc.expected)
+			}
+			if tc.reason == "" && reason != "" {
+				t.Errorf("AdjustAlarm() reason = %q, want empty", reason)
+			}
+			if !strings.Contains(reason, tc.reason) {
+				t.Errorf("AdjustAlarm() reason = %q, want to contain %q", reason, tc.reason)
+			}
+		})
+	}
+}
+
+func TestScheduleConfig_KeepsAlarmWhenWindowPassed(t *testing.T) {
+	cfg := mustSchedule(t, DefaultScheduleConfig())
+
+	// 周六的任务在周五晚上创建，周五 18:00 已经过去
+	alarm := localTime("2025-03-15 09:45")
+	got, reason, err := cfg.AdjustAlarm(alarm, localTime("2025-03-15 10:00"), localTime("2025-03-14 20:00"))
+	if err != nil {
+		t.Fatalf("AdjustAlarm() error = %v", err)
+	}
+	if !got.Equal(alarm) {
+		t.Errorf("AdjustAlarm() = %s, want unchanged %s", got, alarm)
+	}
+	if !strings.Contains(reason, "已过去") {
+		t.Errorf("AdjustAlarm() reason = %q, want to mention passed window", reason)
+	}
+}
+
+func TestScheduleConfig_Holidays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.ics")
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:国庆节\r\nDTSTART;VALUE=DATE:20251001\r\nDTEND;VALUE=DATE:20251004\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:调休\r\nDTSTART:20251006T000000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(ics), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	holidays, err := LoadHolidays(path)
+	if err != nil {
+		t.Fatalf("LoadHolidays() error = %v", err)
+	}
+	for _, day := range []string{"2025-10-01", "2025-10-02", "2025-10-03", "2025-10-06"} {
+		if !holidays[day] {
+			t.Errorf("LoadHolidays() missing %s", day)
+		}
+	}
+	if holidays["2025-10-04"] {
+		t.Errorf("LoadHolidays() DTEND of all-day event should be exclusive")
+	}
+
+	cfg := DefaultScheduleConfig()
+	cfg.HolidaysFile = path
+	cfg = mustSchedule(t, cfg)
+	alarm, reason, err := cfg.AdjustAlarm(localTime("2025-10-02 10:00"), localTime("2025-10-02 10:15"), localTime("2025-09-01 00:00"))
+	if err != nil {
+		t.Fatalf("AdjustAlarm() error = %v", err)
+	}
+	if got := alarm.Format("2006-01-02 15:04"); got != "2025-09-30 18:00" {
+		t.Errorf("AdjustAlarm() = %s, want 2025-09-30 18:00", got)
+	}
+	if !strings.Contains(reason, "节假日") {
+		t.Errorf("AdjustAlarm() reason = %q, want to mention holiday", reason)
+	}
+}
+
+func TestScheduleConfig_ValidateErrors(t *testing.T) {
+	testCases := map[string]ScheduleConfig{
+		"working_hours": {WorkingHours: "18:00-09:00"},
+		"quiet_hours":   {QuietHours: "22:00"},
+		"working_days":  {WorkingDays: []string{"funday"}},
+		"min_lead_time": {MinLeadTime: "soon"},
+		"holidays_file": {HolidaysFile: filepath.Join(t.TempDir(), "missing.ics")},
+	}
+	for field, cfg := range testCases {
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("Validate() error = %v, want error mentioning %s", err, field)
+		}
+	}
+}
+
+func TestParseReminderTimeWithConfig_Schedule(t *testing.T) {
+	config := &ReminderConfig{
+		DefaultRemindBefore: "15m",
+		DefaultTime:         "09:30",
+		Schedule:            mustSchedule(t, DefaultScheduleConfig()),
+	}
+
+	// 2099-03-14 是周六，只有日期的任务使用默认时间，提醒提前到周五下班前
+	parsed, err := ParseReminderTimeWithConfig(Reminder{Title: "交报告", Date: "2099-03-14", RemindBefore: "15m"}, time.UTC, config)
+	if err != nil {
+		t.Fatalf("ParseReminderTimeWithConfig() error = %v", err)
+	}
+	if got := parsed.DueTime.Format("2006-01-02 15:04"); got != "2099-03-14 09:30" {
+		t.Errorf("DueTime = %s, want 2099-03-14 09:30", got)
+	}
+	if got := parsed.AlarmTime.Format("2006-01-02 15:04"); got != "2099-03-13 18:00" {
+		t.Errorf("AlarmTime = %s, want 2099-03-13 18:00", got)
+	}
+	if !parsed.AlarmShifted() || parsed.OriginalAlarmTime.Format("15:04") != "09:15" {
+		t.Errorf("OriginalAlarmTime = %v, want 09:15 on the due date", parsed.OriginalAlarmTime)
+	}
+	if !strings.Contains(parsed.AlarmShiftReason, "周六") {
+		t.Errorf("AlarmShiftReason = %q, want to mention Saturday", parsed.AlarmShiftReason)
+	}
+	if !parsed.AlarmTimeUTC.Equal(parsed.AlarmTime.UTC()) {
+		t.Errorf("AlarmTimeUTC = %v, want adjusted alarm", parsed.AlarmTimeUTC)
+	}
+}
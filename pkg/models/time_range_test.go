@@ -181,4 +181,103 @@ func TestIsValidTimeFormat(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestParseReminderTimeEnd(t *testing.T) {
+	timezone, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("加载时区失败: %v", err)
+	}
+
+	testCases := []struct {
+		reminder Reminder
+		expected string // 期望的时间范围文本
+		desc     string
+	}{
+		{
+			reminder: Reminder{Date: "2025-11-06", Time: "14:30 - 16:30"},
+			expected: "2025-11-06 14:30 - 16:30",
+			desc:     "时间范围保留结束时间",
+		},
+		{
+			reminder: Reminder{Date: "2025-11-06", Time: "14:30", EndTime: "15:00"},
+			expected: "2025-11-06 14:30 - 15:00",
+			desc:     "明确的结束时间",
+		},
+		{
+			reminder: Reminder{Date: "2025-11-06", Time: "14:30", Duration: "1h30m"},
+			expected: "2025-11-06 14:30 - 16:00",
+			desc:     "持续时间",
+		},
+		{
+			reminder: Reminder{Date: "2025-11-06", Time: "22:00 - 01:00"},
+			expected: "2025-11-06 22:00 - 2025-11-07 01:00",
+			desc:     "跨越午夜",
+		},
+		{
+			reminder: Reminder{Date: "2025-12-03", Time: "09:00", EndDate: "2025-12-05", EndTime: "17:00"},
+			expected: "2025-12-03 09:00 - 2025-12-05 17:00",
+			desc:     "跨天范围",
+		},
+		{
+			reminder: Reminder{Date: "2025-12-03", Time: "09:00", EndDate: "2025-12-05"},
+			expected: "2025-12-03 09:00 - 2025-12-05 09:00",
+			desc:     "跨天范围沿用开始时刻",
+		},
+		{
+			reminder: Reminder{Date: "2025-11-06", Time: "14:30"},
+			expected: "2025-11-06 14:30",
+			desc:     "没有结束时间",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.reminder.Title = "会议"
+			parsed, err := ParseReminderTime(tc.reminder, timezone)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if actual := parsed.RangeText(); actual != tc.expected {
+				t.Errorf("期望: %s\n实际: %s", tc.expected, actual)
+			}
+			if parsed.HasEnd() && !parsed.EndTimeUTC.Equal(parsed.EndTime) {
+				t.Errorf("UTC 结束时间不一致: %v vs %v", parsed.EndTimeUTC, parsed.EndTime)
+			}
+		})
+	}
+}
+
+func TestParseReminderTimeEndInvalid(t *testing.T) {
+	invalid := []Reminder{
+		{Title: "会议", Date: "2025-12-05", Time: "09:00", EndDate: "2025-12-03"},
+		{Title: "会议", Date: "2025-12-05", Time: "09:00", Duration: "abc"},
+		{Title: "会议", Date: "2025-12-05", Time: "09:00", EndTime: "25:00"},
+	}
+	for _, reminder := range invalid {
+		if _, err := ParseReminderTime(reminder, time.UTC); err == nil {
+			t.Errorf("期望解析失败: %+v", reminder)
+		}
+	}
+}
+
+func TestParseSpan(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"90m":   90 * time.Minute,
+		"1h30m": 90 * time.Minute,
+		"2d":    48 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"1w":    7 * 24 * time.Hour,
+		"1w2d":  9 * 24 * time.Hour,
+	}
+	for input, expected := range testCases {
+		actual, err := ParseSpan(input)
+		if err != nil || actual != expected {
+			t.Errorf("ParseSpan(%q) = %v, %v; 期望 %v", input, actual, err, expected)
+		}
+	}
+	for _, input := range []string{"", "d", "xd", "-5m", "w"} {
+		if _, err := ParseSpan(input); err == nil {
+			t.Errorf("ParseSpan(%q) 期望失败", input)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// 剪贴板监听触发条件
+const (
+	WatchTriggerAny          = "any"            // 任意文本或图片
+	WatchTriggerText         = "text"           // 仅文本
+	WatchTriggerImage        = "image"          // 仅图片
+	WatchTriggerTextWithDate = "text_with_date" // 包含日期或时间的文本
+)
+
+// WatchConfig 剪贴板监听配置
+type WatchConfig struct {
+	Trigger            string `yaml:"trigger"`                // 触发条件: any, text, image, text_with_date
+	IntervalMs         int    `yaml:"interval_ms"`            // 轮询间隔(毫秒)，0表示使用默认值
+	MinTextLength      int    `yaml:"min_text_length"`        // 文本最少字符数，过短的文本不处理
+	TextPattern        string `yaml:"text_pattern,omitempty"` // 文本需匹配的正则表达式（可选）
+	DedupWindowMinutes int    `yaml:"dedup_window_minutes"`   // 相同内容在该时间窗口内不重复处理
+}
+
+// DefaultWatchConfig 返回默认剪贴板监听配置
+func DefaultWatchConfig() WatchConfig {
+	return WatchConfig{
+		Trigger:            WatchTriggerAny,
+		IntervalMs:         500,
+		MinTextLength:      4,
+		DedupWindowMinutes: 60,
+	}
+}
+
+// Validate 验证剪贴板监听配置
+func (wc *WatchConfig) Validate() error {
+	switch wc.Trigger {
+	case "", WatchTriggerAny, WatchTriggerText, WatchTriggerImage, WatchTriggerTextWithDate:
+	default:
+		return fmt.Errorf("unknown watch trigger: %s", wc.Trigger)
+	}
+
+	if wc.IntervalMs < 0 {
+		return fmt.Errorf("interval_ms cannot be negative")
+	}
+
+	if wc.MinTextLength < 0 {
+		return fmt.Errorf("min_text_length cannot be negative")
+	}
+
+	if wc.DedupWindowMinutes < 0 {
+		return fmt.Errorf("dedup_window_minutes cannot be negative")
+	}
+
+	if wc.TextPattern != "" {
+		if _, err := regexp.Compile(wc.TextPattern); err != nil {
+			return fmt.Errorf("invalid text_pattern: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetTrigger 获取触发条件，未配置时为 any
+func (wc *WatchConfig) GetTrigger() string {
+	if wc.Trigger == "" {
+		return WatchTriggerAny
+	}
+	return wc.Trigger
+}
+
+// GetInterval 获取轮询间隔
+func (wc *WatchConfig) GetInterval() time.Duration {
+	if wc.IntervalMs <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(wc.IntervalMs) * time.Millisecond
+}
+
+// GetDedupWindow 获取去重时间窗口
+func (wc *WatchConfig) GetDedupWindow() time.Duration {
+	return time.Duration(wc.DedupWindowMinutes) * time.Minute
+}
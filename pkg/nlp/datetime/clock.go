@@ -0,0 +1,129 @@
+package datetime
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 时段词，用于区分上午和下午
+const periodWords = `上午|下午|中午|晚上|傍晚|早上|早晨|凌晨|夜里`
+
+var (
+	// 3pm, 3:30 PM, 10 a.m.
+	meridiemClockRegex = regexp.MustCompile(`(?i)\b(\d{1,2})(?::(\d{2}))?\s*([ap])\.?m\b\.?`)
+	// 14:30, 下午3:30
+	colonClockRegex = regexp.MustCompile(`(` + periodWords + `)?\s*(\d{1,2})[:：](\d{2})`)
+	// 3点, 下午3点半, 十点一刻, 9点15分
+	chineseClockRegex = regexp.MustCompile(`(` + periodWords + `)?\s*(` + cnNumber + `)\s*[点時时](?:\s*(半|一刻|三刻|(` + cnNumber + `)\s*分?))?`)
+	// noon, midnight, 中午
+	namedClockRegex = regexp.MustCompile(`(?i)\b(noon|midday|midnight)\b|中午`)
+	// EOD, COB, end of day, 下班前
+	endOfDayRegex = regexp.MustCompile(`(?i)\b(?:EOD|COB|end of (?:the )?day)\b|下班前`)
+)
+
+// resolveClock 查找时刻表达式，返回距零点的分钟数
+func (r *Resolver) resolveClock(text string) (int, *match) {
+	if g := meridiemClockRegex.FindStringSubmatch(text); g != nil {
+		hour, _ := strconv.Atoi(g[1])
+		minute, _ := strconv.Atoi(g[2])
+		if hour >= 1 && hour <= 12 && minute < 60 {
+			hour %= 12
+			if strings.EqualFold(g[3], "p") {
+				hour += 12
+			}
+			return hour*60 + minute, newMatch()
+		}
+	}
+
+	if g := colonClockRegex.FindStringSubmatch(text); g != nil {
+		hour, _ := strconv.Atoi(g[2])
+		minute, _ := strconv.Atoi(g[3])
+		// 两位小时（09:00、14:30）按 24 小时制理解，一位小时且没有时段时存在歧义
+		padded := len(g[2]) == 2
+		if minutes, m, ok := applyPeriod(g[1], hour, minute, padded, false); ok {
+			return minutes, m
+		}
+	}
+
+	if g := chineseClockRegex.FindStringSubmatch(text); g != nil {
+		hour, ok := parseNumber(g[2])
+		// "快一点"、"早一点" 不是时刻
+		if g[2] == "一" && g[1] == "" && g[3] == "" {
+			ok = false
+		}
+		if ok {
+			minute := 0
+			switch g[3] {
+			case "":
+			case "半":
+				minute = 30
+			case "一刻":
+				minute = 15
+			case "三刻":
+				minute = 45
+			default:
+				minute, ok = parseNumber(g[4])
+			}
+			if ok {
+				if minutes, m, ok := applyPeriod(g[1], hour, minute, false, true); ok {
+					return minutes, m
+				}
+			}
+		}
+	}
+
+	if g := namedClockRegex.FindStringSubmatch(text); g != nil {
+		if strings.EqualFold(g[1], "midnight") {
+			return 0, newMatch()
+		}
+		return 12 * 60, newMatch()
+	}
+
+	// 没有明确时刻时，EOD 使用配置的下班时间
+	if endOfDayRegex.MatchString(text) {
+		return r.endOfDayMin, newMatch()
+	}
+
+	return 0, nil
+}
+
+// applyPeriod 根据时段词换算为 24 小时制。
+// 没有时段词时：padded 为 true 表示按 24 小时制书写，不存在歧义；
+// guessAfternoon 为 true 时 1-6 点按下午理解（"3点开会"通常指 15:00），并标记歧义。
+func applyPeriod(period string, hour, minute int, padded, guessAfternoon bool) (int, *match, bool) {
+	if hour < 0 || hour > 24 || minute < 0 || minute > 59 {
+		return 0, nil, false
+	}
+
+	var ambiguities []Ambiguity
+	switch period {
+	case "下午", "晚上", "傍晚", "夜里":
+		if hour < 12 {
+			hour += 12
+		}
+	case "中午":
+		if hour < 11 {
+			hour += 12
+		}
+	case "凌晨", "上午", "早上", "早晨":
+		if hour == 12 {
+			hour = 0
+		}
+	default:
+		if !padded && hour >= 1 && hour <= 11 {
+			ambiguities = append(ambiguities, AmbiguousMeridiem)
+			if guessAfternoon && hour <= 6 {
+				hour += 12
+			}
+		}
+	}
+
+	if hour == 24 && minute == 0 {
+		hour = 0
+	}
+	if hour > 23 {
+		return 0, nil, false
+	}
+	return hour*60 + minute, newMatch(ambiguities...), true
+}
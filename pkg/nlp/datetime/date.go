@@ -0,0 +1,464 @@
+package datetime
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateRule 日期规则，返回解析出的日期（当天零点，或相对时长得到的精确时间）
+type dateRule struct {
+	pattern *regexp.Regexp
+	resolve func(r *Resolver, groups []string, now time.Time) (time.Time, *match, bool)
+}
+
+// cnNumber 匹配数字或简单的中文数字（一至九十九）
+const cnNumber = `\d{1,2}|[零一二两三四五六七八九十]{1,3}`
+
+// dateRules 按优先级排列：明确日期 > 相对时长 > 月底/周几等相对日期 > 今天/明天
+var dateRules = []dateRule{
+	// ISO 周: 2025-W12, 2025W12-3
+	{regexp.MustCompile(`(?i)\b(\d{4})-?W(\d{1,2})(?:-?([1-7]))?\b`), resolveISOWeek},
+	// 2025-03-10, 2025/3/10, 2025.03.10, 2025年3月10日
+	{regexp.MustCompile(`(\d{4})\s*[-/.年]\s*(\d{1,2})\s*[-/.月]\s*(\d{1,2})\s*[日号]?`), resolveYearMonthDay},
+	// 03/15/2025 或 15/03/2025
+	{regexp.MustCompile(`\b(\d{1,2})[/.](\d{1,2})[/.](\d{4})\b`), resolveNumericDate},
+	// 3月15日, 十二月三号
+	{regexp.MustCompile(`(` + cnNumber + `)\s*月\s*(` + cnNumber + `)\s*[日号]`), resolveMonthDay},
+	// March 15, Mar 15th 2025
+	{regexp.MustCompile(`(?i)\b(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec)\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s*(\d{4})\b)?`), resolveEnglishMonthDay},
+	// 第12周
+	{regexp.MustCompile(`第\s*(\d{1,2})\s*周`), resolveWeekNumber},
+	// 2小时后, 半小时后, 3天后, 两周后
+	{regexp.MustCompile(`(\d+|[一二两三四五六七八九十]{1,3}|半)\s*个?\s*(分钟|小时|钟头|天|周|星期|礼拜)\s*(?:后|以后|之后)`), resolveChineseDuration},
+	// in 2 hours, in 30 minutes, in a week
+	{regexp.MustCompile(`(?i)\bin\s+(\d+|an?|one|two|three|half an?)\s+(minutes?|mins?|hours?|hrs?|days?|weeks?)\b`), resolveEnglishDuration},
+	// 3天前
+	{regexp.MustCompile(`(` + cnNumber + `)\s*天前`), resolveDaysAgo},
+	// EOW, end of week
+	{regexp.MustCompile(`(?i)\b(?:EOW|end of (?:the )?week)\b`), resolveEndOfWeek},
+	// 月底, 下个月底, 本月末, 下月初
+	{regexp.MustCompile(`(下个月|下月|本月|这个月|月)(底|末|初)`), resolveMonthBoundary},
+	// end of month, end of next month
+	{regexp.MustCompile(`(?i)\bend of (?:the )?(next )?month\b`), resolveEnglishMonthEnd},
+	// 年底, 明年年底
+	{regexp.MustCompile(`(明年|今年)?\s*年(?:底|末)`), resolveYearEnd},
+	// 周末, 下周末
+	{regexp.MustCompile(`(下|本|这)?\s*个?\s*周末`), resolveWeekend},
+	// 下周三, 本周五, 星期天, 下下周一
+	{regexp.MustCompile(`(下下|下|上|本|这)?\s*个?\s*(?:周|星期|礼拜)([一二三四五六日天])`), resolveChineseWeekday},
+	// next Monday, this Friday, Tuesday
+	{regexp.MustCompile(`(?i)\b(?:(next|this|last|coming)\s+)?(monday|tuesday|wednesday|thursday|friday|saturday|sunday)\b`), resolveEnglishWeekday},
+	// 今天, 明天, 后天
+	{regexp.MustCompile(`大后天|后天|明天|明日|今天|今日|今晚|大前天|前天|昨天`), resolveChineseRelativeDay},
+	{regexp.MustCompile(`(?i)\b(today|tonight|tomorrow|yesterday)\b`), resolveEnglishRelativeDay},
+}
+
+// resolveDate 按规则顺序查找第一个日期表达式
+func (r *Resolver) resolveDate(text string, now time.Time) (time.Time, *match) {
+	for _, rule := range dateRules {
+		groups := rule.pattern.FindStringSubmatch(text)
+		if groups == nil {
+			continue
+		}
+		if date, m, ok := rule.resolve(r, groups, now); ok {
+			return date, m
+		}
+	}
+	return time.Time{}, nil
+}
+
+// newMatch 创建没有默认时刻的匹配结果
+func newMatch(ambiguities ...Ambiguity) *match {
+	return &match{ambiguities: ambiguities, defaultMinutes: -1}
+}
+
+// makeDate 创建日期并校验有效性（拒绝 2 月 30 日等溢出日期）
+func makeDate(year, month, day int, loc *time.Location) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// parseNumber 解析阿拉伯数字或一至九十九的中文数字
+func parseNumber(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+
+	digits := map[rune]int{'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	runes := []rune(s)
+	switch {
+	case len(runes) == 1 && runes[0] == '十':
+		return 10, true
+	case len(runes) == 1:
+		n, ok := digits[runes[0]]
+		return n, ok
+	case len(runes) == 2 && runes[0] == '十':
+		n, ok := digits[runes[1]]
+		return 10 + n, ok
+	case len(runes) == 2 && runes[1] == '十':
+		n, ok := digits[runes[0]]
+		return n * 10, ok
+	case len(runes) == 3 && runes[1] == '十':
+		tens, ok1 := digits[runes[0]]
+		ones, ok2 := digits[runes[2]]
+		return tens*10 + ones, ok1 && ok2
+	}
+	return 0, false
+}
+
+// isoWeekday 返回 ISO 星期几，周一为 1，周日为 7
+func isoWeekday(t time.Time) int {
+	return (int(t.Weekday())+6)%7 + 1
+}
+
+// weekStart 返回所在 ISO 周的周一
+func weekStart(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, 1-isoWeekday(day))
+}
+
+// isoWeekDate 返回 ISO 年第 week 周的第 weekday 天
+func isoWeekDate(year, week, weekday int, loc *time.Location) (time.Time, bool) {
+	if week < 1 || week > 53 {
+		return time.Time{}, false
+	}
+	// 1 月 4 日总是在第 1 周
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	date := weekStart(jan4).AddDate(0, 0, (week-1)*7+weekday-1)
+	if y, w := date.ISOWeek(); y != year || w != week {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func resolveISOWeek(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	year, _ := strconv.Atoi(g[1])
+	week, _ := strconv.Atoi(g[2])
+	weekday := 1
+	if g[3] != "" {
+		weekday, _ = strconv.Atoi(g[3])
+	}
+	date, ok := isoWeekDate(year, week, weekday, r.location)
+	return date, newMatch(), ok
+}
+
+func resolveWeekNumber(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	week, _ := strconv.Atoi(g[1])
+	year, _ := now.ISOWeek()
+	date, ok := isoWeekDate(year, week, 1, r.location)
+	if !ok {
+		return time.Time{}, nil, false
+	}
+	// 省略年份的周数已结束时按下一年解析
+	if date.AddDate(0, 0, 7).Before(weekStart(now).AddDate(0, 0, 1)) {
+		if next, ok := isoWeekDate(year+1, week, 1, r.location); ok {
+			return next, newMatch(AmbiguousYear), true
+		}
+	}
+	return date, newMatch(), true
+}
+
+func resolveYearMonthDay(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	year, _ := strconv.Atoi(g[1])
+	month, _ := strconv.Atoi(g[2])
+	day, _ := strconv.Atoi(g[3])
+	date, ok := makeDate(year, month, day, r.location)
+	return date, newMatch(), ok
+}
+
+func resolveNumericDate(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	first, _ := strconv.Atoi(g[1])
+	second, _ := strconv.Atoi(g[2])
+	year, _ := strconv.Atoi(g[3])
+
+	// 默认按月/日解析，首位大于 12 时只能是日/月
+	if first > 12 {
+		date, ok := makeDate(year, second, first, r.location)
+		return date, newMatch(), ok
+	}
+	date, ok := makeDate(year, first, second, r.location)
+	if !ok {
+		return time.Time{}, nil, false
+	}
+	if second <= 12 && first != second {
+		return date, newMatch(AmbiguousDayMonth), true
+	}
+	return date, newMatch(), true
+}
+
+// resolveMonthDayInYear 省略年份的日期，已过的日期滚动到下一年
+func (r *Resolver) resolveMonthDayInYear(month, day int, now time.Time) (time.Time, *match, bool) {
+	date, ok := makeDate(now.Year(), month, day, r.location)
+	if !ok {
+		// 2 月 29 日等只在部分年份存在的日期
+		date, ok = makeDate(now.Year()+1, month, day, r.location)
+		return date, newMatch(AmbiguousYear), ok
+	}
+	if date.Before(startOfDay(now)) {
+		if next, ok := makeDate(now.Year()+1, month, day, r.location); ok {
+			return next, newMatch(AmbiguousYear), true
+		}
+	}
+	return date, newMatch(), true
+}
+
+func resolveMonthDay(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	month, ok1 := parseNumber(g[1])
+	day, ok2 := parseNumber(g[2])
+	if !ok1 || !ok2 {
+		return time.Time{}, nil, false
+	}
+	return r.resolveMonthDayInYear(month, day, now)
+}
+
+var englishMonths = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+func resolveEnglishMonthDay(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	month := englishMonths[strings.ToLower(g[1])[:3]]
+	day, _ := strconv.Atoi(g[2])
+	if g[3] != "" {
+		year, _ := strconv.Atoi(g[3])
+		date, ok := makeDate(year, month, day, r.location)
+		return date, newMatch(), ok
+	}
+	return r.resolveMonthDayInYear(month, day, now)
+}
+
+// durationUnit 将时长单位换算为分钟数或天数
+func durationUnit(unit string) (minutes int, days int) {
+	unit = strings.ToLower(unit)
+	switch {
+	case unit == "分钟" || strings.HasPrefix(unit, "min"):
+		return 1, 0
+	case unit == "小时" || unit == "钟头" || strings.HasPrefix(unit, "h"):
+		return 60, 0
+	case unit == "天" || strings.HasPrefix(unit, "day"):
+		return 0, 1
+	default: // 周、星期、礼拜、week
+		return 0, 7
+	}
+}
+
+// applyDuration 将数量和单位应用到参考时间；分钟和小时得到精确时间，天和周只确定日期
+func applyDuration(now time.Time, halves int, unit string) (time.Time, *match, bool) {
+	minutes, days := durationUnit(unit)
+	if minutes > 0 {
+		m := newMatch()
+		m.exact = true
+		return now.Add(time.Duration(halves*minutes*30) * time.Second).Truncate(time.Minute), m, true
+	}
+	if halves%2 != 0 {
+		return time.Time{}, nil, false
+	}
+	return startOfDay(now).AddDate(0, 0, halves/2*days), newMatch(), true
+}
+
+func resolveChineseDuration(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	if g[1] == "半" {
+		return applyDuration(now, 1, g[2])
+	}
+	n, ok := parseNumber(g[1])
+	if !ok {
+		return time.Time{}, nil, false
+	}
+	return applyDuration(now, n*2, g[2])
+}
+
+func resolveEnglishDuration(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	var halves int
+	switch strings.ToLower(g[1]) {
+	case "a", "an", "one":
+		halves = 2
+	case "two":
+		halves = 4
+	case "three":
+		halves = 6
+	case "half a", "half an":
+		halves = 1
+	default:
+		n, _ := strconv.Atoi(g[1])
+		halves = n * 2
+	}
+	return applyDuration(now, halves, g[2])
+}
+
+func resolveDaysAgo(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	n, ok := parseNumber(g[1])
+	if !ok {
+		return time.Time{}, nil, false
+	}
+	return startOfDay(now).AddDate(0, 0, -n), newMatch(), true
+}
+
+func resolveEndOfWeek(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	m := newMatch()
+	m.defaultMinutes = r.endOfDayMin
+	friday := weekStart(now).AddDate(0, 0, 4)
+	if friday.Before(startOfDay(now)) {
+		friday = friday.AddDate(0, 0, 7)
+	}
+	return friday, m, true
+}
+
+// monthBoundary 返回 offset 个月后的月初或月底
+func monthBoundary(now time.Time, offset int, end bool) time.Time {
+	first := time.Date(now.Year(), now.Month()+time.Month(offset), 1, 0, 0, 0, 0, now.Location())
+	if end {
+		return first.AddDate(0, 1, -1)
+	}
+	return first
+}
+
+func resolveMonthBoundary(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	offset := 0
+	if strings.HasPrefix(g[1], "下") {
+		offset = 1
+	}
+	if g[2] != "初" {
+		return monthBoundary(now, offset, true), newMatch(), true
+	}
+
+	// 单独的"月初"在月中说出时指下个月初
+	if g[1] == "月" && now.Day() > 1 {
+		offset = 1
+	}
+	date := monthBoundary(now, offset, false)
+	if date.Before(startOfDay(now)) {
+		return date, newMatch(AmbiguousPast), true
+	}
+	return date, newMatch(), true
+}
+
+func resolveEnglishMonthEnd(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	offset := 0
+	if g[1] != "" {
+		offset = 1
+	}
+	return monthBoundary(now, offset, true), newMatch(), true
+}
+
+func resolveYearEnd(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	year := now.Year()
+	if g[1] == "明年" {
+		year++
+	}
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, r.location), newMatch(), true
+}
+
+func resolveWeekend(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	saturday := weekStart(now).AddDate(0, 0, 5)
+	switch g[1] {
+	case "下":
+		return saturday.AddDate(0, 0, 7), newMatch(), true
+	case "本", "这":
+		if saturday.Before(startOfDay(now)) {
+			return saturday, newMatch(AmbiguousPast), true
+		}
+		return saturday, newMatch(), true
+	}
+	// 周日说"周末"指今天
+	if saturday.Before(startOfDay(now)) {
+		return startOfDay(now), newMatch(), true
+	}
+	return saturday, newMatch(), true
+}
+
+var chineseWeekdays = map[string]int{"一": 1, "二": 2, "三": 3, "四": 4, "五": 5, "六": 6, "日": 7, "天": 7}
+
+// weekdayInWeek 返回相对本周偏移 weeks 周的第 weekday 天
+func weekdayInWeek(now time.Time, weeks, weekday int) time.Time {
+	return weekStart(now).AddDate(0, 0, weeks*7+weekday-1)
+}
+
+// upcomingWeekday 返回从今天起（含今天）最近的第 weekday 天；今天恰好是该天时有歧义
+func upcomingWeekday(now time.Time, weekday int) (time.Time, *match) {
+	delta := (weekday - isoWeekday(now) + 7) % 7
+	if delta == 0 {
+		return startOfDay(now), newMatch(AmbiguousWeekday)
+	}
+	return startOfDay(now).AddDate(0, 0, delta), newMatch()
+}
+
+// thisWeekday 本周的第 weekday 天，已过去时带有歧义标记
+func thisWeekday(now time.Time, weekday int) (time.Time, *match) {
+	date := weekdayInWeek(now, 0, weekday)
+	if date.Before(startOfDay(now)) {
+		return date, newMatch(AmbiguousPast)
+	}
+	return date, newMatch()
+}
+
+func resolveChineseWeekday(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	weekday := chineseWeekdays[g[2]]
+	switch g[1] {
+	case "下下":
+		return weekdayInWeek(now, 2, weekday), newMatch(), true
+	case "下":
+		return weekdayInWeek(now, 1, weekday), newMatch(), true
+	case "上":
+		return weekdayInWeek(now, -1, weekday), newMatch(), true
+	case "本", "这":
+		date, m := thisWeekday(now, weekday)
+		return date, m, true
+	}
+	date, m := upcomingWeekday(now, weekday)
+	return date, m, true
+}
+
+var englishWeekdays = map[string]int{
+	"monday": 1, "tuesday": 2, "wednesday": 3, "thursday": 4,
+	"friday": 5, "saturday": 6, "sunday": 7,
+}
+
+func resolveEnglishWeekday(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	weekday := englishWeekdays[strings.ToLower(g[2])]
+	switch strings.ToLower(g[1]) {
+	case "next":
+		// "next Monday" 可以指下周一，也可以指即将到来的周一；两者不同时标记歧义
+		date := weekdayInWeek(now, 1, weekday)
+		if weekday > isoWeekday(now) {
+			return date, newMatch(AmbiguousWeekday), true
+		}
+		return date, newMatch(), true
+	case "last":
+		return weekdayInWeek(now, -1, weekday), newMatch(), true
+	case "this":
+		date, m := thisWeekday(now, weekday)
+		return date, m, true
+	}
+	// 无修饰或 coming: 最近的该天
+	date, m := upcomingWeekday(now, weekday)
+	return date, m, true
+}
+
+var chineseRelativeDays = map[string]int{
+	"大前天": -3, "前天": -2, "昨天": -1,
+	"今天": 0, "今日": 0, "今晚": 0,
+	"明天": 1, "明日": 1, "后天": 2, "大后天": 3,
+}
+
+func resolveChineseRelativeDay(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	return startOfDay(now).AddDate(0, 0, chineseRelativeDays[g[0]]), newMatch(), true
+}
+
+func resolveEnglishRelativeDay(r *Resolver, g []string, now time.Time) (time.Time, *match, bool) {
+	days := 0
+	switch strings.ToLower(g[1]) {
+	case "tomorrow":
+		days = 1
+	case "yesterday":
+		days = -1
+	}
+	return startOfDay(now).AddDate(0, 0, days), newMatch(), true
+}
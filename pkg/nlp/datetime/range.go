@@ -0,0 +1,98 @@
+package datetime
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// rangeSeparator 范围分隔符：-、~、到、至 等
+const rangeSeparator = `\s*(?:-|~|～|–|—|到|至)\s*`
+
+var (
+	// 12月3日-12月5日, 12月3日至5日, 2025-12-03 ~ 2025-12-05
+	dateRangeRegex = regexp.MustCompile(
+		`(\d{4}\s*[-/.年]\s*\d{1,2}\s*[-/.月]\s*\d{1,2}\s*[日号]?|(` + cnNumber + `)\s*月\s*(?:` + cnNumber + `)\s*[日号])` +
+			rangeSeparator +
+			`(\d{4}\s*[-/.年]\s*\d{1,2}\s*[-/.月]\s*\d{1,2}\s*[日号]?|(?:` + cnNumber + `)\s*月\s*(?:` + cnNumber + `)\s*[日号]|(?:` + cnNumber + `)\s*[日号])`)
+	// 14:30 - 16:30, 下午2:00到4:00
+	clockRangeRegex = regexp.MustCompile(`(` + periodWords + `)?\s*(\d{1,2}[:：]\d{2})` + rangeSeparator + `(` + periodWords + `)?\s*(\d{1,2}[:：]\d{2})`)
+)
+
+// Range 时间范围解析结果
+type Range struct {
+	Start Result // 开始时间
+	End   Result // 结束时间；End.HasDate 表示跨天范围，End.HasTime 表示给出了结束时刻
+}
+
+// ResolveRange 在文本中查找日期范围（12月3日-12月5日）或时刻范围（14:30 - 16:30），
+// 两者都没有时返回 false。只有时刻范围且结束早于开始时视为跨越午夜。
+func (r *Resolver) ResolveRange(text string) (Range, bool) {
+	var rng Range
+	var hasDateRange, hasClockRange bool
+	var endDateText, startClock, endClock string
+
+	if g := dateRangeRegex.FindStringSubmatch(text); g != nil {
+		endDateText = g[3]
+		// "12月3日至5日" 的结束日期沿用开始日期的月份
+		if g[2] != "" && !strings.ContainsAny(endDateText, "月年-/.") {
+			endDateText = g[2] + "月" + endDateText
+		}
+		hasDateRange = true
+		text = strings.Replace(text, g[0], g[1], 1)
+	}
+
+	if g := clockRangeRegex.FindStringSubmatch(text); g != nil {
+		endPeriod := g[3]
+		if endPeriod == "" {
+			endPeriod = g[1]
+		}
+		startClock = g[1] + g[2]
+		endClock = endPeriod + g[4]
+		hasClockRange = true
+		text = strings.Replace(text, g[0], startClock, 1)
+	}
+
+	if !hasDateRange && !hasClockRange {
+		return Range{}, false
+	}
+
+	start, ok := r.Resolve(text)
+	if !ok {
+		return Range{}, false
+	}
+	rng.Start = start
+	rng.End = Result{Time: start.Time}
+
+	endDate := start.Time
+	if hasDateRange {
+		end, ok := r.Resolve(endDateText)
+		if !ok || !end.HasDate {
+			return Range{}, false
+		}
+		endDate = end.Time
+		// 省略年份的结束日期早于开始日期时（12月30日-1月2日）顺延一年
+		for endDate.Before(startOfDay(start.Time)) {
+			endDate = endDate.AddDate(1, 0, 0)
+		}
+		rng.End.HasDate = true
+		rng.End.Ambiguities = append(rng.End.Ambiguities, end.Ambiguities...)
+	}
+
+	endMinutes := start.Time.Hour()*60 + start.Time.Minute()
+	if hasClockRange {
+		minutes, m := r.resolveClock(endClock)
+		if m == nil {
+			return Range{}, false
+		}
+		endMinutes = minutes
+		rng.End.HasTime = true
+		rng.End.Ambiguities = append(rng.End.Ambiguities, m.ambiguities...)
+	}
+
+	rng.End.Time = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), endMinutes/60, endMinutes%60, 0, 0, r.location)
+	if !hasDateRange && rng.End.Time.Before(start.Time) {
+		rng.End.Time = rng.End.Time.AddDate(0, 0, 1)
+	}
+	return rng, true
+}
@@ -0,0 +1,191 @@
+// Package datetime 将中英文自然语言中的日期时间表达式解析为具体时间。
+// 所有相对表达式（明天、下周三、in 2 hours 等）都以可注入的参考时间和时区为基准，
+// 无法唯一确定的表达式会在结果中带上歧义标记。
+package datetime
+
+import (
+	"fmt"
+	"time"
+)
+
+// Ambiguity 歧义类型
+type Ambiguity string
+
+const (
+	// AmbiguousMeridiem 没有上午/下午或 AM/PM 标记的 12 小时制时间
+	AmbiguousMeridiem Ambiguity = "meridiem"
+	// AmbiguousYear 省略年份的日期已过，按下一年解析
+	AmbiguousYear Ambiguity = "year"
+	// AmbiguousWeekday 星期表达式可以指本周或下周
+	AmbiguousWeekday Ambiguity = "weekday"
+	// AmbiguousDayMonth 数字日期可以按月/日或日/月解析
+	AmbiguousDayMonth Ambiguity = "day_month"
+	// AmbiguousPast 解析结果早于参考日期
+	AmbiguousPast Ambiguity = "past"
+)
+
+// Options 解析器选项
+type Options struct {
+	Now      func() time.Time // 参考时间，默认 time.Now
+	Location *time.Location   // 解析使用的时区，默认本地时区
+	EndOfDay string           // EOD / 下班前 对应的时间 (HH:MM)
+}
+
+// DefaultOptions 返回默认解析器选项
+func DefaultOptions() *Options {
+	return &Options{
+		Now:      time.Now,
+		Location: time.Local,
+		EndOfDay: "17:00",
+	}
+}
+
+// Resolver 自然语言日期时间解析器
+type Resolver struct {
+	now         func() time.Time
+	location    *time.Location
+	endOfDayMin int // EOD 距零点的分钟数
+}
+
+// NewResolver 创建解析器，options 为 nil 或字段为空时使用默认值
+func NewResolver(options *Options) *Resolver {
+	defaults := DefaultOptions()
+	if options == nil {
+		options = defaults
+	}
+
+	r := &Resolver{
+		now:      options.Now,
+		location: options.Location,
+	}
+	if r.now == nil {
+		r.now = defaults.Now
+	}
+	if r.location == nil {
+		r.location = defaults.Location
+	}
+
+	endOfDay := options.EndOfDay
+	if endOfDay == "" {
+		endOfDay = defaults.EndOfDay
+	}
+	t, err := time.Parse("15:04", endOfDay)
+	if err != nil {
+		t, _ = time.Parse("15:04", defaults.EndOfDay)
+	}
+	r.endOfDayMin = t.Hour()*60 + t.Minute()
+
+	return r
+}
+
+// Result 解析结果
+type Result struct {
+	Time        time.Time   // 解析得到的时间，位于解析器时区；只有日期时为当天零点
+	HasDate     bool        // 文本中是否包含日期
+	HasTime     bool        // 文本中是否包含时刻
+	Ambiguities []Ambiguity // 歧义标记
+}
+
+// Date 返回 YYYY-MM-DD 格式的日期
+func (r Result) Date() string {
+	return r.Time.Format("2006-01-02")
+}
+
+// Clock 返回 HH:MM 格式的时刻
+func (r Result) Clock() string {
+	return r.Time.Format("15:04")
+}
+
+// Ambiguous 是否存在歧义
+func (r Result) Ambiguous() bool {
+	return len(r.Ambiguities) > 0
+}
+
+// Has 是否包含指定歧义
+func (r Result) Has(ambiguity Ambiguity) bool {
+	for _, a := range r.Ambiguities {
+		if a == ambiguity {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回便于日志输出的描述
+func (r Result) String() string {
+	s := r.Time.Format("2006-01-02 15:04")
+	switch {
+	case r.HasDate && !r.HasTime:
+		s = r.Date()
+	case !r.HasDate && r.HasTime:
+		s = r.Clock()
+	}
+	if r.Ambiguous() {
+		s += fmt.Sprintf(" %v", r.Ambiguities)
+	}
+	return s
+}
+
+// Now 返回解析器时区下的参考时间
+func (r *Resolver) Now() time.Time {
+	return r.now().In(r.location)
+}
+
+// Location 返回解析器时区
+func (r *Resolver) Location() *time.Location {
+	return r.location
+}
+
+// Resolve 在文本中查找日期和时刻表达式并解析，未找到任何表达式时返回 false。
+// 只有时刻时日期取参考日期，只有日期时时刻为零点。
+func (r *Resolver) Resolve(text string) (Result, bool) {
+	now := r.Now()
+	today := startOfDay(now)
+
+	var result Result
+	date, dateMatch := r.resolveDate(text, now)
+	if dateMatch != nil {
+		result.HasDate = true
+		result.Ambiguities = append(result.Ambiguities, dateMatch.ambiguities...)
+	}
+
+	// "in 2 hours" 等相对时长同时确定日期和时刻
+	if dateMatch != nil && dateMatch.exact {
+		result.Time = date
+		result.HasTime = true
+		return result, true
+	}
+
+	clock, timeMatch := r.resolveClock(text)
+	if timeMatch != nil {
+		result.HasTime = true
+		result.Ambiguities = append(result.Ambiguities, timeMatch.ambiguities...)
+	} else if dateMatch != nil && dateMatch.defaultMinutes >= 0 {
+		// EOD 等表达式自带默认时刻，明确的时刻优先
+		clock = dateMatch.defaultMinutes
+		result.HasTime = true
+	}
+
+	if !result.HasDate && !result.HasTime {
+		return Result{}, false
+	}
+
+	base := today
+	if result.HasDate {
+		base = date
+	}
+	result.Time = time.Date(base.Year(), base.Month(), base.Day(), clock/60, clock%60, 0, 0, r.location)
+	return result, true
+}
+
+// match 单条规则的匹配结果
+type match struct {
+	ambiguities    []Ambiguity
+	exact          bool // 结果已包含精确时刻（相对时长）
+	defaultMinutes int  // 未给出时刻时使用的默认时刻，-1 表示没有
+}
+
+// startOfDay 返回当天零点
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,183 @@
+package datetime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestResolver 参考时间为 2025-03-12（周三）10:00 上海时间
+func newTestResolver(t *testing.T) *Resolver {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	return NewResolver(&Options{
+		Now:      func() time.Time { return time.Date(2025, 3, 12, 10, 0, 0, 0, loc) },
+		Location: loc,
+	})
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver := newTestResolver(t)
+
+	tests := []struct {
+		text        string
+		date        string // 期望日期，空表示文本中没有日期
+		clock       string // 期望时刻，空表示文本中没有时刻
+		ambiguities []Ambiguity
+	}{
+		// 中文相对日期
+		{"明天交报告", "2025-03-13", "", nil},
+		{"大后天", "2025-03-15", "", nil},
+		{"下周三下午3点开会", "2025-03-19", "15:00", nil},
+		{"本周五之前提交", "2025-03-14", "", nil},
+		{"本周一的周报", "2025-03-10", "", []Ambiguity{AmbiguousPast}},
+		{"下下周一", "2025-03-24", "", nil},
+		{"周三", "2025-03-12", "", []Ambiguity{AmbiguousWeekday}},
+		{"星期天", "2025-03-16", "", nil},
+		{"周末去爬山", "2025-03-15", "", nil},
+		{"月底前报销", "2025-03-31", "", nil},
+		{"下个月底", "2025-04-30", "", nil},
+		{"下月初", "2025-04-01", "", nil},
+		{"年底", "2025-12-31", "", nil},
+		{"3天后", "2025-03-15", "", nil},
+		{"两周后", "2025-03-26", "", nil},
+
+		// 省略年份的日期
+		{"3月20日", "2025-03-20", "", nil},
+		{"3月5日", "2026-03-05", "", []Ambiguity{AmbiguousYear}},
+		{"十二月三号", "2025-12-03", "", nil},
+		{"2025年3月22日 下午2点半", "2025-03-22", "14:30", nil},
+
+		// 英文
+		{"next Monday", "2025-03-17", "", nil},
+		{"next Friday", "2025-03-21", "", []Ambiguity{AmbiguousWeekday}},
+		{"this Friday", "2025-03-14", "", nil},
+		{"tomorrow at 9:30am", "2025-03-13", "09:30", nil},
+		{"March 20", "2025-03-20", "", nil},
+		{"Due Mar 3rd, 2026", "2026-03-03", "", nil},
+		{"4/5/2025", "2025-04-05", "", []Ambiguity{AmbiguousDayMonth}},
+		{"15/04/2025", "2025-04-15", "", nil},
+
+		// ISO 周
+		{"2025-W12", "2025-03-17", "", nil},
+		{"2025W12-3", "2025-03-19", "", nil},
+		{"第12周", "2025-03-17", "", nil},
+		{"第2周", "2026-01-05", "", []Ambiguity{AmbiguousYear}},
+
+		// 时刻
+		{"3点开会", "", "15:00", []Ambiguity{AmbiguousMeridiem}},
+		{"9:00", "", "09:00", []Ambiguity{AmbiguousMeridiem}},
+		{"09:00", "", "09:00", nil},
+		{"晚上8点", "", "20:00", nil},
+		{"中午", "", "12:00", nil},
+		{"十点一刻", "", "10:15", []Ambiguity{AmbiguousMeridiem}},
+		{"4:30 PM", "", "16:30", nil},
+		{"12am", "", "00:00", nil},
+
+		// EOD 与相对时长
+		{"EOD", "", "17:00", nil},
+		{"Friday EOD", "2025-03-14", "17:00", nil},
+		{"EOW", "2025-03-14", "17:00", nil},
+		{"in 2 hours", "2025-03-12", "12:00", nil},
+		{"半小时后提醒我", "2025-03-12", "10:30", nil},
+		{"in 3 days", "2025-03-15", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result, ok := resolver.Resolve(tt.text)
+			require.True(t, ok)
+
+			assert.Equal(t, tt.date != "", result.HasDate, "HasDate")
+			assert.Equal(t, tt.clock != "", result.HasTime, "HasTime")
+			if tt.date != "" {
+				assert.Equal(t, tt.date, result.Date())
+			} else {
+				assert.Equal(t, "2025-03-12", result.Date())
+			}
+			if tt.clock != "" {
+				assert.Equal(t, tt.clock, result.Clock())
+			}
+			assert.ElementsMatch(t, tt.ambiguities, result.Ambiguities)
+		})
+	}
+}
+
+func TestResolver_NoMatch(t *testing.T) {
+	resolver := newTestResolver(t)
+
+	for _, text := range []string{"", "整理桌面文件", "快一点完成", "周会纪要", "Mark 5 items as done"} {
+		_, ok := resolver.Resolve(text)
+		assert.False(t, ok, text)
+	}
+}
+
+func TestResolver_Location(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// UTC 3 月 12 日凌晨 2 点，纽约仍是 3 月 11 日晚上
+	resolver := NewResolver(&Options{
+		Now:      func() time.Time { return time.Date(2025, 3, 12, 2, 0, 0, 0, time.UTC) },
+		Location: newYork,
+	})
+
+	result, ok := resolver.Resolve("明天上午9点")
+	require.True(t, ok)
+	assert.Equal(t, "2025-03-12", result.Date())
+	assert.Equal(t, "09:00", result.Clock())
+	assert.Equal(t, newYork, result.Time.Location())
+}
+
+func TestResolver_EndOfDayOption(t *testing.T) {
+	resolver := NewResolver(&Options{
+		Now:      func() time.Time { return time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC) },
+		Location: time.UTC,
+		EndOfDay: "18:30",
+	})
+
+	result, ok := resolver.Resolve("下班前")
+	require.True(t, ok)
+	assert.Equal(t, "18:30", result.Clock())
+
+	// 明确的时刻优先于 EOD
+	result, ok = resolver.Resolve("EOD 16:00")
+	require.True(t, ok)
+	assert.Equal(t, "16:00", result.Clock())
+}
+
+func TestResolver_ResolveRange(t *testing.T) {
+	resolver := newTestResolver(t)
+
+	tests := []struct {
+		text  string
+		start string
+		end   string
+		days  bool // 是否为跨天范围
+	}{
+		{"14:30 - 16:30", "2025-03-12 14:30", "2025-03-12 16:30", false},
+		{"明天下午2:00到4:00", "2025-03-13 14:00", "2025-03-13 16:00", false},
+		{"22:00-01:00 值班", "2025-03-12 22:00", "2025-03-13 01:00", false},
+		{"12月3日-12月5日", "2025-12-03 00:00", "2025-12-05 00:00", true},
+		{"12月3日至5日 09:00-17:00", "2025-12-03 09:00", "2025-12-05 17:00", true},
+		{"12月30日~1月2日", "2025-12-30 00:00", "2026-01-02 00:00", true},
+		{"2025-04-01 ~ 2025-04-03", "2025-04-01 00:00", "2025-04-03 00:00", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			rng, ok := resolver.ResolveRange(tt.text)
+			require.True(t, ok)
+			assert.Equal(t, tt.start, rng.Start.Time.Format("2006-01-02 15:04"))
+			assert.Equal(t, tt.end, rng.End.Time.Format("2006-01-02 15:04"))
+			assert.Equal(t, tt.days, rng.End.HasDate)
+		})
+	}
+
+	for _, text := range []string{"明天下午3点", "2025-03-22", "14:30"} {
+		_, ok := resolver.ResolveRange(text)
+		assert.False(t, ok, text)
+	}
+}
@@ -36,6 +36,11 @@ func (cs *CacheServiceImpl) GetManager() *cache.UnifiedCacheManager {
 	return cs.cacheManager
 }
 
+// GetQuotaManager 获取缓存配额管理器，此实现不执行配额
+func (cs *CacheServiceImpl) GetQuotaManager() *cache.QuotaManager {
+	return nil
+}
+
 // GetCacheDir 获取缓存目录
 func (cs *CacheServiceImpl) GetCacheDir() string {
 	if cs.cacheManager != nil {
@@ -81,6 +81,24 @@ func (cs *ClipboardServiceImpl) HasContent() (bool, error) {
 	return cs.manager.HasContent()
 }
 
+// ReloadImageConfig 重新创建剪贴板管理器，使修改后的 image_processing.json 生效
+// 尚未初始化时不做处理，首次读取时会加载最新配置；失败时继续使用原来的管理器
+func (cs *ClipboardServiceImpl) ReloadImageConfig() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if !cs.initialized {
+		return nil
+	}
+
+	manager, err := clipboard.NewManager()
+	if err != nil {
+		return fmt.Errorf("重新创建剪贴板管理器失败: %w", err)
+	}
+	cs.manager = manager
+	return nil
+}
+
 // GetContentType 获取剪贴板内容类型
 func (cs *ClipboardServiceImpl) GetContentType() (string, error) {
 	cs.mu.RLock()
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/allanpk716/to_icalendar/pkg/models"
 	"github.com/allanpk716/to_icalendar/pkg/cache"
@@ -21,6 +22,7 @@ type ConfigService interface {
 type CacheService interface {
 	Initialize() error
 	GetManager() *cache.UnifiedCacheManager
+	GetQuotaManager() *cache.QuotaManager
 	GetCacheDir() string
 	Cleanup() error
 }
@@ -38,12 +40,17 @@ type ClipboardService interface {
 	HasContent() (bool, error)
 	GetContentType() (string, error)
 	ProcessContent(ctx context.Context, content *models.ClipboardContent) (*models.ProcessingResult, error)
+	// ReloadImageConfig 重新加载 image_processing.json，之后读取的图片按新配置处理
+	ReloadImageConfig() error
 }
 
 // TodoService Microsoft Todo 服务接口
 type TodoService interface {
 	CreateTask(ctx context.Context, reminder *models.Reminder) error
+	CreateTaskWithAttachment(ctx context.Context, reminder *models.Reminder, screenshot []byte) (*TaskCreationResult, error)
+	CreateTasks(ctx context.Context, reminders []*models.Reminder) (*BatchCreateResult, error)
 	TestConnection() error
+	TestCalendarAccess(ctx context.Context) (string, error)
 	GetServerInfo() (map[string]interface{}, error)
 	GetClient() *microsofttodo.SimpleTodoClient
 }
@@ -101,6 +108,40 @@ type CleanupStats struct {
 }
 
 
+// TaskCreationResult 任务创建结果
+type TaskCreationResult struct {
+	Kind         string   `json:"kind,omitempty"` // task/event/both
+	TaskID       string   `json:"task_id"`
+	ListID       string   `json:"list_id"`
+	ListName     string   `json:"list_name"`
+	AttachmentID string   `json:"attachment_id,omitempty"`
+	EventID      string   `json:"event_id,omitempty"`   // Outlook 日历事件ID
+	EventLink    string   `json:"event_link,omitempty"` // 日历事件网页链接
+	Duplicate    bool     `json:"duplicate,omitempty"`  // 同步缓存中已有相同任务，TaskID 为已有任务，未重复创建
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// BatchCreateItem 批量创建中单个提醒的结果
+type BatchCreateItem struct {
+	Index    int      `json:"index"`
+	Title    string   `json:"title"`
+	ListName string   `json:"list_name,omitempty"`
+	ListID   string   `json:"list_id,omitempty"`
+	TaskID   string   `json:"task_id,omitempty"`
+	EventID  string   `json:"event_id,omitempty"`
+	Attempts int      `json:"attempts"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// BatchCreateResult 批量创建结果
+type BatchCreateResult struct {
+	Items        []*BatchCreateItem `json:"items"`
+	SuccessCount int                `json:"success_count"`
+	FailedCount  int                `json:"failed_count"`
+	Duration     time.Duration      `json:"duration"`
+}
+
 // ProcessClipboardResult 剪贴板处理结果
 type ProcessClipboardResult struct {
 	Success      bool                     `json:"success"`
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/allanpk716/to_icalendar/pkg/logger"
 	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
@@ -17,20 +18,26 @@ type TokenRefresherService interface {
 	Stop() error
 	// GetStatus 获取刷新器状态
 	GetStatus() map[string]interface{}
+	// GetTokenStatus 获取 Token 状态，Token 管理器未初始化时返回 nil
+	GetTokenStatus() *microsofttodo.TokenStatus
 	// RefreshTokenNow 立即刷新 Token
 	RefreshTokenNow(ctx context.Context) error
 	// IsEnabled 检查是否启用
 	IsEnabled() bool
 	// SetReauthCallback 设置重新认证回调
 	SetReauthCallback(callback func(error))
+	// Reload 按新配置重建 Token 管理器，保留回调，已启动时使用新的检查间隔重新启动
+	Reload(config *models.ServerConfig) error
 }
 
 // tokenRefresherServiceImpl Token 刷新服务实现
 type tokenRefresherServiceImpl struct {
+	mu           sync.Mutex
 	tokenManager *microsofttodo.TokenManager
 	logger       logger.Logger
 	ctx          context.Context
 	cancel       context.CancelFunc
+	running      bool
 
 	// 回调函数
 	reauthCallback func(error)
@@ -43,6 +50,21 @@ func NewTokenRefresherService(
 ) TokenRefresherService {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	tokenManager, err := newTokenManager(config, logger)
+	if err != nil {
+		logger.Errorf("创建 Todo 客户端失败: %v", err)
+	}
+
+	return &tokenRefresherServiceImpl{
+		tokenManager: tokenManager,
+		logger:       logger,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// newTokenManager 按服务器配置创建 Token 管理器
+func newTokenManager(config *models.ServerConfig, logger logger.Logger) (*microsofttodo.TokenManager, error) {
 	// 创建 Token 管理器配置
 	var tokenManagerConfig *microsofttodo.TokenManagerConfig
 	if config.TokenManager != nil {
@@ -69,12 +91,7 @@ func NewTokenRefresherService(
 		config.MicrosoftTodo.UserEmail,
 	)
 	if err != nil {
-		logger.Errorf("创建 Todo 客户端失败: %v", err)
-		return &tokenRefresherServiceImpl{
-			logger: logger,
-			ctx:    ctx,
-			cancel: cancel,
-		}
+		return nil, err
 	}
 
 	// 创建 Token 管理器
@@ -85,16 +102,18 @@ func NewTokenRefresherService(
 		logger.Warnf("Token 刷新失败: %v", err)
 	})
 
-	return &tokenRefresherServiceImpl{
-		tokenManager: tokenManager,
-		logger:       logger,
-		ctx:          ctx,
-		cancel:       cancel,
-	}
+	return tokenManager, nil
 }
 
 // Start 启动服务
 func (s *tokenRefresherServiceImpl) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.start()
+}
+
+// start 启动 Token 管理器，调用方持有锁
+func (s *tokenRefresherServiceImpl) start() error {
 	if s.tokenManager == nil {
 		return fmt.Errorf("Token 管理器未初始化")
 	}
@@ -103,17 +122,26 @@ func (s *tokenRefresherServiceImpl) Start() error {
 		return fmt.Errorf("启动 Token 管理器失败: %w", err)
 	}
 
+	s.running = true
 	s.logger.Info("Token 刷新服务已启动")
 	return nil
 }
 
 // Stop 停止服务
 func (s *tokenRefresherServiceImpl) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stop()
+}
+
+// stop 停止 Token 管理器，调用方持有锁
+func (s *tokenRefresherServiceImpl) stop() error {
 	if s.tokenManager == nil {
 		return nil
 	}
 
 	s.cancel()
+	s.running = false
 
 	if err := s.tokenManager.Stop(); err != nil {
 		return fmt.Errorf("停止 Token 管理器失败: %w", err)
@@ -123,17 +151,48 @@ func (s *tokenRefresherServiceImpl) Stop() error {
 	return nil
 }
 
+// Reload 按新配置重建 Token 管理器
+// 新配置无法创建 Token 管理器时返回错误，继续使用原来的管理器
+func (s *tokenRefresherServiceImpl) Reload(config *models.ServerConfig) error {
+	tokenManager, err := newTokenManager(config, s.logger)
+	if err != nil {
+		return fmt.Errorf("创建 Token 管理器失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wasRunning := s.running
+	if wasRunning {
+		if err := s.stop(); err != nil {
+			s.logger.Warnf("停止旧的 Token 管理器失败: %v", err)
+		}
+	}
+
+	s.tokenManager = tokenManager
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if s.reauthCallback != nil {
+		s.setReauthCallback(s.reauthCallback)
+	}
+
+	if wasRunning {
+		return s.start()
+	}
+	return nil
+}
+
 // GetStatus 获取刷新器状态
 func (s *tokenRefresherServiceImpl) GetStatus() map[string]interface{} {
-	if s.tokenManager == nil {
+	tokenManager := s.manager()
+	if tokenManager == nil {
 		return map[string]interface{}{
 			"initialized": false,
 			"error":       "Token 管理器未初始化",
 		}
 	}
 
-	status := s.tokenManager.GetStatus()
-	config := s.tokenManager.GetConfig()
+	status := tokenManager.GetStatus()
+	config := tokenManager.GetConfig()
 
 	return map[string]interface{}{
 		"initialized": true,
@@ -148,26 +207,51 @@ func (s *tokenRefresherServiceImpl) GetStatus() map[string]interface{} {
 	}
 }
 
+// GetTokenStatus 获取 Token 状态
+func (s *tokenRefresherServiceImpl) GetTokenStatus() *microsofttodo.TokenStatus {
+	tokenManager := s.manager()
+	if tokenManager == nil {
+		return nil
+	}
+	return tokenManager.GetStatus()
+}
+
 // RefreshTokenNow 立即刷新 Token
 func (s *tokenRefresherServiceImpl) RefreshTokenNow(ctx context.Context) error {
-	if s.tokenManager == nil {
+	tokenManager := s.manager()
+	if tokenManager == nil {
 		return fmt.Errorf("Token 管理器未初始化")
 	}
 
-	return s.tokenManager.RefreshTokenNow(ctx)
+	return tokenManager.RefreshTokenNow(ctx)
 }
 
 // IsEnabled 检查是否启用
 func (s *tokenRefresherServiceImpl) IsEnabled() bool {
-	if s.tokenManager == nil {
+	tokenManager := s.manager()
+	if tokenManager == nil {
 		return false
 	}
 
-	return s.tokenManager.IsEnabled()
+	return tokenManager.IsEnabled()
+}
+
+// manager 返回当前的 Token 管理器，Reload 可能在其他 goroutine 中替换它
+func (s *tokenRefresherServiceImpl) manager() *microsofttodo.TokenManager {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokenManager
 }
 
 // SetReauthCallback 设置重新认证回调
 func (s *tokenRefresherServiceImpl) SetReauthCallback(callback func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setReauthCallback(callback)
+}
+
+// setReauthCallback 设置重新认证回调，调用方持有锁
+func (s *tokenRefresherServiceImpl) setReauthCallback(callback func(error)) {
 	s.reauthCallback = callback
 
 	if s.tokenManager != nil {
@@ -0,0 +1,493 @@
+package task
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveEntry 归档索引记录，记录任务被打包到哪个归档文件
+type ArchiveEntry struct {
+	TaskID     string    `json:"task_id"`
+	Archive    string    `json:"archive"` // 归档文件名，如 2025-01.zip
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time,omitempty"`
+	Size       int64     `json:"size"`
+	FileCount  int       `json:"file_count"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// lastActivity 任务最后活动时间，没有结束时间时使用开始时间
+func (e *ArchiveEntry) lastActivity() time.Time {
+	if e.EndTime.IsZero() {
+		return e.StartTime
+	}
+	return e.EndTime
+}
+
+// archiveName 任务所属的月度归档文件名
+func archiveName(startTime time.Time) string {
+	return startTime.Format("2006-01") + ".zip"
+}
+
+// IsArchived 任务是否已打包归档
+func (tm *TaskManager) IsArchived(taskID string) bool {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+	_, exists := tm.archiveIndex[taskID]
+	return exists
+}
+
+// ArchiveTaskDirs 将任务目录按开始时间所在月份打包到 cache/archive/YYYY-MM.zip，
+// 写入归档索引后删除原目录。返回归档的任务数量和释放的字节数。
+func (tm *TaskManager) ArchiveTaskDirs(sessions []*TaskSession) (int, int64, error) {
+	byArchive := make(map[string][]*TaskSession)
+	for _, session := range sessions {
+		name := archiveName(session.StartTime)
+		byArchive[name] = append(byArchive[name], session)
+	}
+
+	if err := os.MkdirAll(tm.archiveDir, 0755); err != nil {
+		return 0, 0, fmt.Errorf("创建归档目录失败: %w", err)
+	}
+
+	var archived int
+	var freed int64
+	names := make([]string, 0, len(byArchive))
+	for name := range byArchive {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		entries, err := tm.appendToArchive(name, byArchive[name])
+		if err != nil {
+			return archived, freed, fmt.Errorf("写入归档 %s 失败: %w", name, err)
+		}
+
+		tm.mutex.Lock()
+		for _, entry := range entries {
+			tm.archiveIndex[entry.TaskID] = entry
+		}
+		for _, index := range tm.index {
+			if _, ok := tm.archiveIndex[index.TaskID]; ok {
+				index.Archive = tm.archiveIndex[index.TaskID].Archive
+			}
+		}
+		err = tm.saveArchiveIndex()
+		if err == nil {
+			err = tm.saveIndex()
+		}
+		tm.mutex.Unlock()
+		if err != nil {
+			return archived, freed, err
+		}
+
+		// 归档索引保存成功后再删除原目录
+		for _, entry := range entries {
+			if err := os.RemoveAll(filepath.Join(tm.tasksDir, entry.TaskID)); err != nil {
+				tm.logf("删除已归档的任务目录失败: %s: %v", entry.TaskID, err)
+				continue
+			}
+			archived++
+			freed += entry.Size
+		}
+		tm.logf("已归档 %d 个任务到 %s", len(entries), name)
+	}
+
+	return archived, freed, nil
+}
+
+// appendToArchive 将任务目录追加到归档文件。zip 不支持原地追加，
+// 因此先复制已有内容到临时文件，再写入新任务，最后替换原文件。
+func (tm *TaskManager) appendToArchive(name string, sessions []*TaskSession) ([]*ArchiveEntry, error) {
+	archivePath := filepath.Join(tm.archiveDir, name)
+	tempPath := archivePath + ".tmp"
+
+	out, err := os.Create(tempPath)
+	if err != nil {
+		return nil, err
+	}
+	writer := zip.NewWriter(out)
+
+	fail := func(err error) ([]*ArchiveEntry, error) {
+		writer.Close()
+		out.Close()
+		os.Remove(tempPath)
+		return nil, err
+	}
+
+	adding := make(map[string]bool, len(sessions))
+	for _, session := range sessions {
+		adding[session.TaskID] = true
+	}
+
+	// 复制已有归档内容，重新归档的任务以新内容为准
+	if reader, err := zip.OpenReader(archivePath); err == nil {
+		for _, file := range reader.File {
+			taskID, _, _ := strings.Cut(file.Name, "/")
+			if adding[taskID] {
+				continue
+			}
+			if err := writer.Copy(file); err != nil {
+				reader.Close()
+				return fail(err)
+			}
+		}
+		reader.Close()
+	} else if !os.IsNotExist(err) {
+		return fail(err)
+	}
+
+	now := time.Now()
+	entries := make([]*ArchiveEntry, 0, len(sessions))
+	for _, session := range sessions {
+		entry := &ArchiveEntry{
+			TaskID:     session.TaskID,
+			Archive:    name,
+			StartTime:  session.StartTime,
+			EndTime:    session.EndTime,
+			ArchivedAt: now,
+		}
+		taskDir := filepath.Join(tm.tasksDir, session.TaskID)
+		err := filepath.WalkDir(taskDir, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(taskDir, filePath)
+			if err != nil {
+				return err
+			}
+			size, err := addFileToArchive(writer, filePath, path.Join(session.TaskID, filepath.ToSlash(rel)))
+			if err != nil {
+				return err
+			}
+			entry.Size += size
+			entry.FileCount++
+			return nil
+		})
+		if err != nil {
+			return fail(fmt.Errorf("打包任务 %s 失败: %w", session.TaskID, err))
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := writer.Close(); err != nil {
+		out.Close()
+		os.Remove(tempPath)
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tempPath)
+		return nil, err
+	}
+	if err := os.Rename(tempPath, archivePath); err != nil {
+		os.Remove(tempPath)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// addFileToArchive 以 Deflate 压缩写入单个文件，返回原始大小
+func addFileToArchive(writer *zip.Writer, filePath, name string) (int64, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return 0, err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	dst, err := writer.CreateHeader(header)
+	if err != nil {
+		return 0, err
+	}
+	src, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	return io.Copy(dst, src)
+}
+
+// readArchivedFile 从归档中读取任务文件
+func (tm *TaskManager) readArchivedFile(taskID, name string) ([]byte, error) {
+	tm.mutex.RLock()
+	entry, exists := tm.archiveIndex[taskID]
+	tm.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("任务 %s 未归档", taskID)
+	}
+
+	reader, err := zip.OpenReader(filepath.Join(tm.archiveDir, entry.Archive))
+	if err != nil {
+		return nil, fmt.Errorf("打开归档 %s 失败: %w", entry.Archive, err)
+	}
+	defer reader.Close()
+
+	file, err := reader.Open(path.Join(taskID, name))
+	if err != nil {
+		return nil, fmt.Errorf("归档 %s 中没有 %s/%s: %w", entry.Archive, taskID, name, err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// loadArchivedSession 从归档中加载任务会话
+func (tm *TaskManager) loadArchivedSession(taskID string) (*TaskSession, error) {
+	data, err := tm.readArchivedFile(taskID, "task_info.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var session TaskSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("解析归档的任务信息失败: %w", err)
+	}
+
+	tm.mutex.RLock()
+	session.Archive = tm.archiveIndex[taskID].Archive
+	tm.mutex.RUnlock()
+	return &session, nil
+}
+
+// PruneArchives 删除全部任务都早于 cutoff 的归档文件，并移除对应的归档索引。
+// 预览模式只统计不删除。返回删除的归档文件名、其中的任务数量和释放的字节数。
+func (tm *TaskManager) PruneArchives(cutoff time.Time, dryRun bool) ([]string, int, int64, error) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	// 按归档文件分组，找出每个归档中最新的任务
+	latest := make(map[string]time.Time)
+	counts := make(map[string]int)
+	for _, entry := range tm.archiveIndex {
+		if t := entry.lastActivity(); t.After(latest[entry.Archive]) {
+			latest[entry.Archive] = t
+		}
+		counts[entry.Archive]++
+	}
+
+	var removed []string
+	var tasks int
+	var freed int64
+	for name, newest := range latest {
+		if !newest.Before(cutoff) {
+			continue
+		}
+
+		archivePath := filepath.Join(tm.archiveDir, name)
+		if info, err := os.Stat(archivePath); err == nil {
+			freed += info.Size()
+		}
+		removed = append(removed, name)
+		tasks += counts[name]
+		if dryRun {
+			continue
+		}
+
+		if err := os.Remove(archivePath); err != nil && !os.IsNotExist(err) {
+			return removed, tasks, freed, fmt.Errorf("删除归档 %s 失败: %w", name, err)
+		}
+		for taskID, entry := range tm.archiveIndex {
+			if entry.Archive == name {
+				delete(tm.archiveIndex, taskID)
+			}
+		}
+		tm.logf("已删除归档: %s", name)
+	}
+	sort.Strings(removed)
+
+	if !dryRun && len(removed) > 0 {
+		if err := tm.saveArchiveIndex(); err != nil {
+			return removed, tasks, freed, err
+		}
+	}
+	return removed, tasks, freed, nil
+}
+
+// ArchiveStats 返回归档文件数量和总大小
+func (tm *TaskManager) ArchiveStats() (int, int64) {
+	entries, err := os.ReadDir(tm.archiveDir)
+	if err != nil {
+		return 0, 0
+	}
+
+	var count int
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".zip" {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			count++
+			size += info.Size()
+		}
+	}
+	return count, size
+}
+
+// loadArchiveIndex 加载归档索引。索引与归档文件保存在同一目录，
+// 旧版本保存在 cache/global 的索引会迁移过来；索引丢失时扫描归档文件重建
+func (tm *TaskManager) loadArchiveIndex() error {
+	data, err := os.ReadFile(tm.archiveIndexFile)
+	if os.IsNotExist(err) {
+		legacyFile := filepath.Join(tm.globalDir, filepath.Base(tm.archiveIndexFile))
+		if data, err = os.ReadFile(legacyFile); err == nil {
+			if err := tm.migrateArchiveIndex(data, legacyFile); err != nil {
+				tm.logf("迁移归档索引失败: %v", err)
+			}
+		} else if os.IsNotExist(err) {
+			return tm.rebuildArchiveIndex()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("读取归档索引失败: %w", err)
+	}
+
+	var entries []*ArchiveEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("解析归档索引失败: %w", err)
+	}
+	for _, entry := range entries {
+		tm.archiveIndex[entry.TaskID] = entry
+	}
+	return nil
+}
+
+// migrateArchiveIndex 将旧位置的归档索引写到归档目录并删除旧文件
+func (tm *TaskManager) migrateArchiveIndex(data []byte, legacyFile string) error {
+	if err := os.MkdirAll(tm.archiveDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(tm.archiveIndexFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Remove(legacyFile)
+}
+
+// rebuildArchiveIndex 扫描归档目录中的 zip 文件重建归档索引，没有归档时不写入文件
+func (tm *TaskManager) rebuildArchiveIndex() error {
+	names, err := filepath.Glob(filepath.Join(tm.archiveDir, "*.zip"))
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, archivePath := range names {
+		entries, err := scanArchive(archivePath)
+		if err != nil {
+			tm.logf("读取归档 %s 失败: %v", filepath.Base(archivePath), err)
+			continue
+		}
+		for _, entry := range entries {
+			tm.archiveIndex[entry.TaskID] = entry
+		}
+	}
+	if len(tm.archiveIndex) == 0 {
+		return nil
+	}
+
+	tm.logf("归档索引丢失，已从 %d 个归档重建 %d 条记录", len(names), len(tm.archiveIndex))
+	return tm.saveArchiveIndex()
+}
+
+// scanArchive 读取单个归档文件中的任务，开始和结束时间取自其中的 task_info.json
+func scanArchive(archivePath string) ([]*ArchiveEntry, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var archivedAt time.Time
+	if info, err := os.Stat(archivePath); err == nil {
+		archivedAt = info.ModTime()
+	}
+
+	name := filepath.Base(archivePath)
+	byTask := make(map[string]*ArchiveEntry)
+	modified := make(map[string]time.Time)
+	var taskIDs []string
+	for _, file := range reader.File {
+		taskID, fileName, ok := strings.Cut(file.Name, "/")
+		if !ok || taskID == "" {
+			continue
+		}
+		entry, exists := byTask[taskID]
+		if !exists {
+			entry = &ArchiveEntry{TaskID: taskID, Archive: name, ArchivedAt: archivedAt}
+			byTask[taskID] = entry
+			taskIDs = append(taskIDs, taskID)
+		}
+		entry.Size += int64(file.UncompressedSize64)
+		entry.FileCount++
+		if file.Modified.After(modified[taskID]) {
+			modified[taskID] = file.Modified
+		}
+
+		if fileName != "task_info.json" {
+			continue
+		}
+		var session TaskSession
+		if err := readZipJSON(file, &session); err == nil {
+			entry.StartTime = session.StartTime
+			entry.EndTime = session.EndTime
+		}
+	}
+
+	entries := make([]*ArchiveEntry, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		entry := byTask[taskID]
+		// 缺少任务信息时以文件修改时间估算，避免被当作过期归档删除
+		if entry.StartTime.IsZero() {
+			entry.StartTime = modified[taskID]
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readZipJSON 解析归档中的 JSON 文件
+func readZipJSON(file *zip.File, v interface{}) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+// saveArchiveIndex 保存归档索引（调用方需持有锁）
+func (tm *TaskManager) saveArchiveIndex() error {
+	entries := make([]*ArchiveEntry, 0, len(tm.archiveIndex))
+	for _, entry := range tm.archiveIndex {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].StartTime.After(entries[j].StartTime) })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化归档索引失败: %w", err)
+	}
+
+	tempFile := tm.archiveIndexFile + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("写入归档索引失败: %w", err)
+	}
+	if err := os.Rename(tempFile, tm.archiveIndexFile); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("重命名归档索引失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFinishedTask 创建一个已完成的任务，开始时间为 age 之前
+func createFinishedTask(t *testing.T, tm *TaskManager, age time.Duration, data string) *TaskSession {
+	session, err := tm.CreateTaskSession()
+	require.NoError(t, err)
+	require.NoError(t, tm.SaveFileToTask(session, FileTypeClipboardOriginal, []byte(data)))
+	tm.UpdateTaskStatus(session, TaskStatusSuccess)
+
+	session.StartTime = time.Now().Add(-age)
+	session.EndTime = session.StartTime.Add(time.Minute)
+	require.NoError(t, tm.saveTaskInfo(session))
+	require.NoError(t, tm.updateIndex(session))
+	tm.pendingSaves.Wait()
+	return session
+}
+
+func newArchiveTaskManager(t *testing.T, dir string) *TaskManager {
+	tm, err := NewTaskManager(dir, models.CacheConfig{CompressOldTasks: true, CompressAfterDays: 7}, nil)
+	require.NoError(t, err)
+	return tm
+}
+
+func TestArchiveOldTasks_ReadsTransparently(t *testing.T) {
+	dir := t.TempDir()
+	tm := newArchiveTaskManager(t, dir)
+	old := createFinishedTask(t, tm, 10*24*time.Hour, "old-png")
+	recent := createFinishedTask(t, tm, time.Hour, "recent-png")
+
+	cleaner := NewTaskCleaner(tm, nil)
+
+	// 预览模式不修改文件
+	preview, err := cleaner.ArchiveOldTasks(30*24*time.Hour, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, preview.TasksArchived)
+	assert.DirExists(t, old.TaskDir)
+
+	result, err := cleaner.ArchiveOldTasks(30*24*time.Hour, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.TasksArchived)
+	assert.Zero(t, result.ArchivesRemoved)
+	assert.NoDirExists(t, old.TaskDir)
+	assert.DirExists(t, recent.TaskDir)
+	assert.FileExists(t, filepath.Join(dir, "cache", "archive", archiveName(old.StartTime)))
+
+	// 重新创建任务管理器，从归档索引中读取
+	reloaded := newArchiveTaskManager(t, dir)
+	require.True(t, reloaded.IsArchived(old.TaskID))
+
+	loaded, err := reloaded.GetTaskSession(old.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, archiveName(old.StartTime), loaded.Archive)
+	data, err := reloaded.ReadTaskFile(loaded, FileTypeClipboardOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old-png"), data)
+
+	page := reloaded.QueryHistory(HistoryFilter{Keyword: old.TaskID}, 1, 0)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, loaded.Archive, page.Items[0].Archive)
+}
+
+func TestCleanupOlderThan_PrunesArchives(t *testing.T) {
+	dir := t.TempDir()
+	tm := newArchiveTaskManager(t, dir)
+	expired := createFinishedTask(t, tm, 60*24*time.Hour, "expired-png")
+	kept := createFinishedTask(t, tm, 10*24*time.Hour, "kept-png")
+
+	result, err := NewTaskCleaner(tm, nil).CleanupOlderThan(30)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.TasksArchived)
+	assert.Equal(t, 1, result.TasksCleaned)
+	assert.Equal(t, 1, result.ArchivesRemoved)
+
+	_, err = tm.GetTaskSession(expired.TaskID)
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(dir, "cache", "archive", archiveName(expired.StartTime)))
+	assert.True(t, os.IsNotExist(err))
+
+	// 未过期的任务仍在归档中，索引保留
+	loaded, err := tm.GetTaskSession(kept.TaskID)
+	require.NoError(t, err)
+	data, err := tm.ReadTaskFile(loaded, FileTypeClipboardOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("kept-png"), data)
+	assert.Equal(t, 1, tm.QueryHistory(HistoryFilter{}, 1, 0).Total)
+}
+
+func TestEvictTaskDir_UpdatesIndexes(t *testing.T) {
+	dir := t.TempDir()
+	tm, err := NewTaskManager(dir, models.CacheConfig{PreserveSuccessfulHashes: true}, nil)
+	require.NoError(t, err)
+	removed := createFinishedTask(t, tm, 2*time.Hour, "removed-png")
+	kept := createFinishedTask(t, tm, time.Hour, "kept-png")
+
+	result, err := NewTaskCleaner(tm, nil).EvictTaskDir(removed.TaskDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.TasksCleaned)
+	assert.Equal(t, 1, result.IndexEntriesRemoved)
+	assert.NoDirExists(t, removed.TaskDir)
+	assert.DirExists(t, kept.TaskDir)
+
+	// 重新加载后索引中只剩下保留的任务
+	reloaded, err := NewTaskManager(dir, models.CacheConfig{}, nil)
+	require.NoError(t, err)
+	indexes, err := reloaded.GetRecentTasks(0)
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	assert.Equal(t, kept.TaskID, indexes[0].TaskID)
+
+	// 开启 compress_old_tasks 时先打包归档，仍可读取任务文件
+	archiving := newArchiveTaskManager(t, dir)
+	result, err = NewTaskCleaner(archiving, nil).EvictTaskDir(kept.TaskDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.TasksArchived)
+	assert.NoDirExists(t, kept.TaskDir)
+	require.True(t, archiving.IsArchived(kept.TaskID))
+
+	loaded, err := archiving.GetTaskSession(kept.TaskID)
+	require.NoError(t, err)
+	data, err := archiving.ReadTaskFile(loaded, FileTypeClipboardOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("kept-png"), data)
+}
+
+func TestArchiveIndex_RebuiltWhenMissing(t *testing.T) {
+	dir := t.TempDir()
+	tm := newArchiveTaskManager(t, dir)
+	old := createFinishedTask(t, tm, 10*24*time.Hour, "old-png")
+
+	_, err := NewTaskCleaner(tm, nil).ArchiveOldTasks(0, false)
+	require.NoError(t, err)
+	indexFile := filepath.Join(dir, "cache", "archive", "archive_index.json")
+	require.FileExists(t, indexFile)
+
+	// 索引丢失后从归档文件重建，仍可读取归档的任务
+	require.NoError(t, os.Remove(indexFile))
+	rebuilt := newArchiveTaskManager(t, dir)
+	require.True(t, rebuilt.IsArchived(old.TaskID))
+	assert.FileExists(t, indexFile)
+
+	loaded, err := rebuilt.GetTaskSession(old.TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, old.StartTime.Unix(), loaded.StartTime.Unix())
+	data, err := rebuilt.ReadTaskFile(loaded, FileTypeClipboardOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old-png"), data)
+
+	// 全部任务过期后，重建的索引同样可以删除归档
+	removed, tasks, _, err := rebuilt.PruneArchives(time.Now(), false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{archiveName(old.StartTime)}, removed)
+	assert.Equal(t, 1, tasks)
+
+	// 旧版本保存在 cache/global 的索引迁移到归档目录
+	legacyFile := filepath.Join(dir, "cache", "global", "archive_index.json")
+	require.NoError(t, os.WriteFile(legacyFile, []byte(`[{"task_id":"legacy","archive":"2024-01.zip"}]`), 0644))
+	require.NoError(t, os.Remove(indexFile))
+	migrated := newArchiveTaskManager(t, dir)
+	assert.True(t, migrated.IsArchived("legacy"))
+	assert.NoFileExists(t, legacyFile)
+	assert.FileExists(t, indexFile)
+}
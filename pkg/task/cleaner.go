@@ -60,9 +60,21 @@ func (tc *TaskCleaner) CleanupOlderThan(days int) (*CleanupResult, error) {
 
 	cutoffTime := time.Now().AddDate(0, 0, -days)
 
-	// 清理任务目录
-	if err := tc.cleanupTaskDirectories(cutoffTime, result); err != nil {
-		return nil, fmt.Errorf("failed to cleanup task directories: %w", err)
+	if tc.taskManager.cacheConfig.CompressOldTasks {
+		// 旧任务先打包归档，过期的按整个归档删除
+		archived, err := tc.ArchiveOldTasks(time.Duration(days)*24*time.Hour, false)
+		if err != nil {
+			return nil, err
+		}
+		result.TasksArchived = archived.TasksArchived
+		result.ArchivesRemoved = archived.ArchivesRemoved
+		result.TasksCleaned = archived.TasksCleaned
+		result.BytesFreed = archived.BytesFreed
+	} else {
+		// 清理任务目录
+		if err := tc.cleanupTaskDirectories(cutoffTime, result); err != nil {
+			return nil, fmt.Errorf("failed to cleanup task directories: %w", err)
+		}
 	}
 
 	// 清理任务索引
@@ -83,8 +95,8 @@ func (tc *TaskCleaner) CleanupOlderThan(days int) (*CleanupResult, error) {
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 
-	tc.logger.Printf("Cleanup completed: removed %d task directories, freed %.2f MB space, took %v",
-		result.TasksCleaned, float64(result.BytesFreed)/(1024*1024), result.Duration)
+	tc.logger.Printf("Cleanup completed: removed %d tasks, archived %d tasks, freed %.2f MB space, took %v",
+		result.TasksCleaned, result.TasksArchived, float64(result.BytesFreed)/(1024*1024), result.Duration)
 
 	return result, nil
 }
@@ -110,6 +122,17 @@ func (tc *TaskCleaner) CleanupStatistics() (*CleanupStats, error) {
 
 // cleanupTaskDirectories 清理任务目录
 func (tc *TaskCleaner) cleanupTaskDirectories(cutoffTime time.Time, result *CleanupResult) error {
+	return tc.walkTaskDirectories(cutoffTime, func(taskDir string, _ *TaskSession) {
+		if err := tc.cleanupTaskDirectory(taskDir, result); err != nil {
+			tc.logger.Printf("Failed to cleanup task directory %s: %v", taskDir, err)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	})
+}
+
+// walkTaskDirectories 遍历最后活动时间早于 cutoffTime 的任务目录。
+// 无法读取任务信息时 session 为 nil，此时按目录修改时间判断。
+func (tc *TaskCleaner) walkTaskDirectories(cutoffTime time.Time, fn func(taskDir string, session *TaskSession)) error {
 	entries, err := os.ReadDir(tc.taskManager.tasksDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -127,47 +150,181 @@ func (tc *TaskCleaner) cleanupTaskDirectories(cutoffTime time.Time, result *Clea
 		taskDir := filepath.Join(tc.taskManager.tasksDir, taskID)
 
 		// 尝试加载任务信息以获取准确的任务时间
-		shouldCleanup := false
+		var session *TaskSession
+		var lastActivity time.Time
 
 		// 尝试从任务信息文件获取时间
 		taskInfoFile := filepath.Join(taskDir, "task_info.json")
 		if data, err := os.ReadFile(taskInfoFile); err == nil {
-			var session TaskSession
-			if err := json.Unmarshal(data, &session); err == nil {
-				// 检查任务的开始时间或结束时间
-				if session.EndTime.IsZero() {
-					// 如果没有结束时间，使用开始时间
-					shouldCleanup = session.StartTime.Before(cutoffTime)
-				} else {
-					// 使用结束时间
-					shouldCleanup = session.EndTime.Before(cutoffTime)
+			var info TaskSession
+			if err := json.Unmarshal(data, &info); err == nil {
+				session = &info
+				// 如果没有结束时间，使用开始时间
+				lastActivity = info.EndTime
+				if lastActivity.IsZero() {
+					lastActivity = info.StartTime
 				}
 			} else {
 				tc.logger.Printf("Failed to parse task info file %s: %v", taskInfoFile, err)
+				continue
 			}
 		} else {
 			// 如果无法读取任务信息文件，使用目录的修改时间作为备选
 			tc.logger.Printf("Failed to read task info file %s: %v, using directory mod time", taskInfoFile, err)
-			if info, err := entry.Info(); err == nil {
-				shouldCleanup = info.ModTime().Before(cutoffTime)
-			} else {
+			info, err := entry.Info()
+			if err != nil {
 				tc.logger.Printf("Failed to get directory info %s: %v", taskDir, err)
 				continue
 			}
+			lastActivity = info.ModTime()
 		}
 
-		// 检查是否需要清理
-		if shouldCleanup {
-			if err := tc.cleanupTaskDirectory(taskDir, result); err != nil {
-				tc.logger.Printf("Failed to cleanup task directory %s: %v", taskDir, err)
-				result.Errors = append(result.Errors, err.Error())
-			}
+		if lastActivity.Before(cutoffTime) {
+			fn(taskDir, session)
 		}
 	}
 
 	return nil
 }
 
+// archiveTaskDirectories 将早于 cutoffTime 的任务目录按月打包归档，预览模式只统计
+func (tc *TaskCleaner) archiveTaskDirectories(cutoffTime time.Time, dryRun bool, result *CleanupResult) error {
+	var sessions []*TaskSession
+	err := tc.walkTaskDirectories(cutoffTime, func(taskDir string, session *TaskSession) {
+		// 没有任务信息的目录无法归档，交给孤儿文件清理
+		if session == nil {
+			return
+		}
+		session.TaskID = filepath.Base(taskDir)
+		sessions = append(sessions, session)
+	})
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		result.TasksArchived = len(sessions)
+		return nil
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	archived, _, err := tc.taskManager.ArchiveTaskDirs(sessions)
+	result.TasksArchived += archived
+	if err != nil {
+		return err
+	}
+	tc.logger.Printf("Archived %d task directories", archived)
+	return nil
+}
+
+// pruneArchives 删除全部任务都早于 cutoffTime 的归档
+func (tc *TaskCleaner) pruneArchives(cutoffTime time.Time, dryRun bool, result *CleanupResult) error {
+	removed, tasks, freed, err := tc.taskManager.PruneArchives(cutoffTime, dryRun)
+	result.ArchivesRemoved += len(removed)
+	result.TasksCleaned += tasks
+	result.BytesFreed += freed
+	return err
+}
+
+// ArchiveOldTasks 打包归档超过 compress_after_days 的任务目录，并删除全部任务都早于
+// retention 之前的归档，retention 不大于 0 时只归档不删除。
+// 预览模式下不修改任何文件，只统计将要归档和删除的数量。
+func (tc *TaskCleaner) ArchiveOldTasks(retention time.Duration, dryRun bool) (*CleanupResult, error) {
+	result := &CleanupResult{
+		StartTime: time.Now(),
+	}
+
+	archiveCutoff := time.Now().AddDate(0, 0, -tc.taskManager.cacheConfig.GetCompressAfterDays())
+	cutoffTime := time.Now().Add(-retention)
+	// 已超过保留期的目录同样先归档，随后随归档一起删除
+	if retention > 0 && cutoffTime.After(archiveCutoff) {
+		archiveCutoff = cutoffTime
+	}
+
+	if err := tc.archiveTaskDirectories(archiveCutoff, dryRun, result); err != nil {
+		return nil, fmt.Errorf("failed to archive task directories: %w", err)
+	}
+
+	if retention > 0 {
+		if err := tc.pruneArchives(cutoffTime, dryRun, result); err != nil {
+			return nil, fmt.Errorf("failed to prune task archives: %w", err)
+		}
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	return result, nil
+}
+
+// EvictTaskDir 按缓存配额淘汰单个任务目录。启用 compress_old_tasks 时先打包归档，
+// 否则删除目录并移除对应的任务索引记录，避免索引指向已删除的目录
+func (tc *TaskCleaner) EvictTaskDir(taskDir string) (*CleanupResult, error) {
+	result := &CleanupResult{
+		StartTime: time.Now(),
+	}
+	taskID := filepath.Base(taskDir)
+
+	archived := false
+	if tc.taskManager.cacheConfig.CompressOldTasks {
+		// 没有任务信息的目录无法归档，直接删除
+		if session, err := tc.taskManager.loadTaskSession(taskID); err == nil {
+			session.TaskID = taskID
+			count, freed, err := tc.taskManager.ArchiveTaskDirs([]*TaskSession{session})
+			result.TasksArchived = count
+			result.BytesFreed = freed
+			if err != nil {
+				return nil, fmt.Errorf("failed to archive task directory: %w", err)
+			}
+			archived = count > 0
+		}
+	}
+
+	if !archived {
+		if err := tc.cleanupTaskDirectory(taskDir, result); err != nil {
+			return nil, err
+		}
+		if err := tc.removeTaskIndex(taskID, result); err != nil {
+			return nil, fmt.Errorf("failed to cleanup task index: %w", err)
+		}
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	return result, nil
+}
+
+// removeTaskIndex 移除单个任务的索引记录，成功的图片哈希记录按配置保留
+func (tc *TaskCleaner) removeTaskIndex(taskID string, result *CleanupResult) error {
+	tc.taskManager.mutex.Lock()
+	defer tc.taskManager.mutex.Unlock()
+
+	delete(tc.taskManager.sessions, taskID)
+
+	var keptIndexes []*TaskIndex
+	var removedIndexes []*TaskIndex
+	for _, index := range tc.taskManager.index {
+		if index.TaskID == taskID {
+			removedIndexes = append(removedIndexes, index)
+		} else {
+			keptIndexes = append(keptIndexes, index)
+		}
+	}
+	if len(removedIndexes) == 0 {
+		return nil
+	}
+
+	if tc.taskManager.cacheConfig.PreserveSuccessfulHashes {
+		keptIndexes = tc.preserveSuccessfulHashes(removedIndexes, keptIndexes)
+	}
+
+	tc.taskManager.index = keptIndexes
+	result.IndexEntriesRemoved += len(removedIndexes)
+
+	return tc.taskManager.saveIndex()
+}
+
 // cleanupTaskDirectory 清理单个任务目录
 func (tc *TaskCleaner) cleanupTaskDirectory(taskDir string, result *CleanupResult) error {
 	// 计算目录大小
@@ -200,7 +357,9 @@ func (tc *TaskCleaner) cleanupTaskIndex(cutoffTime time.Time, result *CleanupRes
 	var removedIndexes []*TaskIndex
 
 	for _, index := range tc.taskManager.index {
-		if index.StartTime.Before(cutoffTime) {
+		// 仍在归档中的任务保留索引，以便查看历史和回放
+		_, archived := tc.taskManager.archiveIndex[index.TaskID]
+		if index.StartTime.Before(cutoffTime) && !archived {
 			removedIndexes = append(removedIndexes, index)
 		} else {
 			keptIndexes = append(keptIndexes, index)
@@ -426,6 +585,7 @@ func (tc *TaskCleaner) statsTaskDirectories(stats *CleanupStats) error {
 	}
 
 	stats.TotalSizeBytes = totalSize
+	stats.ArchiveCount, stats.ArchiveSizeBytes = tc.taskManager.ArchiveStats()
 	return nil
 }
 
@@ -454,6 +614,8 @@ type CleanupResult struct {
 	EndTime              time.Time     `json:"end_time"`
 	Duration             time.Duration `json:"duration"`
 	TasksCleaned         int           `json:"tasks_cleaned"`
+	TasksArchived        int           `json:"tasks_archived,omitempty"`
+	ArchivesRemoved      int           `json:"archives_removed,omitempty"`
 	BytesFreed           int64         `json:"bytes_freed"`
 	IndexEntriesRemoved  int           `json:"index_entries_removed"`
 	CacheEntriesRemoved  int           `json:"cache_entries_removed"`
@@ -471,6 +633,8 @@ type CleanupStats struct {
 	TotalSizeBytes     int64 `json:"total_size_bytes"`
 	CacheFiles         int   `json:"cache_files"`
 	CacheSizeBytes     int64 `json:"cache_size_bytes"`
+	ArchiveCount       int   `json:"archive_count"`
+	ArchiveSizeBytes   int64 `json:"archive_size_bytes"`
 }
 
 // GetSizeMB 获取大小（MB）
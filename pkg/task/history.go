@@ -0,0 +1,106 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryPageSize 任务历史默认每页数量
+const DefaultHistoryPageSize = 20
+
+// HistoryFilter 任务历史查询条件，零值字段不参与过滤
+type HistoryFilter struct {
+	Status  TaskStatus `json:"status,omitempty"`  // 任务状态
+	Keyword string     `json:"keyword,omitempty"` // 匹配标题或任务ID，不区分大小写
+	Since   time.Time  `json:"since,omitempty"`   // 开始时间不早于
+	Until   time.Time  `json:"until,omitempty"`   // 开始时间早于
+}
+
+// Match 判断索引记录是否满足查询条件
+func (f HistoryFilter) Match(index *TaskIndex) bool {
+	if f.Status != "" && index.Status != f.Status {
+		return false
+	}
+	if !f.Since.IsZero() && index.StartTime.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !index.StartTime.Before(f.Until) {
+		return false
+	}
+	if keyword := strings.ToLower(strings.TrimSpace(f.Keyword)); keyword != "" {
+		if !strings.Contains(strings.ToLower(index.Title), keyword) &&
+			!strings.Contains(strings.ToLower(index.TaskID), keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// HistoryPage 一页任务历史
+type HistoryPage struct {
+	Items    []*TaskIndex `json:"items"`
+	Total    int          `json:"total"`     // 满足条件的记录总数
+	Page     int          `json:"page"`      // 页码，从 1 开始
+	PageSize int          `json:"page_size"` // 每页数量
+}
+
+// QueryHistory 按条件分页查询任务历史，结果按开始时间倒序
+func (tm *TaskManager) QueryHistory(filter HistoryFilter, page, pageSize int) *HistoryPage {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultHistoryPageSize
+	}
+
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	matched := make([]*TaskIndex, 0)
+	for _, index := range tm.index {
+		if filter.Match(index) {
+			matched = append(matched, index)
+		}
+	}
+
+	result := &HistoryPage{
+		Items:    []*TaskIndex{},
+		Total:    len(matched),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	start := (page - 1) * pageSize
+	if start >= len(matched) {
+		return result
+	}
+	end := start + pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	result.Items = append(result.Items, matched[start:end]...)
+	return result
+}
+
+// ReadTaskFile 读取任务会话中保存的文件
+func (tm *TaskManager) ReadTaskFile(session *TaskSession, fileType FileType) ([]byte, error) {
+	file, exists := session.Files[string(fileType)]
+	if !exists {
+		return nil, fmt.Errorf("任务 %s 没有 %s 文件", session.TaskID, fileType)
+	}
+
+	// 已归档的任务直接从归档文件中读取
+	if session.Archive != "" {
+		return tm.readArchivedFile(session.TaskID, file.Name)
+	}
+
+	// 按任务目录定位文件，任务目录被移动后仍可读取
+	data, err := os.ReadFile(filepath.Join(session.TaskDir, file.Name))
+	if err != nil {
+		return nil, fmt.Errorf("读取任务文件失败: %w", err)
+	}
+	return data, nil
+}
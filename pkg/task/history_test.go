@@ -0,0 +1,81 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTaskManager(t *testing.T) *TaskManager {
+	tm, err := NewTaskManager(t.TempDir(), models.CacheConfig{}, nil)
+	require.NoError(t, err)
+	return tm
+}
+
+func TestQueryHistory_FiltersAndPages(t *testing.T) {
+	tm := newTestTaskManager(t)
+	base := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+
+	// 索引按时间倒序保存，最新的在前
+	for i := 9; i >= 0; i-- {
+		status := TaskStatusSuccess
+		if i%3 == 0 {
+			status = TaskStatusFailed
+		}
+		tm.index = append(tm.index, &TaskIndex{
+			TaskID:    base.Add(time.Duration(i) * time.Hour).Format("2006-01-02_150405"),
+			StartTime: base.Add(time.Duration(i) * time.Hour),
+			Status:    status,
+			Title:     []string{"周会", "Code Review", "提交报销"}[i%3],
+		})
+	}
+
+	page := tm.QueryHistory(HistoryFilter{}, 1, 4)
+	assert.Equal(t, 10, page.Total)
+	require.Len(t, page.Items, 4)
+	assert.True(t, page.Items[0].StartTime.After(page.Items[1].StartTime))
+
+	last := tm.QueryHistory(HistoryFilter{}, 3, 4)
+	assert.Len(t, last.Items, 2)
+
+	beyond := tm.QueryHistory(HistoryFilter{}, 5, 4)
+	assert.Equal(t, 10, beyond.Total)
+	assert.Empty(t, beyond.Items)
+
+	failed := tm.QueryHistory(HistoryFilter{Status: TaskStatusFailed}, 1, 0)
+	assert.Equal(t, 4, failed.Total)
+	assert.Equal(t, DefaultHistoryPageSize, failed.PageSize)
+	for _, item := range failed.Items {
+		assert.Equal(t, "周会", item.Title)
+	}
+
+	keyword := tm.QueryHistory(HistoryFilter{Keyword: "code review"}, 1, 0)
+	assert.Equal(t, 3, keyword.Total)
+
+	ranged := tm.QueryHistory(HistoryFilter{
+		Since: base.Add(2 * time.Hour),
+		Until: base.Add(5 * time.Hour),
+	}, 1, 0)
+	assert.Equal(t, 3, ranged.Total)
+}
+
+func TestReadTaskFile(t *testing.T) {
+	tm := newTestTaskManager(t)
+
+	session, err := tm.CreateTaskSession()
+	require.NoError(t, err)
+	require.NoError(t, tm.SaveFileToTask(session, FileTypeClipboardOriginal, []byte("png-data")))
+
+	loaded, err := tm.GetTaskSession(session.TaskID)
+	require.NoError(t, err)
+
+	data, err := tm.ReadTaskFile(loaded, FileTypeClipboardOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png-data"), data)
+
+	_, err = tm.ReadTaskFile(loaded, FileTypeDifyResponse)
+	assert.Error(t, err)
+}
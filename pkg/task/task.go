@@ -27,8 +27,12 @@ type TaskSession struct {
 	DifySuccess  bool                   `json:"dify_success"`
 	TodoSuccess  bool                   `json:"todo_success"`
 	ErrorMessage string                 `json:"error_message,omitempty"`
+	TodoTaskID   string                 `json:"todo_task_id,omitempty"`  // Microsoft Todo 任务ID
+	TodoListID   string                 `json:"todo_list_id,omitempty"`  // Microsoft Todo 列表ID
+	AttachmentID string                 `json:"attachment_id,omitempty"` // 上传的截图附件ID
 	Files        map[string]TaskFile    `json:"files"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Archive      string                 `json:"-"` // 所在归档文件名，未归档时为空
 }
 
 // TaskStatus 任务状态
@@ -74,6 +78,8 @@ type TaskIndex struct {
 	FileCount   int       `json:"file_count"`  // 文件数量
 	DifySuccess bool      `json:"dify_success"`
 	TodoSuccess bool      `json:"todo_success"`
+	TodoTaskID  string    `json:"todo_task_id,omitempty"` // Microsoft Todo 任务ID
+	Archive     string    `json:"archive,omitempty"`      // 所在归档文件名，未归档时为空
 }
 
 
@@ -83,7 +89,11 @@ type TaskManager struct {
 	tasksDir      string
 	globalDir     string
 	indexFile     string
+	archiveDir    string
+	archiveIndexFile string
+	archiveIndex  map[string]*ArchiveEntry
 	mutex         sync.RWMutex
+	pendingSaves  sync.WaitGroup // 异步保存索引的任务
 	sessions      map[string]*TaskSession
 	index         []*TaskIndex
 	cacheConfig   models.CacheConfig
@@ -101,6 +111,9 @@ func NewTaskManager(baseDir string, cacheConfig models.CacheConfig, logger *log.
 		tasksDir:    filepath.Join(baseDir, "cache", "tasks"),
 		globalDir:   filepath.Join(baseDir, "cache", "global"),
 		indexFile:   filepath.Join(baseDir, "cache", "global", "task_index.json"),
+		archiveDir:  filepath.Join(baseDir, "cache", "archive"),
+		archiveIndexFile: filepath.Join(baseDir, "cache", "archive", "archive_index.json"),
+		archiveIndex: make(map[string]*ArchiveEntry),
 		sessions:    make(map[string]*TaskSession),
 		index:       make([]*TaskIndex, 0),
 		cacheConfig: cacheConfig,
@@ -117,11 +130,21 @@ func NewTaskManager(baseDir string, cacheConfig models.CacheConfig, logger *log.
 		tm.logf("加载任务索引失败: %v", err)
 	}
 
+	// 加载归档索引
+	if err := tm.loadArchiveIndex(); err != nil {
+		tm.logf("加载归档索引失败: %v", err)
+	}
+
 	return tm, nil
 }
 
 // generateTaskID 生成唯一的任务ID
 func (tm *TaskManager) generateTaskID() string {
+	return NewTaskID()
+}
+
+// NewTaskID 生成唯一的任务ID（时间戳加短哈希），也用作没有任务会话的处理的日志关联 ID
+func NewTaskID() string {
 	timestamp := time.Now().Format("2006-01-02_150405")
 
 	// 使用当前时间的微秒和进程ID生成哈希
@@ -221,6 +244,13 @@ func (tm *TaskManager) UpdateTaskStatus(session *TaskSession, status TaskStatus,
 		tm.logf("更新任务索引失败: %v", err)
 	}
 
+	// 已结束的会话已保存到磁盘，从内存中移除，需要时由 GetTaskSession 重新加载
+	if status != TaskStatusRunning {
+		tm.mutex.Lock()
+		delete(tm.sessions, session.TaskID)
+		tm.mutex.Unlock()
+	}
+
 	tm.logf("任务 %s 状态更新为: %s", session.TaskID, status)
 }
 
@@ -282,6 +312,20 @@ func (tm *TaskManager) SetTodoResult(session *TaskSession, success bool, todoDat
 	return nil
 }
 
+// SetTodoTaskInfo 记录创建的 Microsoft Todo 任务及截图附件信息
+func (tm *TaskManager) SetTodoTaskInfo(session *TaskSession, taskID, listID, attachmentID string) {
+	session.TodoTaskID = taskID
+	session.TodoListID = listID
+	session.AttachmentID = attachmentID
+	if attachmentID != "" {
+		session.Metadata["attachment_uploaded"] = time.Now()
+	}
+
+	if err := tm.saveTaskInfo(session); err != nil {
+		tm.logf("设置Todo任务信息失败: %v", err)
+	}
+}
+
 // GetTaskSession 获取任务会话
 func (tm *TaskManager) GetTaskSession(taskID string) (*TaskSession, error) {
 	tm.mutex.RLock()
@@ -361,6 +405,11 @@ func (tm *TaskManager) saveTaskInfo(session *TaskSession) error {
 	return nil
 }
 
+// GetCacheConfig 获取缓存配置
+func (tm *TaskManager) GetCacheConfig() models.CacheConfig {
+	return tm.cacheConfig
+}
+
 // loadTaskSession 从文件加载任务会话
 func (tm *TaskManager) loadTaskSession(taskID string) (*TaskSession, error) {
 	taskDir := filepath.Join(tm.tasksDir, taskID)
@@ -368,6 +417,10 @@ func (tm *TaskManager) loadTaskSession(taskID string) (*TaskSession, error) {
 
 	data, err := os.ReadFile(infoFile)
 	if err != nil {
+		// 已归档的任务从归档文件中读取
+		if os.IsNotExist(err) && tm.IsArchived(taskID) {
+			return tm.loadArchivedSession(taskID)
+		}
 		return nil, fmt.Errorf("读取任务信息文件失败: %w", err)
 	}
 
@@ -376,10 +429,12 @@ func (tm *TaskManager) loadTaskSession(taskID string) (*TaskSession, error) {
 		return nil, fmt.Errorf("解析任务信息文件失败: %w", err)
 	}
 
-	// 添加到内存缓存
-	tm.mutex.Lock()
-	tm.sessions[taskID] = &session
-	tm.mutex.Unlock()
+	// 只缓存仍在运行的会话，已结束的会话每次从文件读取
+	if session.Status == TaskStatusRunning {
+		tm.mutex.Lock()
+		tm.sessions[taskID] = &session
+		tm.mutex.Unlock()
+	}
 
 	return &session, nil
 }
@@ -427,6 +482,7 @@ func (tm *TaskManager) updateIndex(session *TaskSession) error {
 		FileCount:   fileCount,
 		DifySuccess: session.DifySuccess,
 		TodoSuccess: session.TodoSuccess,
+		TodoTaskID:  session.TodoTaskID,
 	}
 
 	tm.mutex.Lock()
@@ -447,9 +503,14 @@ func (tm *TaskManager) updateIndex(session *TaskSession) error {
 		tm.index = append([]*TaskIndex{index}, tm.index...)
 	}
 
-	// 异步保存索引文件
+	// 异步保存索引文件，持有读锁避免与归档、清理同时修改索引
+	tm.pendingSaves.Add(1)
 	go func() {
-		if err := tm.saveIndex(); err != nil {
+		defer tm.pendingSaves.Done()
+		tm.mutex.RLock()
+		err := tm.saveIndex()
+		tm.mutex.RUnlock()
+		if err != nil {
 			tm.logf("保存任务索引失败: %v", err)
 		}
 	}()
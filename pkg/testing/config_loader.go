@@ -5,8 +5,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/allanpk716/to_icalendar/pkg/config"
 	"github.com/allanpk716/to_icalendar/pkg/models"
-	"gopkg.in/yaml.v3"
 )
 
 // ConfigLoader 提供统一的配置加载和验证功能
@@ -33,20 +33,13 @@ func LoadConfigFromFile(configPath string) (*models.ServerConfig, error) {
 		return nil, fmt.Errorf("配置文件不存在: %s", configPath)
 	}
 
-	// 读取文件
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %w", err)
-	}
-
-	// 解析YAML
-	var config models.ServerConfig
-	err = yaml.Unmarshal(data, &config)
+	// 解析YAML，展开环境变量、覆盖和密钥引用
+	serverConfig, err := config.NewConfigManager().ReadServerConfig(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("解析YAML配置失败: %w", err)
 	}
 
-	return &config, nil
+	return serverConfig, nil
 }
 
 // ValidateConfigFile 验证配置文件
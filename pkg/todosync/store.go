@@ -0,0 +1,248 @@
+package todosync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyncedTask 本地镜像中的任务记录
+type SyncedTask struct {
+	ID             string    `json:"id"`
+	ListID         string    `json:"list_id"`
+	ListName       string    `json:"list_name"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description,omitempty"`
+	Status         string    `json:"status"`
+	Importance     string    `json:"importance,omitempty"`
+	DueDateTime    string    `json:"due_date_time,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastModifiedAt time.Time `json:"last_modified_at,omitempty"`
+	CompletedAt    time.Time `json:"completed_at,omitempty"`
+	Deleted        bool      `json:"deleted"`
+	DeletedAt      time.Time `json:"deleted_at,omitempty"`
+	SyncedAt       time.Time `json:"synced_at"`
+}
+
+// IsCompleted 任务是否已完成
+func (t *SyncedTask) IsCompleted() bool {
+	return t.Status == "completed"
+}
+
+// ListState 单个任务列表的同步状态
+type ListState struct {
+	ListID     string                 `json:"list_id"`
+	ListName   string                 `json:"list_name"`
+	DeltaLink  string                 `json:"delta_link,omitempty"`
+	LastSyncAt time.Time              `json:"last_sync_at"`
+	Tasks      map[string]*SyncedTask `json:"tasks"`
+}
+
+// Clone 深拷贝同步状态，修改副本不影响存储中的状态
+func (st *ListState) Clone() *ListState {
+	clone := *st
+	clone.Tasks = make(map[string]*SyncedTask, len(st.Tasks))
+	for id, task := range st.Tasks {
+		taskCopy := *task
+		clone.Tasks[id] = &taskCopy
+	}
+	return &clone
+}
+
+// Store 任务同步本地存储，每个列表保存为一个 JSON 文件
+type Store struct {
+	dir    string
+	mutex  sync.RWMutex
+	states map[string]*ListState
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// NewStore 创建同步存储并加载已有数据
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建同步缓存目录失败: %w", err)
+	}
+
+	store := &Store{
+		dir:    dir,
+		states: make(map[string]*ListState),
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// GetListState 获取列表同步状态的副本，不存在时返回新的空状态
+// 修改副本后通过 SaveListState 保存，避免并发读取时看到修改了一半的状态
+func (s *Store) GetListState(listID, listName string) *ListState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if state, exists := s.states[listID]; exists {
+		return state.Clone()
+	}
+
+	return &ListState{
+		ListID:   listID,
+		ListName: listName,
+		Tasks:    make(map[string]*SyncedTask),
+	}
+}
+
+// SaveListState 保存列表同步状态
+func (s *Store) SaveListState(state *ListState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化同步状态失败: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 原子写入
+	filePath := s.listFilePath(state.ListID)
+	tempFile := filePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("写入临时同步文件失败: %w", err)
+	}
+	if err := os.Rename(tempFile, filePath); err != nil {
+		os.Remove(tempFile) // 清理临时文件
+		return fmt.Errorf("重命名同步文件失败: %w", err)
+	}
+
+	s.states[state.ListID] = state.Clone()
+	return nil
+}
+
+// Lists 获取所有已同步列表的状态
+func (s *Store) Lists() []*ListState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	lists := make([]*ListState, 0, len(s.states))
+	for _, state := range s.states {
+		lists = append(lists, state)
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].ListName < lists[j].ListName
+	})
+	return lists
+}
+
+// RecentTasks 获取最近修改的任务（不含已删除任务），按修改时间倒序
+func (s *Store) RecentTasks(limit int) []*SyncedTask {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var tasks []*SyncedTask
+	for _, state := range s.states {
+		for _, task := range state.Tasks {
+			if !task.Deleted {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return taskSortTime(tasks[i]).After(taskSortTime(tasks[j]))
+	})
+
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks
+}
+
+// FindTasks 按列表名称和标题关键词查找任务，供去重等场景使用
+// listName 为空表示查找所有列表
+func (s *Store) FindTasks(listName, titleKeyword string, incompleteOnly bool) []*SyncedTask {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keyword := strings.ToLower(titleKeyword)
+	var tasks []*SyncedTask
+	for _, state := range s.states {
+		if listName != "" && state.ListName != listName {
+			continue
+		}
+		for _, task := range state.Tasks {
+			if task.Deleted || (incompleteOnly && task.IsCompleted()) {
+				continue
+			}
+			if keyword != "" && !strings.Contains(strings.ToLower(task.Title), keyword) {
+				continue
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// FindDuplicate 查找列表中标题相同（忽略大小写）且截止日期相同的任务，没有时返回 nil
+// date 为 YYYY-MM-DD 格式，为空时只比较标题
+func (s *Store) FindDuplicate(listName, title, date string, incompleteOnly bool) *SyncedTask {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil
+	}
+
+	for _, task := range s.FindTasks(listName, title, incompleteOnly) {
+		if !strings.EqualFold(strings.TrimSpace(task.Title), title) {
+			continue
+		}
+		if date != "" && !strings.HasPrefix(task.DueDateTime, date) {
+			continue
+		}
+		return task
+	}
+	return nil
+}
+
+// load 加载目录下的所有列表状态
+func (s *Store) load() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "list_*.json"))
+	if err != nil {
+		return fmt.Errorf("读取同步缓存目录失败: %w", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("读取同步文件失败: %w", err)
+		}
+
+		var state ListState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("解析同步文件 %s 失败: %w", filepath.Base(file), err)
+		}
+		if state.Tasks == nil {
+			state.Tasks = make(map[string]*SyncedTask)
+		}
+		s.states[state.ListID] = &state
+	}
+
+	return nil
+}
+
+// listFilePath 获取列表状态文件路径
+func (s *Store) listFilePath(listID string) string {
+	return filepath.Join(s.dir, "list_"+unsafeFileChars.ReplaceAllString(listID, "_")+".json")
+}
+
+// taskSortTime 获取任务排序使用的时间
+func taskSortTime(task *SyncedTask) time.Time {
+	if !task.LastModifiedAt.IsZero() {
+		return task.LastModifiedAt
+	}
+	return task.CreatedAt
+}
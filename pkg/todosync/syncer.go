@@ -0,0 +1,203 @@
+package todosync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/allanpk716/to_icalendar/pkg/logger"
+	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
+)
+
+// DeltaClient 增量同步所需的 Microsoft Todo 客户端接口
+type DeltaClient interface {
+	ListTaskLists(ctx context.Context) ([]microsofttodo.TaskListInfo, error)
+	GetTaskDelta(ctx context.Context, listID, deltaLink string) (*microsofttodo.DeltaResult, error)
+}
+
+// ListSyncResult 单个列表的同步结果
+type ListSyncResult struct {
+	ListID     string `json:"list_id"`
+	ListName   string `json:"list_name"`
+	FullSync   bool   `json:"full_sync"` // 是否为全量同步
+	Added      int    `json:"added"`
+	Updated    int    `json:"updated"`
+	Completed  int    `json:"completed"`
+	Deleted    int    `json:"deleted"`
+	TotalTasks int    `json:"total_tasks"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SyncResult 同步结果
+type SyncResult struct {
+	Lists     []*ListSyncResult `json:"lists"`
+	StartTime time.Time         `json:"start_time"`
+	Duration  time.Duration     `json:"duration"`
+}
+
+// HasErrors 是否有列表同步失败
+func (r *SyncResult) HasErrors() bool {
+	for _, list := range r.Lists {
+		if list.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Syncer 基于 Graph 增量查询的任务同步器
+type Syncer struct {
+	client DeltaClient
+	store  *Store
+	now    func() time.Time
+}
+
+// NewSyncer 创建同步器
+func NewSyncer(client DeltaClient, store *Store) *Syncer {
+	return &Syncer{
+		client: client,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// SyncAll 同步指定名称的列表，listNames 为空时同步全部列表
+// fullSync 为 true 时忽略已保存的 deltaLink，重新全量同步
+func (s *Syncer) SyncAll(ctx context.Context, listNames []string, fullSync bool) (*SyncResult, error) {
+	result := &SyncResult{StartTime: s.now()}
+
+	lists, err := s.client.ListTaskLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取任务列表失败: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(listNames))
+	for _, name := range listNames {
+		wanted[name] = true
+	}
+
+	for _, list := range lists {
+		if len(wanted) > 0 && !wanted[list.DisplayName] {
+			continue
+		}
+		delete(wanted, list.DisplayName)
+
+		listResult, err := s.SyncList(ctx, list, fullSync)
+		if err != nil {
+			logger.Errorf("同步列表 '%s' 失败: %v", list.DisplayName, err)
+			listResult = &ListSyncResult{ListID: list.ID, ListName: list.DisplayName, Error: err.Error()}
+		}
+		result.Lists = append(result.Lists, listResult)
+	}
+
+	for name := range wanted {
+		result.Lists = append(result.Lists, &ListSyncResult{ListName: name, Error: "任务列表不存在"})
+	}
+
+	result.Duration = s.now().Sub(result.StartTime)
+	return result, nil
+}
+
+// SyncList 同步单个列表，并将变更写入本地存储
+func (s *Syncer) SyncList(ctx context.Context, list microsofttodo.TaskListInfo, fullSync bool) (*ListSyncResult, error) {
+	// GetListState 返回副本，修改完成后整体保存
+	state := s.store.GetListState(list.ID, list.DisplayName)
+	state.ListName = list.DisplayName
+
+	deltaLink := state.DeltaLink
+	if fullSync {
+		deltaLink = ""
+	}
+
+	delta, err := s.client.GetTaskDelta(ctx, list.ID, deltaLink)
+	if errors.Is(err, microsofttodo.ErrDeltaTokenExpired) && deltaLink != "" {
+		logger.Warnf("列表 '%s' 的增量令牌已失效，执行全量同步", list.DisplayName)
+		deltaLink = ""
+		delta, err = s.client.GetTaskDelta(ctx, list.ID, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListSyncResult{
+		ListID:   list.ID,
+		ListName: list.DisplayName,
+		FullSync: deltaLink == "",
+	}
+
+	// 全量同步时，未出现在结果中的任务视为已删除
+	seen := make(map[string]bool, len(delta.Changes))
+	now := s.now()
+
+	for _, change := range delta.Changes {
+		seen[change.Task.ID] = true
+		existing, exists := state.Tasks[change.Task.ID]
+
+		if change.Removed {
+			if exists && !existing.Deleted {
+				existing.Deleted = true
+				existing.DeletedAt = now
+				existing.SyncedAt = now
+				result.Deleted++
+			}
+			continue
+		}
+
+		task := newSyncedTask(change.Task, list, now)
+		switch {
+		case !exists || existing.Deleted:
+			result.Added++
+		case task.IsCompleted() && !existing.IsCompleted():
+			result.Completed++
+		default:
+			result.Updated++
+		}
+		state.Tasks[task.ID] = task
+	}
+
+	if result.FullSync {
+		for id, task := range state.Tasks {
+			if !seen[id] && !task.Deleted {
+				task.Deleted = true
+				task.DeletedAt = now
+				task.SyncedAt = now
+				result.Deleted++
+			}
+		}
+	}
+
+	state.DeltaLink = delta.DeltaLink
+	state.LastSyncAt = now
+	if err := s.store.SaveListState(state); err != nil {
+		return nil, err
+	}
+
+	for _, task := range state.Tasks {
+		if !task.Deleted {
+			result.TotalTasks++
+		}
+	}
+
+	logger.Infof("列表 '%s' 同步完成: 新增 %d, 更新 %d, 完成 %d, 删除 %d",
+		list.DisplayName, result.Added, result.Updated, result.Completed, result.Deleted)
+	return result, nil
+}
+
+// newSyncedTask 根据 Graph 任务创建本地任务记录
+func newSyncedTask(info microsofttodo.TaskInfo, list microsofttodo.TaskListInfo, now time.Time) *SyncedTask {
+	return &SyncedTask{
+		ID:             info.ID,
+		ListID:         list.ID,
+		ListName:       list.DisplayName,
+		Title:          info.Title,
+		Description:    info.Description,
+		Status:         info.Status,
+		Importance:     info.Importance,
+		DueDateTime:    info.DueDateTime,
+		CreatedAt:      info.CreatedAt,
+		LastModifiedAt: info.LastModifiedAt,
+		CompletedAt:    info.CompletedAt,
+		SyncedAt:       now,
+	}
+}
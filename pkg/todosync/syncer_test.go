@@ -0,0 +1,168 @@
+package todosync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/allanpk716/to_icalendar/pkg/microsofttodo"
+)
+
+// fakeDeltaClient 按顺序返回预设增量结果的测试客户端
+type fakeDeltaClient struct {
+	lists      []microsofttodo.TaskListInfo
+	responses  []*microsofttodo.DeltaResult
+	errs       []error
+	deltaLinks []string
+}
+
+func (f *fakeDeltaClient) ListTaskLists(ctx context.Context) ([]microsofttodo.TaskListInfo, error) {
+	return f.lists, nil
+}
+
+func (f *fakeDeltaClient) GetTaskDelta(ctx context.Context, listID, deltaLink string) (*microsofttodo.DeltaResult, error) {
+	f.deltaLinks = append(f.deltaLinks, deltaLink)
+	call := len(f.deltaLinks) - 1
+	if call < len(f.errs) && f.errs[call] != nil {
+		return nil, f.errs[call]
+	}
+	return f.responses[call], nil
+}
+
+func task(id, title, status string) microsofttodo.TaskDelta {
+	return microsofttodo.TaskDelta{Task: microsofttodo.TaskInfo{ID: id, Title: title, Status: status}}
+}
+
+func TestSyncer_AppliesIncrementalChanges(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	client := &fakeDeltaClient{
+		lists: []microsofttodo.TaskListInfo{{ID: "list-1", DisplayName: "Tasks"}},
+		responses: []*microsofttodo.DeltaResult{
+			{
+				Changes:   []microsofttodo.TaskDelta{task("a", "Report", "notStarted"), task("b", "Call Bob", "notStarted"), task("c", "Lunch", "notStarted")},
+				DeltaLink: "delta-1",
+			},
+			{
+				Changes: []microsofttodo.TaskDelta{
+					task("a", "Report", "completed"),
+					task("b", "Call Bob tomorrow", "notStarted"),
+					{Task: microsofttodo.TaskInfo{ID: "c"}, Removed: true},
+					task("d", "New task", "notStarted"),
+				},
+				DeltaLink: "delta-2",
+			},
+		},
+	}
+	syncer := NewSyncer(client, store)
+
+	first, err := syncer.SyncAll(context.Background(), nil, false)
+	require.NoError(t, err)
+	require.Len(t, first.Lists, 1)
+	assert.True(t, first.Lists[0].FullSync)
+	assert.Equal(t, 3, first.Lists[0].Added)
+
+	second, err := syncer.SyncAll(context.Background(), nil, false)
+	require.NoError(t, err)
+	result := second.Lists[0]
+	assert.False(t, result.FullSync)
+	assert.Equal(t, 1, result.Added)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Completed)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, 3, result.TotalTasks)
+	assert.Equal(t, []string{"", "delta-1"}, client.deltaLinks)
+
+	// 重新加载后状态应保持一致
+	reloaded, err := NewStore(store.dir)
+	require.NoError(t, err)
+	state := reloaded.GetListState("list-1", "Tasks")
+	assert.Equal(t, "delta-2", state.DeltaLink)
+	assert.True(t, state.Tasks["c"].Deleted)
+	assert.Len(t, reloaded.FindTasks("Tasks", "call", true), 1)
+	assert.Empty(t, reloaded.FindTasks("Tasks", "report", true))
+}
+
+func TestSyncer_ResyncsWhenDeltaTokenExpired(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.SaveListState(&ListState{
+		ListID:    "list-1",
+		ListName:  "Tasks",
+		DeltaLink: "stale",
+		Tasks: map[string]*SyncedTask{
+			"gone": {ID: "gone", Title: "Deleted while offline"},
+		},
+	}))
+
+	client := &fakeDeltaClient{
+		lists:     []microsofttodo.TaskListInfo{{ID: "list-1", DisplayName: "Tasks"}},
+		errs:      []error{microsofttodo.ErrDeltaTokenExpired},
+		responses: []*microsofttodo.DeltaResult{nil, {Changes: []microsofttodo.TaskDelta{task("a", "Report", "notStarted")}, DeltaLink: "fresh"}},
+	}
+
+	result, err := NewSyncer(client, store).SyncAll(context.Background(), []string{"Tasks", "Missing"}, false)
+	require.NoError(t, err)
+	require.Len(t, result.Lists, 2)
+	assert.True(t, result.Lists[0].FullSync)
+	assert.Equal(t, 1, result.Lists[0].Deleted)
+	assert.Equal(t, "任务列表不存在", result.Lists[1].Error)
+	assert.True(t, result.HasErrors())
+	assert.Equal(t, []string{"stale", ""}, client.deltaLinks)
+}
+
+func TestStore_GetListStateReturnsCopy(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.SaveListState(&ListState{
+		ListID:    "list-1",
+		ListName:  "Tasks",
+		DeltaLink: "delta-1",
+		Tasks:     map[string]*SyncedTask{"a": {ID: "a", Title: "Report"}},
+	}))
+
+	// 保存前修改副本不影响存储中的状态
+	state := store.GetListState("list-1", "Tasks")
+	state.DeltaLink = "delta-2"
+	state.Tasks["a"].Title = "Changed"
+	state.Tasks["b"] = &SyncedTask{ID: "b"}
+
+	current := store.GetListState("list-1", "Tasks")
+	assert.Equal(t, "delta-1", current.DeltaLink)
+	assert.Equal(t, "Report", current.Tasks["a"].Title)
+	assert.Len(t, current.Tasks, 1)
+
+	require.NoError(t, store.SaveListState(state))
+	assert.Equal(t, "delta-2", store.GetListState("list-1", "Tasks").DeltaLink)
+}
+
+func TestStore_FindDuplicate(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.SaveListState(&ListState{
+		ListID:   "list-1",
+		ListName: "Tasks",
+		Tasks: map[string]*SyncedTask{
+			"a": {ID: "a", Title: "Submit report", DueDateTime: "2025-03-14T00:00:00.0000000"},
+			"b": {ID: "b", Title: "Submit report draft", DueDateTime: "2025-03-14T00:00:00.0000000"},
+			"c": {ID: "c", Title: "Pay rent", Status: "completed", DueDateTime: "2025-03-01T00:00:00.0000000"},
+			"d": {ID: "d", Title: "Old task", Deleted: true},
+		},
+	}))
+
+	// 标题需要完全相同（忽略大小写），截止日期相同
+	found := store.FindDuplicate("Tasks", "submit report", "2025-03-14", true)
+	require.NotNil(t, found)
+	assert.Equal(t, "a", found.ID)
+	assert.Nil(t, store.FindDuplicate("Tasks", "Submit report", "2025-03-15", true))
+	assert.Nil(t, store.FindDuplicate("Other", "Submit report", "2025-03-14", true))
+
+	// 已完成任务只在不限制未完成时匹配，已删除任务不匹配
+	assert.Nil(t, store.FindDuplicate("Tasks", "Pay rent", "2025-03-01", true))
+	assert.NotNil(t, store.FindDuplicate("Tasks", "Pay rent", "2025-03-01", false))
+	assert.Nil(t, store.FindDuplicate("Tasks", "Old task", "", false))
+	assert.Nil(t, store.FindDuplicate("Tasks", " ", "", false))
+}
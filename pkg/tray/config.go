@@ -0,0 +1,127 @@
+package tray
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// MenuConfigFileName 托盘菜单配置文件名，位于配置目录 ~/.to_icalendar 下
+const MenuConfigFileName = "tray_menu.json"
+
+// 托盘菜单动作
+const (
+	ActionShowWindow       = "show_window"       // 显示主窗口
+	ActionProcessClipboard = "process_clipboard" // 立即处理剪贴板
+	ActionRecentTasks      = "recent_tasks"      // 最近任务（动态子菜单）
+	ActionOpenLastTask     = "open_last_task"    // 在 Microsoft To Do 中打开最近创建的任务
+	ActionToggleWatcher    = "toggle_watcher"    // 暂停/恢复剪贴板监听
+	ActionSwitchProfile    = "switch_profile"    // 切换配置（动态子菜单）
+	ActionTestConnection   = "test_connection"   // 运行连接测试
+	ActionCleanCache       = "clean_cache"       // 清理缓存
+	ActionQuit             = "quit"              // 退出
+)
+
+// KnownActions 托盘支持的全部动作
+var KnownActions = []string{
+	ActionShowWindow,
+	ActionProcessClipboard,
+	ActionRecentTasks,
+	ActionOpenLastTask,
+	ActionToggleWatcher,
+	ActionSwitchProfile,
+	ActionTestConnection,
+	ActionCleanCache,
+	ActionQuit,
+}
+
+// DefaultTrayMenu 返回默认托盘菜单
+func DefaultTrayMenu() *TrayMenu {
+	menu := NewTrayMenu()
+	menu.AddItem(NewMenuItem("show_window", "显示窗口", "显示主窗口", MenuTypeAction, ActionShowWindow, "", false, true, 10))
+	menu.AddItem(NewSeparatorMenuItem(15))
+	menu.AddItem(NewMenuItem("process_clipboard", "立即处理剪贴板", "读取当前剪贴板内容并创建任务", MenuTypeAction, ActionProcessClipboard, "", false, true, 20))
+	menu.AddItem(NewMenuItem("recent_tasks", "最近任务", "最近处理的任务", MenuTypeSubmenu, ActionRecentTasks, "", false, true, 30))
+	menu.AddItem(NewMenuItem("open_last_task", "在 To Do 中打开最近任务", "在浏览器中打开最近创建的 Microsoft To Do 任务", MenuTypeAction, ActionOpenLastTask, "", false, true, 40))
+
+	watcher := NewMenuItem("toggle_watcher", "自动监听剪贴板", "暂停或恢复剪贴板自动监听", MenuTypeAction, ActionToggleWatcher, "", false, true, 50)
+	watcher.Checkable = true
+	menu.AddItem(watcher)
+
+	menu.AddItem(NewSeparatorMenuItem(55))
+	menu.AddItem(NewMenuItem("switch_profile", "切换配置", "切换 server.yaml 配置", MenuTypeSubmenu, ActionSwitchProfile, "", false, true, 60))
+	menu.AddItem(NewMenuItem("test_connection", "运行连接测试", "测试配置和服务连接", MenuTypeAction, ActionTestConnection, "", false, true, 70))
+	menu.AddItem(NewMenuItem("clean_cache", "清理缓存", "清理过期的缓存文件", MenuTypeAction, ActionCleanCache, "", false, true, 80))
+	menu.AddItem(NewSeparatorMenuItem(85))
+	menu.AddItem(NewMenuItem("quit", "退出", "退出应用程序", MenuTypeAction, ActionQuit, "", false, true, 90))
+	return menu
+}
+
+// ValidateActions 检查菜单中的动作是否都受支持
+func (tm *TrayMenu) ValidateActions(known []string) error {
+	allowed := make(map[string]bool, len(known))
+	for _, action := range known {
+		allowed[action] = true
+	}
+	return validateActions(tm.Items, allowed)
+}
+
+// validateActions 递归检查动作
+func validateActions(items []MenuItem, allowed map[string]bool) error {
+	for _, item := range items {
+		if item.Action != "" && !allowed[item.Action] {
+			return fmt.Errorf("菜单项 %s 使用了未知动作: %s", item.ID, item.Action)
+		}
+		if err := validateActions(item.Children, allowed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadMenuConfig 从配置文件加载托盘菜单
+// 文件不存在时写入并返回默认菜单；文件无效时返回错误，调用方可回退到默认菜单
+func LoadMenuConfig(path string) (*TrayMenu, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		menu := DefaultTrayMenu()
+		if err := SaveMenuConfig(path, menu); err != nil {
+			return menu, fmt.Errorf("写入默认托盘菜单配置失败: %w", err)
+		}
+		return menu, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取托盘菜单配置失败: %w", err)
+	}
+
+	menu := NewTrayMenu()
+	if err := json.Unmarshal(data, menu); err != nil {
+		return nil, fmt.Errorf("解析托盘菜单配置失败: %w", err)
+	}
+	if err := menu.Validate(); err != nil {
+		return nil, err
+	}
+	if err := menu.ValidateActions(KnownActions); err != nil {
+		return nil, err
+	}
+
+	// 托盘必须保留退出入口，避免配置错误导致无法退出
+	if item, ok := menu.FindAction(ActionQuit); !ok || item.Hidden {
+		return nil, fmt.Errorf("托盘菜单必须包含可见的退出菜单项")
+	}
+
+	return menu, nil
+}
+
+// SaveMenuConfig 保存托盘菜单配置
+func SaveMenuConfig(path string, menu *TrayMenu) error {
+	data, err := json.MarshalIndent(menu, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化托盘菜单失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
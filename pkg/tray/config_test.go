@@ -0,0 +1,113 @@
+package tray
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTrayMenu(t *testing.T) {
+	menu := DefaultTrayMenu()
+	require.NoError(t, menu.Validate())
+	require.NoError(t, menu.ValidateActions(KnownActions))
+
+	for _, action := range KnownActions {
+		_, ok := menu.FindAction(action)
+		assert.True(t, ok, "default menu should include %s", action)
+	}
+
+	items := menu.VisibleItems()
+	assert.Equal(t, ActionShowWindow, items[0].Action)
+	assert.Equal(t, ActionQuit, items[len(items)-1].Action)
+}
+
+func TestLoadMenuConfig_WritesDefaultWhenMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), MenuConfigFileName)
+
+	menu, err := LoadMenuConfig(path)
+	require.NoError(t, err)
+	assert.Len(t, menu.Items, len(DefaultTrayMenu().Items))
+
+	_, err = os.Stat(path)
+	require.NoError(t, err, "default menu should be written for users to edit")
+
+	reloaded, err := LoadMenuConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, len(menu.Items), len(reloaded.Items))
+}
+
+func TestLoadMenuConfig_ReordersHidesAndGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), MenuConfigFileName)
+	config := `{
+  "items": [
+    {"id": "quit", "label": "退出", "type": "action", "action": "quit", "is_enabled": true, "order": 1},
+    {"type": "separator", "is_separator": true, "is_enabled": true, "order": 2},
+    {"id": "clean", "label": "清理缓存", "type": "action", "action": "clean_cache", "is_enabled": true, "order": 3, "hidden": true},
+    {"id": "tools", "label": "工具", "type": "submenu", "is_enabled": true, "order": 4, "children": [
+      {"id": "test", "label": "连接测试", "type": "action", "action": "test_connection", "shortcut": "Ctrl+T", "is_enabled": true, "order": 2},
+      {"id": "now", "label": "处理剪贴板", "type": "action", "action": "process_clipboard", "is_enabled": true, "order": 1}
+    ]}
+  ]
+}`
+	require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+
+	menu, err := LoadMenuConfig(path)
+	require.NoError(t, err)
+
+	items := menu.VisibleItems()
+	require.Len(t, items, 3)
+	assert.Equal(t, "quit", items[0].ID)
+	assert.True(t, items[1].IsSeparator)
+	assert.Equal(t, "tools", items[2].ID)
+	require.Len(t, items[2].Children, 2)
+	assert.Equal(t, "now", items[2].Children[0].ID)
+	assert.Equal(t, "连接测试\tCtrl+T", items[2].Children[1].DisplayLabel())
+}
+
+func TestLoadMenuConfig_RejectsInvalidMenus(t *testing.T) {
+	tests := map[string]string{
+		"unknown action": `{"items": [
+			{"id": "quit", "label": "退出", "type": "action", "action": "quit", "order": 1},
+			{"id": "x", "label": "X", "type": "action", "action": "format_disk", "order": 2}]}`,
+		"hidden quit": `{"items": [
+			{"id": "quit", "label": "退出", "type": "action", "action": "quit", "order": 1, "hidden": true}]}`,
+		"no quit": `{"items": [
+			{"id": "show", "label": "显示", "type": "action", "action": "show_window", "order": 1}]}`,
+		"empty submenu": `{"items": [
+			{"id": "quit", "label": "退出", "type": "action", "action": "quit", "order": 1},
+			{"id": "tools", "label": "工具", "type": "submenu", "order": 2}]}`,
+		"malformed": `{"items": [`,
+	}
+
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), MenuConfigFileName)
+			require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+
+			_, err := LoadMenuConfig(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVisibleItems_CollapsesSeparators(t *testing.T) {
+	menu := NewTrayMenu()
+	menu.AddItem(NewSeparatorMenuItem(1))
+	menu.AddItem(NewMenuItem("a", "A", "", MenuTypeAction, ActionShowWindow, "", false, true, 2))
+	menu.AddItem(NewSeparatorMenuItem(3))
+	hidden := NewMenuItem("b", "B", "", MenuTypeAction, ActionCleanCache, "", false, true, 4)
+	hidden.Hidden = true
+	menu.AddItem(hidden)
+	menu.AddItem(NewSeparatorMenuItem(5))
+	menu.AddItem(NewMenuItem("c", "C", "", MenuTypeAction, ActionQuit, "", false, true, 6))
+	menu.AddItem(NewSeparatorMenuItem(7))
+
+	items := menu.VisibleItems()
+	require.Len(t, items, 3)
+	assert.Equal(t, "a", items[0].ID)
+	assert.True(t, items[1].IsSeparator)
+	assert.Equal(t, "c", items[2].ID)
+}
@@ -1,7 +1,10 @@
 package tray
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -16,15 +19,18 @@ const (
 
 // MenuItem 托盘菜单项定义
 type MenuItem struct {
-	ID          string      `json:"id"`
-	Label       string      `json:"label"`
-	Tooltip     string      `json:"tooltip,omitempty"`
-	Type        MenuType    `json:"type"`
-	Action      string      `json:"action,omitempty"`
-	Shortcut    string      `json:"shortcut,omitempty"`
-	IsSeparator bool        `json:"is_separator"`
-	IsEnabled   bool        `json:"is_enabled"`
-	Order       int         `json:"order"`
+	ID          string     `json:"id"`
+	Label       string     `json:"label"`
+	Tooltip     string     `json:"tooltip,omitempty"`
+	Type        MenuType   `json:"type"`
+	Action      string     `json:"action,omitempty"`
+	Shortcut    string     `json:"shortcut,omitempty"`
+	IsSeparator bool       `json:"is_separator"`
+	IsEnabled   bool       `json:"is_enabled"`
+	Order       int        `json:"order"`
+	Hidden      bool       `json:"hidden,omitempty"`    // 隐藏的菜单项不显示
+	Checkable   bool       `json:"checkable,omitempty"` // 显示为可勾选的开关
+	Children    []MenuItem `json:"children,omitempty"`  // 子菜单项，动态子菜单由动作填充
 }
 
 // NewMenuItem 创建新的菜单项
@@ -68,9 +74,30 @@ func (mi *MenuItem) Validate() error {
 	if mi.Type == MenuTypeAction && mi.Action == "" && !mi.IsSeparator {
 		return fmt.Errorf("动作类型菜单项必须指定动作")
 	}
+	if mi.Type == MenuTypeSubmenu && mi.Action == "" && len(mi.Children) == 0 {
+		return fmt.Errorf("子菜单必须包含子菜单项或指定动态动作")
+	}
+	for _, child := range mi.Children {
+		if err := child.Validate(); err != nil {
+			return fmt.Errorf("子菜单项 %s 验证失败: %w", child.ID, err)
+		}
+	}
 	return nil
 }
 
+// IsVisible 菜单项是否显示
+func (mi *MenuItem) IsVisible() bool {
+	return !mi.Hidden
+}
+
+// DisplayLabel 显示用的标签，有快捷键时附加在标签后
+func (mi *MenuItem) DisplayLabel() string {
+	if mi.Shortcut == "" {
+		return mi.Label
+	}
+	return mi.Label + "\t" + mi.Shortcut
+}
+
 // SetEnabled 设置是否启用
 func (mi *MenuItem) SetEnabled(enabled bool) {
 	mi.IsEnabled = enabled
@@ -83,10 +110,10 @@ func (mi *MenuItem) SetLabel(label string) {
 
 // TrayMenu 托盘右键菜单配置
 type TrayMenu struct {
-	ID       string      `json:"id"`
-	AppID    string      `json:"app_id"`
-	Items    []MenuItem  `json:"items"`
-	IsActive bool        `json:"is_active"`
+	ID        string     `json:"id"`
+	AppID     string     `json:"app_id"`
+	Items     []MenuItem `json:"items"`
+	IsActive  bool       `json:"is_active"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 }
@@ -191,6 +218,62 @@ func (tm *TrayMenu) GetActionItems() []MenuItem {
 	return actionItems
 }
 
+// VisibleItems 获取按顺序排列的可见菜单项（包括子菜单）
+// 隐藏项被移除后，开头、结尾和连续的分隔符会被合并
+func (tm *TrayMenu) VisibleItems() []MenuItem {
+	return visibleItems(tm.Items)
+}
+
+// visibleItems 过滤并排序菜单项
+func visibleItems(items []MenuItem) []MenuItem {
+	sorted := make([]MenuItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	var result []MenuItem
+	for _, item := range sorted {
+		if !item.IsVisible() {
+			continue
+		}
+		if item.IsSeparator {
+			if len(result) == 0 || result[len(result)-1].IsSeparator {
+				continue
+			}
+			result = append(result, item)
+			continue
+		}
+		if len(item.Children) > 0 {
+			item.Children = visibleItems(item.Children)
+		}
+		result = append(result, item)
+	}
+
+	if len(result) > 0 && result[len(result)-1].IsSeparator {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+// FindAction 查找指定动作的第一个菜单项（包括子菜单）
+func (tm *TrayMenu) FindAction(action string) (*MenuItem, bool) {
+	return findAction(tm.Items, action)
+}
+
+// findAction 递归查找动作
+func findAction(items []MenuItem, action string) (*MenuItem, bool) {
+	for i := range items {
+		if items[i].Action == action {
+			return &items[i], true
+		}
+		if item, ok := findAction(items[i].Children, action); ok {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
 // GetEnabledItems 获取所有启用的菜单项
 func (tm *TrayMenu) GetEnabledItems() []MenuItem {
 	var enabledItems []MenuItem
@@ -200,4 +283,13 @@ func (tm *TrayMenu) GetEnabledItems() []MenuItem {
 		}
 	}
 	return enabledItems
-}
\ No newline at end of file
+}
+
+// 辅助函数
+
+// generateID 生成唯一ID
+func generateID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
@@ -312,10 +312,11 @@ func TestTrayMenu_Validate(t *testing.T) {
 				ID: "valid-menu",
 				Items: []MenuItem{
 					{
-						ID:    "exit",
-						Label: "退出",
-						Type:  MenuTypeAction,
-						Order: 1,
+						ID:     "exit",
+						Label:  "退出",
+						Type:   MenuTypeAction,
+						Action: ActionQuit,
+						Order:  1,
 					},
 				},
 			},
@@ -439,4 +440,4 @@ func TestTrayMenu_GetEnabledItems(t *testing.T) {
 
 	assert.Equal(t, 1, len(enabledItems))
 	assert.Equal(t, enabledItem.ID, enabledItems[0].ID)
-}
\ No newline at end of file
+}